@@ -10,8 +10,11 @@ import (
 
 	_ "github.com/tursodatabase/go-libsql"
 
+	"github.com/emiliopalmerini/mclaude/internal/adapters/git"
 	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
 	"github.com/emiliopalmerini/mclaude/internal/migrate"
+	"github.com/emiliopalmerini/mclaude/internal/pricing"
 	"github.com/emiliopalmerini/mclaude/internal/web"
 )
 
@@ -70,6 +73,18 @@ func run() error {
 		repos.Quality, repos.PlanConfig, repos.Experiments,
 		repos.Pricing, repos.Sessions, repos.Metrics,
 		repos.Stats, repos.Projects,
+		pricing.NewRecomputer(repos.Costs, repos.ModelUsage, repos.Subagents, repos.Pricing, nil),
+		ingest.NewRecorder(ingest.Deps{
+			Projects:    repos.Projects,
+			Experiments: repos.Experiments,
+			Pricing:     repos.Pricing,
+			Recordings:  repos.Recordings,
+			Live:        repos.Live,
+			States:      repos.States,
+			ToolRules:   repos.ToolRules,
+			PlanConfig:  repos.PlanConfig,
+			Git:         git.NewInspector(),
+		}),
 	)
 	return server.Start(ctx)
 }
@@ -0,0 +1,162 @@
+// Package git reads the state and history of git repositories by running
+// the git command.
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// commandTimeout bounds each git command, so a slow repository can't hold up
+// recording a session.
+const commandTimeout = 5 * time.Second
+
+// Inspector runs git in the directory being inspected.
+type Inspector struct{}
+
+func NewInspector() *Inspector {
+	return &Inspector{}
+}
+
+// Inspect reads the commits the repository's user made on HEAD between since
+// and until by commit date, ignoring commits by others that were pulled in
+// meanwhile, and where HEAD stood around them. The branch and working tree
+// state are only read while HEAD is still where it was at until: once later
+// commits have moved it, they describe later work. A directory outside any
+// repository, or a system without git, yields nil.
+func (i *Inspector) Inspect(ctx context.Context, dir string, since, until time.Time) (*domain.GitContext, error) {
+	if _, err := i.git(ctx, dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, nil
+	}
+
+	gc := &domain.GitContext{}
+
+	// Fails in a repository without commits
+	head, err := i.git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return i.readWorkTree(ctx, dir, gc)
+	}
+
+	// Empty if HEAD's history starts after until
+	headAtUntil, err := i.git(ctx, dir, "rev-list", "-1",
+		"--before="+until.UTC().Format(time.RFC3339), "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	gc.HeadBefore, gc.HeadAfter = headAtUntil, headAtUntil
+
+	args := []string{"log", "--shortstat",
+		"--since=" + since.UTC().Format(time.RFC3339),
+		"--until=" + until.UTC().Format(time.RFC3339),
+		"--format=" + logFormat}
+	// Without a configured user every author's commits count
+	if email, err := i.git(ctx, dir, "config", "user.email"); err == nil && email != "" {
+		args = append(args, "--fixed-strings", "--author=<"+email+">")
+	}
+	log, err := i.git(ctx, dir, append(args, "HEAD")...)
+	if err != nil {
+		return nil, err
+	}
+	commits, oldestParent := parseLog(log)
+	gc.Commits = commits
+	if len(commits) > 0 {
+		gc.HeadBefore = oldestParent
+	}
+
+	if head != headAtUntil {
+		return gc, nil
+	}
+	return i.readWorkTree(ctx, dir, gc)
+}
+
+// readWorkTree adds the current branch and working tree state to gc.
+func (i *Inspector) readWorkTree(ctx context.Context, dir string, gc *domain.GitContext) (*domain.GitContext, error) {
+	// Fails on a detached HEAD, leaving the branch empty
+	if branch, err := i.git(ctx, dir, "symbolic-ref", "--short", "-q", "HEAD"); err == nil {
+		gc.Branch = branch
+	}
+
+	status, err := i.git(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	dirty := status != ""
+	gc.Dirty = &dirty
+	return gc, nil
+}
+
+func (i *Inspector) git(ctx context.Context, dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// logFormat starts each commit with a record separator, followed by its
+// fields separated by unit separators. --shortstat adds the diff stats on
+// the lines after it.
+const logFormat = "%x1e%H%x1f%P%x1f%an%x1f%cI%x1f%s"
+
+var (
+	filesChangedPattern = regexp.MustCompile(`(\d+) files? changed`)
+	insertionsPattern   = regexp.MustCompile(`(\d+) insertions?\(\+\)`)
+	deletionsPattern    = regexp.MustCompile(`(\d+) deletions?\(-\)`)
+)
+
+// parseLog reads the commits of a log written with logFormat, newest first
+// as git lists them, and returns them oldest first along with the first
+// parent of the oldest one ("" for a root commit).
+func parseLog(log string) (commits []*domain.SessionCommit, oldestParent string) {
+	records := strings.Split(log, "\x1e")
+	for j := len(records) - 1; j >= 0; j-- {
+		record := strings.TrimSpace(records[j])
+		if record == "" {
+			continue
+		}
+		header, stats, _ := strings.Cut(record, "\n")
+		fields := strings.Split(header, "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+
+		committedAt, _ := time.Parse(time.RFC3339, fields[3])
+		commit := &domain.SessionCommit{
+			SHA:          fields[0],
+			Author:       fields[2],
+			CommittedAt:  committedAt.UTC(),
+			Subject:      fields[4],
+			FilesChanged: matchCount(filesChangedPattern, stats),
+			Insertions:   matchCount(insertionsPattern, stats),
+			Deletions:    matchCount(deletionsPattern, stats),
+		}
+		if len(commits) == 0 {
+			oldestParent, _, _ = strings.Cut(fields[1], " ")
+		}
+		commits = append(commits, commit)
+	}
+	return commits, oldestParent
+}
+
+func matchCount(pattern *regexp.Regexp, s string) int64 {
+	m := pattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.ParseInt(m[1], 10, 64)
+	return n
+}
@@ -0,0 +1,182 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// initRepo creates a repository with a fixed author, skipping the test when
+// git isn't installed.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run(t, dir, "init", "-q", "-b", "main")
+	run(t, dir, "config", "user.name", "Test Author")
+	run(t, dir, "config", "user.email", "test@example.com")
+	run(t, dir, "config", "commit.gpgsign", "false")
+	return dir
+}
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return string(out)
+}
+
+func commit(t *testing.T, dir, file, content, subject string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "add", file)
+	run(t, dir, "commit", "-q", "-m", subject)
+	return strings.TrimSpace(run(t, dir, "rev-parse", "HEAD"))
+}
+
+func TestInspect(t *testing.T) {
+	dir := initRepo(t)
+	ctx := context.Background()
+	since := time.Now().Add(-time.Minute)
+
+	first := commit(t, dir, "a.txt", "one\n", "Add a")
+	second := commit(t, dir, "b.txt", "one\ntwo\nthree\n", "Add b")
+
+	gc, err := NewInspector().Inspect(ctx, dir, since, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if gc == nil {
+		t.Fatal("Inspect() = nil")
+	}
+	if gc.Branch != "main" {
+		t.Errorf("Branch = %q, want main", gc.Branch)
+	}
+	if gc.Dirty == nil || *gc.Dirty {
+		t.Errorf("Dirty = %v, want false", gc.Dirty)
+	}
+	if gc.HeadAfter != second {
+		t.Errorf("HeadAfter = %q, want %q", gc.HeadAfter, second)
+	}
+	// The first commit is the repository's root
+	if gc.HeadBefore != "" {
+		t.Errorf("HeadBefore = %q, want empty", gc.HeadBefore)
+	}
+	if len(gc.Commits) != 2 {
+		t.Fatalf("Commits = %d, want 2", len(gc.Commits))
+	}
+	if gc.Commits[0].SHA != first || gc.Commits[1].SHA != second {
+		t.Errorf("Commits not oldest first: %s, %s", gc.Commits[0].SHA, gc.Commits[1].SHA)
+	}
+	c := gc.Commits[1]
+	if c.Subject != "Add b" || c.Author != "Test Author" {
+		t.Errorf("Commit = %q by %q", c.Subject, c.Author)
+	}
+	if c.FilesChanged != 1 || c.Insertions != 3 || c.Deletions != 0 {
+		t.Errorf("Commit stats = %d files +%d -%d, want 1 files +3 -0", c.FilesChanged, c.Insertions, c.Deletions)
+	}
+
+	// A change left uncommitted marks the tree dirty
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gc, err = NewInspector().Inspect(ctx, dir, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if gc.Dirty == nil || !*gc.Dirty {
+		t.Errorf("Dirty = %v, want true", gc.Dirty)
+	}
+	// No commits in the window leaves HEAD where it was
+	if len(gc.Commits) != 0 || gc.HeadBefore != second || gc.HeadAfter != second {
+		t.Errorf("got %d commits, HEAD %q..%q, want none at %q", len(gc.Commits), gc.HeadBefore, gc.HeadAfter, second)
+	}
+}
+
+// commitAt commits file as author at the given author and commit date.
+func commitAt(t *testing.T, dir, file, content, subject, author string, at time.Time) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run(t, dir, "add", file)
+	date := at.Format(time.RFC3339)
+	cmd := exec.Command("git", "-C", dir, "commit", "-q", "-m", subject, "--author", author, "--date", date)
+	cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v: %s", err, out)
+	}
+	return strings.TrimSpace(run(t, dir, "rev-parse", "HEAD"))
+}
+
+func TestInspect_SessionWindow(t *testing.T) {
+	dir := initRepo(t)
+	start := time.Date(2026, 1, 17, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	me := "Test Author <test@example.com>"
+
+	base := commitAt(t, dir, "a.txt", "one\n", "Base", me, start.Add(-time.Hour))
+	own := commitAt(t, dir, "b.txt", "two\n", "Session work", me, start.Add(10*time.Minute))
+	// Pulled in while the session ran
+	pulled := commitAt(t, dir, "c.txt", "three\n", "Teammate's work", "Teammate <mate@example.com>", start.Add(20*time.Minute))
+	// Committed after the session, before it was recorded
+	commitAt(t, dir, "d.txt", "four\n", "Later work", me, end.Add(time.Hour))
+
+	gc, err := NewInspector().Inspect(context.Background(), dir, start, end)
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if len(gc.Commits) != 1 || gc.Commits[0].SHA != own {
+		t.Fatalf("Commits = %v, want only %s", gc.Commits, own)
+	}
+	if gc.HeadBefore != base || gc.HeadAfter != pulled {
+		t.Errorf("HEAD %q..%q, want %q..%q", gc.HeadBefore, gc.HeadAfter, base, pulled)
+	}
+	// The branch and working tree are those of the later work
+	if gc.Branch != "" || gc.Dirty != nil {
+		t.Errorf("Branch = %q, Dirty = %v, want unknown", gc.Branch, gc.Dirty)
+	}
+}
+
+func TestInspect_NotARepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	gc, err := NewInspector().Inspect(context.Background(), t.TempDir(), time.Now(), time.Now())
+	if err != nil || gc != nil {
+		t.Errorf("Inspect() = %v, %v, want nil, nil", gc, err)
+	}
+}
+
+func TestParseLog(t *testing.T) {
+	log := "\x1eccc\x1fbbb\x1fAnn\x1f2026-01-02T10:00:00+01:00\x1fSecond\n\n 2 files changed, 5 insertions(+), 1 deletion(-)\n" +
+		"\x1ebbb\x1faaa\x1fBob\x1f2026-01-01T10:00:00Z\x1fFirst\n\n 1 file changed, 1 deletion(-)\n"
+
+	commits, oldestParent := parseLog(log)
+	if len(commits) != 2 {
+		t.Fatalf("commits = %d, want 2", len(commits))
+	}
+	if oldestParent != "aaa" {
+		t.Errorf("oldestParent = %q, want aaa", oldestParent)
+	}
+	if commits[0].SHA != "bbb" || commits[0].Insertions != 0 || commits[0].Deletions != 1 || commits[0].FilesChanged != 1 {
+		t.Errorf("commits[0] = %+v", commits[0])
+	}
+	if commits[1].SHA != "ccc" || commits[1].Insertions != 5 || commits[1].Deletions != 1 || commits[1].FilesChanged != 2 {
+		t.Errorf("commits[1] = %+v", commits[1])
+	}
+	if want := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC); !commits[1].CommittedAt.Equal(want) {
+		t.Errorf("CommittedAt = %v, want %v", commits[1].CommittedAt, want)
+	}
+}
@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/util"
+)
+
+const (
+	// Retry delays double from recordRetryBase up to recordRetryMax.
+	recordRetryBase = time.Minute
+	recordRetryMax  = time.Hour
+
+	// A lock older than this belongs to a process that died mid-recording.
+	staleLockAge = 10 * time.Minute
+)
+
+// RecordQueue keeps one JSON file per session in the queue directory, plus a
+// lock file while a process is recording it.
+type RecordQueue struct {
+	baseDir string
+}
+
+func NewRecordQueue() (*RecordQueue, error) {
+	baseDir, err := util.GetXDGDataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	queueDir := filepath.Join(baseDir, "queue")
+	if err := os.MkdirAll(queueDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+
+	return &RecordQueue{baseDir: queueDir}, nil
+}
+
+// Enqueue adds a job for the session, replacing any queued one: a continued
+// session ends again with a longer transcript.
+func (q *RecordQueue) Enqueue(ctx context.Context, input *domain.HookInput) error {
+	if err := validateSessionID(input.SessionID); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	return q.write(&domain.RecordJob{
+		Input:         *input,
+		EnqueuedAt:    now,
+		NextAttemptAt: now,
+	})
+}
+
+// List returns the queued jobs, oldest first.
+func (q *RecordQueue) List(ctx context.Context) ([]*domain.RecordJob, error) {
+	entries, err := os.ReadDir(q.baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue directory: %w", err)
+	}
+
+	var jobs []*domain.RecordJob
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+		job, err := q.read(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].EnqueuedAt.Before(jobs[j].EnqueuedAt)
+	})
+	return jobs, nil
+}
+
+func (q *RecordQueue) Claim(ctx context.Context, job *domain.RecordJob) (bool, error) {
+	sessionID := job.Input.SessionID
+	if err := validateSessionID(sessionID); err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(q.jobPath(sessionID)); os.IsNotExist(err) {
+		return false, nil
+	}
+
+	lockPath := q.lockPath(sessionID)
+	if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLockAge {
+		os.Remove(lockPath)
+	}
+
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to lock queued session %s: %w", sessionID, err)
+	}
+	fmt.Fprintf(f, "%d\n", os.Getpid())
+	f.Close()
+	return true, nil
+}
+
+func (q *RecordQueue) Complete(ctx context.Context, job *domain.RecordJob) error {
+	sessionID := job.Input.SessionID
+	current, err := q.read(sessionID)
+	if err != nil {
+		return err
+	}
+	if current != nil && !current.EnqueuedAt.Equal(job.EnqueuedAt) {
+		// Queued again while recording: keep the newer job.
+		return q.unlock(sessionID)
+	}
+	return q.Remove(ctx, sessionID)
+}
+
+func (q *RecordQueue) Fail(ctx context.Context, job *domain.RecordJob, cause error) error {
+	sessionID := job.Input.SessionID
+	defer q.unlock(sessionID)
+
+	current, err := q.read(sessionID)
+	if err != nil || current == nil || !current.EnqueuedAt.Equal(job.EnqueuedAt) {
+		return err
+	}
+
+	current.Attempts++
+	current.LastError = cause.Error()
+	current.NextAttemptAt = time.Now().UTC().Add(retryDelay(current.Attempts))
+	return q.write(current)
+}
+
+func (q *RecordQueue) Remove(ctx context.Context, sessionID string) error {
+	if err := validateSessionID(sessionID); err != nil {
+		return err
+	}
+	if err := os.Remove(q.jobPath(sessionID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove queued session %s: %w", sessionID, err)
+	}
+	return q.unlock(sessionID)
+}
+
+func (q *RecordQueue) unlock(sessionID string) error {
+	if err := os.Remove(q.lockPath(sessionID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to unlock queued session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+func (q *RecordQueue) read(sessionID string) (*domain.RecordJob, error) {
+	data, err := os.ReadFile(q.jobPath(sessionID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read queued session %s: %w", sessionID, err)
+	}
+
+	var job domain.RecordJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse queued session %s: %w", sessionID, err)
+	}
+	return &job, nil
+}
+
+// write replaces the job file atomically so readers never see a partial job.
+func (q *RecordQueue) write(job *domain.RecordJob) error {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode queued session: %w", err)
+	}
+
+	path := q.jobPath(job.Input.SessionID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write queued session: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write queued session: %w", err)
+	}
+	return nil
+}
+
+func (q *RecordQueue) jobPath(sessionID string) string {
+	return filepath.Join(q.baseDir, sessionID+".json")
+}
+
+func (q *RecordQueue) lockPath(sessionID string) string {
+	return filepath.Join(q.baseDir, sessionID+".lock")
+}
+
+// retryDelay returns the wait before the next attempt after the given
+// number of failures.
+func retryDelay(attempts int) time.Duration {
+	delay := recordRetryBase
+	for i := 1; i < attempts && delay < recordRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, recordRetryMax)
+}
+
+// validateSessionID rejects IDs that can't be used as a file name.
+func validateSessionID(sessionID string) error {
+	if sessionID == "" || sessionID != filepath.Base(sessionID) || strings.HasPrefix(sessionID, ".") {
+		return fmt.Errorf("invalid session ID %q", sessionID)
+	}
+	return nil
+}
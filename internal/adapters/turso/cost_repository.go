@@ -0,0 +1,146 @@
+package turso
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/util"
+	"github.com/emiliopalmerini/mclaude/sqlc/generated"
+)
+
+type SessionCostRepository struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+func NewSessionCostRepository(db *sql.DB) *SessionCostRepository {
+	return &SessionCostRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *SessionCostRepository) ListForRecompute(ctx context.Context, filter domain.CostRecomputeFilter) ([]*domain.SessionCostTarget, error) {
+	since := filter.Since
+	if since == "" {
+		since = time.Unix(0, 0).UTC().Format(time.RFC3339)
+	}
+	until := filter.Until
+	if until == "" {
+		until = "9999-12-31T23:59:59Z"
+	}
+
+	rows, err := r.queries.ListSessionsForCostRecompute(ctx, sqlc.ListSessionsForCostRecomputeParams{
+		Since:        since,
+		Until:        until,
+		ProjectID:    optionalParam(filter.ProjectID),
+		ExperimentID: optionalParam(filter.ExperimentID),
+		ModelID:      optionalParam(filter.ModelID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions for recompute: %w", err)
+	}
+
+	targets := make([]*domain.SessionCostTarget, len(rows))
+	for i, row := range rows {
+		var startedAt *time.Time
+		if row.StartedAt.Valid {
+			t, _ := time.Parse(time.RFC3339, row.StartedAt.String)
+			startedAt = &t
+		}
+
+		var costEstimate *float64
+		if row.CostEstimateUsd.Valid {
+			costEstimate = &row.CostEstimateUsd.Float64
+		}
+
+		targets[i] = &domain.SessionCostTarget{
+			SessionID: row.ID,
+			StartedAt: startedAt,
+			Metrics: domain.SessionMetrics{
+				SessionID:         row.ID,
+				ModelID:           util.NullStringToPtr(row.ModelID),
+				TokenInput:        row.TokenInput,
+				TokenOutput:       row.TokenOutput,
+				TokenCacheRead:    row.TokenCacheRead,
+				TokenCacheWrite:   row.TokenCacheWrite,
+				CostEstimateUSD:   costEstimate,
+				WebSearchRequests: row.WebSearchRequests,
+				WebFetchRequests:  row.WebFetchRequests,
+			},
+		}
+	}
+	return targets, nil
+}
+
+func (r *SessionCostRepository) ApplyUpdates(ctx context.Context, updates []*domain.SessionCostUpdate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+	for _, u := range updates {
+		err := qtx.UpdateSessionMetricsCost(ctx, sqlc.UpdateSessionMetricsCostParams{
+			CostEstimateUsd: util.NullFloat64(u.NewCost),
+			SessionID:       u.SessionID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update cost for session %s: %w", u.SessionID, err)
+		}
+
+		if err := qtx.DeleteSessionModelUsage(ctx, u.SessionID); err != nil {
+			return fmt.Errorf("failed to clear model usage for session %s: %w", u.SessionID, err)
+		}
+		for _, mu := range u.ModelUsage {
+			err := qtx.CreateSessionModelUsage(ctx, sqlc.CreateSessionModelUsageParams{
+				SessionID:       mu.SessionID,
+				ModelID:         mu.ModelID,
+				MessageCount:    mu.MessageCount,
+				TokenInput:      mu.TokenInput,
+				TokenOutput:     mu.TokenOutput,
+				TokenCacheRead:  mu.TokenCacheRead,
+				TokenCacheWrite: mu.TokenCacheWrite,
+				CostEstimateUsd: util.NullFloat64(mu.CostEstimateUSD),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update model usage for session %s: %w", u.SessionID, err)
+			}
+		}
+
+		for _, sa := range u.Subagents {
+			err := qtx.UpdateSessionSubagentCost(ctx, sqlc.UpdateSessionSubagentCostParams{
+				CostEstimateUsd: util.NullFloat64(sa.NewCost),
+				ID:              sa.ID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update subagent cost for session %s: %w", u.SessionID, err)
+			}
+		}
+
+		for _, turn := range u.Turns {
+			err := qtx.UpdateSessionTurnCost(ctx, sqlc.UpdateSessionTurnCostParams{
+				CostEstimateUsd: util.NullFloat64(turn.CostEstimateUSD),
+				SessionID:       u.SessionID,
+				TurnIndex:       turn.TurnIndex,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update turn cost for session %s: %w", u.SessionID, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// optionalParam converts an optional filter value into a query parameter that
+// is NULL when the filter is unset.
+func optionalParam(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
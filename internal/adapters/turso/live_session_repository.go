@@ -0,0 +1,156 @@
+package turso
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/util"
+	"github.com/emiliopalmerini/mclaude/sqlc/generated"
+)
+
+type LiveSessionRepository struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+func NewLiveSessionRepository(db *sql.DB) *LiveSessionRepository {
+	return &LiveSessionRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *LiveSessionRepository) Get(ctx context.Context, sessionID string) (*domain.LiveSession, error) {
+	row, err := r.queries.GetLiveSession(ctx, sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get live session: %w", err)
+	}
+	return liveSessionFromRow(row), nil
+}
+
+func (r *LiveSessionRepository) List(ctx context.Context) ([]*domain.LiveSession, error) {
+	rows, err := r.queries.ListLiveSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list live sessions: %w", err)
+	}
+
+	sessions := make([]*domain.LiveSession, len(rows))
+	for i, row := range rows {
+		sessions[i] = liveSessionFromRow(row)
+	}
+	return sessions, nil
+}
+
+func (r *LiveSessionRepository) Create(ctx context.Context, live *domain.LiveSession) (bool, error) {
+	startedAt, updatedAt := liveSessionTimes(live)
+	n, err := r.queries.CreateLiveSession(ctx, sqlc.CreateLiveSessionParams{
+		SessionID:           live.SessionID,
+		TranscriptPath:      live.TranscriptPath,
+		Cwd:                 live.Cwd,
+		LastEvent:           live.LastEvent,
+		ByteOffset:          live.ByteOffset,
+		ModelID:             util.NullStringPtr(live.ModelID),
+		TokenInput:          live.TokenInput,
+		TokenOutput:         live.TokenOutput,
+		TokenCacheRead:      live.TokenCacheRead,
+		TokenCacheWrite:     live.TokenCacheWrite,
+		CostEstimateUsd:     util.NullFloat64(live.CostEstimateUSD),
+		StartedAt:           startedAt,
+		UpdatedAt:           updatedAt,
+		TranscriptLineCount: live.TranscriptLines,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create live session: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *LiveSessionRepository) Update(ctx context.Context, live *domain.LiveSession, fromOffset int64) (bool, error) {
+	startedAt, updatedAt := liveSessionTimes(live)
+	n, err := r.queries.UpdateLiveSession(ctx, sqlc.UpdateLiveSessionParams{
+		TranscriptPath:      live.TranscriptPath,
+		Cwd:                 live.Cwd,
+		LastEvent:           live.LastEvent,
+		ByteOffset:          live.ByteOffset,
+		ModelID:             util.NullStringPtr(live.ModelID),
+		TokenInput:          live.TokenInput,
+		TokenOutput:         live.TokenOutput,
+		TokenCacheRead:      live.TokenCacheRead,
+		TokenCacheWrite:     live.TokenCacheWrite,
+		CostEstimateUsd:     util.NullFloat64(live.CostEstimateUSD),
+		StartedAt:           startedAt,
+		UpdatedAt:           updatedAt,
+		TranscriptLineCount: live.TranscriptLines,
+		SessionID:           live.SessionID,
+		PrevByteOffset:      fromOffset,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to update live session: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *LiveSessionRepository) Delete(ctx context.Context, sessionID string) error {
+	if err := r.queries.DeleteLiveSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete live session: %w", err)
+	}
+	return nil
+}
+
+func (r *LiveSessionRepository) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+	cutoff := before.UTC().Format(time.RFC3339)
+	n, err := qtx.DeleteStaleLiveSessions(ctx, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete stale live sessions: %w", err)
+	}
+	if err := qtx.DeleteStaleTranscriptStates(ctx, cutoff); err != nil {
+		return 0, fmt.Errorf("failed to delete stale transcript states: %w", err)
+	}
+	return n, tx.Commit()
+}
+
+func liveSessionTimes(live *domain.LiveSession) (sql.NullString, string) {
+	var startedAt sql.NullString
+	if live.StartedAt != nil {
+		startedAt = sql.NullString{String: live.StartedAt.UTC().Format(time.RFC3339), Valid: true}
+	}
+	return startedAt, live.UpdatedAt.UTC().Format(time.RFC3339)
+}
+
+func liveSessionFromRow(row sqlc.LiveSession) *domain.LiveSession {
+	live := &domain.LiveSession{
+		SessionID:       row.SessionID,
+		TranscriptPath:  row.TranscriptPath,
+		Cwd:             row.Cwd,
+		LastEvent:       row.LastEvent,
+		ByteOffset:      row.ByteOffset,
+		TranscriptLines: row.TranscriptLineCount,
+		ModelID:         util.NullStringToPtr(row.ModelID),
+		TokenInput:      row.TokenInput,
+		TokenOutput:     row.TokenOutput,
+		TokenCacheRead:  row.TokenCacheRead,
+		TokenCacheWrite: row.TokenCacheWrite,
+		UpdatedAt:       util.ParseTimeRFC3339(row.UpdatedAt),
+	}
+	if row.CostEstimateUsd.Valid {
+		live.CostEstimateUSD = &row.CostEstimateUsd.Float64
+	}
+	if row.StartedAt.Valid {
+		t := util.ParseTimeRFC3339(row.StartedAt.String)
+		live.StartedAt = &t
+	}
+	return live
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/util"
 	"github.com/emiliopalmerini/mclaude/sqlc/generated"
 )
 
@@ -23,6 +24,10 @@ func NewSessionMetricsRepository(db *sql.DB) *SessionMetricsRepository {
 }
 
 func (r *SessionMetricsRepository) Create(ctx context.Context, metrics *domain.SessionMetrics) error {
+	return createSessionMetrics(ctx, r.queries, metrics)
+}
+
+func createSessionMetrics(ctx context.Context, q *sqlc.Queries, metrics *domain.SessionMetrics) error {
 	var costEstimate sql.NullFloat64
 	if metrics.CostEstimateUSD != nil {
 		costEstimate = sql.NullFloat64{Float64: *metrics.CostEstimateUSD, Valid: true}
@@ -33,7 +38,7 @@ func (r *SessionMetricsRepository) Create(ctx context.Context, metrics *domain.S
 		modelID = sql.NullString{String: *metrics.ModelID, Valid: true}
 	}
 
-	return r.queries.CreateSessionMetrics(ctx, sqlc.CreateSessionMetricsParams{
+	return q.CreateSessionMetrics(ctx, sqlc.CreateSessionMetricsParams{
 		SessionID:             metrics.SessionID,
 		ModelID:               modelID,
 		MessageCountUser:      metrics.MessageCountUser,
@@ -45,6 +50,19 @@ func (r *SessionMetricsRepository) Create(ctx context.Context, metrics *domain.S
 		TokenCacheWrite:       metrics.TokenCacheWrite,
 		CostEstimateUsd:       costEstimate,
 		ErrorCount:            metrics.ErrorCount,
+		LinesAdded:            metrics.LinesAdded,
+		LinesRemoved:          metrics.LinesRemoved,
+		CompactionCount:       metrics.CompactionCount,
+		TokenCacheWasted:      metrics.TokenCacheWasted,
+		ThinkingBlockCount:    metrics.ThinkingBlocks,
+		ThinkingTokens:        metrics.ThinkingTokens,
+		WebSearchRequests:     metrics.WebSearchRequests,
+		WebFetchRequests:      metrics.WebFetchRequests,
+		InterruptionCount:     metrics.Interruptions,
+		ToolRejectionCount:    metrics.ToolRejections,
+		PermissionDenialCount: metrics.PermissionDenials,
+		TranscriptLineCount:   metrics.TranscriptLines,
+		ParserVersion:         metrics.ParserVersion,
 	})
 }
 
@@ -79,6 +97,19 @@ func (r *SessionMetricsRepository) GetBySessionID(ctx context.Context, sessionID
 		TokenCacheWrite:       row.TokenCacheWrite,
 		CostEstimateUSD:       costEstimate,
 		ErrorCount:            row.ErrorCount,
+		LinesAdded:            row.LinesAdded,
+		LinesRemoved:          row.LinesRemoved,
+		CompactionCount:       row.CompactionCount,
+		TokenCacheWasted:      row.TokenCacheWasted,
+		ThinkingBlocks:        row.ThinkingBlockCount,
+		ThinkingTokens:        row.ThinkingTokens,
+		WebSearchRequests:     row.WebSearchRequests,
+		WebFetchRequests:      row.WebFetchRequests,
+		Interruptions:         row.InterruptionCount,
+		ToolRejections:        row.ToolRejectionCount,
+		PermissionDenials:     row.PermissionDenialCount,
+		TranscriptLines:       row.TranscriptLineCount,
+		ParserVersion:         row.ParserVersion,
 	}, nil
 }
 
@@ -101,25 +132,36 @@ func (r *SessionToolRepository) CreateBatch(ctx context.Context, tools []*domain
 	}
 	defer tx.Rollback()
 
-	qtx := r.queries.WithTx(tx)
+	if err := createSessionTools(ctx, r.queries.WithTx(tx), tools); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func createSessionTools(ctx context.Context, q *sqlc.Queries, tools []*domain.SessionTool) error {
 	for _, tool := range tools {
 		var totalDurationMs sql.NullInt64
 		if tool.TotalDurationMs != nil {
 			totalDurationMs = sql.NullInt64{Int64: *tool.TotalDurationMs, Valid: true}
 		}
 
-		err := qtx.CreateSessionTool(ctx, sqlc.CreateSessionToolParams{
-			SessionID:       tool.SessionID,
-			ToolName:        tool.ToolName,
-			InvocationCount: tool.InvocationCount,
-			TotalDurationMs: totalDurationMs,
-			ErrorCount:      tool.ErrorCount,
+		err := q.CreateSessionTool(ctx, sqlc.CreateSessionToolParams{
+			SessionID:             tool.SessionID,
+			ToolName:              tool.ToolName,
+			InvocationCount:       tool.InvocationCount,
+			TotalDurationMs:       totalDurationMs,
+			ErrorCount:            tool.ErrorCount,
+			McpServer:             util.NullString(tool.MCPServer),
+			McpTool:               util.NullString(tool.MCPTool),
+			ResultTokens:          tool.ResultTokens,
+			RejectionCount:        tool.RejectionCount,
+			PermissionDenialCount: tool.PermissionDenialCount,
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create session tool %s: %w", tool.ToolName, err)
 		}
 	}
-	return tx.Commit()
+	return nil
 }
 
 func (r *SessionToolRepository) ListBySessionID(ctx context.Context, sessionID string) ([]*domain.SessionTool, error) {
@@ -135,12 +177,17 @@ func (r *SessionToolRepository) ListBySessionID(ctx context.Context, sessionID s
 			totalDurationMs = &row.TotalDurationMs.Int64
 		}
 		tools[i] = &domain.SessionTool{
-			ID:              row.ID,
-			SessionID:       row.SessionID,
-			ToolName:        row.ToolName,
-			InvocationCount: row.InvocationCount,
-			TotalDurationMs: totalDurationMs,
-			ErrorCount:      row.ErrorCount,
+			ID:                    row.ID,
+			SessionID:             row.SessionID,
+			ToolName:              row.ToolName,
+			InvocationCount:       row.InvocationCount,
+			TotalDurationMs:       totalDurationMs,
+			ErrorCount:            row.ErrorCount,
+			MCPServer:             row.McpServer.String,
+			MCPTool:               row.McpTool.String,
+			ResultTokens:          row.ResultTokens,
+			RejectionCount:        row.RejectionCount,
+			PermissionDenialCount: row.PermissionDenialCount,
 		}
 	}
 	return tools, nil
@@ -165,19 +212,28 @@ func (r *SessionFileRepository) CreateBatch(ctx context.Context, files []*domain
 	}
 	defer tx.Rollback()
 
-	qtx := r.queries.WithTx(tx)
+	if err := createSessionFiles(ctx, r.queries.WithTx(tx), files); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func createSessionFiles(ctx context.Context, q *sqlc.Queries, files []*domain.SessionFile) error {
 	for _, file := range files {
-		err := qtx.CreateSessionFile(ctx, sqlc.CreateSessionFileParams{
+		err := q.CreateSessionFile(ctx, sqlc.CreateSessionFileParams{
 			SessionID:      file.SessionID,
 			FilePath:       file.FilePath,
 			Operation:      file.Operation,
 			OperationCount: file.OperationCount,
+			LinesAdded:     file.LinesAdded,
+			LinesRemoved:   file.LinesRemoved,
+			Language:       util.NullString(file.Language),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create session file %s: %w", file.FilePath, err)
 		}
 	}
-	return tx.Commit()
+	return nil
 }
 
 func (r *SessionFileRepository) ListBySessionID(ctx context.Context, sessionID string) ([]*domain.SessionFile, error) {
@@ -194,6 +250,9 @@ func (r *SessionFileRepository) ListBySessionID(ctx context.Context, sessionID s
 			FilePath:       row.FilePath,
 			Operation:      row.Operation,
 			OperationCount: row.OperationCount,
+			LinesAdded:     row.LinesAdded,
+			LinesRemoved:   row.LinesRemoved,
+			Language:       row.Language.String,
 		}
 	}
 	return files, nil
@@ -218,7 +277,13 @@ func (r *SessionCommandRepository) CreateBatch(ctx context.Context, commands []*
 	}
 	defer tx.Rollback()
 
-	qtx := r.queries.WithTx(tx)
+	if err := createSessionCommands(ctx, r.queries.WithTx(tx), commands); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func createSessionCommands(ctx context.Context, q *sqlc.Queries, commands []*domain.SessionCommand) error {
 	for _, cmd := range commands {
 		var exitCode sql.NullInt64
 		if cmd.ExitCode != nil {
@@ -230,17 +295,71 @@ func (r *SessionCommandRepository) CreateBatch(ctx context.Context, commands []*
 			executedAt = sql.NullString{String: cmd.ExecutedAt.Format(time.RFC3339), Valid: true}
 		}
 
-		err := qtx.CreateSessionCommand(ctx, sqlc.CreateSessionCommandParams{
+		err := q.CreateSessionCommand(ctx, sqlc.CreateSessionCommandParams{
 			SessionID:  cmd.SessionID,
 			Command:    cmd.Command,
 			ExitCode:   exitCode,
 			ExecutedAt: executedAt,
+			ToolUseID:  util.NullString(cmd.ToolUseID),
+			Status:     util.NullString(cmd.Status),
+			DurationMs: util.NullInt64(cmd.DurationMs),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create session command: %w", err)
 		}
 	}
-	return tx.Commit()
+	return nil
+}
+
+func createSessionCommits(ctx context.Context, q *sqlc.Queries, commits []*domain.SessionCommit) error {
+	for _, commit := range commits {
+		err := q.CreateSessionCommit(ctx, sqlc.CreateSessionCommitParams{
+			SessionID:    commit.SessionID,
+			Sha:          commit.SHA,
+			Subject:      commit.Subject,
+			Author:       commit.Author,
+			CommittedAt:  commit.CommittedAt.Format(time.RFC3339),
+			FilesChanged: commit.FilesChanged,
+			Insertions:   commit.Insertions,
+			Deletions:    commit.Deletions,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create session commit %s: %w", commit.SHA, err)
+		}
+	}
+	return nil
+}
+
+func createSessionTurns(ctx context.Context, q *sqlc.Queries, turns []*domain.SessionTurn) error {
+	for _, turn := range turns {
+		var startedAt, endedAt sql.NullString
+		if turn.StartedAt != nil {
+			startedAt = sql.NullString{String: turn.StartedAt.Format(time.RFC3339), Valid: true}
+		}
+		if turn.EndedAt != nil {
+			endedAt = sql.NullString{String: turn.EndedAt.Format(time.RFC3339), Valid: true}
+		}
+
+		err := q.CreateSessionTurn(ctx, sqlc.CreateSessionTurnParams{
+			SessionID:       turn.SessionID,
+			TurnIndex:       turn.TurnIndex,
+			Prompt:          turn.Prompt,
+			StartedAt:       startedAt,
+			EndedAt:         endedAt,
+			DurationMs:      util.NullInt64(turn.DurationMs),
+			MessageCount:    turn.MessageCount,
+			ToolCalls:       turn.ToolCalls,
+			TokenInput:      turn.TokenInput,
+			TokenOutput:     turn.TokenOutput,
+			TokenCacheRead:  turn.TokenCacheRead,
+			TokenCacheWrite: turn.TokenCacheWrite,
+			CostEstimateUsd: util.NullFloat64(turn.CostEstimateUSD),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create session turn %d: %w", turn.TurnIndex, err)
+		}
+	}
+	return nil
 }
 
 func (r *SessionCommandRepository) ListBySessionID(ctx context.Context, sessionID string) ([]*domain.SessionCommand, error) {
@@ -269,6 +388,11 @@ func (r *SessionCommandRepository) ListBySessionID(ctx context.Context, sessionI
 			Command:    row.Command,
 			ExitCode:   exitCode,
 			ExecutedAt: executedAt,
+			ToolUseID:  row.ToolUseID.String,
+			Status:     row.Status.String,
+		}
+		if row.DurationMs.Valid {
+			commands[i].DurationMs = &row.DurationMs.Int64
 		}
 	}
 	return commands, nil
@@ -293,7 +417,13 @@ func (r *SessionSubagentRepository) CreateBatch(ctx context.Context, subagents [
 	}
 	defer tx.Rollback()
 
-	qtx := r.queries.WithTx(tx)
+	if err := createSessionSubagents(ctx, r.queries.WithTx(tx), subagents); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func createSessionSubagents(ctx context.Context, q *sqlc.Queries, subagents []*domain.SessionSubagent) error {
 	for _, sa := range subagents {
 		var description sql.NullString
 		if sa.Description != nil {
@@ -315,7 +445,7 @@ func (r *SessionSubagentRepository) CreateBatch(ctx context.Context, subagents [
 			costEstimate = sql.NullFloat64{Float64: *sa.CostEstimateUSD, Valid: true}
 		}
 
-		err := qtx.CreateSessionSubagent(ctx, sqlc.CreateSessionSubagentParams{
+		err := q.CreateSessionSubagent(ctx, sqlc.CreateSessionSubagentParams{
 			SessionID:       sa.SessionID,
 			AgentType:       sa.AgentType,
 			AgentKind:       sa.AgentKind,
@@ -329,12 +459,53 @@ func (r *SessionSubagentRepository) CreateBatch(ctx context.Context, subagents [
 			TotalDurationMs: totalDurationMs,
 			ToolUseCount:    sa.ToolUseCount,
 			CostEstimateUsd: costEstimate,
+			ToolUseID:       util.NullString(sa.ToolUseID),
+			AgentID:         util.NullStringPtr(sa.AgentID),
+			ParentToolUseID: util.NullStringPtr(sa.ParentToolUseID),
+			ErrorCount:      sa.ErrorCount,
+			ModelID:         util.NullStringPtr(sa.ModelID),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to create session subagent %s: %w", sa.AgentType, err)
 		}
+		if err := createSessionSubagentBreakdown(ctx, q, sa); err != nil {
+			return err
+		}
 	}
-	return tx.Commit()
+	return nil
+}
+
+// createSessionSubagentBreakdown saves the tools and files of a sub-agent,
+// keyed by the call that started it.
+func createSessionSubagentBreakdown(ctx context.Context, q *sqlc.Queries, sa *domain.SessionSubagent) error {
+	for _, tool := range sa.Tools {
+		err := q.CreateSessionSubagentTool(ctx, sqlc.CreateSessionSubagentToolParams{
+			SessionID:         sa.SessionID,
+			SubagentToolUseID: sa.ToolUseID,
+			ToolName:          tool.ToolName,
+			InvocationCount:   tool.InvocationCount,
+			ErrorCount:        tool.ErrorCount,
+			TotalDurationMs:   util.NullInt64(tool.TotalDurationMs),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create session subagent tool %s: %w", tool.ToolName, err)
+		}
+	}
+	for _, file := range sa.Files {
+		err := q.CreateSessionSubagentFile(ctx, sqlc.CreateSessionSubagentFileParams{
+			SessionID:         sa.SessionID,
+			SubagentToolUseID: sa.ToolUseID,
+			FilePath:          file.FilePath,
+			Operation:         file.Operation,
+			OperationCount:    file.OperationCount,
+			LinesAdded:        file.LinesAdded,
+			LinesRemoved:      file.LinesRemoved,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create session subagent file %s: %w", file.FilePath, err)
+		}
+	}
+	return nil
 }
 
 func (r *SessionSubagentRepository) ListBySessionID(ctx context.Context, sessionID string) ([]*domain.SessionSubagent, error) {
@@ -379,8 +550,239 @@ func (r *SessionSubagentRepository) ListBySessionID(ctx context.Context, session
 			TokenCacheWrite: row.TokenCacheWrite,
 			TotalDurationMs: totalDurationMs,
 			ToolUseCount:    row.ToolUseCount,
+			ErrorCount:      row.ErrorCount,
 			CostEstimateUSD: costEstimate,
 		}
+		if row.ToolUseID.Valid {
+			subagents[i].ToolUseID = row.ToolUseID.String
+		}
+		if row.AgentID.Valid {
+			subagents[i].AgentID = &row.AgentID.String
+		}
+		if row.ModelID.Valid {
+			subagents[i].ModelID = &row.ModelID.String
+		}
+		if row.ParentToolUseID.Valid {
+			subagents[i].ParentToolUseID = &row.ParentToolUseID.String
+		}
+	}
+	if err := r.attachBreakdown(ctx, sessionID, subagents); err != nil {
+		return nil, err
 	}
 	return subagents, nil
 }
+
+// attachBreakdown sets the tools and files of each sub-agent.
+func (r *SessionSubagentRepository) attachBreakdown(ctx context.Context, sessionID string, subagents []*domain.SessionSubagent) error {
+	byToolUse := make(map[string]*domain.SessionSubagent, len(subagents))
+	for _, sa := range subagents {
+		if sa.ToolUseID != "" {
+			byToolUse[sa.ToolUseID] = sa
+		}
+	}
+	if len(byToolUse) == 0 {
+		return nil
+	}
+
+	tools, err := r.queries.ListSessionSubagentToolsBySessionID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to list session subagent tools: %w", err)
+	}
+	for _, row := range tools {
+		sa, ok := byToolUse[row.SubagentToolUseID]
+		if !ok {
+			continue
+		}
+		var totalDurationMs *int64
+		if row.TotalDurationMs.Valid {
+			totalDurationMs = &row.TotalDurationMs.Int64
+		}
+		sa.Tools = append(sa.Tools, &domain.SessionTool{
+			ID:              row.ID,
+			SessionID:       row.SessionID,
+			ToolName:        row.ToolName,
+			InvocationCount: row.InvocationCount,
+			ErrorCount:      row.ErrorCount,
+			TotalDurationMs: totalDurationMs,
+		})
+	}
+
+	files, err := r.queries.ListSessionSubagentFilesBySessionID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to list session subagent files: %w", err)
+	}
+	for _, row := range files {
+		sa, ok := byToolUse[row.SubagentToolUseID]
+		if !ok {
+			continue
+		}
+		sa.Files = append(sa.Files, &domain.SessionFile{
+			ID:             row.ID,
+			SessionID:      row.SessionID,
+			FilePath:       row.FilePath,
+			Operation:      row.Operation,
+			OperationCount: row.OperationCount,
+			LinesAdded:     row.LinesAdded,
+			LinesRemoved:   row.LinesRemoved,
+		})
+	}
+	return nil
+}
+
+type SessionModelUsageRepository struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+func NewSessionModelUsageRepository(db *sql.DB) *SessionModelUsageRepository {
+	return &SessionModelUsageRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *SessionModelUsageRepository) CreateBatch(ctx context.Context, usage []*domain.SessionModelUsage) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := createSessionModelUsage(ctx, r.queries.WithTx(tx), usage); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func createSessionModelUsage(ctx context.Context, q *sqlc.Queries, usage []*domain.SessionModelUsage) error {
+	for _, mu := range usage {
+		var costEstimate sql.NullFloat64
+		if mu.CostEstimateUSD != nil {
+			costEstimate = sql.NullFloat64{Float64: *mu.CostEstimateUSD, Valid: true}
+		}
+
+		err := q.CreateSessionModelUsage(ctx, sqlc.CreateSessionModelUsageParams{
+			SessionID:       mu.SessionID,
+			ModelID:         mu.ModelID,
+			MessageCount:    mu.MessageCount,
+			TokenInput:      mu.TokenInput,
+			TokenOutput:     mu.TokenOutput,
+			TokenCacheRead:  mu.TokenCacheRead,
+			TokenCacheWrite: mu.TokenCacheWrite,
+			CostEstimateUsd: costEstimate,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create session model usage %s: %w", mu.ModelID, err)
+		}
+	}
+	return nil
+}
+
+func (r *SessionModelUsageRepository) ListBySessionID(ctx context.Context, sessionID string) ([]*domain.SessionModelUsage, error) {
+	rows, err := r.queries.ListSessionModelUsageBySessionID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session model usage: %w", err)
+	}
+
+	usage := make([]*domain.SessionModelUsage, len(rows))
+	for i, row := range rows {
+		var costEstimate *float64
+		if row.CostEstimateUsd.Valid {
+			costEstimate = &row.CostEstimateUsd.Float64
+		}
+
+		usage[i] = &domain.SessionModelUsage{
+			ID:              row.ID,
+			SessionID:       row.SessionID,
+			ModelID:         row.ModelID,
+			MessageCount:    row.MessageCount,
+			TokenInput:      row.TokenInput,
+			TokenOutput:     row.TokenOutput,
+			TokenCacheRead:  row.TokenCacheRead,
+			TokenCacheWrite: row.TokenCacheWrite,
+			CostEstimateUSD: costEstimate,
+		}
+	}
+	return usage, nil
+}
+
+func createSessionSlashCommands(ctx context.Context, q *sqlc.Queries, commands []*domain.SessionSlashCommand) error {
+	for _, cmd := range commands {
+		err := q.CreateSessionSlashCommand(ctx, sqlc.CreateSessionSlashCommandParams{
+			SessionID:       cmd.SessionID,
+			Command:         cmd.Command,
+			Kind:            cmd.Kind,
+			InvocationCount: cmd.InvocationCount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create session slash command %s: %w", cmd.Command, err)
+		}
+	}
+	return nil
+}
+
+func createSessionHooks(ctx context.Context, q *sqlc.Queries, hooks []*domain.SessionHook) error {
+	for _, hook := range hooks {
+		err := q.CreateSessionHook(ctx, sqlc.CreateSessionHookParams{
+			SessionID:      hook.SessionID,
+			Event:          hook.Event,
+			HookName:       hook.HookName,
+			Command:        hook.Command,
+			ExecutionCount: hook.ExecutionCount,
+			ErrorCount:     hook.ErrorCount,
+			BlockedCount:   hook.BlockedCount,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create session hook %s: %w", hook.HookName, err)
+		}
+	}
+	return nil
+}
+
+func createSessionParseAnomalies(ctx context.Context, q *sqlc.Queries, anomalies []*domain.SessionParseAnomaly) error {
+	for _, anomaly := range anomalies {
+		err := q.CreateSessionParseAnomaly(ctx, sqlc.CreateSessionParseAnomalyParams{
+			SessionID:       anomaly.SessionID,
+			Kind:            anomaly.Kind,
+			Detail:          anomaly.Detail,
+			OccurrenceCount: anomaly.OccurrenceCount,
+			FirstLine:       anomaly.FirstLine,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create session parse anomaly %s: %w", anomaly.Kind, err)
+		}
+	}
+	return nil
+}
+
+type SessionParseAnomalyRepository struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+func NewSessionParseAnomalyRepository(db *sql.DB) *SessionParseAnomalyRepository {
+	return &SessionParseAnomalyRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *SessionParseAnomalyRepository) ListBySessionID(ctx context.Context, sessionID string) ([]*domain.SessionParseAnomaly, error) {
+	rows, err := r.queries.ListSessionParseAnomaliesBySessionID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session parse anomalies: %w", err)
+	}
+
+	anomalies := make([]*domain.SessionParseAnomaly, len(rows))
+	for i, row := range rows {
+		anomalies[i] = &domain.SessionParseAnomaly{
+			ID:              row.ID,
+			SessionID:       row.SessionID,
+			Kind:            row.Kind,
+			Detail:          row.Detail,
+			OccurrenceCount: row.OccurrenceCount,
+			FirstLine:       row.FirstLine,
+		}
+	}
+	return anomalies, nil
+}
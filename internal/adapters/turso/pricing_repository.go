@@ -24,7 +24,100 @@ func NewPricingRepository(db *sql.DB) *PricingRepository {
 }
 
 func (r *PricingRepository) Create(ctx context.Context, pricing *domain.ModelPricing) error {
-	return r.queries.CreateModelPricing(ctx, sqlc.CreateModelPricingParams{
+	return createPricing(ctx, r.queries, pricing)
+}
+
+// AddVersion makes pricing the current version of its model from
+// pricing.EffectiveFrom on, closing the previous version at that time.
+func (r *PricingRepository) AddVersion(ctx context.Context, pricing *domain.ModelPricing) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := addPricingVersion(ctx, r.queries.WithTx(tx), pricing); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func addPricingVersion(ctx context.Context, q *sqlc.Queries, pricing *domain.ModelPricing) error {
+	current, err := q.GetModelPricingByID(ctx, pricing.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get model pricing: %w", err)
+	}
+	if err == nil {
+		currentFrom, _ := time.Parse(time.RFC3339, current.EffectiveFrom)
+		if !pricing.EffectiveFrom.After(currentFrom) {
+			return fmt.Errorf("pricing for %s must take effect after %s", pricing.ID, current.EffectiveFrom)
+		}
+		if err := q.CloseModelPricing(ctx, sqlc.CloseModelPricingParams{
+			EffectiveTo: util.NullString(pricing.EffectiveFrom.UTC().Format(time.RFC3339)),
+			ID:          pricing.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to close model pricing: %w", err)
+		}
+	}
+
+	pricing.EffectiveTo = nil
+	if err := createPricing(ctx, q, pricing); err != nil {
+		return fmt.Errorf("failed to create model pricing: %w", err)
+	}
+	return nil
+}
+
+// Apply writes a set of pricing changes in one transaction, so a failure
+// leaves the pricing as it was.
+func (r *PricingRepository) Apply(ctx context.Context, changes *domain.PricingChanges) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+
+	for _, pricing := range changes.Added {
+		if err := createPricing(ctx, qtx, pricing); err != nil {
+			return fmt.Errorf("failed to create model pricing %s: %w", pricing.ID, err)
+		}
+	}
+	for _, pricing := range changes.Updated {
+		if err := updatePricing(ctx, qtx, pricing); err != nil {
+			return fmt.Errorf("failed to update model pricing %s: %w", pricing.ID, err)
+		}
+	}
+	for _, pricing := range changes.NewVersions {
+		if err := addPricingVersion(ctx, qtx, pricing); err != nil {
+			return err
+		}
+	}
+	if changes.DefaultID != "" {
+		if err := qtx.SetDefaultModelPricing(ctx, changes.DefaultID); err != nil {
+			return fmt.Errorf("failed to set default model pricing: %w", err)
+		}
+	}
+	for _, alias := range changes.Aliases {
+		if err := setAlias(ctx, qtx, alias.Alias, alias.ModelID); err != nil {
+			return fmt.Errorf("failed to set model alias %s: %w", alias.Alias, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func createPricing(ctx context.Context, q *sqlc.Queries, pricing *domain.ModelPricing) error {
+	effectiveFrom := pricing.EffectiveFrom
+	if effectiveFrom.IsZero() {
+		effectiveFrom = domain.PricingEpoch
+	}
+	var effectiveTo sql.NullString
+	if pricing.EffectiveTo != nil {
+		effectiveTo = util.NullString(pricing.EffectiveTo.UTC().Format(time.RFC3339))
+	}
+
+	return q.CreateModelPricing(ctx, sqlc.CreateModelPricingParams{
 		ID:                          pricing.ID,
 		DisplayName:                 pricing.DisplayName,
 		InputPerMillion:             pricing.InputPerMillion,
@@ -34,8 +127,12 @@ func (r *PricingRepository) Create(ctx context.Context, pricing *domain.ModelPri
 		LongContextInputPerMillion:  util.NullFloat64(pricing.LongContextInputPerMillion),
 		LongContextOutputPerMillion: util.NullFloat64(pricing.LongContextOutputPerMillion),
 		LongContextThreshold:        util.NullInt64(pricing.LongContextThreshold),
+		WebSearchPerThousand:        util.NullFloat64(pricing.WebSearchPerThousand),
+		WebFetchPerThousand:         util.NullFloat64(pricing.WebFetchPerThousand),
 		IsDefault:                   util.BoolToInt64(pricing.IsDefault),
 		CreatedAt:                   pricing.CreatedAt.Format(time.RFC3339),
+		EffectiveFrom:               effectiveFrom.UTC().Format(time.RFC3339),
+		EffectiveTo:                 effectiveTo,
 	})
 }
 
@@ -50,6 +147,21 @@ func (r *PricingRepository) GetByID(ctx context.Context, id string) (*domain.Mod
 	return pricingFromRow(row), nil
 }
 
+// GetByIDAt returns the pricing of a model in force at the given time.
+func (r *PricingRepository) GetByIDAt(ctx context.Context, id string, at time.Time) (*domain.ModelPricing, error) {
+	row, err := r.queries.GetModelPricingAt(ctx, sqlc.GetModelPricingAtParams{
+		ID: id,
+		At: at.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get model pricing: %w", err)
+	}
+	return pricingFromRow(row), nil
+}
+
 func (r *PricingRepository) GetDefault(ctx context.Context) (*domain.ModelPricing, error) {
 	row, err := r.queries.GetDefaultModelPricing(ctx)
 	if err != nil {
@@ -74,8 +186,26 @@ func (r *PricingRepository) List(ctx context.Context) ([]*domain.ModelPricing, e
 	return pricing, nil
 }
 
+func (r *PricingRepository) ListHistory(ctx context.Context) ([]*domain.ModelPricing, error) {
+	rows, err := r.queries.ListModelPricingHistory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list model pricing history: %w", err)
+	}
+
+	pricing := make([]*domain.ModelPricing, len(rows))
+	for i, row := range rows {
+		pricing[i] = pricingFromRow(row)
+	}
+	return pricing, nil
+}
+
+// Update changes the current version of a model's pricing in place.
 func (r *PricingRepository) Update(ctx context.Context, pricing *domain.ModelPricing) error {
-	return r.queries.UpdateModelPricing(ctx, sqlc.UpdateModelPricingParams{
+	return updatePricing(ctx, r.queries, pricing)
+}
+
+func updatePricing(ctx context.Context, q *sqlc.Queries, pricing *domain.ModelPricing) error {
+	return q.UpdateModelPricing(ctx, sqlc.UpdateModelPricingParams{
 		DisplayName:                 pricing.DisplayName,
 		InputPerMillion:             pricing.InputPerMillion,
 		OutputPerMillion:            pricing.OutputPerMillion,
@@ -84,6 +214,8 @@ func (r *PricingRepository) Update(ctx context.Context, pricing *domain.ModelPri
 		LongContextInputPerMillion:  util.NullFloat64(pricing.LongContextInputPerMillion),
 		LongContextOutputPerMillion: util.NullFloat64(pricing.LongContextOutputPerMillion),
 		LongContextThreshold:        util.NullInt64(pricing.LongContextThreshold),
+		WebSearchPerThousand:        util.NullFloat64(pricing.WebSearchPerThousand),
+		WebFetchPerThousand:         util.NullFloat64(pricing.WebFetchPerThousand),
 		IsDefault:                   util.BoolToInt64(pricing.IsDefault),
 		ID:                          pricing.ID,
 	})
@@ -97,8 +229,65 @@ func (r *PricingRepository) Delete(ctx context.Context, id string) error {
 	return r.queries.DeleteModelPricing(ctx, id)
 }
 
+func (r *PricingRepository) SetAlias(ctx context.Context, alias, modelID string) error {
+	return setAlias(ctx, r.queries, alias, modelID)
+}
+
+func setAlias(ctx context.Context, q *sqlc.Queries, alias, modelID string) error {
+	return q.UpsertModelAlias(ctx, sqlc.UpsertModelAliasParams{
+		Alias:     alias,
+		ModelID:   modelID,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func (r *PricingRepository) GetAlias(ctx context.Context, alias string) (*domain.ModelAlias, error) {
+	row, err := r.queries.GetModelAlias(ctx, alias)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get model alias: %w", err)
+	}
+	return aliasFromRow(row), nil
+}
+
+func (r *PricingRepository) ListAliases(ctx context.Context) ([]*domain.ModelAlias, error) {
+	rows, err := r.queries.ListModelAliases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list model aliases: %w", err)
+	}
+
+	aliases := make([]*domain.ModelAlias, len(rows))
+	for i, row := range rows {
+		aliases[i] = aliasFromRow(row)
+	}
+	return aliases, nil
+}
+
+func (r *PricingRepository) DeleteAlias(ctx context.Context, alias string) error {
+	return r.queries.DeleteModelAlias(ctx, alias)
+}
+
+func aliasFromRow(row sqlc.ModelAlias) *domain.ModelAlias {
+	createdAt, _ := time.Parse(time.RFC3339, row.CreatedAt)
+	return &domain.ModelAlias{
+		Alias:     row.Alias,
+		ModelID:   row.ModelID,
+		CreatedAt: createdAt,
+	}
+}
+
 func pricingFromRow(row sqlc.ModelPricing) *domain.ModelPricing {
 	createdAt, _ := time.Parse(time.RFC3339, row.CreatedAt)
+	effectiveFrom, _ := time.Parse(time.RFC3339, row.EffectiveFrom)
+
+	var effectiveTo *time.Time
+	if row.EffectiveTo.Valid {
+		if t, err := time.Parse(time.RFC3339, row.EffectiveTo.String); err == nil {
+			effectiveTo = &t
+		}
+	}
 
 	var cacheReadPerMillion, cacheWritePerMillion *float64
 	if row.CacheReadPerMillion.Valid {
@@ -120,6 +309,14 @@ func pricingFromRow(row sqlc.ModelPricing) *domain.ModelPricing {
 		longContextThreshold = &row.LongContextThreshold.Int64
 	}
 
+	var webSearchPerThousand, webFetchPerThousand *float64
+	if row.WebSearchPerThousand.Valid {
+		webSearchPerThousand = &row.WebSearchPerThousand.Float64
+	}
+	if row.WebFetchPerThousand.Valid {
+		webFetchPerThousand = &row.WebFetchPerThousand.Float64
+	}
+
 	return &domain.ModelPricing{
 		ID:                          row.ID,
 		DisplayName:                 row.DisplayName,
@@ -130,7 +327,11 @@ func pricingFromRow(row sqlc.ModelPricing) *domain.ModelPricing {
 		LongContextInputPerMillion:  longContextInputPerMillion,
 		LongContextOutputPerMillion: longContextOutputPerMillion,
 		LongContextThreshold:        longContextThreshold,
+		WebSearchPerThousand:        webSearchPerThousand,
+		WebFetchPerThousand:         webFetchPerThousand,
 		IsDefault:                   row.IsDefault == 1,
 		CreatedAt:                   createdAt,
+		EffectiveFrom:               effectiveFrom,
+		EffectiveTo:                 effectiveTo,
 	}
 }
@@ -0,0 +1,123 @@
+package turso
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/sqlc/generated"
+)
+
+type SessionRecordingRepository struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+func NewSessionRecordingRepository(db *sql.DB) *SessionRecordingRepository {
+	return &SessionRecordingRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+// Save writes the session and all of its rows atomically. Child rows left by
+// an earlier recording of the session are deleted first, so a continued
+// session replaces its metrics instead of adding to them. Quality reviews
+// are cleared as well since they no longer match the transcript, and so is
+// the session's live usage, which the recording supersedes.
+func (r *SessionRecordingRepository) Save(ctx context.Context, rec *domain.SessionRecording) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	qtx := r.queries.WithTx(tx)
+	sessionID := rec.Session.ID
+
+	if err := createSession(ctx, qtx, rec.Session); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	if err := qtx.DeleteLiveSession(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete live session: %w", err)
+	}
+	if err := qtx.DeleteSessionQuality(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session quality: %w", err)
+	}
+	if err := qtx.DeleteSessionTools(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session tools: %w", err)
+	}
+	if err := qtx.DeleteSessionFiles(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session files: %w", err)
+	}
+	if err := qtx.DeleteSessionCommands(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session commands: %w", err)
+	}
+	if err := qtx.DeleteSessionModelUsage(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session model usage: %w", err)
+	}
+	if err := qtx.DeleteSessionSubagents(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session subagents: %w", err)
+	}
+	if err := qtx.DeleteSessionSubagentTools(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session subagent tools: %w", err)
+	}
+	if err := qtx.DeleteSessionSubagentFiles(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session subagent files: %w", err)
+	}
+	if err := qtx.DeleteSessionCommits(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session commits: %w", err)
+	}
+	if err := qtx.DeleteSessionTurns(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session turns: %w", err)
+	}
+	if err := qtx.DeleteSessionSlashCommands(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session slash commands: %w", err)
+	}
+	if err := qtx.DeleteSessionHooks(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session hooks: %w", err)
+	}
+	if err := qtx.DeleteSessionParseAnomalies(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete session parse anomalies: %w", err)
+	}
+
+	if rec.Metrics != nil {
+		if err := createSessionMetrics(ctx, qtx, rec.Metrics); err != nil {
+			return fmt.Errorf("failed to create session metrics: %w", err)
+		}
+	}
+	if err := createSessionTools(ctx, qtx, rec.Tools); err != nil {
+		return err
+	}
+	if err := createSessionFiles(ctx, qtx, rec.Files); err != nil {
+		return err
+	}
+	if err := createSessionCommands(ctx, qtx, rec.Commands); err != nil {
+		return err
+	}
+	if err := createSessionModelUsage(ctx, qtx, rec.ModelUsage); err != nil {
+		return err
+	}
+	if err := createSessionSubagents(ctx, qtx, rec.Subagents); err != nil {
+		return err
+	}
+	if err := createSessionCommits(ctx, qtx, rec.Commits); err != nil {
+		return err
+	}
+	if err := createSessionTurns(ctx, qtx, rec.Turns); err != nil {
+		return err
+	}
+	if err := createSessionSlashCommands(ctx, qtx, rec.SlashCommands); err != nil {
+		return err
+	}
+	if err := createSessionHooks(ctx, qtx, rec.Hooks); err != nil {
+		return err
+	}
+	if err := createSessionParseAnomalies(ctx, qtx, rec.Anomalies); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
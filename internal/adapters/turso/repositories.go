@@ -8,18 +8,25 @@ import (
 
 // Repositories holds all turso repository implementations as port interfaces.
 type Repositories struct {
-	Sessions   ports.SessionRepository
-	Metrics    ports.SessionMetricsRepository
-	Tools      ports.SessionToolRepository
-	Files      ports.SessionFileRepository
-	Commands   ports.SessionCommandRepository
-	Subagents  ports.SessionSubagentRepository
+	Sessions    ports.SessionRepository
+	Metrics     ports.SessionMetricsRepository
+	Tools       ports.SessionToolRepository
+	Files       ports.SessionFileRepository
+	Commands    ports.SessionCommandRepository
+	Subagents   ports.SessionSubagentRepository
+	ModelUsage  ports.SessionModelUsageRepository
+	Anomalies   ports.SessionParseAnomalyRepository
+	Recordings  ports.SessionRecordingRepository
+	Live        ports.LiveSessionRepository
+	States      ports.TranscriptStateRepository
+	ToolRules   ports.ToolRuleRepository
 	Experiments ports.ExperimentRepository
-	Projects   ports.ProjectRepository
-	Pricing    ports.PricingRepository
-	Quality    ports.SessionQualityRepository
-	PlanConfig ports.PlanConfigRepository
-	Stats      ports.StatsRepository
+	Projects    ports.ProjectRepository
+	Pricing     ports.PricingRepository
+	Costs       ports.SessionCostRepository
+	Quality     ports.SessionQualityRepository
+	PlanConfig  ports.PlanConfigRepository
+	Stats       ports.StatsRepository
 }
 
 // NewRepositories creates all turso repository implementations from a database connection.
@@ -31,9 +38,16 @@ func NewRepositories(db *sql.DB) *Repositories {
 		Files:       NewSessionFileRepository(db),
 		Commands:    NewSessionCommandRepository(db),
 		Subagents:   NewSessionSubagentRepository(db),
+		ModelUsage:  NewSessionModelUsageRepository(db),
+		Anomalies:   NewSessionParseAnomalyRepository(db),
+		Recordings:  NewSessionRecordingRepository(db),
+		Live:        NewLiveSessionRepository(db),
+		States:      NewTranscriptStateRepository(db),
+		ToolRules:   NewToolRuleRepository(db),
 		Experiments: NewExperimentRepository(db),
 		Projects:    NewProjectRepository(db),
 		Pricing:     NewPricingRepository(db),
+		Costs:       NewSessionCostRepository(db),
 		Quality:     NewSessionQualityRepository(db),
 		PlanConfig:  NewPlanConfigRepository(db),
 		Stats:       NewStatsRepository(db),
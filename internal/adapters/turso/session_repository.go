@@ -25,6 +25,10 @@ func NewSessionRepository(db *sql.DB) *SessionRepository {
 }
 
 func (r *SessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	return createSession(ctx, r.queries, session)
+}
+
+func createSession(ctx context.Context, q *sqlc.Queries, session *domain.Session) error {
 	var startedAt, endedAt sql.NullString
 	if session.StartedAt != nil {
 		startedAt = sql.NullString{String: session.StartedAt.Format(time.RFC3339), Valid: true}
@@ -38,7 +42,12 @@ func (r *SessionRepository) Create(ctx context.Context, session *domain.Session)
 		durationSeconds = sql.NullInt64{Int64: *session.DurationSeconds, Valid: true}
 	}
 
-	return r.queries.CreateSession(ctx, sqlc.CreateSessionParams{
+	var gitDirty sql.NullInt64
+	if session.GitDirty != nil {
+		gitDirty = sql.NullInt64{Int64: util.BoolToInt64(*session.GitDirty), Valid: true}
+	}
+
+	return q.CreateSession(ctx, sqlc.CreateSessionParams{
 		ID:                   session.ID,
 		ProjectID:            session.ProjectID,
 		ExperimentID:         util.NullStringPtr(session.ExperimentID),
@@ -51,6 +60,10 @@ func (r *SessionRepository) Create(ctx context.Context, session *domain.Session)
 		EndedAt:              endedAt,
 		DurationSeconds:      durationSeconds,
 		CreatedAt:            session.CreatedAt.Format(time.RFC3339),
+		GitBranch:            util.NullStringPtr(session.GitBranch),
+		GitHeadBefore:        util.NullStringPtr(session.GitHeadBefore),
+		GitHeadAfter:         util.NullStringPtr(session.GitHeadAfter),
+		GitDirty:             gitDirty,
 	})
 }
 
@@ -79,6 +92,11 @@ func (r *SessionRepository) List(ctx context.Context, opts ports.ListSessionsOpt
 			ProjectID: *opts.ProjectID,
 			Limit:     limit,
 		})
+	} else if opts.Branch != nil {
+		rows, err = r.queries.ListSessionsByBranch(ctx, sqlc.ListSessionsByBranchParams{
+			GitBranch: util.NullStringPtr(opts.Branch),
+			Limit:     limit,
+		})
 	} else if opts.ExperimentID != nil {
 		rows, err = r.queries.ListSessionsByExperiment(ctx, sqlc.ListSessionsByExperimentParams{
 			ExperimentID: util.NullStringPtr(opts.ExperimentID),
@@ -100,19 +118,44 @@ func (r *SessionRepository) List(ctx context.Context, opts ports.ListSessionsOpt
 }
 
 func (r *SessionRepository) Delete(ctx context.Context, id string) error {
-	return r.queries.DeleteSession(ctx, id)
+	if err := r.queries.DeleteSession(ctx, id); err != nil {
+		return err
+	}
+	return r.deleteOrphanedStates(ctx)
 }
 
 func (r *SessionRepository) DeleteBefore(ctx context.Context, before string) (int64, error) {
-	return r.queries.DeleteSessionsBefore(ctx, before)
+	n, err := r.queries.DeleteSessionsBefore(ctx, before)
+	if err != nil {
+		return n, err
+	}
+	return n, r.deleteOrphanedStates(ctx)
 }
 
 func (r *SessionRepository) DeleteByProject(ctx context.Context, projectID string) (int64, error) {
-	return r.queries.DeleteSessionsByProject(ctx, projectID)
+	n, err := r.queries.DeleteSessionsByProject(ctx, projectID)
+	if err != nil {
+		return n, err
+	}
+	return n, r.deleteOrphanedStates(ctx)
 }
 
 func (r *SessionRepository) DeleteByExperiment(ctx context.Context, experimentID string) (int64, error) {
-	return r.queries.DeleteSessionsByExperiment(ctx, util.NullStringPtr(&experimentID))
+	n, err := r.queries.DeleteSessionsByExperiment(ctx, util.NullStringPtr(&experimentID))
+	if err != nil {
+		return n, err
+	}
+	return n, r.deleteOrphanedStates(ctx)
+}
+
+// deleteOrphanedStates drops the saved parser state of deleted sessions.
+// Transcript states aren't tied to sessions by a foreign key since they're
+// saved before a running session is recorded.
+func (r *SessionRepository) deleteOrphanedStates(ctx context.Context) error {
+	if err := r.queries.DeleteOrphanedTranscriptStates(ctx); err != nil {
+		return fmt.Errorf("failed to delete transcript states: %w", err)
+	}
+	return nil
 }
 
 func (r *SessionRepository) GetTranscriptPathsBefore(ctx context.Context, before string) ([]domain.TranscriptPathInfo, error) {
@@ -169,6 +212,12 @@ func sessionFromRow(row sqlc.Session) *domain.Session {
 		durationSeconds = &row.DurationSeconds.Int64
 	}
 
+	var gitDirty *bool
+	if row.GitDirty.Valid {
+		dirty := row.GitDirty.Int64 == 1
+		gitDirty = &dirty
+	}
+
 	return &domain.Session{
 		ID:                   row.ID,
 		ProjectID:            row.ProjectID,
@@ -182,5 +231,9 @@ func sessionFromRow(row sqlc.Session) *domain.Session {
 		EndedAt:              endedAt,
 		DurationSeconds:      durationSeconds,
 		CreatedAt:            createdAt,
+		GitBranch:            util.NullStringToPtr(row.GitBranch),
+		GitHeadBefore:        util.NullStringToPtr(row.GitHeadBefore),
+		GitHeadAfter:         util.NullStringToPtr(row.GitHeadAfter),
+		GitDirty:             gitDirty,
 	}
 }
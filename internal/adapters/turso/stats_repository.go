@@ -34,6 +34,10 @@ func (r *StatsRepository) GetAggregate(ctx context.Context, since string) (*doma
 		TotalTokenCacheWrite:   util.ToInt64(row.TotalTokenCacheWrite),
 		TotalCostUsd:           util.ToFloat64(row.TotalCostUsd),
 		TotalErrors:            util.ToInt64(row.TotalErrors),
+		TotalLinesAdded:        util.ToInt64(row.TotalLinesAdded),
+		TotalLinesRemoved:      util.ToInt64(row.TotalLinesRemoved),
+		TotalCompactions:       util.ToInt64(row.TotalCompactions),
+		TotalTokenCacheWasted:  util.ToInt64(row.TotalTokenCacheWasted),
 	}, nil
 }
 
@@ -56,6 +60,10 @@ func (r *StatsRepository) GetAggregateByExperiment(ctx context.Context, experime
 		TotalTokenCacheWrite:   util.ToInt64(row.TotalTokenCacheWrite),
 		TotalCostUsd:           util.ToFloat64(row.TotalCostUsd),
 		TotalErrors:            util.ToInt64(row.TotalErrors),
+		TotalLinesAdded:        util.ToInt64(row.TotalLinesAdded),
+		TotalLinesRemoved:      util.ToInt64(row.TotalLinesRemoved),
+		TotalCompactions:       util.ToInt64(row.TotalCompactions),
+		TotalTokenCacheWasted:  util.ToInt64(row.TotalTokenCacheWasted),
 	}, nil
 }
 
@@ -78,6 +86,10 @@ func (r *StatsRepository) GetAggregateByProject(ctx context.Context, projectID s
 		TotalTokenCacheWrite:   util.ToInt64(row.TotalTokenCacheWrite),
 		TotalCostUsd:           util.ToFloat64(row.TotalCostUsd),
 		TotalErrors:            util.ToInt64(row.TotalErrors),
+		TotalLinesAdded:        util.ToInt64(row.TotalLinesAdded),
+		TotalLinesRemoved:      util.ToInt64(row.TotalLinesRemoved),
+		TotalCompactions:       util.ToInt64(row.TotalCompactions),
+		TotalTokenCacheWasted:  util.ToInt64(row.TotalTokenCacheWasted),
 	}, nil
 }
 
@@ -93,13 +105,281 @@ func (r *StatsRepository) GetTopTools(ctx context.Context, since string, limit i
 	for i, row := range rows {
 		tools[i] = domain.ToolUsageStats{
 			ToolName:         row.ToolName,
-			TotalInvocations: int64(util.ToFloat64(row.TotalInvocations)),
-			TotalErrors:      int64(util.ToFloat64(row.TotalErrors)),
+			TotalInvocations: int64(row.TotalInvocations.Float64),
+			TotalErrors:      int64(row.TotalErrors.Float64),
+			AvgDurationMs:    util.AverageMs(row.TotalDurationMs.Float64, row.TimedInvocations.Float64),
 		}
 	}
 	return tools, nil
 }
 
+func (r *StatsRepository) GetLinesByLanguageByExperiment(ctx context.Context, experimentID string, since string) ([]domain.LanguageStats, error) {
+	rows, err := r.queries.GetLineChangesByLanguageByExperiment(ctx, sqlc.GetLineChangesByLanguageByExperimentParams{
+		ExperimentID: util.NullString(experimentID),
+		CreatedAt:    since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment line changes: %w", err)
+	}
+	languages := make([]domain.LanguageStats, len(rows))
+	for i, row := range rows {
+		languages[i] = domain.LanguageStats{
+			Language:     row.Language.String,
+			FileCount:    row.FileCount,
+			LinesAdded:   int64(row.TotalLinesAdded.Float64),
+			LinesRemoved: int64(row.TotalLinesRemoved.Float64),
+		}
+	}
+	return languages, nil
+}
+
+func (r *StatsRepository) GetMCPServers(ctx context.Context, since string) ([]domain.MCPServerStats, error) {
+	rows, err := r.queries.GetMCPServerUsage(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MCP server usage: %w", err)
+	}
+	servers := make([]domain.MCPServerStats, len(rows))
+	for i, row := range rows {
+		servers[i] = mcpServerStats(sqlc.GetMCPServerUsageRow(row))
+	}
+	return servers, nil
+}
+
+func (r *StatsRepository) GetMCPServersByExperiment(ctx context.Context, experimentID string, since string) ([]domain.MCPServerStats, error) {
+	rows, err := r.queries.GetMCPServerUsageByExperiment(ctx, sqlc.GetMCPServerUsageByExperimentParams{
+		ExperimentID: util.NullString(experimentID),
+		CreatedAt:    since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment MCP server usage: %w", err)
+	}
+	servers := make([]domain.MCPServerStats, len(rows))
+	for i, row := range rows {
+		servers[i] = mcpServerStats(sqlc.GetMCPServerUsageRow(row))
+	}
+	return servers, nil
+}
+
+func mcpServerStats(row sqlc.GetMCPServerUsageRow) domain.MCPServerStats {
+	return domain.MCPServerStats{
+		Server:           row.McpServer.String,
+		ToolCount:        row.ToolCount,
+		SessionCount:     row.SessionCount,
+		TotalInvocations: int64(row.TotalInvocations.Float64),
+		TotalErrors:      int64(row.TotalErrors.Float64),
+		ResultTokens:     int64(row.TotalResultTokens.Float64),
+	}
+}
+
+func (r *StatsRepository) GetMCPTools(ctx context.Context, since string) ([]domain.MCPToolStats, error) {
+	rows, err := r.queries.GetMCPToolUsage(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MCP tool usage: %w", err)
+	}
+	tools := make([]domain.MCPToolStats, len(rows))
+	for i, row := range rows {
+		tools[i] = mcpToolStats(sqlc.GetMCPToolUsageRow(row))
+	}
+	return tools, nil
+}
+
+func (r *StatsRepository) GetMCPToolsByExperiment(ctx context.Context, experimentID string, since string) ([]domain.MCPToolStats, error) {
+	rows, err := r.queries.GetMCPToolUsageByExperiment(ctx, sqlc.GetMCPToolUsageByExperimentParams{
+		ExperimentID: util.NullString(experimentID),
+		CreatedAt:    since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment MCP tool usage: %w", err)
+	}
+	tools := make([]domain.MCPToolStats, len(rows))
+	for i, row := range rows {
+		tools[i] = mcpToolStats(sqlc.GetMCPToolUsageRow(row))
+	}
+	return tools, nil
+}
+
+func mcpToolStats(row sqlc.GetMCPToolUsageRow) domain.MCPToolStats {
+	return domain.MCPToolStats{
+		Server:           row.McpServer.String,
+		Tool:             row.McpTool.String,
+		TotalInvocations: int64(row.TotalInvocations.Float64),
+		TotalErrors:      int64(row.TotalErrors.Float64),
+		ResultTokens:     int64(row.TotalResultTokens.Float64),
+	}
+}
+
+func (r *StatsRepository) GetSlashCommands(ctx context.Context, since string) ([]domain.SlashCommandStats, error) {
+	rows, err := r.queries.GetSlashCommandUsage(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slash command usage: %w", err)
+	}
+	commands := make([]domain.SlashCommandStats, len(rows))
+	for i, row := range rows {
+		commands[i] = domain.SlashCommandStats{
+			Command:          row.Command,
+			Kind:             row.Kind,
+			SessionCount:     row.SessionCount,
+			TotalInvocations: int64(row.TotalInvocations.Float64),
+			LastUsedAt:       util.ParseTimeRFC3339(row.LastUsedAt),
+		}
+	}
+	return commands, nil
+}
+
+func (r *StatsRepository) GetSkills(ctx context.Context, since string) ([]domain.SkillStats, error) {
+	rows, err := r.queries.GetSkillUsage(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get skill usage: %w", err)
+	}
+	skills := make([]domain.SkillStats, len(rows))
+	for i, row := range rows {
+		skills[i] = domain.SkillStats{
+			Skill:            row.Skill,
+			SessionCount:     row.SessionCount,
+			TotalInvocations: row.TotalInvocations,
+			LastUsedAt:       util.ParseTimeRFC3339(row.LastUsedAt),
+		}
+	}
+	return skills, nil
+}
+
+func (r *StatsRepository) GetHooks(ctx context.Context, since string) ([]domain.HookStats, error) {
+	rows, err := r.queries.GetHookUsage(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hook usage: %w", err)
+	}
+	hooks := make([]domain.HookStats, len(rows))
+	for i, row := range rows {
+		hooks[i] = domain.HookStats{
+			Event:           row.Event,
+			HookName:        row.HookName,
+			Command:         row.Command,
+			SessionCount:    row.SessionCount,
+			TotalExecutions: int64(row.TotalExecutions.Float64),
+			TotalErrors:     int64(row.TotalErrors.Float64),
+			TotalBlocked:    int64(row.TotalBlocked.Float64),
+		}
+	}
+	return hooks, nil
+}
+
+func (r *StatsRepository) GetSlowestCommands(ctx context.Context, since string, limit int) ([]domain.CommandStats, error) {
+	rows, err := r.queries.GetSlowestCommands(ctx, sqlc.GetSlowestCommandsParams{
+		CreatedAt: since,
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get slowest commands: %w", err)
+	}
+	commands := make([]domain.CommandStats, len(rows))
+	for i, row := range rows {
+		commands[i] = commandStats(row.Command, row.RunCount, row.FailureCount, row.AvgDurationMs, row.MaxDurationMs)
+	}
+	return commands, nil
+}
+
+func (r *StatsRepository) GetFailingCommands(ctx context.Context, since string, limit int) ([]domain.CommandStats, error) {
+	rows, err := r.queries.GetMostFailingCommands(ctx, sqlc.GetMostFailingCommandsParams{
+		CreatedAt: since,
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get failing commands: %w", err)
+	}
+	commands := make([]domain.CommandStats, len(rows))
+	for i, row := range rows {
+		commands[i] = commandStats(row.Command, row.RunCount, row.FailureCount, row.AvgDurationMs, row.MaxDurationMs)
+	}
+	return commands, nil
+}
+
+func commandStats(command string, runs int64, failures, avgMs sql.NullFloat64, maxMs any) domain.CommandStats {
+	stats := domain.CommandStats{
+		Command:      command,
+		RunCount:     runs,
+		FailureCount: int64(failures.Float64),
+	}
+	if avgMs.Valid {
+		maxDuration := util.ToFloat64(maxMs)
+		stats.AvgDurationMs = &avgMs.Float64
+		stats.MaxDurationMs = &maxDuration
+	}
+	return stats
+}
+
+func (r *StatsRepository) GetModelUsage(ctx context.Context, since string) ([]domain.ModelUsageStats, error) {
+	rows, err := r.queries.GetModelUsageStats(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model usage stats: %w", err)
+	}
+	models := make([]domain.ModelUsageStats, len(rows))
+	for i, row := range rows {
+		models[i] = domain.ModelUsageStats{
+			ModelID:              row.ModelID,
+			SessionCount:         row.SessionCount,
+			TotalMessages:        util.ToInt64(row.TotalMessages),
+			TotalTokenInput:      util.ToInt64(row.TotalTokenInput),
+			TotalTokenOutput:     util.ToInt64(row.TotalTokenOutput),
+			TotalTokenCacheRead:  util.ToInt64(row.TotalTokenCacheRead),
+			TotalTokenCacheWrite: util.ToInt64(row.TotalTokenCacheWrite),
+			TotalCostUsd:         util.ToFloat64(row.TotalCostUsd),
+		}
+	}
+	return models, nil
+}
+
+// GetParseDiagnostics returns the parse anomalies of sessions recorded since
+// the given date, and up to limit of the most recent sessions flagged by them.
+func (r *StatsRepository) GetParseDiagnostics(ctx context.Context, since string, limit int) (*domain.ParseDiagnostics, error) {
+	coverage, err := r.queries.GetParseDiagnosticsCoverage(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parse diagnostics coverage: %w", err)
+	}
+	diagnostics := &domain.ParseDiagnostics{
+		SessionCount:   coverage.SessionCount,
+		UncheckedCount: coverage.UncheckedCount,
+	}
+
+	anomalies, err := r.queries.GetParseAnomalyUsage(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parse anomalies: %w", err)
+	}
+	diagnostics.Anomalies = make([]domain.ParseAnomalyStats, len(anomalies))
+	for i, row := range anomalies {
+		diagnostics.Anomalies[i] = domain.ParseAnomalyStats{
+			Kind:             row.Kind,
+			Detail:           row.Detail,
+			SessionCount:     row.SessionCount,
+			TotalOccurrences: int64(row.TotalOccurrences.Float64),
+			FirstSeenAt:      util.ParseTimeRFC3339(row.FirstSeenAt),
+			LastSeenAt:       util.ParseTimeRFC3339(row.LastSeenAt),
+		}
+	}
+
+	sessions, err := r.queries.GetAnomalousSessions(ctx, sqlc.GetAnomalousSessionsParams{
+		CreatedAt: since,
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anomalous sessions: %w", err)
+	}
+	diagnostics.Sessions = make([]domain.AnomalousSession, len(sessions))
+	for i, row := range sessions {
+		diagnostics.Sessions[i] = domain.AnomalousSession{
+			SessionID:         row.ID,
+			TranscriptPath:    row.TranscriptPath,
+			RecordedAt:        util.ParseTimeRFC3339(row.RecordedAt),
+			TranscriptLines:   row.TranscriptLineCount,
+			AssistantMessages: row.MessageCountAssistant,
+			TotalTokens:       row.TotalTokens,
+			MalformedLines:    row.MalformedLines,
+			UnknownTypes:      row.UnknownTypes,
+			MissingUsage:      row.MissingUsage,
+		}
+	}
+	return diagnostics, nil
+}
+
 func (r *StatsRepository) GetAllExperimentStats(ctx context.Context) ([]domain.ExperimentStats, error) {
 	rows, err := r.queries.GetStatsForAllExperiments(ctx)
 	if err != nil {
@@ -0,0 +1,78 @@
+package turso
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/sqlc/generated"
+)
+
+type ToolRuleRepository struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+func NewToolRuleRepository(db *sql.DB) *ToolRuleRepository {
+	return &ToolRuleRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *ToolRuleRepository) Set(ctx context.Context, rule *domain.ToolRule) error {
+	err := r.queries.UpsertToolRule(ctx, sqlc.UpsertToolRuleParams{
+		Tool:      rule.Tool,
+		Operation: rule.Operation,
+		Fields:    strings.Join(rule.Fields, ","),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save tool rule: %w", err)
+	}
+	return nil
+}
+
+func (r *ToolRuleRepository) Get(ctx context.Context, tool string) (*domain.ToolRule, error) {
+	row, err := r.queries.GetToolRule(ctx, tool)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tool rule: %w", err)
+	}
+	return toolRuleFromRow(row), nil
+}
+
+func (r *ToolRuleRepository) List(ctx context.Context) ([]*domain.ToolRule, error) {
+	rows, err := r.queries.ListToolRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tool rules: %w", err)
+	}
+
+	rules := make([]*domain.ToolRule, len(rows))
+	for i, row := range rows {
+		rules[i] = toolRuleFromRow(row)
+	}
+	return rules, nil
+}
+
+func (r *ToolRuleRepository) Delete(ctx context.Context, tool string) error {
+	if err := r.queries.DeleteToolRule(ctx, tool); err != nil {
+		return fmt.Errorf("failed to delete tool rule: %w", err)
+	}
+	return nil
+}
+
+func toolRuleFromRow(row sqlc.ToolRule) *domain.ToolRule {
+	createdAt, _ := time.Parse(time.RFC3339, row.CreatedAt)
+	return &domain.ToolRule{
+		Tool:      row.Tool,
+		Operation: row.Operation,
+		Fields:    strings.Split(row.Fields, ","),
+		CreatedAt: createdAt,
+	}
+}
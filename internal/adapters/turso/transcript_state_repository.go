@@ -0,0 +1,62 @@
+package turso
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/util"
+	"github.com/emiliopalmerini/mclaude/sqlc/generated"
+)
+
+type TranscriptStateRepository struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+func NewTranscriptStateRepository(db *sql.DB) *TranscriptStateRepository {
+	return &TranscriptStateRepository{
+		db:      db,
+		queries: sqlc.New(db),
+	}
+}
+
+func (r *TranscriptStateRepository) Get(ctx context.Context, sessionID string) (*domain.TranscriptState, error) {
+	row, err := r.queries.GetTranscriptState(ctx, sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get transcript state: %w", err)
+	}
+	return &domain.TranscriptState{
+		SessionID:      row.SessionID,
+		TranscriptPath: row.TranscriptPath,
+		ByteOffset:     row.ByteOffset,
+		State:          []byte(row.State),
+		UpdatedAt:      util.ParseTimeRFC3339(row.UpdatedAt),
+	}, nil
+}
+
+func (r *TranscriptStateRepository) Save(ctx context.Context, state *domain.TranscriptState) error {
+	err := r.queries.SaveTranscriptState(ctx, sqlc.SaveTranscriptStateParams{
+		SessionID:      state.SessionID,
+		TranscriptPath: state.TranscriptPath,
+		ByteOffset:     state.ByteOffset,
+		State:          string(state.State),
+		UpdatedAt:      state.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save transcript state: %w", err)
+	}
+	return nil
+}
+
+func (r *TranscriptStateRepository) Delete(ctx context.Context, sessionID string) error {
+	if err := r.queries.DeleteTranscriptState(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to delete transcript state: %w", err)
+	}
+	return nil
+}
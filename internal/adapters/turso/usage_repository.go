@@ -64,8 +64,10 @@ func (r *PlanConfigRepository) GetRollingWindowSummary(ctx context.Context, wind
 		return nil, fmt.Errorf("failed to get rolling window usage: %w", err)
 	}
 	return &domain.UsageSummary{
-		TotalTokens: row.TotalTokens,
-		TotalCost:   row.TotalCost,
+		TotalTokens:  row.TotalTokens,
+		TotalCost:    row.TotalCost,
+		LiveTokens:   row.LiveTokens,
+		LiveSessions: int(row.LiveSessions),
 	}, nil
 }
 
@@ -127,8 +129,10 @@ func (r *PlanConfigRepository) GetWeeklyWindowSummary(ctx context.Context) (*dom
 		return nil, fmt.Errorf("failed to get weekly window usage: %w", err)
 	}
 	return &domain.UsageSummary{
-		TotalTokens: row.TotalTokens,
-		TotalCost:   row.TotalCost,
+		TotalTokens:  row.TotalTokens,
+		TotalCost:    row.TotalCost,
+		LiveTokens:   row.LiveTokens,
+		LiveSessions: int(row.LiveSessions),
 	}, nil
 }
 
@@ -5,25 +5,31 @@ import (
 
 	"github.com/emiliopalmerini/mclaude/internal/adapters/storage"
 	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
 	"github.com/emiliopalmerini/mclaude/internal/ports"
 )
 
 // AppContext holds all shared dependencies for CLI commands.
 type AppContext struct {
-	DB               *turso.DB
-	SessionRepo      ports.SessionRepository
-	MetricsRepo      ports.SessionMetricsRepository
-	ToolRepo         ports.SessionToolRepository
-	FileRepo         ports.SessionFileRepository
-	CommandRepo      ports.SessionCommandRepository
-	SubagentRepo     ports.SessionSubagentRepository
-	ExperimentRepo   ports.ExperimentRepository
-	ProjectRepo      ports.ProjectRepository
-	PricingRepo      ports.PricingRepository
-	QualityRepo      ports.SessionQualityRepository
+	DB                *turso.DB
+	SessionRepo       ports.SessionRepository
+	MetricsRepo       ports.SessionMetricsRepository
+	ToolRepo          ports.SessionToolRepository
+	FileRepo          ports.SessionFileRepository
+	CommandRepo       ports.SessionCommandRepository
+	SubagentRepo      ports.SessionSubagentRepository
+	ModelUsageRepo    ports.SessionModelUsageRepository
+	AnomalyRepo       ports.SessionParseAnomalyRepository
+	ExperimentRepo    ports.ExperimentRepository
+	ProjectRepo       ports.ProjectRepository
+	PricingRepo       ports.PricingRepository
+	CostRepo          ports.SessionCostRepository
+	QualityRepo       ports.SessionQualityRepository
 	PlanConfigRepo    ports.PlanConfigRepository
 	StatsRepo         ports.StatsRepository
+	ToolRuleRepo      ports.ToolRuleRepository
 	TranscriptStorage ports.TranscriptStorage
+	Recorder          *ingest.Recorder
 }
 
 // NewAppContext creates an AppContext with all dependencies initialized.
@@ -39,21 +45,28 @@ func NewAppContext() (*AppContext, error) {
 		return nil, fmt.Errorf("failed to initialize transcript storage: %w", err)
 	}
 
+	repos := turso.NewRepositories(db.DB)
+
 	return &AppContext{
 		DB:                db,
-		SessionRepo:       turso.NewSessionRepository(db.DB),
-		MetricsRepo:       turso.NewSessionMetricsRepository(db.DB),
-		ToolRepo:          turso.NewSessionToolRepository(db.DB),
-		FileRepo:          turso.NewSessionFileRepository(db.DB),
-		CommandRepo:       turso.NewSessionCommandRepository(db.DB),
-		SubagentRepo:      turso.NewSessionSubagentRepository(db.DB),
-		ExperimentRepo:    turso.NewExperimentRepository(db.DB),
-		ProjectRepo:       turso.NewProjectRepository(db.DB),
-		PricingRepo:       turso.NewPricingRepository(db.DB),
-		QualityRepo:       turso.NewSessionQualityRepository(db.DB),
-		PlanConfigRepo:    turso.NewPlanConfigRepository(db.DB),
-		StatsRepo:         turso.NewStatsRepository(db.DB),
+		SessionRepo:       repos.Sessions,
+		MetricsRepo:       repos.Metrics,
+		ToolRepo:          repos.Tools,
+		FileRepo:          repos.Files,
+		CommandRepo:       repos.Commands,
+		SubagentRepo:      repos.Subagents,
+		ModelUsageRepo:    repos.ModelUsage,
+		AnomalyRepo:       repos.Anomalies,
+		ExperimentRepo:    repos.Experiments,
+		ProjectRepo:       repos.Projects,
+		PricingRepo:       repos.Pricing,
+		CostRepo:          repos.Costs,
+		QualityRepo:       repos.Quality,
+		PlanConfigRepo:    repos.PlanConfig,
+		StatsRepo:         repos.Stats,
+		ToolRuleRepo:      repos.ToolRules,
 		TranscriptStorage: transcriptStorage,
+		Recorder:          newRecorder(repos, transcriptStorage),
 	}, nil
 }
 
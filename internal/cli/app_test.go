@@ -15,6 +15,8 @@ func TestAppContextFieldTypes(t *testing.T) {
 	var _ ports.SessionFileRepository = a.FileRepo
 	var _ ports.SessionCommandRepository = a.CommandRepo
 	var _ ports.SessionSubagentRepository = a.SubagentRepo
+	var _ ports.SessionModelUsageRepository = a.ModelUsageRepo
+	var _ ports.SessionCostRepository = a.CostRepo
 	var _ ports.ExperimentRepository = a.ExperimentRepo
 	var _ ports.ProjectRepository = a.ProjectRepo
 	var _ ports.PricingRepository = a.PricingRepo
@@ -3,11 +3,15 @@ package cli
 import (
 	"context"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/parser"
 )
 
 var configCmd = &cobra.Command{
@@ -24,21 +28,76 @@ var configModelCmd = &cobra.Command{
 Without arguments, shows the current default model.
 With an argument, sets the default model.
 
-Accepts short names: opus, sonnet, haiku
+Accepts model aliases (see 'mclaude cost alias list'), e.g. opus, sonnet, haiku
 Or partial matches: "opus 4.5", "Claude Sonnet"
 
 Examples:
   mclaude config model           # Show current default
-  mclaude config model opus      # Set Opus 4.5 as default
-  mclaude config model sonnet    # Set Sonnet 4.5 as default
-  mclaude config model haiku     # Set Haiku 3.5 as default`,
+  mclaude config model opus      # Set the model the opus alias points to
+  mclaude config model sonnet    # Set the model the sonnet alias points to
+  mclaude config model "opus 4.1"`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runConfigModel,
 }
 
+var configToolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Manage tool rules for file tracking",
+	Long: `Manage the rules that tell which files a tool call touches.
+
+mclaude knows the files touched by Claude Code's own tools (Read, Edit,
+MultiEdit, NotebookEdit, Glob, Grep...), by common shell commands run through
+Bash and, by guessing from their names, by MCP tools. Rules added here map
+other tools to a file operation and the input fields naming the files, and
+replace the built-in rule for the same tool. They apply to sessions recorded
+afterwards.`,
+}
+
+var configToolsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List tool rules",
+	RunE:  runConfigToolsList,
+}
+
+var configToolsSetCmd = &cobra.Command{
+	Use:   "set <tool> <operation> <field>...",
+	Short: "Map a tool to a file operation",
+	Long: `Map a tool to a file operation, reading file paths from the given input
+fields. A tool name ending in "*" matches every tool with that prefix.
+
+Operations: read, write, edit, search, delete
+
+Examples:
+  mclaude config tools set mcp__notes__append_note write path
+  mclaude config tools set "mcp__docs__*" read file_path paths`,
+	Args: cobra.MinimumNArgs(3),
+	RunE: runConfigToolsSet,
+}
+
+var configToolsDeleteCmd = &cobra.Command{
+	Use:   "delete <tool>",
+	Short: "Delete a tool rule",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigToolsDelete,
+}
+
+// toolOperations are the operations a tool rule may map to.
+var toolOperations = []string{
+	parser.OperationRead,
+	parser.OperationWrite,
+	parser.OperationEdit,
+	parser.OperationSearch,
+	parser.OperationDelete,
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configModelCmd)
+	configCmd.AddCommand(configToolsCmd)
+
+	configToolsCmd.AddCommand(configToolsListCmd)
+	configToolsCmd.AddCommand(configToolsSetCmd)
+	configToolsCmd.AddCommand(configToolsDeleteCmd)
 }
 
 func runConfigModel(cmd *cobra.Command, args []string) error {
@@ -55,7 +114,13 @@ func showDefaultModel(ctx context.Context) error {
 	if err != nil || defaultModel == nil {
 		fmt.Println("No default model configured")
 		fmt.Println("\nUse 'mclaude config model <name>' to set one")
-		fmt.Println("Available: opus, sonnet, haiku")
+		if aliases, _ := app.PricingRepo.ListAliases(ctx); len(aliases) > 0 {
+			names := make([]string, len(aliases))
+			for i, a := range aliases {
+				names[i] = a.Alias
+			}
+			fmt.Printf("Available: %s\n", strings.Join(names, ", "))
+		}
 		return nil
 	}
 
@@ -86,34 +151,25 @@ func setDefaultModel(ctx context.Context, name string) error {
 	nameLower := strings.ToLower(name)
 	var match *domain.ModelPricing
 
-	for _, m := range models {
-		idLower := strings.ToLower(m.ID)
-		displayLower := strings.ToLower(m.DisplayName)
-
-		switch nameLower {
-		case "opus":
-			if strings.Contains(idLower, "opus") {
-				match = m
-			}
-		case "sonnet":
-			if strings.Contains(idLower, "sonnet") {
-				match = m
-			}
-		case "haiku":
-			if strings.Contains(idLower, "haiku") {
+	// An alias names the model exactly
+	if alias, _ := app.PricingRepo.GetAlias(ctx, nameLower); alias != nil {
+		for _, m := range models {
+			if m.ID == alias.ModelID {
 				match = m
+				break
 			}
 		}
+	}
 
-		if match == nil {
+	if match == nil {
+		for _, m := range models {
+			idLower := strings.ToLower(m.ID)
+			displayLower := strings.ToLower(m.DisplayName)
 			if strings.Contains(idLower, nameLower) || strings.Contains(displayLower, nameLower) {
 				match = m
+				break
 			}
 		}
-
-		if match != nil {
-			break
-		}
 	}
 
 	if match == nil {
@@ -132,3 +188,72 @@ func setDefaultModel(ctx context.Context, name string) error {
 	fmt.Printf("Default model set to: %s\n", match.DisplayName)
 	return nil
 }
+
+func runConfigToolsList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	rules, err := app.ToolRuleRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		fmt.Println("No tool rules configured, only the built-in rules apply")
+		fmt.Println("\nUse 'mclaude config tools set' to add one")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOOL\tOPERATION\tFIELDS")
+	fmt.Fprintln(w, "----\t---------\t------")
+	for _, r := range rules {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Tool, r.Operation, strings.Join(r.Fields, ", "))
+	}
+	w.Flush()
+	return nil
+}
+
+func runConfigToolsSet(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	rule := &domain.ToolRule{
+		Tool:      args[0],
+		Operation: strings.ToLower(args[1]),
+		Fields:    args[2:],
+	}
+
+	if !slices.Contains(toolOperations, rule.Operation) {
+		return fmt.Errorf("invalid operation %q (valid: %s)", rule.Operation, strings.Join(toolOperations, ", "))
+	}
+	for _, field := range rule.Fields {
+		if field == "" || strings.Contains(field, ",") {
+			return fmt.Errorf("invalid input field %q", field)
+		}
+	}
+
+	if err := app.ToolRuleRepo.Set(ctx, rule); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s calls now %s the files in: %s\n", rule.Tool, rule.Operation, strings.Join(rule.Fields, ", "))
+	return nil
+}
+
+func runConfigToolsDelete(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	tool := args[0]
+
+	existing, err := app.ToolRuleRepo.Get(ctx, tool)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("no tool rule for %q", tool)
+	}
+
+	if err := app.ToolRuleRepo.Delete(ctx, tool); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted tool rule for %s\n", tool)
+	return nil
+}
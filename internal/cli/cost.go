@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/pricing"
 )
 
 var costCmd = &cobra.Command{
@@ -21,7 +23,10 @@ var costCmd = &cobra.Command{
 var costListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List configured model pricing",
-	RunE:  runCostList,
+	Long: `List the current pricing of each model.
+
+Use --history to include past rates with the period each one applied to.`,
+	RunE: runCostList,
 }
 
 var costSetCmd = &cobra.Command{
@@ -29,10 +34,15 @@ var costSetCmd = &cobra.Command{
 	Short: "Set model pricing",
 	Long: `Set pricing for a model (USD per 1M tokens).
 
+Setting pricing for a model that already has pricing adds a new version
+effective from now (or --effective-from). Sessions that ran earlier keep
+being priced at the previous rates.
+
 Examples:
   mclaude cost set claude-sonnet-4-20250514 --input 3.00 --output 15.00
   mclaude cost set claude-opus-4-6-20260115 --input 5.00 --output 25.00 --cache-read 0.50 --cache-write 6.25 --long-input 10.00 --long-output 37.50
-  mclaude cost set claude-opus-4-20250514 --input 15.00 --output 75.00 --cache-read 1.50 --cache-write 18.75`,
+  mclaude cost set claude-opus-4-20250514 --input 15.00 --output 75.00 --cache-read 1.50 --cache-write 18.75
+  mclaude cost set claude-sonnet-4-20250514 --input 2.50 --output 12.50 --effective-from 2026-03-01`,
 	Args: cobra.ExactArgs(1),
 	RunE: runCostSet,
 }
@@ -51,6 +61,112 @@ var costDeleteCmd = &cobra.Command{
 	RunE:  runCostDelete,
 }
 
+var costRecomputeCmd = &cobra.Command{
+	Use:   "recompute",
+	Short: "Re-price recorded sessions with the configured pricing",
+	Long: `Recalculate session, per-model and sub-agent cost estimates using the
+model pricing in force when each session ran, e.g. after 'mclaude cost set'
+or a pricing migration.
+
+Sessions with an archived transcript are re-parsed so each request is priced
+on its own; the rest are re-priced from their stored token counts. All
+updates are written in a single transaction.
+
+Examples:
+  mclaude cost recompute --dry-run                      # Preview all changes
+  mclaude cost recompute --since 2026-01-01             # Sessions since a date
+  mclaude cost recompute --experiment "baseline"        # One experiment
+  mclaude cost recompute --model claude-opus-4-6-20260206`,
+	RunE: runCostRecompute,
+}
+
+var costImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import model pricing and aliases from a file",
+	Long: `Import model pricing and aliases from a YAML or JSON pricing file
+(chosen by extension), e.g. one shared in version control.
+
+Models in the file are created or updated and models not in the file are
+left alone. When a model's rates change and the file gives a later
+effective_from, a new pricing version is added so earlier sessions keep the
+old rates. Every change is listed before it is applied.
+
+File format (token rates are USD per 1M tokens, web_search and web_fetch
+are USD per 1,000 requests):
+
+  models:
+    - id: claude-sonnet-4-5-20250929
+      name: Claude Sonnet 4.5
+      input: 3.00
+      output: 15.00
+      cache_read: 0.30             # optional
+      cache_write: 3.75            # optional
+      long_context:                # optional
+        input: 6.00
+        output: 22.50
+        threshold: 200000          # defaults to 200000
+      web_search: 10.00            # optional, defaults to 10.00
+      web_fetch: 0.00              # optional
+      default: true                # at most one model
+      effective_from: 2026-01-01   # optional, YYYY-MM-DD or RFC 3339
+  aliases:
+    sonnet: claude-sonnet-4-5-20250929
+
+Examples:
+  mclaude cost import pricing.yaml --dry-run   # Preview changes
+  mclaude cost import pricing.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCostImport,
+}
+
+var costExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export model pricing and aliases to a file",
+	Long: `Write the current model pricing and aliases as a pricing file that
+'mclaude cost import' can read.
+
+Examples:
+  mclaude cost export                        # YAML to stdout
+  mclaude cost export -o pricing.yaml
+  mclaude cost export --format json -o pricing.json`,
+	RunE: runCostExport,
+}
+
+var costAliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage model aliases",
+	Long: `Manage the aliases that map short model names to model IDs.
+
+Sub-agents report models by alias (e.g. "haiku"), so their costs are priced
+with the model each alias points to. Aliases are also accepted by
+'mclaude config model'.`,
+}
+
+var costAliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List model aliases",
+	RunE:  runCostAliasList,
+}
+
+var costAliasSetCmd = &cobra.Command{
+	Use:   "set <alias> <model-id>",
+	Short: "Point an alias at a model",
+	Long: `Point an alias at a model, creating the alias if needed.
+
+Examples:
+  mclaude cost alias set haiku claude-haiku-4-5-20251001
+  mclaude cost alias set sonnet claude-sonnet-4-5-20250929`,
+	Args: cobra.ExactArgs(2),
+	RunE: runCostAliasSet,
+}
+
+var costAliasDeleteCmd = &cobra.Command{
+	Use:   "delete <alias>",
+	Short: "Delete a model alias",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runCostAliasDelete,
+}
+
 // Flags
 var (
 	costInput         float64
@@ -61,6 +177,21 @@ var (
 	costLongInput     float64
 	costLongOutput    float64
 	costLongThreshold int64
+	costWebSearch     float64
+	costWebFetch      float64
+	costEffectiveFrom string
+	costListHistory   bool
+
+	recomputeSince      string
+	recomputeUntil      string
+	recomputeProject    string
+	recomputeExperiment string
+	recomputeModel      string
+	recomputeDryRun     bool
+
+	costImportDryRun bool
+	costExportOutput string
+	costExportFormat string
 )
 
 func init() {
@@ -70,6 +201,14 @@ func init() {
 	costCmd.AddCommand(costSetCmd)
 	costCmd.AddCommand(costDefaultCmd)
 	costCmd.AddCommand(costDeleteCmd)
+	costCmd.AddCommand(costRecomputeCmd)
+	costCmd.AddCommand(costImportCmd)
+	costCmd.AddCommand(costExportCmd)
+	costCmd.AddCommand(costAliasCmd)
+
+	costAliasCmd.AddCommand(costAliasListCmd)
+	costAliasCmd.AddCommand(costAliasSetCmd)
+	costAliasCmd.AddCommand(costAliasDeleteCmd)
 
 	costSetCmd.Flags().Float64Var(&costInput, "input", 0, "Input tokens cost per 1M (required)")
 	costSetCmd.Flags().Float64Var(&costOutput, "output", 0, "Output tokens cost per 1M (required)")
@@ -78,15 +217,36 @@ func init() {
 	costSetCmd.Flags().Float64Var(&costLongInput, "long-input", 0, "Long context input cost per 1M (>200K tokens)")
 	costSetCmd.Flags().Float64Var(&costLongOutput, "long-output", 0, "Long context output cost per 1M (>200K tokens)")
 	costSetCmd.Flags().Int64Var(&costLongThreshold, "long-threshold", 200000, "Input token threshold for long context pricing")
+	costSetCmd.Flags().Float64Var(&costWebSearch, "web-search", 0, "Web search cost per 1K requests (defaults to $10)")
+	costSetCmd.Flags().Float64Var(&costWebFetch, "web-fetch", 0, "Web fetch cost per 1K requests")
 	costSetCmd.Flags().StringVar(&costName, "name", "", "Display name (defaults to model ID)")
+	costSetCmd.Flags().StringVar(&costEffectiveFrom, "effective-from", "", "Date the pricing takes effect (YYYY-MM-DD, defaults to now)")
 	costSetCmd.MarkFlagRequired("input")
 	costSetCmd.MarkFlagRequired("output")
+
+	costListCmd.Flags().BoolVar(&costListHistory, "history", false, "Include past pricing versions")
+
+	costImportCmd.Flags().BoolVar(&costImportDryRun, "dry-run", false, "Show the changes without saving")
+
+	costExportCmd.Flags().StringVarP(&costExportOutput, "output", "o", "", "Output file (defaults to stdout)")
+	costExportCmd.Flags().StringVar(&costExportFormat, "format", "", "File format: yaml or json (defaults to the output file extension, else yaml)")
+
+	costRecomputeCmd.Flags().StringVar(&recomputeSince, "since", "", "Only sessions created on or after this date (YYYY-MM-DD)")
+	costRecomputeCmd.Flags().StringVar(&recomputeUntil, "until", "", "Only sessions created on or before this date (YYYY-MM-DD)")
+	costRecomputeCmd.Flags().StringVar(&recomputeProject, "project", "", "Filter by project ID")
+	costRecomputeCmd.Flags().StringVarP(&recomputeExperiment, "experiment", "e", "", "Filter by experiment name")
+	costRecomputeCmd.Flags().StringVar(&recomputeModel, "model", "", "Filter by model ID")
+	costRecomputeCmd.Flags().BoolVar(&recomputeDryRun, "dry-run", false, "Show the before/after costs without saving")
 }
 
 func runCostList(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	pricing, err := app.PricingRepo.List(ctx)
+	list := app.PricingRepo.List
+	if costListHistory {
+		list = app.PricingRepo.ListHistory
+	}
+	pricing, err := list(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to list pricing: %w", err)
 	}
@@ -98,8 +258,14 @@ func runCostList(cmd *cobra.Command, args []string) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "MODEL ID\tNAME\tINPUT/1M\tOUTPUT/1M\tCACHE R/1M\tCACHE W/1M\tLONG IN/1M\tLONG OUT/1M\tDEFAULT")
-	fmt.Fprintln(w, "--------\t----\t--------\t---------\t----------\t----------\t----------\t-----------\t-------")
+	header := "MODEL ID\tNAME\tINPUT/1M\tOUTPUT/1M\tCACHE R/1M\tCACHE W/1M\tLONG IN/1M\tLONG OUT/1M\tSEARCH/1K\tFETCH/1K\tDEFAULT"
+	divider := "--------\t----\t--------\t---------\t----------\t----------\t----------\t-----------\t---------\t--------\t-------"
+	if costListHistory {
+		header += "\tFROM\tTO"
+		divider += "\t----\t--"
+	}
+	fmt.Fprintln(w, header)
+	fmt.Fprintln(w, divider)
 
 	for _, p := range pricing {
 		cacheRead := "-"
@@ -118,14 +284,34 @@ func runCostList(cmd *cobra.Command, args []string) error {
 		if p.LongContextOutputPerMillion != nil {
 			longOutput = fmt.Sprintf("$%.2f", *p.LongContextOutputPerMillion)
 		}
+		webSearch := "-"
+		if p.WebSearchPerThousand != nil {
+			webSearch = fmt.Sprintf("$%.2f", *p.WebSearchPerThousand)
+		}
+		webFetch := "-"
+		if p.WebFetchPerThousand != nil {
+			webFetch = fmt.Sprintf("$%.2f", *p.WebFetchPerThousand)
+		}
 		isDefault := ""
-		if p.IsDefault {
+		if p.IsDefault && p.EffectiveTo == nil {
 			isDefault = "*"
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t$%.2f\t$%.2f\t%s\t%s\t%s\t%s\t%s\n",
+		fmt.Fprintf(w, "%s\t%s\t$%.2f\t$%.2f\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
 			p.ID, p.DisplayName, p.InputPerMillion, p.OutputPerMillion,
-			cacheRead, cacheWrite, longInput, longOutput, isDefault)
+			cacheRead, cacheWrite, longInput, longOutput, webSearch, webFetch, isDefault)
+		if costListHistory {
+			from := "-"
+			if p.EffectiveFrom.After(domain.PricingEpoch) {
+				from = p.EffectiveFrom.Local().Format("2006-01-02 15:04")
+			}
+			to := "current"
+			if p.EffectiveTo != nil {
+				to = p.EffectiveTo.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "\t%s\t%s", from, to)
+		}
+		fmt.Fprintln(w)
 	}
 
 	w.Flush()
@@ -142,11 +328,11 @@ func runCostSet(cmd *cobra.Command, args []string) error {
 	}
 
 	pricing := &domain.ModelPricing{
-		ID:              modelID,
-		DisplayName:     displayName,
-		InputPerMillion: costInput,
+		ID:               modelID,
+		DisplayName:      displayName,
+		InputPerMillion:  costInput,
 		OutputPerMillion: costOutput,
-		CreatedAt:       time.Now().UTC(),
+		CreatedAt:        time.Now().UTC(),
 	}
 
 	if costCacheRead > 0 {
@@ -164,15 +350,32 @@ func runCostSet(cmd *cobra.Command, args []string) error {
 	if costLongInput > 0 || costLongOutput > 0 {
 		pricing.LongContextThreshold = &costLongThreshold
 	}
+	if costWebSearch > 0 {
+		pricing.WebSearchPerThousand = &costWebSearch
+	}
+	if costWebFetch > 0 {
+		pricing.WebFetchPerThousand = &costWebFetch
+	}
+
+	if costEffectiveFrom != "" {
+		t, err := time.ParseInLocation("2006-01-02", costEffectiveFrom, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --effective-from date %q (expected YYYY-MM-DD): %w", costEffectiveFrom, err)
+		}
+		pricing.EffectiveFrom = t.UTC()
+	}
 
 	existing, _ := app.PricingRepo.GetByID(ctx, modelID)
 	if existing != nil {
 		pricing.IsDefault = existing.IsDefault
-		pricing.CreatedAt = existing.CreatedAt
-		if err := app.PricingRepo.Update(ctx, pricing); err != nil {
+		if pricing.EffectiveFrom.IsZero() {
+			pricing.EffectiveFrom = pricing.CreatedAt.Truncate(time.Second)
+		}
+		if err := app.PricingRepo.AddVersion(ctx, pricing); err != nil {
 			return fmt.Errorf("failed to update pricing: %w", err)
 		}
-		fmt.Printf("Updated pricing for %s\n", modelID)
+		fmt.Printf("Updated pricing for %s (effective %s)\n", modelID, pricing.EffectiveFrom.Local().Format("2006-01-02 15:04"))
+		fmt.Println("Run 'mclaude cost recompute' to re-price sessions recorded since then")
 	} else {
 		allPricing, _ := app.PricingRepo.List(ctx)
 		if len(allPricing) == 0 {
@@ -216,3 +419,217 @@ func runCostDelete(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Deleted pricing for %s\n", modelID)
 	return nil
 }
+
+func runCostRecompute(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	filter := domain.CostRecomputeFilter{}
+	if recomputeSince != "" {
+		t, err := time.Parse("2006-01-02", recomputeSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since date (use YYYY-MM-DD): %w", err)
+		}
+		filter.Since = t.Format(time.RFC3339)
+	}
+	if recomputeUntil != "" {
+		t, err := time.Parse("2006-01-02", recomputeUntil)
+		if err != nil {
+			return fmt.Errorf("invalid --until date (use YYYY-MM-DD): %w", err)
+		}
+		// Include the whole --until day
+		filter.Until = t.AddDate(0, 0, 1).Format(time.RFC3339)
+	}
+	if recomputeProject != "" {
+		filter.ProjectID = &recomputeProject
+	}
+	if recomputeExperiment != "" {
+		exp, err := getExperimentByName(ctx, app.ExperimentRepo, recomputeExperiment)
+		if err != nil {
+			return err
+		}
+		filter.ExperimentID = &exp.ID
+	}
+	if recomputeModel != "" {
+		filter.ModelID = &recomputeModel
+	}
+
+	recomputer := pricing.NewRecomputer(app.CostRepo, app.ModelUsageRepo, app.SubagentRepo, app.PricingRepo, app.TranscriptStorage)
+	result, err := recomputer.Recompute(ctx, filter, recomputeDryRun)
+	if err != nil {
+		return fmt.Errorf("failed to recompute costs: %w", err)
+	}
+
+	if len(result.Updates) == 0 {
+		fmt.Println("No sessions match the given filters")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tSOURCE\tOLD COST\tNEW COST\tDIFF")
+	fmt.Fprintln(w, "-------\t------\t--------\t--------\t----")
+	for _, u := range result.Updates {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			truncate(u.SessionID, 16), u.Source, formatOptionalCost(u.OldCost), formatOptionalCost(u.NewCost),
+			pricing.FormatCostDiff(u.OldCost, u.NewCost))
+	}
+	w.Flush()
+
+	fmt.Println()
+	fmt.Printf("Total: $%.4f -> $%.4f (%s)\n", result.OldTotal, result.NewTotal,
+		pricing.FormatCostDiff(&result.OldTotal, &result.NewTotal))
+	if result.DryRun {
+		fmt.Printf("Dry run: %d sessions would be updated\n", len(result.Updates))
+	} else {
+		fmt.Printf("Updated %d sessions\n", len(result.Updates))
+	}
+	return nil
+}
+
+func formatOptionalCost(cost *float64) string {
+	if cost == nil {
+		return "-"
+	}
+	return fmt.Sprintf("$%.4f", *cost)
+}
+
+func runCostImport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	path := args[0]
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open pricing file: %w", err)
+	}
+	defer f.Close()
+
+	file, err := pricing.DecodeFile(f, pricing.FormatFromPath(path))
+	if err != nil {
+		return err
+	}
+
+	changes, err := pricing.Import(ctx, app.PricingRepo, file, costImportDryRun)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("Pricing is already up to date")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANGE\tMODEL/ALIAS\tDETAILS")
+	fmt.Fprintln(w, "------\t-----------\t-------")
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.Kind, c.Subject, c.Detail)
+	}
+	w.Flush()
+
+	fmt.Println()
+	if costImportDryRun {
+		fmt.Printf("Dry run: %d changes not saved\n", len(changes))
+	} else {
+		fmt.Printf("Imported %d changes from %s\n", len(changes), path)
+		fmt.Println("Run 'mclaude cost recompute' to re-price recorded sessions")
+	}
+	return nil
+}
+
+func runCostExport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	format := costExportFormat
+	if format == "" {
+		format = pricing.FormatFromPath(costExportOutput)
+	}
+	if format != pricing.FormatYAML && format != pricing.FormatJSON {
+		return fmt.Errorf("invalid format %q (expected yaml or json)", format)
+	}
+
+	file, err := pricing.Export(ctx, app.PricingRepo)
+	if err != nil {
+		return fmt.Errorf("failed to export pricing: %w", err)
+	}
+
+	if costExportOutput == "" {
+		return file.Encode(os.Stdout, format)
+	}
+
+	out, err := os.Create(costExportOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer out.Close()
+
+	if err := file.Encode(out, format); err != nil {
+		return fmt.Errorf("failed to write pricing file: %w", err)
+	}
+
+	fmt.Printf("Exported %d models and %d aliases to %s\n", len(file.Models), len(file.Aliases), costExportOutput)
+	return nil
+}
+
+func runCostAliasList(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	aliases, err := app.PricingRepo.ListAliases(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list aliases: %w", err)
+	}
+
+	if len(aliases) == 0 {
+		fmt.Println("No model aliases configured")
+		fmt.Println("\nUse 'mclaude cost alias set' to add one")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ALIAS\tMODEL ID\tPRICED")
+	fmt.Fprintln(w, "-----\t--------\t------")
+	for _, a := range aliases {
+		priced := "no"
+		if p, _ := app.PricingRepo.GetByID(ctx, a.ModelID); p != nil {
+			priced = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", a.Alias, a.ModelID, priced)
+	}
+	w.Flush()
+	return nil
+}
+
+func runCostAliasSet(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	alias := strings.ToLower(args[0])
+	modelID := args[1]
+
+	if err := app.PricingRepo.SetAlias(ctx, alias, modelID); err != nil {
+		return fmt.Errorf("failed to set alias: %w", err)
+	}
+
+	fmt.Printf("Alias %s now points to %s\n", alias, modelID)
+	if p, _ := app.PricingRepo.GetByID(ctx, modelID); p == nil {
+		fmt.Printf("Warning: %s has no pricing, so the default model's pricing applies\n", modelID)
+		fmt.Println("Use 'mclaude cost set' to add it")
+	}
+	return nil
+}
+
+func runCostAliasDelete(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	alias := strings.ToLower(args[0])
+
+	existing, err := app.PricingRepo.GetAlias(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return fmt.Errorf("alias %q not found", alias)
+	}
+
+	if err := app.PricingRepo.DeleteAlias(ctx, alias); err != nil {
+		return fmt.Errorf("failed to delete alias: %w", err)
+	}
+
+	fmt.Printf("Deleted alias %s\n", alias)
+	return nil
+}
@@ -0,0 +1,569 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/adapters/storage"
+	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
+	"github.com/emiliopalmerini/mclaude/internal/pricing"
+)
+
+func TestCostRecompute_DryRunAndApply(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	testDBOverride = db
+	defer func() { testDBOverride = nil }()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+
+	transcriptPath, err := filepath.Abs("testdata/transcript.jsonl")
+	if err != nil {
+		t.Fatalf("Failed to get transcript path: %v", err)
+	}
+
+	sessionID := "test-recompute-" + randomID()
+	cwd := "/test/recompute-" + randomID()
+	err = processRecordInput(&domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: transcriptPath,
+		Cwd:            cwd,
+		PermissionMode: "default",
+		HookEventName:  "SessionEnd",
+		Reason:         "exit",
+	})
+	if err != nil {
+		t.Fatalf("Failed to record session: %v", err)
+	}
+	defer repos.Sessions.Delete(ctx, sessionID)
+
+	before, err := repos.Metrics.GetBySessionID(ctx, sessionID)
+	if err != nil || before == nil || before.CostEstimateUSD == nil {
+		t.Fatalf("Expected recorded cost, got %v (err %v)", before, err)
+	}
+
+	// Double the session model's rates
+	sonnet, err := repos.Pricing.GetByID(ctx, "claude-sonnet-4-20250514")
+	if err != nil || sonnet == nil {
+		t.Fatalf("Expected seeded pricing for sonnet 4, got %v (err %v)", sonnet, err)
+	}
+	original := *sonnet
+	defer repos.Pricing.Update(ctx, &original)
+
+	sonnet.InputPerMillion *= 2
+	sonnet.OutputPerMillion *= 2
+	if err := repos.Pricing.Update(ctx, sonnet); err != nil {
+		t.Fatalf("Failed to update pricing: %v", err)
+	}
+
+	transcripts, err := storage.NewTranscriptStorage()
+	if err != nil {
+		t.Fatalf("Failed to create transcript storage: %v", err)
+	}
+	recomputer := pricing.NewRecomputer(repos.Costs, repos.ModelUsage, repos.Subagents, repos.Pricing, transcripts)
+
+	project, err := repos.Projects.GetOrCreate(ctx, cwd)
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	filter := domain.CostRecomputeFilter{ProjectID: &project.ID}
+
+	// Dry run reports the new cost without saving it
+	result, err := recomputer.Recompute(ctx, filter, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if len(result.Updates) != 1 {
+		t.Fatalf("Expected 1 update, got %d", len(result.Updates))
+	}
+	update := result.Updates[0]
+	assertEqual(t, "update.Source", pricing.SourceTranscript, update.Source)
+	if update.NewCost == nil || *update.NewCost <= *before.CostEstimateUSD {
+		t.Fatalf("Expected higher new cost than %f, got %v", *before.CostEstimateUSD, update.NewCost)
+	}
+
+	unchanged, _ := repos.Metrics.GetBySessionID(ctx, sessionID)
+	assertEqual(t, "cost after dry run", *before.CostEstimateUSD, *unchanged.CostEstimateUSD)
+
+	// Applying writes the new cost
+	if _, err := recomputer.Recompute(ctx, filter, false); err != nil {
+		t.Fatalf("Recompute failed: %v", err)
+	}
+
+	after, _ := repos.Metrics.GetBySessionID(ctx, sessionID)
+	assertEqual(t, "cost after recompute", *update.NewCost, *after.CostEstimateUSD)
+
+	usage, _ := repos.ModelUsage.ListBySessionID(ctx, sessionID)
+	var usageTotal float64
+	for _, mu := range usage {
+		usageTotal += *mu.CostEstimateUSD
+	}
+	if diff := usageTotal - *after.CostEstimateUSD; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected model usage costs to sum to %f, got %f", *after.CostEstimateUSD, usageTotal)
+	}
+}
+
+func TestCostRecompute_NestedSubagentsUnchanged(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	testDBOverride = db
+	defer func() { testDBOverride = nil }()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+
+	// Agent a1 starts agent a2, whose usage is only in a1's own transcript,
+	// which isn't archived with the session
+	session := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Explore"}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"subagent_type":"Explore","prompt":"A"}}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:01:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":"Done"}]},"toolUseResult":{"status":"completed","agentId":"a1","totalTokens":1000,"usage":{"input_tokens":800,"output_tokens":200,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`
+	agent := `{"type":"assistant","isSidechain":true,"agentId":"a1","timestamp":"2025-01-17T10:00:20Z","message":{"id":"s1","role":"assistant","content":[{"type":"tool_use","id":"task2","name":"Task","input":{"subagent_type":"Bash","prompt":"B"}}]}}
+{"type":"user","isSidechain":true,"agentId":"a1","timestamp":"2025-01-17T10:00:50Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task2","content":"Ran"}]},"toolUseResult":{"status":"completed","agentId":"a2","totalTokens":300,"usage":{"input_tokens":250,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`
+	dir := t.TempDir()
+	transcriptPath := filepath.Join(dir, "session.jsonl")
+	writeFile(t, transcriptPath, session)
+	writeFile(t, filepath.Join(dir, "session", "subagents", "agent-a1.jsonl"), agent)
+
+	sessionID := "test-recompute-nested-" + randomID()
+	cwd := "/test/recompute-" + randomID()
+	err := processRecordInput(&domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: transcriptPath,
+		Cwd:            cwd,
+		HookEventName:  "SessionEnd",
+		Reason:         "exit",
+	})
+	if err != nil {
+		t.Fatalf("Failed to record session: %v", err)
+	}
+	defer repos.Sessions.Delete(ctx, sessionID)
+
+	before, err := repos.Metrics.GetBySessionID(ctx, sessionID)
+	if err != nil || before == nil || before.CostEstimateUSD == nil {
+		t.Fatalf("Expected recorded cost, got %v (err %v)", before, err)
+	}
+	assertEqual(t, "recorded input tokens", int64(1150), before.TokenInput)
+
+	transcripts, err := storage.NewTranscriptStorage()
+	if err != nil {
+		t.Fatalf("Failed to create transcript storage: %v", err)
+	}
+	recomputer := pricing.NewRecomputer(repos.Costs, repos.ModelUsage, repos.Subagents, repos.Pricing, transcripts)
+
+	project, err := repos.Projects.GetOrCreate(ctx, cwd)
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	result, err := recomputer.Recompute(ctx, domain.CostRecomputeFilter{ProjectID: &project.ID}, false)
+	if err != nil {
+		t.Fatalf("Recompute failed: %v", err)
+	}
+	if len(result.Updates) != 1 {
+		t.Fatalf("Expected 1 update, got %d", len(result.Updates))
+	}
+	update := result.Updates[0]
+	assertEqual(t, "update.Source", pricing.SourceStored, update.Source)
+	if update.NewCost == nil {
+		t.Fatal("Expected a new cost")
+	}
+	if diff := *update.NewCost - *before.CostEstimateUSD; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected cost %f to stay unchanged, got %f", *before.CostEstimateUSD, *update.NewCost)
+	}
+	assertEqual(t, "sub-agent updates", 2, len(update.Subagents))
+	for _, sa := range update.Subagents {
+		if sa.OldCost == nil || sa.NewCost == nil || *sa.OldCost != *sa.NewCost {
+			t.Errorf("Expected sub-agent %d cost to stay unchanged, got %v -> %v", sa.ID, sa.OldCost, sa.NewCost)
+		}
+	}
+
+	usage, _ := repos.ModelUsage.ListBySessionID(ctx, sessionID)
+	var usageInput int64
+	for _, mu := range usage {
+		usageInput += mu.TokenInput
+	}
+	assertEqual(t, "model usage input tokens", int64(1150), usageInput)
+}
+
+func TestCostRecompute_SubagentOnSwitchedModel(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	// The session starts on Opus and switches to Sonnet before the Task
+	// without a model of its own runs
+	sessionID := "test-recompute-switched-" + randomID()
+	cwd := "/test/recompute-" + randomID()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	writeFile(t, path, `{"type":"assistant","timestamp":"2025-07-17T10:00:00Z","message":{"id":"m1","role":"assistant","model":"claude-opus-4-20250514","content":[{"type":"text","text":"Planning"}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"assistant","timestamp":"2025-07-17T10:00:05Z","message":{"id":"m2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"subagent_type":"Explore","prompt":"Look"}}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-07-17T10:01:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":"Done"}]},"toolUseResult":{"status":"completed","agentId":"a1","totalTokens":1000,"usage":{"input_tokens":800,"output_tokens":200,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`)
+	input := &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            cwd,
+		HookEventName:  "SessionEnd",
+	}
+	if _, err := recorder.Record(ctx, input, ingest.Options{}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	defer repos.Sessions.Delete(ctx, sessionID)
+
+	subagents, err := repos.Subagents.ListBySessionID(ctx, sessionID)
+	if err != nil || len(subagents) != 1 {
+		t.Fatalf("Expected 1 sub-agent, got %d (err %v)", len(subagents), err)
+	}
+	sa := subagents[0]
+	if sa.ModelID == nil || *sa.ModelID != "claude-sonnet-4-20250514" {
+		t.Fatalf("Expected sub-agent counted under Sonnet, got %v", sa.ModelID)
+	}
+	sonnet, err := repos.Pricing.GetByID(ctx, "claude-sonnet-4-20250514")
+	if err != nil || sonnet == nil {
+		t.Fatalf("Expected Sonnet pricing, got %v (err %v)", sonnet, err)
+	}
+	want := sonnet.CalculateStandardCost(800, 200, 0, 0)
+	if sa.CostEstimateUSD == nil || *sa.CostEstimateUSD != want {
+		t.Errorf("Expected sub-agent cost %f at Sonnet rates, got %v", want, sa.CostEstimateUSD)
+	}
+
+	// Re-pricing from stored usage agrees
+	project, err := repos.Projects.GetOrCreate(ctx, cwd)
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	recomputer := pricing.NewRecomputer(repos.Costs, repos.ModelUsage, repos.Subagents, repos.Pricing, nil)
+	result, err := recomputer.Recompute(ctx, domain.CostRecomputeFilter{ProjectID: &project.ID}, false)
+	if err != nil {
+		t.Fatalf("Recompute failed: %v", err)
+	}
+	if len(result.Updates) != 1 || len(result.Updates[0].Subagents) != 1 {
+		t.Fatalf("Expected 1 update with 1 sub-agent, got %+v", result.Updates)
+	}
+	saUpdate := result.Updates[0].Subagents[0]
+	if saUpdate.NewCost == nil || *saUpdate.NewCost != want {
+		t.Errorf("Expected re-priced sub-agent cost %f, got %v", want, saUpdate.NewCost)
+	}
+}
+
+func TestCostApplyUpdates_ReplacesModelUsage(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	sessionID := "test-apply-model-usage-" + randomID()
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	writeFile(t, path, `{"type":"assistant","timestamp":"2025-07-17T10:00:00Z","message":{"id":"m1","role":"assistant","model":"claude-opus-4-20250514","content":[{"type":"text","text":"Planning"}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"assistant","timestamp":"2025-07-17T10:00:05Z","message":{"id":"m2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Done"}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`)
+	input := &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/recompute-" + randomID(),
+		HookEventName:  "SessionEnd",
+	}
+	if _, err := recorder.Record(ctx, input, ingest.Options{}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	defer repos.Sessions.Delete(ctx, sessionID)
+
+	usage, err := repos.ModelUsage.ListBySessionID(ctx, sessionID)
+	if err != nil || len(usage) != 2 {
+		t.Fatalf("Expected usage for 2 models, got %d (err %v)", len(usage), err)
+	}
+
+	// The re-priced usage is all attributed to one model
+	cost := 0.01
+	err = repos.Costs.ApplyUpdates(ctx, []*domain.SessionCostUpdate{{
+		SessionID: sessionID,
+		NewCost:   &cost,
+		ModelUsage: []*domain.SessionModelUsage{{
+			SessionID:       sessionID,
+			ModelID:         "claude-sonnet-4-20250514",
+			MessageCount:    2,
+			TokenInput:      200,
+			TokenOutput:     100,
+			CostEstimateUSD: &cost,
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("ApplyUpdates failed: %v", err)
+	}
+
+	usage, err = repos.ModelUsage.ListBySessionID(ctx, sessionID)
+	if err != nil || len(usage) != 1 {
+		t.Fatalf("Expected usage for 1 model, got %d (err %v)", len(usage), err)
+	}
+	assertEqual(t, "ModelID", "claude-sonnet-4-20250514", usage[0].ModelID)
+	assertEqual(t, "TokenInput", int64(200), usage[0].TokenInput)
+}
+
+func TestPricingVersions(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := turso.NewPricingRepository(db)
+
+	modelID := "test-model-" + randomID()
+	defer repo.Delete(ctx, modelID)
+
+	if err := repo.Create(ctx, &domain.ModelPricing{
+		ID:               modelID,
+		DisplayName:      "Test Model",
+		InputPerMillion:  3,
+		OutputPerMillion: 15,
+		CreatedAt:        time.Now().UTC(),
+	}); err != nil {
+		t.Fatalf("Failed to create pricing: %v", err)
+	}
+
+	changedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := repo.AddVersion(ctx, &domain.ModelPricing{
+		ID:               modelID,
+		DisplayName:      "Test Model",
+		InputPerMillion:  2,
+		OutputPerMillion: 10,
+		CreatedAt:        time.Now().UTC(),
+		EffectiveFrom:    changedAt,
+	}); err != nil {
+		t.Fatalf("Failed to add pricing version: %v", err)
+	}
+
+	current, _ := repo.GetByID(ctx, modelID)
+	assertEqual(t, "current input rate", 2.0, current.InputPerMillion)
+
+	before, _ := repo.GetByIDAt(ctx, modelID, changedAt.Add(-time.Hour))
+	assertEqual(t, "input rate before change", 3.0, before.InputPerMillion)
+	if before.EffectiveTo == nil || !before.EffectiveTo.Equal(changedAt) {
+		t.Errorf("Expected previous version to end at %v, got %v", changedAt, before.EffectiveTo)
+	}
+
+	after, _ := repo.GetByIDAt(ctx, modelID, changedAt)
+	assertEqual(t, "input rate at change", 2.0, after.InputPerMillion)
+
+	// A version can't start before the current one
+	err := repo.AddVersion(ctx, &domain.ModelPricing{
+		ID:            modelID,
+		DisplayName:   "Test Model",
+		EffectiveFrom: changedAt.Add(-24 * time.Hour),
+	})
+	if err == nil {
+		t.Error("Expected error adding a version older than the current one")
+	}
+
+	history, _ := repo.ListHistory(ctx)
+	var versions int
+	for _, p := range history {
+		if p.ID == modelID {
+			versions++
+		}
+	}
+	assertEqual(t, "versions", 2, versions)
+
+	// The pricer picks the version by request time
+	pricer := pricing.NewPricer(ctx, repo)
+	old := changedAt.Add(-time.Hour)
+	assertEqual(t, "pricer rate before change", 3.0, pricer.LookupAt(modelID, &old).InputPerMillion)
+	assertEqual(t, "pricer rate after change", 2.0, pricer.LookupAt(modelID, &changedAt).InputPerMillion)
+	assertEqual(t, "pricer current rate", 2.0, pricer.LookupAt(modelID, nil).InputPerMillion)
+}
+
+func TestCostImportExport(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := turso.NewPricingRepository(db)
+
+	modelID := "test-import-" + randomID()
+	alias := "alias-" + modelID
+	defer repo.Delete(ctx, modelID)
+	defer repo.DeleteAlias(ctx, alias)
+
+	file := `
+models:
+  - id: ` + modelID + `
+    name: Imported Model
+    input: 3.00
+    output: 15.00
+    cache_read: 0.30
+    web_search: 10.00
+    long_context:
+      input: 6.00
+      output: 22.50
+aliases:
+  ` + strings.ToUpper(alias) + `: ` + modelID + `
+`
+	parsed, err := pricing.DecodeFile(strings.NewReader(file), pricing.FormatYAML)
+	if err != nil {
+		t.Fatalf("Failed to decode pricing file: %v", err)
+	}
+
+	// Dry run lists the changes without saving them
+	changes, err := pricing.Import(ctx, repo, parsed, true)
+	if err != nil {
+		t.Fatalf("Dry run import failed: %v", err)
+	}
+	assertEqual(t, "dry run changes", 2, len(changes))
+	if p, _ := repo.GetByID(ctx, modelID); p != nil {
+		t.Fatal("Dry run should not create pricing")
+	}
+
+	if _, err := pricing.Import(ctx, repo, parsed, false); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	imported, _ := repo.GetByID(ctx, modelID)
+	if imported == nil {
+		t.Fatal("Expected imported pricing")
+	}
+	assertEqual(t, "long context threshold", int64(200000), *imported.LongContextThreshold)
+	if imported.WebSearchPerThousand == nil || imported.WebFetchPerThousand != nil {
+		t.Fatalf("Expected only a web search rate, got %v / %v", imported.WebSearchPerThousand, imported.WebFetchPerThousand)
+	}
+	assertEqual(t, "web search rate", 10.0, *imported.WebSearchPerThousand)
+	// Aliases are stored in lowercase, as they're looked up
+	if a, _ := repo.GetAlias(ctx, alias); a == nil || a.ModelID != modelID {
+		t.Errorf("Expected alias %s -> %s, got %v", alias, modelID, a)
+	}
+
+	// Importing the same file again changes nothing
+	changes, _ = pricing.Import(ctx, repo, parsed, false)
+	assertEqual(t, "changes on re-import", 0, len(changes))
+
+	// A later effective_from with new rates adds a pricing version
+	parsed.Models[0].Input = 2.5
+	parsed.Models[0].EffectiveFrom = "2026-03-01T00:00:00Z"
+	changes, err = pricing.Import(ctx, repo, parsed, false)
+	if err != nil {
+		t.Fatalf("Import of new version failed: %v", err)
+	}
+	assertEqual(t, "new version changes", 1, len(changes))
+	assertEqual(t, "change kind", pricing.ChangeNewVersion, changes[0].Kind)
+
+	old, _ := repo.GetByIDAt(ctx, modelID, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	assertEqual(t, "rate before new version", 3.0, old.InputPerMillion)
+
+	// Exported pricing can be read back
+	exported, err := pricing.Export(ctx, repo)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := exported.Encode(&buf, pricing.FormatJSON); err != nil {
+		t.Fatalf("Failed to encode pricing file: %v", err)
+	}
+	roundTrip, err := pricing.DecodeFile(&buf, pricing.FormatJSON)
+	if err != nil {
+		t.Fatalf("Failed to decode exported file: %v", err)
+	}
+	changes, _ = pricing.Import(ctx, repo, roundTrip, true)
+	assertEqual(t, "changes after export round trip", 0, len(changes))
+}
+
+func TestPricingApply_AllOrNothing(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := turso.NewPricingRepository(db)
+
+	existingID := "test-apply-" + randomID()
+	addedID := "test-apply-added-" + randomID()
+	defer repo.Delete(ctx, existingID)
+	defer repo.Delete(ctx, addedID)
+
+	if err := repo.Create(ctx, &domain.ModelPricing{
+		ID:               existingID,
+		DisplayName:      "Existing",
+		InputPerMillion:  3,
+		OutputPerMillion: 15,
+		CreatedAt:        time.Now().UTC(),
+		EffectiveFrom:    time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}); err != nil {
+		t.Fatalf("Failed to create pricing: %v", err)
+	}
+
+	// The new version doesn't take effect after the current one, so the
+	// model added before it must not be saved either
+	err := repo.Apply(ctx, &domain.PricingChanges{
+		Added: []*domain.ModelPricing{{
+			ID:               addedID,
+			DisplayName:      "Added",
+			InputPerMillion:  1,
+			OutputPerMillion: 5,
+			CreatedAt:        time.Now().UTC(),
+		}},
+		NewVersions: []*domain.ModelPricing{{
+			ID:               existingID,
+			DisplayName:      "Existing",
+			InputPerMillion:  2,
+			OutputPerMillion: 10,
+			CreatedAt:        time.Now().UTC(),
+			EffectiveFrom:    time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	})
+	if err == nil {
+		t.Fatal("Expected Apply to fail")
+	}
+	if p, _ := repo.GetByID(ctx, addedID); p != nil {
+		t.Error("Expected the added model to be rolled back")
+	}
+	if p, _ := repo.GetByID(ctx, existingID); p == nil || p.InputPerMillion != 3 {
+		t.Errorf("Expected the existing pricing unchanged, got %v", p)
+	}
+}
+
+func TestModelAliases(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repo := turso.NewPricingRepository(db)
+
+	// Seeded aliases point at models with pricing
+	for _, name := range []string{"haiku", "sonnet", "opus"} {
+		alias, err := repo.GetAlias(ctx, name)
+		if err != nil || alias == nil {
+			t.Fatalf("Expected seeded alias %s, got %v (err %v)", name, alias, err)
+		}
+		if p, _ := repo.GetByID(ctx, alias.ModelID); p == nil {
+			t.Errorf("Alias %s points to %s, which has no pricing", name, alias.ModelID)
+		}
+	}
+
+	name := "alias-" + randomID()
+	defer repo.DeleteAlias(ctx, name)
+
+	if err := repo.SetAlias(ctx, name, "claude-opus-4-20250514"); err != nil {
+		t.Fatalf("Failed to set alias: %v", err)
+	}
+	if err := repo.SetAlias(ctx, name, "claude-opus-4-1-20250414"); err != nil {
+		t.Fatalf("Failed to update alias: %v", err)
+	}
+
+	pricer := pricing.NewPricer(ctx, repo)
+	assertEqual(t, "resolved alias", "claude-opus-4-1-20250414", pricer.ResolveAlias(name))
+	assertEqual(t, "non-alias", "claude-opus-4-20250514", pricer.ResolveAlias("claude-opus-4-20250514"))
+	assertEqual(t, "priced model", "claude-opus-4-1-20250414", pricer.LookupAt(name, nil).ID)
+}
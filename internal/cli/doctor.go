@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/util"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check recorded data for problems",
+	Long:  `Check recorded sessions for signs that they weren't recorded correctly.`,
+}
+
+var doctorTranscriptsCmd = &cobra.Command{
+	Use:   "transcripts [session-id]",
+	Short: "Flag sessions whose transcripts weren't fully understood",
+	Long: `Report what the parser couldn't read in recorded transcripts: malformed
+lines, entry and content block types it doesn't know, and assistant
+messages without token usage. Sessions with any of these, or whose
+assistant messages carried no tokens at all, are flagged.
+
+A type that first appeared recently usually means Claude Code changed its
+transcript format, and the metrics of sessions since then are incomplete.
+
+Given a session ID, lists that session's anomalies with the transcript line
+each was first found on.
+
+Examples:
+  mclaude doctor transcripts                  # All recorded sessions
+  mclaude doctor transcripts --period week    # Sessions recorded this week
+  mclaude doctor transcripts <session-id>     # One session's anomalies`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDoctorTranscripts,
+}
+
+// Flags
+var (
+	doctorPeriod string
+	doctorLimit  int
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.AddCommand(doctorTranscriptsCmd)
+
+	doctorTranscriptsCmd.Flags().StringVarP(&doctorPeriod, "period", "p", "all", "Time period: today, week, month, all")
+	doctorTranscriptsCmd.Flags().IntVarP(&doctorLimit, "limit", "n", 20, "Number of flagged sessions to show")
+}
+
+func runDoctorTranscripts(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	if len(args) == 1 {
+		return runDoctorSession(ctx, args[0])
+	}
+
+	diagnostics, err := app.StatsRepo.GetParseDiagnostics(ctx, getStartDate(doctorPeriod), doctorLimit)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("  Checked %d sessions\n", diagnostics.SessionCount-diagnostics.UncheckedCount)
+	if diagnostics.UncheckedCount > 0 {
+		fmt.Printf("  %d sessions were recorded before parse anomalies were kept\n", diagnostics.UncheckedCount)
+	}
+	fmt.Println()
+
+	fmt.Println("  Unrecognized Transcript Content")
+	fmt.Println("  -------------------------------")
+	if len(diagnostics.Anomalies) == 0 {
+		fmt.Println("  None found")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  KIND\tDETAIL\tSESSIONS\tLINES\tFIRST SEEN\tLAST SEEN")
+		for _, a := range diagnostics.Anomalies {
+			fmt.Fprintf(w, "  %s\t%s\t%d\t%d\t%s\t%s\n",
+				anomalyKindLabel(a.Kind), truncate(anomalyDetail(a.Detail), 50), a.SessionCount,
+				a.TotalOccurrences, formatLastUsed(a.FirstSeenAt), formatLastUsed(a.LastSeenAt))
+		}
+		w.Flush()
+	}
+	fmt.Println()
+
+	fmt.Println("  Flagged Sessions")
+	fmt.Println("  ----------------")
+	if len(diagnostics.Sessions) == 0 {
+		fmt.Println("  None found")
+		fmt.Println()
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  SESSION\tRECORDED\tLINES\tTOKENS\tISSUES")
+	for _, s := range diagnostics.Sessions {
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%s\t%s\n",
+			s.SessionID, formatLastUsed(s.RecordedAt), s.TranscriptLines,
+			util.FormatNumber(s.TotalTokens), sessionIssues(s))
+	}
+	w.Flush()
+	fmt.Println()
+	return nil
+}
+
+func runDoctorSession(ctx context.Context, sessionID string) error {
+	session, err := app.SessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	anomalies, err := app.AnomalyRepo.ListBySessionID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("  Transcript: %s\n", session.TranscriptPath)
+	if m, err := app.MetricsRepo.GetBySessionID(ctx, sessionID); err == nil && m != nil {
+		if m.ParserVersion == 0 {
+			fmt.Println("  Recorded before parse anomalies were kept")
+			fmt.Println()
+			return nil
+		}
+		fmt.Printf("  Lines:      %d\n", m.TranscriptLines)
+	}
+	fmt.Println()
+
+	if len(anomalies) == 0 {
+		fmt.Println("  No anomalies found")
+		fmt.Println()
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  KIND\tDETAIL\tLINES\tFIRST LINE")
+	for _, a := range anomalies {
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%d\n",
+			anomalyKindLabel(a.Kind), truncate(anomalyDetail(a.Detail), 60), a.OccurrenceCount, a.FirstLine)
+	}
+	w.Flush()
+	fmt.Println()
+	return nil
+}
+
+func anomalyKindLabel(kind string) string {
+	switch kind {
+	case domain.AnomalyMalformedLine:
+		return "malformed line"
+	case domain.AnomalyUnknownEntryType:
+		return "unknown entry type"
+	case domain.AnomalyUnknownContentType:
+		return "unknown content type"
+	case domain.AnomalyMissingUsage:
+		return "no usage"
+	}
+	return kind
+}
+
+// anomalyDetail shows an empty detail, e.g. an entry without a type.
+func anomalyDetail(detail string) string {
+	if detail == "" {
+		return "(none)"
+	}
+	return detail
+}
+
+// sessionIssues summarizes why a session was flagged.
+func sessionIssues(s domain.AnomalousSession) string {
+	var issues []string
+	if s.MalformedLines > 0 {
+		issues = append(issues, fmt.Sprintf("%d malformed lines", s.MalformedLines))
+	}
+	if s.UnknownTypes > 0 {
+		issues = append(issues, fmt.Sprintf("%d unknown types", s.UnknownTypes))
+	}
+	if s.MissingUsage > 0 {
+		issues = append(issues, fmt.Sprintf("%d messages without usage", s.MissingUsage))
+	}
+	if s.AssistantMessages > 0 && s.TotalTokens == 0 {
+		issues = append(issues, "no tokens")
+	}
+	return strings.Join(issues, ", ")
+}
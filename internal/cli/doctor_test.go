@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
+	"github.com/emiliopalmerini/mclaude/internal/parser"
+)
+
+func TestParseDiagnostics(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	sessionID := "test-doctor-" + randomID()
+	defer repos.Sessions.Delete(ctx, sessionID)
+
+	// An entry type unique to this test, a malformed line and an assistant
+	// message without usage, so the session has no tokens at all
+	entryType := "type-" + randomID()
+	path := filepath.Join(t.TempDir(), sessionID+".jsonl")
+	writeFile(t, path, `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Fix it"}}
+{"type":"`+entryType+`","timestamp":"2025-01-17T10:00:01Z"}
+{"type":"assistant","timestamp":"2025-01-17T10:00:02Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"On it"}]}}
+{"type":"assistant",
+`)
+
+	_, err := recorder.Record(ctx, &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "SessionEnd",
+		Reason:         "exit",
+	}, ingest.Options{})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	anomalies, err := repos.Anomalies.ListBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("ListBySessionID failed: %v", err)
+	}
+	assertEqual(t, "anomalies", 3, len(anomalies))
+	assertEqual(t, "first kind", domain.AnomalyUnknownEntryType, anomalies[0].Kind)
+	assertEqual(t, "first detail", entryType, anomalies[0].Detail)
+	assertEqual(t, "first line", int64(2), anomalies[0].FirstLine)
+
+	metrics, err := repos.Metrics.GetBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("GetBySessionID failed: %v", err)
+	}
+	assertEqual(t, "TranscriptLines", int64(4), metrics.TranscriptLines)
+	assertEqual(t, "ParserVersion", int64(parser.StateVersion), metrics.ParserVersion)
+
+	diagnostics, err := repos.Stats.GetParseDiagnostics(ctx, "1970-01-01T00:00:00Z", 1000)
+	if err != nil {
+		t.Fatalf("GetParseDiagnostics failed: %v", err)
+	}
+	var found bool
+	for _, a := range diagnostics.Anomalies {
+		if a.Kind == domain.AnomalyUnknownEntryType && a.Detail == entryType {
+			found = true
+			assertEqual(t, "SessionCount", int64(1), a.SessionCount)
+			assertEqual(t, "TotalOccurrences", int64(1), a.TotalOccurrences)
+		}
+	}
+	if !found {
+		t.Errorf("Expected entry type %q among anomalies", entryType)
+	}
+
+	var flagged *domain.AnomalousSession
+	for i := range diagnostics.Sessions {
+		if diagnostics.Sessions[i].SessionID == sessionID {
+			flagged = &diagnostics.Sessions[i]
+		}
+	}
+	if flagged == nil {
+		t.Fatalf("Expected session %s to be flagged", sessionID)
+	}
+	assertEqual(t, "issues", "1 malformed lines, 1 unknown types, 1 messages without usage, no tokens", sessionIssues(*flagged))
+}
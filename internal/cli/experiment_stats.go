@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/emiliopalmerini/mclaude/internal/domain"
 	"github.com/emiliopalmerini/mclaude/internal/util"
 )
 
@@ -27,6 +28,10 @@ type expData struct {
 	totalTokens  int64
 	tokensPerSes int64
 	costPerSes   float64
+	linesAdded   int64
+	linesRemoved int64
+	compactions  int64
+	cacheWasted  int64
 }
 
 func runExperimentStats(cmd *cobra.Command, args []string) error {
@@ -87,6 +92,14 @@ func runExperimentStats(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Total:             %s\n", util.FormatNumber(totalTokens))
 	fmt.Println()
 
+	fmt.Printf("  Cache\n")
+	fmt.Printf("  -----\n")
+	fmt.Printf("  Hit ratio:         %.1f%%\n", domain.CacheHitRatio(stats.TotalTokenInput, stats.TotalTokenCacheRead, stats.TotalTokenCacheWrite)*100)
+	fmt.Printf("  Writes wasted:     %s (%.1f%%)\n", util.FormatNumber(stats.TotalTokenCacheWasted),
+		domain.CacheWasteRatio(stats.TotalTokenCacheWrite, stats.TotalTokenCacheWasted)*100)
+	fmt.Printf("  Compactions:       %d\n", stats.TotalCompactions)
+	fmt.Println()
+
 	fmt.Printf("  Cost\n")
 	fmt.Printf("  ----\n")
 	fmt.Printf("  Estimated:         $%.4f\n", stats.TotalCostUsd)
@@ -100,6 +113,42 @@ func runExperimentStats(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
+	linesChanged := stats.TotalLinesAdded + stats.TotalLinesRemoved
+	if linesChanged > 0 {
+		fmt.Printf("  Code\n")
+		fmt.Printf("  ----\n")
+		fmt.Printf("  Lines added:       %s\n", util.FormatNumber(stats.TotalLinesAdded))
+		fmt.Printf("  Lines removed:     %s\n", util.FormatNumber(stats.TotalLinesRemoved))
+		fmt.Printf("  LOC/session:       %s\n", util.FormatNumber(linesChanged/stats.SessionCount))
+		fmt.Printf("  LOC per dollar:    %s\n", formatLinesPerDollar(linesChanged, stats.TotalCostUsd))
+		fmt.Println()
+
+		languages, err := app.StatsRepo.GetLinesByLanguageByExperiment(ctx, exp.ID, "1970-01-01T00:00:00Z")
+		if err != nil {
+			return err
+		}
+		if len(languages) > 0 {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "  LANGUAGE\tFILES\tADDED\tREMOVED")
+			for _, l := range languages {
+				fmt.Fprintf(w, "  %s\t%d\t%s\t%s\n", l.Language, l.FileCount,
+					util.FormatNumber(l.LinesAdded), util.FormatNumber(l.LinesRemoved))
+			}
+			w.Flush()
+			fmt.Println()
+		}
+	}
+
+	servers, err := app.StatsRepo.GetMCPServersByExperiment(ctx, exp.ID, "1970-01-01T00:00:00Z")
+	if err != nil {
+		return err
+	}
+	if len(servers) > 0 {
+		fmt.Printf("  MCP Servers\n")
+		fmt.Printf("  -----------\n")
+		printMCPServers(servers)
+	}
+
 	return nil
 }
 
@@ -145,6 +194,10 @@ func runExperimentCompare(cmd *cobra.Command, args []string) error {
 			totalTokens:  totalTokens,
 			tokensPerSes: tokensPerSes,
 			costPerSes:   costPerSes,
+			linesAdded:   stats.TotalLinesAdded,
+			linesRemoved: stats.TotalLinesRemoved,
+			compactions:  stats.TotalCompactions,
+			cacheWasted:  stats.TotalTokenCacheWasted,
 		})
 	}
 
@@ -189,9 +242,34 @@ func runExperimentCompare(cmd *cobra.Command, args []string) error {
 	printCompareRow(w, "Cache write", experiments, func(e expData) string { return util.FormatNumber(e.cacheWrite) })
 	printCompareRow(w, "Total tokens", experiments, func(e expData) string { return util.FormatNumber(e.totalTokens) })
 	fmt.Fprintln(w)
+	printCompareRow(w, "Cache hit ratio", experiments, func(e expData) string {
+		return fmt.Sprintf("%.1f%%", domain.CacheHitRatio(e.tokenInput, e.cacheRead, e.cacheWrite)*100)
+	})
+	printCompareRow(w, "Cache writes wasted", experiments, func(e expData) string {
+		return fmt.Sprintf("%.1f%%", domain.CacheWasteRatio(e.cacheWrite, e.cacheWasted)*100)
+	})
+	printCompareRow(w, "Compactions/session", experiments, func(e expData) string {
+		if e.sessions == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", float64(e.compactions)/float64(e.sessions))
+	})
+	fmt.Fprintln(w)
 	printCompareRow(w, "Cost", experiments, func(e expData) string { return fmt.Sprintf("$%.2f", e.cost) })
 	printCompareRow(w, "Tokens/session", experiments, func(e expData) string { return util.FormatNumber(e.tokensPerSes) })
 	printCompareRow(w, "Cost/session", experiments, func(e expData) string { return fmt.Sprintf("$%.4f", e.costPerSes) })
+	fmt.Fprintln(w)
+	printCompareRow(w, "Lines added", experiments, func(e expData) string { return util.FormatNumber(e.linesAdded) })
+	printCompareRow(w, "Lines removed", experiments, func(e expData) string { return util.FormatNumber(e.linesRemoved) })
+	printCompareRow(w, "LOC/session", experiments, func(e expData) string {
+		if e.sessions == 0 {
+			return "-"
+		}
+		return util.FormatNumber((e.linesAdded + e.linesRemoved) / e.sessions)
+	})
+	printCompareRow(w, "LOC per dollar", experiments, func(e expData) string {
+		return formatLinesPerDollar(e.linesAdded+e.linesRemoved, e.cost)
+	})
 
 	w.Flush()
 	fmt.Println()
@@ -199,6 +277,15 @@ func runExperimentCompare(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// formatLinesPerDollar formats the lines changed per dollar spent, or "-"
+// when nothing was spent.
+func formatLinesPerDollar(lines int64, cost float64) string {
+	if cost <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", float64(lines)/cost)
+}
+
 func printCompareRow(w *tabwriter.Writer, label string, experiments []expData, getValue func(expData) string) {
 	fmt.Fprintf(w, "  %s\t", label)
 	for _, e := range experiments {
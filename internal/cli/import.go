@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
+	"github.com/emiliopalmerini/mclaude/internal/parser"
+	"github.com/emiliopalmerini/mclaude/internal/ports"
+)
+
+// importExitReason marks sessions recorded by 'mclaude import' rather than
+// the SessionEnd hook, which would report why the session ended.
+const importExitReason = "imported"
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import historical Claude Code transcripts",
+	Long: `Record sessions from transcripts written before mclaude was installed.
+
+Walks the given directory (by default ~/.claude/projects) for JSONL
+transcripts, reads each session's ID and working directory from its
+entries, and records every session that isn't recorded yet, exactly as
+'mclaude record' would. Sub-agent transcripts are read as part of their
+parent session.
+
+Imported sessions are dated by when they started, aren't assigned to the
+active experiment and don't reset usage windows.
+
+Examples:
+  mclaude import --dry-run                         # List sessions to import
+  mclaude import --from ~/.claude/projects
+  mclaude import --from ~/.claude/projects/-home-me-myproject --concurrency 8`,
+	RunE: runImport,
+}
+
+// Flags
+var (
+	importFrom        string
+	importConcurrency int
+	importDryRun      bool
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importFrom, "from", "", "Directory to search for transcripts (default: ~/.claude/projects)")
+	importCmd.Flags().IntVarP(&importConcurrency, "concurrency", "j", 4, "Number of transcripts to parse in parallel")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "List the sessions that would be imported without saving")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	root := importFrom
+	if root == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		root = filepath.Join(home, ".claude", "projects")
+	}
+
+	paths, err := findTranscripts(root)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		fmt.Printf("No transcripts found in %s\n", root)
+		return nil
+	}
+
+	summary, err := importTranscripts(ctx, app.Recorder, app.SessionRepo, paths, importConcurrency, importDryRun)
+	if err != nil {
+		return err
+	}
+
+	if !importDryRun && summary.Imported > 0 {
+		if err := app.DB.Sync(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: sync failed: %v\n", err)
+		}
+	}
+
+	fmt.Println()
+	if importDryRun {
+		fmt.Printf("Dry run: %d sessions to import, %d already recorded, %d skipped\n",
+			summary.Imported, summary.Recorded, summary.Skipped)
+		return nil
+	}
+	fmt.Printf("Imported %d sessions (%d already recorded, %d skipped, %d failed)\n",
+		summary.Imported, summary.Recorded, summary.Skipped, summary.Failed)
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d sessions failed to import", summary.Failed)
+	}
+	return nil
+}
+
+// findTranscripts lists the session transcripts under root. Sub-agent
+// transcripts are skipped since the parser reads them with the session that
+// started the agent.
+func findTranscripts(root string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "subagents" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".jsonl" && !strings.HasPrefix(d.Name(), "agent-") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", root, err)
+	}
+	return paths, nil
+}
+
+type importSummary struct {
+	Imported int // Sessions imported, or that would be on a dry run
+	Recorded int // Sessions already recorded
+	Skipped  int // Transcripts without a usable session
+	Failed   int
+}
+
+// importTranscripts records the sessions of the given transcripts that aren't
+// recorded yet. Transcripts are parsed concurrently while sessions are saved
+// one at a time, so a failure only skips its own session.
+func importTranscripts(ctx context.Context, recorder *ingest.Recorder, sessions ports.SessionRepository, paths []string, concurrency int, dryRun bool) (*importSummary, error) {
+	summary := &importSummary{}
+
+	var pending []*domain.HookInput
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		info, err := parser.ReadSessionInfo(path)
+		if err != nil || info.Cwd == "" {
+			summary.Skipped++
+			continue
+		}
+		if seen[info.SessionID] {
+			summary.Skipped++
+			continue
+		}
+		seen[info.SessionID] = true
+
+		existing, err := sessions.GetByID(ctx, info.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check session %s: %w", info.SessionID, err)
+		}
+		if existing != nil {
+			summary.Recorded++
+			continue
+		}
+
+		pending = append(pending, &domain.HookInput{
+			SessionID:      info.SessionID,
+			TranscriptPath: path,
+			Cwd:            info.Cwd,
+			HookEventName:  "SessionEnd",
+			Reason:         importExitReason,
+		})
+	}
+
+	if dryRun {
+		for _, input := range pending {
+			fmt.Printf("%s  %s  %s\n", input.SessionID, input.Cwd, input.TranscriptPath)
+		}
+		summary.Imported = len(pending)
+		return summary, nil
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	classifier, err := recorder.Classifier(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	done := 0
+	var g errgroup.Group
+	g.SetLimit(concurrency)
+
+	for _, input := range pending {
+		g.Go(func() error {
+			p := parser.NewParser(input.SessionID)
+			p.SetClassifier(classifier)
+			err := p.ParseFileFinal(input.TranscriptPath)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			var result *ingest.Result
+			if err == nil {
+				result, err = recorder.RecordParsed(ctx, input, p.Result(), ingest.Options{Historical: true})
+			}
+
+			done++
+			if err != nil {
+				summary.Failed++
+				fmt.Fprintf(os.Stderr, "[%d/%d] %s failed: %v\n", done, len(pending), input.SessionID, err)
+				return nil
+			}
+
+			summary.Imported++
+			fmt.Printf("[%d/%d] %s imported", done, len(pending), input.SessionID)
+			if cost := result.Metrics.CostEstimateUSD; cost != nil {
+				fmt.Printf(" ($%.4f)", *cost)
+			}
+			fmt.Println()
+			for _, warning := range result.Warnings {
+				fmt.Fprintf(os.Stderr, "warning: %s: %s\n", input.SessionID, warning)
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	return summary, nil
+}
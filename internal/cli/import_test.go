@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emiliopalmerini/mclaude/internal/adapters/storage"
+	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
+)
+
+func TestImportTranscripts(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+
+	fixture, err := os.ReadFile("testdata/transcript.jsonl")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	sessionID := "test-import-" + randomID()
+	cwd := "/test/import-" + randomID()
+	defer repos.Sessions.Delete(ctx, sessionID)
+
+	// Lay out transcripts like ~/.claude/projects
+	root := t.TempDir()
+	projectDir := filepath.Join(root, "-test-import")
+	writeFile(t, filepath.Join(projectDir, sessionID+".jsonl"),
+		`{"type":"summary","sessionId":"`+sessionID+`","cwd":"`+cwd+`"}`+"\n"+string(fixture))
+	writeFile(t, filepath.Join(projectDir, sessionID, "subagents", "agent-abc.jsonl"), string(fixture))
+	writeFile(t, filepath.Join(projectDir, "no-cwd.jsonl"), string(fixture))
+
+	paths, err := findTranscripts(root)
+	if err != nil {
+		t.Fatalf("Failed to find transcripts: %v", err)
+	}
+	assertEqual(t, "transcripts found", 2, len(paths))
+
+	transcripts, err := storage.NewTranscriptStorage()
+	if err != nil {
+		t.Fatalf("Failed to create transcript storage: %v", err)
+	}
+	recorder := newRecorder(repos, transcripts)
+
+	// Dry run saves nothing
+	summary, err := importTranscripts(ctx, recorder, repos.Sessions, paths, 2, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	assertEqual(t, "dry run imported", 1, summary.Imported)
+	assertEqual(t, "dry run skipped", 1, summary.Skipped)
+	if s, _ := repos.Sessions.GetByID(ctx, sessionID); s != nil {
+		t.Fatal("Dry run should not record the session")
+	}
+
+	summary, err = importTranscripts(ctx, recorder, repos.Sessions, paths, 2, false)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	assertEqual(t, "imported", 1, summary.Imported)
+	assertEqual(t, "failed", 0, summary.Failed)
+
+	session, err := repos.Sessions.GetByID(ctx, sessionID)
+	if err != nil || session == nil {
+		t.Fatalf("Expected imported session, got %v (err %v)", session, err)
+	}
+	assertEqual(t, "session.Cwd", cwd, session.Cwd)
+	assertEqual(t, "session.ExitReason", importExitReason, session.ExitReason)
+	if session.StartedAt == nil || !session.CreatedAt.Equal(*session.StartedAt) {
+		t.Errorf("Expected imported session dated at its start %v, got %v", session.StartedAt, session.CreatedAt)
+	}
+
+	metrics, _ := repos.Metrics.GetBySessionID(ctx, sessionID)
+	if metrics == nil || metrics.CostEstimateUSD == nil {
+		t.Error("Expected imported session to be priced")
+	}
+
+	// Importing again skips recorded sessions
+	summary, err = importTranscripts(ctx, recorder, repos.Sessions, paths, 2, false)
+	if err != nil {
+		t.Fatalf("Second import failed: %v", err)
+	}
+	assertEqual(t, "imported again", 0, summary.Imported)
+	assertEqual(t, "already recorded", 1, summary.Recorded)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
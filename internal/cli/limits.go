@@ -217,6 +217,7 @@ func runLimitsList(cmd *cobra.Command, args []string) error {
 		fmt.Printf(" / ~%s (%.0f%%)", util.FormatTokens(limit), percentage*100)
 	}
 	fmt.Println()
+	printLiveUsage(summary)
 	fmt.Printf("  Status: %s", status)
 	if limit > 0 {
 		fmt.Printf(" [%s]", tokenLimitSource)
@@ -252,6 +253,7 @@ func runLimitsList(cmd *cobra.Command, args []string) error {
 		fmt.Printf(" / ~%s (%.0f%%)", util.FormatTokens(weeklyLimit), weeklyPercentage*100)
 	}
 	fmt.Println()
+	printLiveUsage(weeklySummary)
 	fmt.Printf("  Status: %s", weeklyStatus)
 	if weeklyLimit > 0 {
 		fmt.Printf(" [%s]", weeklyLimitSource)
@@ -350,3 +352,12 @@ func runLimitsCheck(cmd *cobra.Command, args []string) error {
 	fmt.Println("\nOK")
 	return nil
 }
+
+// printLiveUsage notes how much of a window's usage comes from sessions that
+// are still running.
+func printLiveUsage(summary *domain.UsageSummary) {
+	if summary.LiveSessions == 0 {
+		return
+	}
+	fmt.Printf("  Live:   %s from %d running session(s)\n", util.FormatTokens(summary.LiveTokens), summary.LiveSessions)
+}
@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
+	sqlc "github.com/emiliopalmerini/mclaude/sqlc/generated"
+)
+
+// liveTranscriptLine returns an assistant entry with the given usage, dated
+// now so it falls inside the usage windows.
+func liveTranscriptLine(messageID string, input, output int64) string {
+	return fmt.Sprintf(`{"type":"assistant","timestamp":%q,"message":{"id":%q,"role":"assistant","model":"claude-sonnet-4-5-20241022","content":[{"type":"text","text":"Working."}],"usage":{"input_tokens":%d,"output_tokens":%d,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}`+"\n",
+		time.Now().UTC().Format(time.RFC3339), messageID, input, output)
+}
+
+func TestTrackLiveSession(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	sessionID := "test-live-" + randomID()
+	defer sqlc.New(db).DeleteSession(ctx, sessionID)
+	defer repos.Live.Delete(ctx, sessionID)
+	defer repos.States.Delete(ctx, sessionID)
+
+	path := filepath.Join(t.TempDir(), sessionID+".jsonl")
+	writeFile(t, path, liveTranscriptLine("msg1", 1000, 200))
+
+	input := &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "PostToolUse",
+	}
+
+	before, err := repos.PlanConfig.GetRollingWindowSummary(ctx, 5)
+	if err != nil {
+		t.Fatalf("GetRollingWindowSummary failed: %v", err)
+	}
+
+	live, err := recorder.Track(ctx, input)
+	if err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+	assertEqual(t, "TotalTokens", int64(1200), live.TotalTokens())
+	if live.CostEstimateUSD == nil {
+		t.Error("Expected live session to be priced")
+	}
+
+	// Tracking again only adds what the transcript gained
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open transcript: %v", err)
+	}
+	f.WriteString(liveTranscriptLine("msg2", 500, 100))
+	f.Close()
+
+	input.HookEventName = "Stop"
+	if _, err := recorder.Track(ctx, input); err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+	if _, err := recorder.Track(ctx, input); err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+
+	live, err = repos.Live.Get(ctx, sessionID)
+	if err != nil || live == nil {
+		t.Fatalf("Expected live session, got %v (err: %v)", live, err)
+	}
+	assertEqual(t, "TotalTokens", int64(1800), live.TotalTokens())
+	assertEqual(t, "LastEvent", "Stop", live.LastEvent)
+
+	// The parser state is saved where the live usage stops, for the next hook
+	state, err := repos.States.Get(ctx, sessionID)
+	if err != nil || state == nil {
+		t.Fatalf("Expected transcript state, got %v (err: %v)", state, err)
+	}
+	assertEqual(t, "state ByteOffset", live.ByteOffset, state.ByteOffset)
+
+	during, err := repos.PlanConfig.GetRollingWindowSummary(ctx, 5)
+	if err != nil {
+		t.Fatalf("GetRollingWindowSummary failed: %v", err)
+	}
+	assertEqual(t, "window tokens", before.TotalTokens+1800, during.TotalTokens)
+	assertEqual(t, "LiveTokens", before.LiveTokens+1800, during.LiveTokens)
+	assertEqual(t, "LiveSessions", before.LiveSessions+1, during.LiveSessions)
+
+	// SessionEnd replaces the live usage with the recorded session
+	input.HookEventName = "SessionEnd"
+	if _, err := recorder.Record(ctx, input, ingest.Options{}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	live, err = repos.Live.Get(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if live != nil {
+		t.Error("Expected live session to be removed once recorded")
+	}
+
+	after, err := repos.PlanConfig.GetRollingWindowSummary(ctx, 5)
+	if err != nil {
+		t.Fatalf("GetRollingWindowSummary failed: %v", err)
+	}
+	assertEqual(t, "window tokens", during.TotalTokens, after.TotalTokens)
+
+	// Deleting the session drops its parser state
+	if err := repos.Sessions.Delete(ctx, sessionID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if state, err := repos.States.Get(ctx, sessionID); err != nil || state != nil {
+		t.Errorf("Expected transcript state to be deleted, got %v (err: %v)", state, err)
+	}
+	assertEqual(t, "LiveSessions", before.LiveSessions, after.LiveSessions)
+}
+
+func TestTrackAfterRecord(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	sessionID := "test-live-late-" + randomID()
+	defer sqlc.New(db).DeleteSession(ctx, sessionID)
+	defer repos.Live.Delete(ctx, sessionID)
+	defer repos.States.Delete(ctx, sessionID)
+
+	path := filepath.Join(t.TempDir(), sessionID+".jsonl")
+	writeFile(t, path, liveTranscriptLine("msg1", 1000, 200))
+
+	input := &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "SessionEnd",
+	}
+	if _, err := recorder.Record(ctx, input, ingest.Options{}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	before, err := repos.PlanConfig.GetRollingWindowSummary(ctx, 5)
+	if err != nil {
+		t.Fatalf("GetRollingWindowSummary failed: %v", err)
+	}
+
+	// A Stop hook that lost the race with SessionEnd
+	input.HookEventName = "Stop"
+	live, err := recorder.Track(ctx, input)
+	if err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+	if live != nil {
+		t.Error("Expected no live usage for a recorded session")
+	}
+	if live, err := repos.Live.Get(ctx, sessionID); err != nil || live != nil {
+		t.Errorf("Expected no live session, got %v (err: %v)", live, err)
+	}
+
+	after, err := repos.PlanConfig.GetRollingWindowSummary(ctx, 5)
+	if err != nil {
+		t.Fatalf("GetRollingWindowSummary failed: %v", err)
+	}
+	assertEqual(t, "window tokens", before.TotalTokens, after.TotalTokens)
+	assertEqual(t, "LiveSessions", before.LiveSessions, after.LiveSessions)
+}
+
+func TestTrackResumedSession(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	sessionID := "test-live-resumed-" + randomID()
+	defer sqlc.New(db).DeleteSession(ctx, sessionID)
+	defer repos.Live.Delete(ctx, sessionID)
+	defer repos.States.Delete(ctx, sessionID)
+
+	path := filepath.Join(t.TempDir(), sessionID+".jsonl")
+	writeFile(t, path, liveTranscriptLine("msg1", 1000, 200))
+
+	input := &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "SessionEnd",
+	}
+	if _, err := recorder.Record(ctx, input, ingest.Options{}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	before, err := repos.PlanConfig.GetRollingWindowSummary(ctx, 5)
+	if err != nil {
+		t.Fatalf("GetRollingWindowSummary failed: %v", err)
+	}
+
+	// The session is resumed, so its transcript grows past the recording
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open transcript: %v", err)
+	}
+	f.WriteString(liveTranscriptLine("msg2", 500, 100))
+	f.Close()
+
+	input.HookEventName = "Stop"
+	live, err := recorder.Track(ctx, input)
+	if err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+	if live == nil {
+		t.Fatal("Expected live usage for a resumed session")
+	}
+	assertEqual(t, "TotalTokens", int64(1800), live.TotalTokens())
+
+	// The live usage stands in for the recording rather than adding to it
+	during, err := repos.PlanConfig.GetRollingWindowSummary(ctx, 5)
+	if err != nil {
+		t.Fatalf("GetRollingWindowSummary failed: %v", err)
+	}
+	assertEqual(t, "window tokens", before.TotalTokens+600, during.TotalTokens)
+	assertEqual(t, "LiveSessions", before.LiveSessions+1, during.LiveSessions)
+
+	input.HookEventName = "SessionEnd"
+	if _, err := recorder.Record(ctx, input, ingest.Options{}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	after, err := repos.PlanConfig.GetRollingWindowSummary(ctx, 5)
+	if err != nil {
+		t.Fatalf("GetRollingWindowSummary failed: %v", err)
+	}
+	assertEqual(t, "window tokens", during.TotalTokens, after.TotalTokens)
+	assertEqual(t, "LiveSessions", before.LiveSessions, after.LiveSessions)
+}
+
+func TestTrackPrunesStaleSessions(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	// A session whose SessionEnd hook never fired
+	staleID := "test-live-stale-" + randomID()
+	defer repos.Live.Delete(ctx, staleID)
+	defer repos.States.Delete(ctx, staleID)
+	staleAt := time.Now().Add(-domain.LiveSessionExpiry - time.Hour)
+	if _, err := repos.Live.Create(ctx, &domain.LiveSession{
+		SessionID:      staleID,
+		TranscriptPath: "/tmp/" + staleID + ".jsonl",
+		Cwd:            "/test/project",
+		LastEvent:      "Stop",
+		TokenInput:     1000,
+		StartedAt:      &staleAt,
+		UpdatedAt:      staleAt,
+	}); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := repos.States.Save(ctx, &domain.TranscriptState{
+		SessionID:      staleID,
+		TranscriptPath: "/tmp/" + staleID + ".jsonl",
+		State:          []byte("{}"),
+		UpdatedAt:      staleAt,
+	}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	sessionID := "test-live-fresh-" + randomID()
+	defer repos.Live.Delete(ctx, sessionID)
+	defer repos.States.Delete(ctx, sessionID)
+
+	path := filepath.Join(t.TempDir(), sessionID+".jsonl")
+	writeFile(t, path, liveTranscriptLine("msg1", 1000, 200))
+	input := &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "PostToolUse",
+	}
+	if _, err := recorder.Track(ctx, input); err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+
+	if live, err := repos.Live.Get(ctx, staleID); err != nil || live != nil {
+		t.Errorf("Expected stale live session to be pruned, got %v (err: %v)", live, err)
+	}
+	if state, err := repos.States.Get(ctx, staleID); err != nil || state != nil {
+		t.Errorf("Expected stale transcript state to be pruned, got %v (err: %v)", state, err)
+	}
+	if live, err := repos.Live.Get(ctx, sessionID); err != nil || live == nil {
+		t.Errorf("Expected live session, got %v (err: %v)", live, err)
+	}
+}
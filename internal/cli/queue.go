@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/emiliopalmerini/mclaude/internal/adapters/storage"
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
+	"github.com/emiliopalmerini/mclaude/internal/ports"
+)
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Inspect sessions waiting to be recorded",
+	Long: `The record hook spools each session to a queue under the data directory
+and records it in the background. Sessions that fail to record (e.g. while
+Turso or the disk is unavailable) stay queued with their last error and are
+retried with backoff the next time a session is recorded, up to 5 attempts.
+
+Examples:
+  mclaude queue status              # List queued sessions and their errors
+  mclaude queue retry               # Record all queued sessions now
+  mclaude queue retry <session-id>  # Record one queued session now
+  mclaude queue purge               # Drop sessions that ran out of retries
+  mclaude queue purge --all         # Drop every queued session`,
+	// The queue must be inspectable while the database is unavailable, so
+	// only 'retry' opens it.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+var queueStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List queued sessions",
+	RunE:  runQueueStatus,
+}
+
+var queueRetryCmd = &cobra.Command{
+	Use:   "retry [session-id...]",
+	Short: "Record queued sessions now, ignoring backoff",
+	RunE:  runQueueRetry,
+}
+
+var queuePurgeCmd = &cobra.Command{
+	Use:   "purge [session-id...]",
+	Short: "Remove failed sessions from the queue",
+	RunE:  runQueuePurge,
+}
+
+// Flags
+var queuePurgeAll bool
+
+func init() {
+	rootCmd.AddCommand(queueCmd)
+	queueCmd.AddCommand(queueStatusCmd)
+	queueCmd.AddCommand(queueRetryCmd)
+	queueCmd.AddCommand(queuePurgeCmd)
+
+	queuePurgeCmd.Flags().BoolVar(&queuePurgeAll, "all", false, "Remove every queued session, not only failed ones")
+}
+
+func runQueueStatus(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	queue, err := storage.NewRecordQueue()
+	if err != nil {
+		return err
+	}
+
+	jobs, err := queue.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(jobs) == 0 {
+		fmt.Println("Queue is empty")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SESSION\tSTATUS\tATTEMPTS\tQUEUED\tNEXT ATTEMPT\tLAST ERROR")
+	fmt.Fprintln(w, "-------\t------\t--------\t------\t------------\t----------")
+	for _, job := range jobs {
+		next := "-"
+		if !job.Failed() {
+			next = job.NextAttemptAt.Local().Format("2006-01-02 15:04")
+		}
+		lastError := job.LastError
+		if lastError == "" {
+			lastError = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n",
+			job.Input.SessionID,
+			job.Status(),
+			job.Attempts,
+			job.EnqueuedAt.Local().Format("2006-01-02 15:04"),
+			next,
+			lastError,
+		)
+	}
+	w.Flush()
+
+	fmt.Printf("\n%d session(s) queued\n", len(jobs))
+	return nil
+}
+
+func runQueueRetry(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	queue, err := storage.NewRecordQueue()
+	if err != nil {
+		return err
+	}
+
+	jobs, err := queue.List(ctx)
+	if err != nil {
+		return err
+	}
+	jobs = filterQueuedJobs(jobs, args)
+	if len(jobs) == 0 {
+		fmt.Println("No queued sessions to retry")
+		return nil
+	}
+
+	app, err = NewAppContext()
+	if err != nil {
+		return err
+	}
+
+	record := func(input *domain.HookInput) error {
+		_, err := app.Recorder.Record(ctx, input, ingest.Options{})
+		return err
+	}
+	summary, err := runQueuedJobs(ctx, queue, jobs, record)
+	if err != nil {
+		return err
+	}
+
+	if summary.Recorded > 0 {
+		if err := app.DB.Sync(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: sync failed: %v\n", err)
+		}
+	}
+
+	fmt.Printf("Recorded %d session(s), %d failed\n", summary.Recorded, summary.Failed)
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d sessions failed to record, see 'mclaude queue status'", summary.Failed)
+	}
+	return nil
+}
+
+func runQueuePurge(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	queue, err := storage.NewRecordQueue()
+	if err != nil {
+		return err
+	}
+
+	jobs, err := queue.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	purged := 0
+	for _, job := range filterQueuedJobs(jobs, args) {
+		if !queuePurgeAll && len(args) == 0 && !job.Failed() {
+			continue
+		}
+		if err := queue.Remove(ctx, job.Input.SessionID); err != nil {
+			return err
+		}
+		purged++
+	}
+
+	fmt.Printf("Purged %d session(s) from the queue\n", purged)
+	return nil
+}
+
+// filterQueuedJobs keeps the jobs of the given sessions, or all jobs if no
+// session IDs are given.
+func filterQueuedJobs(jobs []*domain.RecordJob, sessionIDs []string) []*domain.RecordJob {
+	if len(sessionIDs) == 0 {
+		return jobs
+	}
+
+	wanted := make(map[string]bool, len(sessionIDs))
+	for _, id := range sessionIDs {
+		wanted[id] = true
+	}
+
+	var filtered []*domain.RecordJob
+	for _, job := range jobs {
+		if wanted[job.Input.SessionID] {
+			filtered = append(filtered, job)
+		}
+	}
+	return filtered
+}
+
+type queueRunSummary struct {
+	Recorded int
+	Failed   int
+}
+
+// runQueuedJobs records each job it can claim, removing it from the queue on
+// success and keeping it with its error otherwise. Jobs claimed by another
+// process are skipped. The returned error only reports queue failures.
+func runQueuedJobs(ctx context.Context, queue ports.RecordQueue, jobs []*domain.RecordJob, record func(*domain.HookInput) error) (*queueRunSummary, error) {
+	summary := &queueRunSummary{}
+
+	for _, job := range jobs {
+		sessionID := job.Input.SessionID
+		claimed, err := queue.Claim(ctx, job)
+		if err != nil {
+			return summary, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if recordErr := record(&job.Input); recordErr != nil {
+			summary.Failed++
+			fmt.Fprintf(os.Stderr, "%s failed: %v\n", sessionID, recordErr)
+			if err := queue.Fail(ctx, job, recordErr); err != nil {
+				return summary, err
+			}
+			continue
+		}
+
+		summary.Recorded++
+		if err := queue.Complete(ctx, job); err != nil {
+			return summary, err
+		}
+	}
+
+	return summary, nil
+}
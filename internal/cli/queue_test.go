@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/adapters/storage"
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+func TestRunQueuedJobs(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	ctx := context.Background()
+
+	queue, err := storage.NewRecordQueue()
+	if err != nil {
+		t.Fatalf("NewRecordQueue failed: %v", err)
+	}
+
+	for _, id := range []string{"session-ok", "session-bad"} {
+		if err := queue.Enqueue(ctx, &domain.HookInput{SessionID: id, Cwd: "/test/project"}); err != nil {
+			t.Fatalf("Enqueue(%s) failed: %v", id, err)
+		}
+	}
+
+	dbDown := errors.New("failed to connect to database")
+	record := func(input *domain.HookInput) error {
+		if input.SessionID == "session-bad" {
+			return dbDown
+		}
+		return nil
+	}
+
+	jobs, err := queue.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	summary, err := runQueuedJobs(ctx, queue, jobs, record)
+	if err != nil {
+		t.Fatalf("runQueuedJobs failed: %v", err)
+	}
+	assertEqual(t, "Recorded", 1, summary.Recorded)
+	assertEqual(t, "Failed", 1, summary.Failed)
+
+	jobs, err = queue.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("Expected 1 queued job, got %d", len(jobs))
+	}
+	job := jobs[0]
+	assertEqual(t, "SessionID", "session-bad", job.Input.SessionID)
+	assertEqual(t, "Attempts", 1, job.Attempts)
+	assertEqual(t, "LastError", dbDown.Error(), job.LastError)
+	assertEqual(t, "Status", "retrying", job.Status())
+	if job.Due(time.Now()) {
+		t.Error("Expected failed job to back off before its next attempt")
+	}
+
+	// Retrying until the attempts run out leaves the job failed
+	for range domain.RecordJobMaxAttempts - 1 {
+		if _, err := runQueuedJobs(ctx, queue, jobs, record); err != nil {
+			t.Fatalf("runQueuedJobs failed: %v", err)
+		}
+	}
+	jobs, _ = queue.List(ctx)
+	assertEqual(t, "Status", "failed", jobs[0].Status())
+
+	// A successful retry removes it
+	summary, err = runQueuedJobs(ctx, queue, jobs, func(*domain.HookInput) error { return nil })
+	if err != nil {
+		t.Fatalf("runQueuedJobs failed: %v", err)
+	}
+	assertEqual(t, "Recorded", 1, summary.Recorded)
+	jobs, _ = queue.List(ctx)
+	assertEqual(t, "len(jobs)", 0, len(jobs))
+}
+
+func TestRunQueuedJobs_KeepsRequeuedSession(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	ctx := context.Background()
+
+	queue, err := storage.NewRecordQueue()
+	if err != nil {
+		t.Fatalf("NewRecordQueue failed: %v", err)
+	}
+
+	input := &domain.HookInput{SessionID: "session-continued", Cwd: "/test/project"}
+	if err := queue.Enqueue(ctx, input); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	jobs, _ := queue.List(ctx)
+
+	// The session ends again while its first recording is running
+	record := func(*domain.HookInput) error {
+		time.Sleep(time.Millisecond)
+		return queue.Enqueue(ctx, input)
+	}
+	if _, err := runQueuedJobs(ctx, queue, jobs, record); err != nil {
+		t.Fatalf("runQueuedJobs failed: %v", err)
+	}
+
+	jobs, _ = queue.List(ctx)
+	assertEqual(t, "len(jobs)", 1, len(jobs))
+	assertEqual(t, "Status", "pending", jobs[0].Status())
+}
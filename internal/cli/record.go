@@ -8,18 +8,20 @@ import (
 	"io"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/emiliopalmerini/mclaude/internal/adapters/git"
 	"github.com/emiliopalmerini/mclaude/internal/adapters/storage"
 	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
 	"github.com/emiliopalmerini/mclaude/internal/domain"
-	"github.com/emiliopalmerini/mclaude/internal/parser"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
+	"github.com/emiliopalmerini/mclaude/internal/ports"
 )
 
-var recordBackgroundFile string
+var recordBackground bool
+var recordLive bool
 var recordSync bool
 
 // testDBOverride allows tests to inject a database connection.
@@ -32,6 +34,10 @@ var recordCmd = &cobra.Command{
 	Long: `Reads session data from stdin (Claude Code SessionEnd hook),
 parses the transcript, and saves all data to the database.
 
+The Stop, PostToolUse, SubagentStop and UserPromptSubmit hooks are accepted
+too: they read what the transcript gained since the last hook and update the
+session's live usage, so usage limits include sessions still running.
+
 This command is designed to be called from a Claude Code hook:
 
   {
@@ -52,15 +58,17 @@ This command is designed to be called from a Claude Code hook:
 }
 
 func init() {
-	recordCmd.Flags().StringVar(&recordBackgroundFile, "background", "", "process hook input from file (internal use)")
+	recordCmd.Flags().BoolVar(&recordBackground, "background", false, "process queued hook inputs (internal use)")
 	recordCmd.Flags().MarkHidden("background")
+	recordCmd.Flags().BoolVar(&recordLive, "live", false, "track live hook input from stdin (internal use)")
+	recordCmd.Flags().MarkHidden("live")
 	recordCmd.Flags().BoolVar(&recordSync, "sync", false, "process synchronously (for debugging)")
 }
 
 func runRecord(cmd *cobra.Command, args []string) error {
-	// If --background flag is set, process from file
-	if recordBackgroundFile != "" {
-		return processRecordBackground(recordBackgroundFile)
+	// If --background flag is set, process the queue
+	if recordBackground {
+		return processRecordQueue()
 	}
 
 	// Read hook input from stdin
@@ -74,35 +82,47 @@ func runRecord(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
 
+	// Hooks fired while the session runs only update its live usage
+	if domain.LiveHookEvents[hookInput.HookEventName] {
+		if recordSync || recordLive {
+			return processLiveInput(&hookInput)
+		}
+		return spawnLiveTracking(input, &hookInput)
+	}
+	if hookInput.HookEventName != "" && hookInput.HookEventName != "SessionEnd" {
+		return fmt.Errorf("unsupported hook event %q", hookInput.HookEventName)
+	}
+
 	// Process synchronously if --sync flag is set
 	if recordSync {
 		return processRecordInput(&hookInput)
 	}
 
-	// Write input to temp file for background processing
-	tempDir := os.TempDir()
-	tempFile := filepath.Join(tempDir, fmt.Sprintf("mclaude-record-%s.json", hookInput.SessionID))
-	if err := os.WriteFile(tempFile, input, 0600); err != nil {
-		return fmt.Errorf("failed to write temp file: %w", err)
+	// Spool input for background processing. If the queue is unusable,
+	// record synchronously rather than lose the session.
+	queue, err := storage.NewRecordQueue()
+	if err != nil {
+		return processRecordInput(&hookInput)
+	}
+	if err := queue.Enqueue(context.Background(), &hookInput); err != nil {
+		return processRecordInput(&hookInput)
 	}
 
 	// Spawn background process
 	executable, err := os.Executable()
 	if err != nil {
 		// Fallback to synchronous if we can't find executable
-		os.Remove(tempFile)
-		return processRecordInput(&hookInput)
+		return processRecordQueue()
 	}
 
-	bgCmd := exec.Command(executable, "record", "--background", tempFile)
+	bgCmd := exec.Command(executable, "record", "--background")
 	bgCmd.Stdout = nil
 	bgCmd.Stderr = nil
 	bgCmd.Stdin = nil
 
 	if err := bgCmd.Start(); err != nil {
 		// Fallback to synchronous if spawn fails
-		os.Remove(tempFile)
-		return processRecordInput(&hookInput)
+		return processRecordQueue()
 	}
 
 	// Detach from child process
@@ -111,22 +131,88 @@ func runRecord(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func processRecordBackground(inputFile string) error {
-	// Read hook input from file
-	input, err := os.ReadFile(inputFile)
+// spawnLiveTracking hands a live hook input to a background process, since
+// these hooks fire often and shouldn't wait on the database. The input is
+// written to the child's stdin before it's detached.
+func spawnLiveTracking(input []byte, hookInput *domain.HookInput) error {
+	executable, err := os.Executable()
 	if err != nil {
-		return fmt.Errorf("failed to read input file: %w", err)
+		return processLiveInput(hookInput)
 	}
 
-	// Clean up temp file
-	defer os.Remove(inputFile)
+	r, w, err := os.Pipe()
+	if err != nil {
+		return processLiveInput(hookInput)
+	}
+	defer r.Close()
 
-	var hookInput domain.HookInput
-	if err := json.Unmarshal(input, &hookInput); err != nil {
-		return fmt.Errorf("failed to parse hook input: %w", err)
+	bgCmd := exec.Command(executable, "record", "--live")
+	bgCmd.Stdout = nil
+	bgCmd.Stderr = nil
+	bgCmd.Stdin = r
+
+	if err := bgCmd.Start(); err != nil {
+		w.Close()
+		return processLiveInput(hookInput)
+	}
+	w.Write(input)
+	w.Close()
+
+	bgCmd.Process.Release()
+	return nil
+}
+
+// processLiveInput updates the live usage of a running session. It prints
+// nothing on success: UserPromptSubmit hook output is added to the prompt.
+func processLiveInput(hookInput *domain.HookInput) error {
+	ctx := context.Background()
+
+	if testDBOverride != nil {
+		_, err := newRecorder(turso.NewRepositories(testDBOverride), nil).Track(ctx, hookInput)
+		return err
+	}
+
+	db, err := turso.NewDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err := newRecorder(turso.NewRepositories(db.DB), nil).Track(ctx, hookInput); err != nil {
+		return err
+	}
+
+	if err := db.Sync(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: sync failed: %v\n", err)
+	}
+	return nil
+}
+
+// processRecordQueue records the queued sessions that are due. Failures are
+// kept in the queue with their error, to be retried with backoff.
+func processRecordQueue() error {
+	ctx := context.Background()
+
+	queue, err := storage.NewRecordQueue()
+	if err != nil {
+		return err
+	}
+
+	jobs, err := queue.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var due []*domain.RecordJob
+	for _, job := range jobs {
+		if job.Due(now) {
+			due = append(due, job)
+		}
 	}
 
-	return processRecordInput(&hookInput)
+	_, err = runQueuedJobs(ctx, queue, due, processRecordInput)
+	return err
 }
 
 func processRecordInput(hookInput *domain.HookInput) error {
@@ -151,177 +237,26 @@ func processRecordInput(hookInput *domain.HookInput) error {
 	}
 	defer closeDB()
 
-	// Initialize repositories
-	projectRepo := turso.NewProjectRepository(sqlDB)
-	experimentRepo := turso.NewExperimentRepository(sqlDB)
-	sessionRepo := turso.NewSessionRepository(sqlDB)
-	metricsRepo := turso.NewSessionMetricsRepository(sqlDB)
-	toolRepo := turso.NewSessionToolRepository(sqlDB)
-	fileRepo := turso.NewSessionFileRepository(sqlDB)
-	commandRepo := turso.NewSessionCommandRepository(sqlDB)
-	subagentRepo := turso.NewSessionSubagentRepository(sqlDB)
-	pricingRepo := turso.NewPricingRepository(sqlDB)
-	qualityRepo := turso.NewSessionQualityRepository(sqlDB)
-	planConfigRepo := turso.NewPlanConfigRepository(sqlDB)
-
 	// Initialize transcript storage
 	transcriptStorage, err := storage.NewTranscriptStorage()
 	if err != nil {
 		return fmt.Errorf("failed to initialize transcript storage: %w", err)
 	}
 
-	// Get or create project
-	project, err := projectRepo.GetOrCreate(ctx, hookInput.Cwd)
+	recorder := newRecorder(turso.NewRepositories(sqlDB), transcriptStorage)
+	result, err := recorder.Record(ctx, hookInput, ingest.Options{})
 	if err != nil {
-		return fmt.Errorf("failed to get/create project: %w", err)
+		return err
 	}
 
-	// Get active experiment (if any)
-	activeExperiment, err := experimentRepo.GetActive(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get active experiment: %w", err)
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
 	}
-
-	// Parse transcript
-	parsed, err := parser.ParseTranscript(hookInput.SessionID, hookInput.TranscriptPath)
-	if err != nil {
-		return fmt.Errorf("failed to parse transcript: %w", err)
+	if result.WindowReset {
+		fmt.Println("5-hour usage window reset")
 	}
-
-	// Reset usage windows if expired
-	if parsed.StartedAt != nil {
-		if reset, err := planConfigRepo.ResetWindowIfExpired(ctx, *parsed.StartedAt); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to check window reset: %v\n", err)
-		} else if reset {
-			fmt.Println("5-hour usage window reset")
-		}
-		if reset, err := planConfigRepo.ResetWeeklyWindowIfExpired(ctx, *parsed.StartedAt); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to check weekly window reset: %v\n", err)
-		} else if reset {
-			fmt.Println("Weekly usage window reset")
-		}
-	}
-
-	// Store transcript copy
-	storedPath, err := transcriptStorage.Store(ctx, hookInput.SessionID, hookInput.TranscriptPath)
-	if err != nil {
-		// Log but don't fail - transcript storage is not critical
-		fmt.Fprintf(os.Stderr, "warning: failed to store transcript copy: %v\n", err)
-	}
-
-	// Set model ID from parsed transcript
-	parsed.Metrics.ModelID = parsed.ModelID
-
-	// Calculate cost estimate using model-specific pricing if available
-	var costEstimate *float64
-	var pricing *domain.ModelPricing
-
-	// Try to get pricing for the specific model used
-	if parsed.ModelID != nil {
-		pricing, _ = pricingRepo.GetByID(ctx, *parsed.ModelID)
-	}
-
-	// Fall back to default pricing if model-specific pricing not found
-	if pricing == nil {
-		pricing, _ = pricingRepo.GetDefault(ctx)
-	}
-
-	if pricing != nil {
-		cost := pricing.CalculateCost(
-			parsed.Metrics.TokenInput,
-			parsed.Metrics.TokenOutput,
-			parsed.Metrics.TokenCacheRead,
-			parsed.Metrics.TokenCacheWrite,
-		)
-		costEstimate = &cost
-	}
-	parsed.Metrics.CostEstimateUSD = costEstimate
-
-	// Calculate duration
-	var durationSeconds *int64
-	if parsed.StartedAt != nil && parsed.EndedAt != nil {
-		dur := int64(parsed.EndedAt.Sub(*parsed.StartedAt).Seconds())
-		durationSeconds = &dur
-	}
-
-	// Build session
-	session := &domain.Session{
-		ID:              hookInput.SessionID,
-		ProjectID:       project.ID,
-		TranscriptPath:  hookInput.TranscriptPath,
-		Cwd:             hookInput.Cwd,
-		PermissionMode:  hookInput.PermissionMode,
-		ExitReason:      hookInput.Reason,
-		StartedAt:       parsed.StartedAt,
-		EndedAt:         parsed.EndedAt,
-		DurationSeconds: durationSeconds,
-		CreatedAt:       time.Now().UTC(),
-	}
-
-	if storedPath != "" {
-		session.TranscriptStoredPath = &storedPath
-	}
-
-	if activeExperiment != nil {
-		session.ExperimentID = &activeExperiment.ID
-	}
-
-	// Save session (upsert - handles continued sessions)
-	if err := sessionRepo.Create(ctx, session); err != nil {
-		return fmt.Errorf("failed to create session: %w", err)
-	}
-
-	// Clear any existing quality data (stale after session continuation)
-	_ = qualityRepo.Delete(ctx, session.ID)
-
-	// Save metrics
-	if err := metricsRepo.Create(ctx, parsed.Metrics); err != nil {
-		return fmt.Errorf("failed to create session metrics: %w", err)
-	}
-
-	// Save tools
-	if len(parsed.Tools) > 0 {
-		if err := toolRepo.CreateBatch(ctx, parsed.Tools); err != nil {
-			return fmt.Errorf("failed to create session tools: %w", err)
-		}
-	}
-
-	// Save files
-	if len(parsed.Files) > 0 {
-		if err := fileRepo.CreateBatch(ctx, parsed.Files); err != nil {
-			return fmt.Errorf("failed to create session files: %w", err)
-		}
-	}
-
-	// Save commands
-	if len(parsed.Commands) > 0 {
-		if err := commandRepo.CreateBatch(ctx, parsed.Commands); err != nil {
-			return fmt.Errorf("failed to create session commands: %w", err)
-		}
-	}
-
-	// Calculate sub-agent cost estimates and save
-	if len(parsed.Subagents) > 0 {
-		for _, sa := range parsed.Subagents {
-			// Try to find pricing for sub-agent model
-			var saPricing *domain.ModelPricing
-			if sa.Model != nil {
-				// Try alias lookup (e.g., "haiku" -> model ID)
-				if modelID := resolveModelAlias(*sa.Model); modelID != "" {
-					saPricing, _ = pricingRepo.GetByID(ctx, modelID)
-				}
-			}
-			if saPricing == nil {
-				saPricing = pricing // Fall back to session's default pricing
-			}
-			if saPricing != nil {
-				cost := saPricing.CalculateCost(sa.TokenInput, sa.TokenOutput, sa.TokenCacheRead, sa.TokenCacheWrite)
-				sa.CostEstimateUSD = &cost
-			}
-		}
-		if err := subagentRepo.CreateBatch(ctx, parsed.Subagents); err != nil {
-			return fmt.Errorf("failed to create session subagents: %w", err)
-		}
+	if result.WeeklyWindowReset {
+		fmt.Println("Weekly usage window reset")
 	}
 
 	// Sync to remote if enabled (only for real Turso connection)
@@ -334,31 +269,38 @@ func processRecordInput(hookInput *domain.HookInput) error {
 	// Output success message (goes to stdout, visible in hook output)
 	fmt.Printf("Session %s recorded: %d input tokens, %d output tokens",
 		hookInput.SessionID[:8],
-		parsed.Metrics.TokenInput,
-		parsed.Metrics.TokenOutput,
+		result.Metrics.TokenInput,
+		result.Metrics.TokenOutput,
 	)
-	if costEstimate != nil {
-		fmt.Printf(", $%.4f estimated cost", *costEstimate)
+	if result.Metrics.CostEstimateUSD != nil {
+		fmt.Printf(", $%.4f estimated cost", *result.Metrics.CostEstimateUSD)
+	}
+	if len(result.ModelUsage) > 1 {
+		fmt.Printf(", %d models", len(result.ModelUsage))
+	}
+	if result.Subagents > 0 {
+		fmt.Printf(", %d sub-agents", result.Subagents)
 	}
-	if len(parsed.Subagents) > 0 {
-		fmt.Printf(", %d sub-agents", len(parsed.Subagents))
+	if result.Commits > 0 {
+		fmt.Printf(", %d commits", result.Commits)
 	}
 	fmt.Println()
 
 	return nil
 }
 
-// resolveModelAlias maps short model aliases to full model IDs for pricing lookup.
-func resolveModelAlias(alias string) string {
-	aliases := map[string]string{
-		"haiku":  "claude-haiku-4-5-20251001",
-		"sonnet": "claude-sonnet-4-5-20250929",
-		"opus":   "claude-opus-4-6-20260206",
-	}
-	if id, ok := aliases[alias]; ok {
-		return id
-	}
-	// If it's already a full model ID, return as-is
-	return alias
+// newRecorder wires a Recorder to the given repositories.
+func newRecorder(repos *turso.Repositories, transcripts ports.TranscriptStorage) *ingest.Recorder {
+	return ingest.NewRecorder(ingest.Deps{
+		Projects:    repos.Projects,
+		Experiments: repos.Experiments,
+		Pricing:     repos.Pricing,
+		Recordings:  repos.Recordings,
+		Live:        repos.Live,
+		States:      repos.States,
+		ToolRules:   repos.ToolRules,
+		PlanConfig:  repos.PlanConfig,
+		Transcripts: transcripts,
+		Git:         git.NewInspector(),
+	})
 }
-
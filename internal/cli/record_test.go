@@ -11,7 +11,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
 	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
 	sqlc "github.com/emiliopalmerini/mclaude/sqlc/generated"
 )
 
@@ -217,6 +219,7 @@ func runRecordTest(t *testing.T, db *sql.DB) {
 		t.Fatalf("Expected 1 command, got %d", len(commands))
 	}
 	assertEqual(t, "command.Command", "go build ./...", commands[0].Command)
+	assertEqual(t, "command.Status", domain.CommandSucceeded, commands[0].Status.String)
 
 	// Verify sub-agents
 	subagents, err := queries.ListSessionSubagentsBySessionID(ctx, sessionID)
@@ -251,6 +254,35 @@ func runRecordTest(t *testing.T, db *sql.DB) {
 		assertEqual(t, "subagent.Model", "haiku", subagents[0].Model.String)
 	}
 
+	// Verify per-model usage: parent messages on sonnet, sub-agent on the model the haiku alias points to
+	modelUsage, err := queries.ListSessionModelUsageBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to get model usage: %v", err)
+	}
+
+	if len(modelUsage) != 2 {
+		t.Fatalf("Expected 2 model usage rows, got %d", len(modelUsage))
+	}
+	assertEqual(t, "modelUsage[0].ModelID", "claude-sonnet-4-20250514", modelUsage[0].ModelID)
+	assertEqual(t, "modelUsage[0].MessageCount", int64(5), modelUsage[0].MessageCount)
+	assertEqual(t, "modelUsage[0].TokenInput", int64(370), modelUsage[0].TokenInput)
+	assertEqual(t, "modelUsage[0].TokenOutput", int64(185), modelUsage[0].TokenOutput)
+	assertEqual(t, "modelUsage[1].ModelID", "claude-haiku-4-5-20250101", modelUsage[1].ModelID)
+	assertEqual(t, "modelUsage[1].TokenInput", int64(6000), modelUsage[1].TokenInput)
+	assertEqual(t, "modelUsage[1].TokenCacheWrite", int64(249), modelUsage[1].TokenCacheWrite)
+
+	// Session cost is the sum of the per-model costs
+	var modelCostSum float64
+	for _, mu := range modelUsage {
+		if !mu.CostEstimateUsd.Valid {
+			t.Errorf("Expected cost estimate for model %s", mu.ModelID)
+		}
+		modelCostSum += mu.CostEstimateUsd.Float64
+	}
+	if diff := modelCostSum - metrics.CostEstimateUsd.Float64; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected session cost %f to equal sum of model costs %f", metrics.CostEstimateUsd.Float64, modelCostSum)
+	}
+
 	// Verify transcript was stored
 	storedPath := session.TranscriptStoredPath
 	if !storedPath.Valid {
@@ -266,6 +298,233 @@ func runRecordTest(t *testing.T, db *sql.DB) {
 	t.Log("All assertions passed!")
 }
 
+// TestRecord_ReplacesChildRows records the same session twice, as happens
+// when a session is continued, and checks its rows are replaced rather than
+// appended to.
+func TestRecord_ReplacesChildRows(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	queries := sqlc.New(db)
+	recorder := newRecorder(turso.NewRepositories(db), nil)
+
+	transcriptPath, err := filepath.Abs("testdata/transcript.jsonl")
+	if err != nil {
+		t.Fatalf("Failed to get transcript path: %v", err)
+	}
+
+	sessionID := "test-rerecord-" + randomID()
+	defer queries.DeleteSession(ctx, sessionID)
+
+	input := &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: transcriptPath,
+		Cwd:            "/test/project",
+		HookEventName:  "SessionEnd",
+		Reason:         "exit",
+	}
+
+	for i := range 2 {
+		if _, err := recorder.Record(ctx, input, ingest.Options{}); err != nil {
+			t.Fatalf("Record #%d failed: %v", i+1, err)
+		}
+	}
+
+	commands, err := queries.ListSessionCommandsBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to list commands: %v", err)
+	}
+	assertEqual(t, "len(commands)", 1, len(commands))
+
+	tools, err := queries.ListSessionToolsBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to list tools: %v", err)
+	}
+	for _, tool := range tools {
+		if tool.ToolName == "Bash" {
+			assertEqual(t, "Bash invocations", int64(1), tool.InvocationCount)
+		}
+	}
+
+	subagents, err := queries.ListSessionSubagentsBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to list subagents: %v", err)
+	}
+	assertEqual(t, "len(subagents)", 1, len(subagents))
+
+	modelUsage, err := queries.ListSessionModelUsageBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to list model usage: %v", err)
+	}
+	assertEqual(t, "len(modelUsage)", 2, len(modelUsage))
+
+	turns, err := queries.ListSessionTurnsBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to list turns: %v", err)
+	}
+	assertEqual(t, "len(turns)", 2, len(turns))
+	assertEqual(t, "turns[0].Prompt", "Help me with code", turns[0].Prompt)
+	assertEqual(t, "turns[1].Prompt", "Thanks!", turns[1].Prompt)
+
+	// Turn costs add up to the session's
+	metrics, err := queries.GetSessionMetricsBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to get metrics: %v", err)
+	}
+	var turnCost float64
+	for _, turn := range turns {
+		if !turn.CostEstimateUsd.Valid {
+			t.Fatalf("Turn %d has no cost", turn.TurnIndex)
+		}
+		turnCost += turn.CostEstimateUsd.Float64
+	}
+	if diff := turnCost - metrics.CostEstimateUsd.Float64; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Turn costs sum to %f, session cost is %f", turnCost, metrics.CostEstimateUsd.Float64)
+	}
+}
+
+// TestRecord_SubagentTranscripts records a session whose agent left its own
+// transcript, twice, and checks the agent's tools and files are saved once.
+func TestRecord_SubagentTranscripts(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	queries := sqlc.New(db)
+	recorder := newRecorder(turso.NewRepositories(db), nil)
+
+	sessionID := "test-subagent-" + randomID()
+	defer queries.DeleteSession(ctx, sessionID)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, sessionID+".jsonl")
+	writeFile(t, path, `{"type":"assistant","timestamp":"2025-01-17T10:00:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"subagent_type":"Explore","prompt":"Look"}}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:01:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":"Done"}]},"toolUseResult":{"status":"completed","agentId":"a1","totalTokens":1000,"usage":{"input_tokens":800,"output_tokens":200,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`)
+	if err := os.MkdirAll(filepath.Join(dir, sessionID, "subagents"), 0755); err != nil {
+		t.Fatalf("Failed to create subagents directory: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, sessionID, "subagents", "agent-a1.jsonl"), `{"type":"assistant","isSidechain":true,"agentId":"a1","timestamp":"2025-01-17T10:00:10Z","message":{"id":"s1","role":"assistant","content":[{"type":"tool_use","id":"read1","name":"Read","input":{"file_path":"/src/a.go"}}]}}
+`)
+
+	input := &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "SessionEnd",
+		Reason:         "exit",
+	}
+	for i := range 2 {
+		if _, err := recorder.Record(ctx, input, ingest.Options{}); err != nil {
+			t.Fatalf("Record #%d failed: %v", i+1, err)
+		}
+	}
+
+	subagents, err := queries.ListSessionSubagentsBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to list subagents: %v", err)
+	}
+	assertEqual(t, "len(subagents)", 1, len(subagents))
+	assertEqual(t, "subagent tool use", "task1", subagents[0].ToolUseID.String)
+	assertEqual(t, "subagent agent ID", "a1", subagents[0].AgentID.String)
+
+	tools, err := queries.ListSessionSubagentToolsBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to list subagent tools: %v", err)
+	}
+	assertEqual(t, "len(tools)", 1, len(tools))
+	assertEqual(t, "tools[0].ToolName", "Read", tools[0].ToolName)
+	assertEqual(t, "tools[0].SubagentToolUseID", "task1", tools[0].SubagentToolUseID)
+
+	files, err := queries.ListSessionSubagentFilesBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to list subagent files: %v", err)
+	}
+	assertEqual(t, "len(files)", 1, len(files))
+	assertEqual(t, "files[0].FilePath", "/src/a.go", files[0].FilePath)
+}
+
+func TestRecord_UserToolRules(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	queries := sqlc.New(db)
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	sessionID := "test-toolrules-" + randomID()
+	defer queries.DeleteSession(ctx, sessionID)
+
+	tool := "mcp__notes" + randomID() + "__append"
+	if err := repos.ToolRules.Set(ctx, &domain.ToolRule{Tool: tool, Operation: "write", Fields: []string{"note"}}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	defer repos.ToolRules.Delete(ctx, tool)
+
+	path := filepath.Join(t.TempDir(), sessionID+".jsonl")
+	writeFile(t, path, `{"type":"assistant","timestamp":"2025-01-17T10:00:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"t1","name":"`+tool+`","input":{"note":"/notes/today.md"}},{"type":"tool_use","id":"t2","name":"MultiEdit","input":{"file_path":"/src/a.go","edits":[]}}]}}
+`)
+
+	_, err := recorder.Record(ctx, &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "SessionEnd",
+		Reason:         "exit",
+	}, ingest.Options{})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	files, err := queries.ListSessionFilesBySessionID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+	ops := make(map[string]string)
+	for _, f := range files {
+		ops[f.FilePath] = f.Operation
+	}
+	assertEqual(t, "note operation", "write", ops["/notes/today.md"])
+	assertEqual(t, "MultiEdit operation", "edit", ops["/src/a.go"])
+}
+
+func TestRecord_UnterminatedLastLine(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	sessionID := "test-unterminated-" + randomID()
+	defer repos.Sessions.Delete(ctx, sessionID)
+
+	// The session ended, so the last response counts without a newline
+	path := filepath.Join(t.TempDir(), sessionID+".jsonl")
+	writeFile(t, path, `{"type":"assistant","timestamp":"2025-01-17T10:00:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"One"}],"usage":{"input_tokens":100,"output_tokens":10}}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Two"}],"usage":{"input_tokens":200,"output_tokens":20}}}`)
+
+	_, err := recorder.Record(ctx, &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "SessionEnd",
+		Reason:         "exit",
+	}, ingest.Options{})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	metrics, err := repos.Metrics.GetBySessionID(ctx, sessionID)
+	if err != nil || metrics == nil {
+		t.Fatalf("Expected metrics, got %v (err %v)", metrics, err)
+	}
+	assertEqual(t, "TokenInput", int64(300), metrics.TokenInput)
+	assertEqual(t, "MessageCountAssistant", int64(2), metrics.MessageCountAssistant)
+}
+
 func assertEqual[T comparable](t *testing.T, name string, expected, actual T) {
 	t.Helper()
 	if expected != actual {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/emiliopalmerini/mclaude/internal/pricing"
 	"github.com/emiliopalmerini/mclaude/internal/web"
 )
 
@@ -45,6 +46,8 @@ func runServe(cmd *cobra.Command, args []string) error {
 	server := web.NewServer(
 		app.DB.DB, servePort, app.TranscriptStorage, app.QualityRepo, app.PlanConfigRepo,
 		app.ExperimentRepo, app.PricingRepo, app.SessionRepo, app.MetricsRepo, app.StatsRepo, app.ProjectRepo,
+		pricing.NewRecomputer(app.CostRepo, app.ModelUsageRepo, app.SubagentRepo, app.PricingRepo, app.TranscriptStorage),
+		app.Recorder,
 	)
 	return server.Start(ctx)
 }
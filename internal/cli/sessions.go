@@ -27,7 +27,8 @@ Examples:
   mclaude sessions list                     # Last 10 sessions
   mclaude sessions list --last 20           # Last 20 sessions
   mclaude sessions list --experiment "exp"  # Sessions for experiment
-  mclaude sessions list --project <id>      # Sessions for project`,
+  mclaude sessions list --project <id>      # Sessions for project
+  mclaude sessions list --branch main       # Sessions recorded on a git branch`,
 	RunE: runSessionsList,
 }
 
@@ -36,6 +37,7 @@ var (
 	sessionsLast       int
 	sessionsExperiment string
 	sessionsProject    string
+	sessionsBranch     string
 )
 
 func init() {
@@ -45,6 +47,7 @@ func init() {
 	sessionsListCmd.Flags().IntVarP(&sessionsLast, "last", "n", 10, "Number of sessions to show")
 	sessionsListCmd.Flags().StringVarP(&sessionsExperiment, "experiment", "e", "", "Filter by experiment name")
 	sessionsListCmd.Flags().StringVar(&sessionsProject, "project", "", "Filter by project ID")
+	sessionsListCmd.Flags().StringVar(&sessionsBranch, "branch", "", "Filter by git branch")
 }
 
 func runSessionsList(cmd *cobra.Command, args []string) error {
@@ -62,6 +65,8 @@ func runSessionsList(cmd *cobra.Command, args []string) error {
 		opts.ExperimentID = &exp.ID
 	} else if sessionsProject != "" {
 		opts.ProjectID = &sessionsProject
+	} else if sessionsBranch != "" {
+		opts.Branch = &sessionsBranch
 	}
 
 	sessions, err := app.SessionRepo.List(ctx, opts)
@@ -75,8 +80,8 @@ func runSessionsList(cmd *cobra.Command, args []string) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\tDATE\tTURNS\tTOKENS\tCOST\tREASON")
-	fmt.Fprintln(w, "--\t----\t-----\t------\t----\t------")
+	fmt.Fprintln(w, "ID\tDATE\tBRANCH\tTURNS\tTOKENS\tCOST\tREASON")
+	fmt.Fprintln(w, "--\t----\t------\t-----\t------\t----\t------")
 
 	for _, s := range sessions {
 		id := s.ID
@@ -99,7 +104,12 @@ func runSessionsList(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", id, date, turns, tokens, cost, s.ExitReason)
+		branch := "-"
+		if s.GitBranch != nil {
+			branch = truncate(*s.GitBranch, 24)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", id, date, branch, turns, tokens, cost, s.ExitReason)
 	}
 
 	w.Flush()
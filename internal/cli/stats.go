@@ -21,7 +21,9 @@ Examples:
   mclaude stats --period today           # Today's stats
   mclaude stats --period week            # This week's stats
   mclaude stats --experiment "baseline"  # Stats for an experiment
-  mclaude stats --project <id>           # Stats for a project`,
+  mclaude stats --project <id>           # Stats for a project
+  mclaude stats --mcp                    # Usage per MCP server and tool
+  mclaude stats --slash-commands         # Slash commands, skills and hooks used`,
 	RunE: runStats,
 }
 
@@ -30,6 +32,8 @@ var (
 	statsPeriod     string
 	statsExperiment string
 	statsProject    string
+	statsMCP        bool
+	statsSlash      bool
 )
 
 func init() {
@@ -38,6 +42,8 @@ func init() {
 	statsCmd.Flags().StringVarP(&statsPeriod, "period", "p", "all", "Time period: today, week, month, all")
 	statsCmd.Flags().StringVarP(&statsExperiment, "experiment", "e", "", "Filter by experiment name")
 	statsCmd.Flags().StringVar(&statsProject, "project", "", "Filter by project ID")
+	statsCmd.Flags().BoolVar(&statsMCP, "mcp", false, "Show usage per MCP server and tool")
+	statsCmd.Flags().BoolVar(&statsSlash, "slash-commands", false, "Show slash command, skill and hook usage")
 }
 
 func runStats(cmd *cobra.Command, args []string) error {
@@ -45,6 +51,13 @@ func runStats(cmd *cobra.Command, args []string) error {
 
 	startDate := getStartDate(statsPeriod)
 
+	if statsMCP {
+		return runStatsMCP(ctx, startDate)
+	}
+	if statsSlash {
+		return runStatsSlashCommands(ctx, startDate)
+	}
+
 	var stats *domain.AggregateStats
 	var filterLabel string
 
@@ -85,7 +98,10 @@ func runStats(cmd *cobra.Command, args []string) error {
 	// Get top tools
 	tools, _ := app.StatsRepo.GetTopTools(ctx, startDate, 5)
 
-	printStats(stats, filterLabel, statsPeriod, activeExpName, tools)
+	// Get per-model breakdown
+	models, _ := app.StatsRepo.GetModelUsage(ctx, startDate)
+
+	printStats(stats, filterLabel, statsPeriod, activeExpName, tools, models)
 
 	return nil
 }
@@ -112,7 +128,7 @@ func getStartDate(period string) string {
 	return start.Format(time.RFC3339)
 }
 
-func printStats(stats *domain.AggregateStats, filterLabel, period, activeExp string, tools []domain.ToolUsageStats) {
+func printStats(stats *domain.AggregateStats, filterLabel, period, activeExp string, tools []domain.ToolUsageStats, models []domain.ModelUsageStats) {
 	periodLabel := "All time"
 	switch period {
 	case "today":
@@ -157,11 +173,31 @@ func printStats(stats *domain.AggregateStats, filterLabel, period, activeExp str
 	fmt.Printf("  Estimated:         $%.4f\n", stats.TotalCostUsd)
 	fmt.Println()
 
+	if len(models) > 0 {
+		fmt.Printf("  Models\n")
+		fmt.Printf("  ------\n")
+		for _, m := range models {
+			fmt.Printf("  %-30s %s tokens  $%.4f\n",
+				m.ModelID,
+				util.FormatTokensInt(m.TotalTokenInput+m.TotalTokenOutput),
+				m.TotalCostUsd,
+			)
+		}
+		fmt.Println()
+	}
+
 	if len(tools) > 0 {
 		fmt.Printf("  Top Tools\n")
 		fmt.Printf("  ---------\n")
 		for _, tool := range tools {
-			fmt.Printf("  %-18s %s calls\n", tool.ToolName, util.FormatNumber(tool.TotalInvocations))
+			fmt.Printf("  %-18s %s calls", tool.ToolName, util.FormatNumber(tool.TotalInvocations))
+			if tool.TotalErrors > 0 {
+				fmt.Printf("  %s errors", util.FormatNumber(tool.TotalErrors))
+			}
+			if tool.AvgDurationMs != nil {
+				fmt.Printf("  avg %s", util.FormatDurationMs(*tool.AvgDurationMs))
+			}
+			fmt.Println()
 		}
 		fmt.Println()
 	}
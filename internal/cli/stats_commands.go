@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/util"
+)
+
+var statsCommandsCmd = &cobra.Command{
+	Use:   "commands",
+	Short: "Show the slowest and most failing Bash commands",
+	Long: `Show the Bash commands that took longest on average and those that
+failed most often, from the tool results recorded with each session.
+
+Examples:
+  mclaude stats commands                   # All-time report
+  mclaude stats commands --period week     # This week's commands
+  mclaude stats commands --limit 20`,
+	RunE: runStatsCommands,
+}
+
+// Flags
+var (
+	statsCommandsPeriod string
+	statsCommandsLimit  int
+)
+
+func init() {
+	statsCmd.AddCommand(statsCommandsCmd)
+
+	statsCommandsCmd.Flags().StringVarP(&statsCommandsPeriod, "period", "p", "all", "Time period: today, week, month, all")
+	statsCommandsCmd.Flags().IntVarP(&statsCommandsLimit, "limit", "n", 10, "Number of commands per list")
+}
+
+func runStatsCommands(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	startDate := getStartDate(statsCommandsPeriod)
+
+	slowest, err := app.StatsRepo.GetSlowestCommands(ctx, startDate, statsCommandsLimit)
+	if err != nil {
+		return err
+	}
+	failing, err := app.StatsRepo.GetFailingCommands(ctx, startDate, statsCommandsLimit)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("  Slowest Commands")
+	fmt.Println("  ----------------")
+	printCommandStats(slowest)
+
+	fmt.Println("  Most Failing Commands")
+	fmt.Println("  ---------------------")
+	printCommandStats(failing)
+	return nil
+}
+
+func printCommandStats(commands []domain.CommandStats) {
+	if len(commands) == 0 {
+		fmt.Println("  No commands recorded")
+		fmt.Println()
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  COMMAND\tRUNS\tFAILED\tAVG\tMAX")
+	for _, c := range commands {
+		avg, max := "-", "-"
+		if c.AvgDurationMs != nil {
+			avg = util.FormatDurationMs(*c.AvgDurationMs)
+		}
+		if c.MaxDurationMs != nil {
+			max = util.FormatDurationMs(*c.MaxDurationMs)
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%d\t%s\t%s\n",
+			truncate(commandLine(c.Command), 60), c.RunCount, c.FailureCount, avg, max)
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+// commandLine flattens a multi-line command onto one line for tables.
+func commandLine(command string) string {
+	return strings.Join(strings.Fields(command), " ")
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
+)
+
+func TestCommandStats(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	sessionID := "test-commands-" + randomID()
+	defer repos.Sessions.Delete(ctx, sessionID)
+
+	// A command unique to this test, run twice: once failing after 3s and
+	// once succeeding after 1s
+	command := "make check-" + sessionID
+	path := filepath.Join(t.TempDir(), sessionID+".jsonl")
+	writeFile(t, path, `{"type":"assistant","timestamp":"2025-01-17T10:00:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"bash1","name":"Bash","input":{"command":"`+command+`"}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:03Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"bash1","content":"Exit code 1","is_error":true}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:04Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"bash2","name":"Bash","input":{"command":"`+command+`"}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:05Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"bash2","content":"ok"}]}}
+`)
+
+	_, err := recorder.Record(ctx, &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "SessionEnd",
+		Reason:         "exit",
+	}, ingest.Options{})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	failing, err := repos.Stats.GetFailingCommands(ctx, "1970-01-01T00:00:00Z", 1000)
+	if err != nil {
+		t.Fatalf("GetFailingCommands failed: %v", err)
+	}
+	var stats *domain.CommandStats
+	for i := range failing {
+		if failing[i].Command == command {
+			stats = &failing[i]
+		}
+	}
+	if stats == nil {
+		t.Fatalf("Expected %q among failing commands", command)
+	}
+	assertEqual(t, "RunCount", int64(2), stats.RunCount)
+	assertEqual(t, "FailureCount", int64(1), stats.FailureCount)
+	if stats.AvgDurationMs == nil || *stats.AvgDurationMs != 2000 {
+		t.Errorf("Expected average duration 2000, got %v", stats.AvgDurationMs)
+	}
+	if stats.MaxDurationMs == nil || *stats.MaxDurationMs != 3000 {
+		t.Errorf("Expected max duration 3000, got %v", stats.MaxDurationMs)
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/util"
+)
+
+// runStatsMCP prints usage per MCP server and per MCP tool, for the
+// experiment given with --experiment or for all sessions.
+func runStatsMCP(ctx context.Context, startDate string) error {
+	var servers []domain.MCPServerStats
+	var tools []domain.MCPToolStats
+
+	if statsExperiment != "" {
+		exp, err := getExperimentByName(ctx, app.ExperimentRepo, statsExperiment)
+		if err != nil {
+			return err
+		}
+		if servers, err = app.StatsRepo.GetMCPServersByExperiment(ctx, exp.ID, startDate); err != nil {
+			return err
+		}
+		if tools, err = app.StatsRepo.GetMCPToolsByExperiment(ctx, exp.ID, startDate); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if servers, err = app.StatsRepo.GetMCPServers(ctx, startDate); err != nil {
+			return err
+		}
+		if tools, err = app.StatsRepo.GetMCPTools(ctx, startDate); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("  MCP Servers")
+	fmt.Println("  -----------")
+	printMCPServers(servers)
+
+	if len(tools) > 0 {
+		fmt.Println("  MCP Tools")
+		fmt.Println("  ---------")
+		printMCPTools(tools)
+	}
+	return nil
+}
+
+func printMCPServers(servers []domain.MCPServerStats) {
+	if len(servers) == 0 {
+		fmt.Println("  No MCP tools used")
+		fmt.Println()
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  SERVER\tTOOLS\tSESSIONS\tCALLS\tERRORS\tRESULT TOKENS")
+	for _, s := range servers {
+		fmt.Fprintf(w, "  %s\t%d\t%d\t%s\t%d\t%s\n",
+			s.Server, s.ToolCount, s.SessionCount, util.FormatNumber(s.TotalInvocations),
+			s.TotalErrors, util.FormatTokensInt(s.ResultTokens))
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+func printMCPTools(tools []domain.MCPToolStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  SERVER\tTOOL\tCALLS\tERRORS\tRESULT TOKENS")
+	for _, t := range tools {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%d\t%s\n",
+			t.Server, t.Tool, util.FormatNumber(t.TotalInvocations),
+			t.TotalErrors, util.FormatTokensInt(t.ResultTokens))
+	}
+	w.Flush()
+	fmt.Println()
+}
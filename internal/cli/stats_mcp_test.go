@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
+)
+
+func TestMCPStats(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	sessionID := "test-mcp-" + randomID()
+	defer repos.Sessions.Delete(ctx, sessionID)
+
+	// A server unique to this test with two tools, one call failing
+	server := "srv_" + randomID()
+	path := filepath.Join(t.TempDir(), sessionID+".jsonl")
+	writeFile(t, path, `{"type":"assistant","timestamp":"2025-01-17T10:00:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"t1","name":"mcp__`+server+`__search","input":{}},{"type":"tool_use","id":"t2","name":"mcp__`+server+`__search","input":{}},{"type":"tool_use","id":"t3","name":"mcp__`+server+`__fetch","input":{}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:01Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"12345678"},{"type":"tool_result","tool_use_id":"t2","content":"boom","is_error":true},{"type":"tool_result","tool_use_id":"t3","content":"1234"}]}}
+`)
+
+	_, err := recorder.Record(ctx, &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "SessionEnd",
+		Reason:         "exit",
+	}, ingest.Options{})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	servers, err := repos.Stats.GetMCPServers(ctx, "1970-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("GetMCPServers failed: %v", err)
+	}
+	var stats *domain.MCPServerStats
+	for i := range servers {
+		if servers[i].Server == server {
+			stats = &servers[i]
+		}
+	}
+	if stats == nil {
+		t.Fatalf("Expected server %q", server)
+	}
+	assertEqual(t, "ToolCount", int64(2), stats.ToolCount)
+	assertEqual(t, "SessionCount", int64(1), stats.SessionCount)
+	assertEqual(t, "TotalInvocations", int64(3), stats.TotalInvocations)
+	assertEqual(t, "TotalErrors", int64(1), stats.TotalErrors)
+	assertEqual(t, "ResultTokens", int64(2+1+1), stats.ResultTokens)
+
+	tools, err := repos.Stats.GetMCPTools(ctx, "1970-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("GetMCPTools failed: %v", err)
+	}
+	calls := make(map[string]int64)
+	for _, tool := range tools {
+		if tool.Server == server {
+			calls[tool.Tool] = tool.TotalInvocations
+		}
+	}
+	assertEqual(t, "search calls", int64(2), calls["search"])
+	assertEqual(t, "fetch calls", int64(1), calls["fetch"])
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/util"
+)
+
+// runStatsSlashCommands prints how often each slash command, skill and hook
+// was used, followed by the commands shared in the current project's
+// .claude/commands that no session ran.
+func runStatsSlashCommands(ctx context.Context, startDate string) error {
+	commands, err := app.StatsRepo.GetSlashCommands(ctx, startDate)
+	if err != nil {
+		return err
+	}
+	skills, err := app.StatsRepo.GetSkills(ctx, startDate)
+	if err != nil {
+		return err
+	}
+	hooks, err := app.StatsRepo.GetHooks(ctx, startDate)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("  Slash Commands")
+	fmt.Println("  --------------")
+	printSlashCommands(commands)
+
+	fmt.Println("  Skills")
+	fmt.Println("  ------")
+	printSkills(skills)
+
+	fmt.Println("  Hooks")
+	fmt.Println("  -----")
+	printHooks(hooks)
+
+	if cwd, err := os.Getwd(); err == nil {
+		if unused := unusedSlashCommands(projectSlashCommands(cwd), commands); len(unused) > 0 {
+			fmt.Println("  Unused Project Commands")
+			fmt.Println("  -----------------------")
+			for _, name := range unused {
+				fmt.Printf("  %s\n", name)
+			}
+			fmt.Println()
+		}
+	}
+	return nil
+}
+
+func printSlashCommands(commands []domain.SlashCommandStats) {
+	if len(commands) == 0 {
+		fmt.Println("  No slash commands used")
+		fmt.Println()
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  COMMAND\tKIND\tSESSIONS\tRUNS\tLAST USED")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%s\t%s\n",
+			c.Command, c.Kind, c.SessionCount, util.FormatNumber(c.TotalInvocations), formatLastUsed(c.LastUsedAt))
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+func printSkills(skills []domain.SkillStats) {
+	if len(skills) == 0 {
+		fmt.Println("  No skills used")
+		fmt.Println()
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  SKILL\tSESSIONS\tRUNS\tLAST USED")
+	for _, s := range skills {
+		fmt.Fprintf(w, "  %s\t%d\t%s\t%s\n",
+			s.Skill, s.SessionCount, util.FormatNumber(s.TotalInvocations), formatLastUsed(s.LastUsedAt))
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+func printHooks(hooks []domain.HookStats) {
+	if len(hooks) == 0 {
+		fmt.Println("  No hooks run")
+		fmt.Println()
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  HOOK\tCOMMAND\tSESSIONS\tRUNS\tERRORS\tBLOCKED")
+	for _, h := range hooks {
+		command := h.Command
+		if command == "" {
+			command = "-"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%s\t%d\t%d\n",
+			h.HookName, truncate(command, 40), h.SessionCount, util.FormatNumber(h.TotalExecutions),
+			h.TotalErrors, h.TotalBlocked)
+	}
+	w.Flush()
+	fmt.Println()
+}
+
+func formatLastUsed(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+// projectSlashCommands lists the commands defined in dir's .claude/commands.
+// Commands in subdirectories are still named after their file.
+func projectSlashCommands(dir string) []string {
+	var names []string
+	root := filepath.Join(dir, ".claude", "commands")
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && filepath.Ext(path) == ".md" {
+			names = append(names, "/"+strings.TrimSuffix(d.Name(), ".md"))
+		}
+		return nil
+	})
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// unusedSlashCommands returns the defined commands that weren't used.
+func unusedSlashCommands(defined []string, used []domain.SlashCommandStats) []string {
+	ran := make(map[string]bool, len(used))
+	for _, c := range used {
+		ran[c.Command] = true
+	}
+	var unused []string
+	for _, name := range defined {
+		if !ran[name] {
+			unused = append(unused, name)
+		}
+	}
+	return unused
+}
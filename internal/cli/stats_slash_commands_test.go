@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
+)
+
+func TestSlashCommandStats(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	repos := turso.NewRepositories(db)
+	recorder := newRecorder(repos, nil)
+
+	sessionID := "test-slash-" + randomID()
+	defer repos.Sessions.Delete(ctx, sessionID)
+
+	// A command and a hook unique to this test, each run twice
+	command := "/cmd-" + randomID()
+	hook := "PostToolUse:Edit"
+	hookCommand := "~/.claude/hooks/" + randomID() + ".sh"
+	path := filepath.Join(t.TempDir(), sessionID+".jsonl")
+	writeFile(t, path, `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"<command-name>`+command+`</command-name>"}}
+{"type":"system","timestamp":"2025-01-17T10:00:01Z","content":"`+hook+` [`+hookCommand+`] completed successfully","level":"info"}
+{"type":"user","timestamp":"2025-01-17T10:01:00Z","message":{"role":"user","content":"<command-name>`+command+`</command-name>"}}
+{"type":"system","timestamp":"2025-01-17T10:01:01Z","content":"`+hook+` [`+hookCommand+`] failed with non-blocking status code 1: oops","level":"warning"}
+`)
+
+	_, err := recorder.Record(ctx, &domain.HookInput{
+		SessionID:      sessionID,
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "SessionEnd",
+		Reason:         "exit",
+	}, ingest.Options{})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	commands, err := repos.Stats.GetSlashCommands(ctx, "1970-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("GetSlashCommands failed: %v", err)
+	}
+	var cmdStats *domain.SlashCommandStats
+	for i := range commands {
+		if commands[i].Command == command {
+			cmdStats = &commands[i]
+		}
+	}
+	if cmdStats == nil {
+		t.Fatalf("Expected command %q", command)
+	}
+	assertEqual(t, "Kind", domain.SlashCommandCustom, cmdStats.Kind)
+	assertEqual(t, "SessionCount", int64(1), cmdStats.SessionCount)
+	assertEqual(t, "TotalInvocations", int64(2), cmdStats.TotalInvocations)
+	if cmdStats.LastUsedAt.IsZero() {
+		t.Error("Expected LastUsedAt to be set")
+	}
+
+	hooks, err := repos.Stats.GetHooks(ctx, "1970-01-01T00:00:00Z")
+	if err != nil {
+		t.Fatalf("GetHooks failed: %v", err)
+	}
+	var hookStats *domain.HookStats
+	for i := range hooks {
+		if hooks[i].Command == hookCommand {
+			hookStats = &hooks[i]
+		}
+	}
+	if hookStats == nil {
+		t.Fatalf("Expected hook %q", hookCommand)
+	}
+	assertEqual(t, "HookName", hook, hookStats.HookName)
+	assertEqual(t, "Event", "PostToolUse", hookStats.Event)
+	assertEqual(t, "TotalExecutions", int64(2), hookStats.TotalExecutions)
+	assertEqual(t, "TotalErrors", int64(1), hookStats.TotalErrors)
+}
+
+func TestUnusedSlashCommands(t *testing.T) {
+	dir := t.TempDir()
+	commands := filepath.Join(dir, ".claude", "commands")
+	if err := os.MkdirAll(filepath.Join(commands, "frontend"), 0755); err != nil {
+		t.Fatalf("Failed to create commands dir: %v", err)
+	}
+	writeFile(t, filepath.Join(commands, "review.md"), "Review the diff")
+	writeFile(t, filepath.Join(commands, "frontend", "component.md"), "Add a component")
+	writeFile(t, filepath.Join(commands, "notes.txt"), "not a command")
+
+	defined := projectSlashCommands(dir)
+	assertEqual(t, "defined", 2, len(defined))
+
+	unused := unusedSlashCommands(defined, []domain.SlashCommandStats{{Command: "/review"}})
+	if len(unused) != 1 || unused[0] != "/component" {
+		t.Errorf("Expected [/component] unused, got %v", unused)
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// GitContext is the state of the git repository a session ran in, read when
+// the session is recorded.
+type GitContext struct {
+	// Branch and Dirty are unknown (empty and nil) once HEAD has moved on
+	// since the session ended. Branch is also empty on a detached HEAD.
+	Branch string
+	// HeadBefore is the commit HEAD pointed to before the session's first
+	// commit, HeadAfter the one it pointed to when the session ended. They're
+	// equal when the session made no commits.
+	HeadBefore string
+	HeadAfter  string
+	Dirty      *bool // Uncommitted or untracked changes
+	Commits    []*SessionCommit
+}
+
+// SessionCommit is a commit made on HEAD while a session ran.
+type SessionCommit struct {
+	ID           int64
+	SessionID    string
+	SHA          string
+	Subject      string
+	Author       string
+	CommittedAt  time.Time
+	FilesChanged int64
+	Insertions   int64
+	Deletions    int64
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// LiveHookEvents are the hook events that report a session still running.
+// They update the session's live usage, which SessionEnd replaces with the
+// full recording.
+var LiveHookEvents = map[string]bool{
+	"Stop":             true,
+	"PostToolUse":      true,
+	"SubagentStop":     true,
+	"UserPromptSubmit": true,
+}
+
+// LiveSessionExpiry is how long live usage is kept without any hook updating
+// it, e.g. for sessions whose SessionEnd hook never fired. It's the length of
+// the weekly usage window, so expired usage no longer counts in any window.
+const LiveSessionExpiry = 7 * 24 * time.Hour
+
+// LiveSession is the running usage of a session that hasn't ended yet,
+// read incrementally from its transcript.
+type LiveSession struct {
+	SessionID      string
+	TranscriptPath string
+	Cwd            string
+	LastEvent      string
+	// ByteOffset is how much of the transcript the usage covers, and
+	// TranscriptLines how many lines that is.
+	ByteOffset      int64
+	TranscriptLines int64
+	ModelID         *string
+	TokenInput      int64
+	TokenOutput     int64
+	TokenCacheRead  int64
+	TokenCacheWrite int64
+	CostEstimateUSD *float64
+	StartedAt       *time.Time
+	UpdatedAt       time.Time
+}
+
+// TotalTokens returns all tokens used so far, cache included.
+func (l *LiveSession) TotalTokens() int64 {
+	return l.TokenInput + l.TokenOutput + l.TokenCacheRead + l.TokenCacheWrite
+}
+
+// TranscriptState is the saved state of a transcript parser, so a growing
+// transcript can be parsed from where the last read stopped. State is opaque
+// outside the parser.
+type TranscriptState struct {
+	SessionID      string
+	TranscriptPath string
+	ByteOffset     int64
+	State          []byte
+	UpdatedAt      time.Time
+}
@@ -11,21 +11,96 @@ type ModelPricing struct {
 	CacheWritePerMillion        *float64
 	LongContextInputPerMillion  *float64 // Premium pricing for >threshold input tokens
 	LongContextOutputPerMillion *float64
-	LongContextThreshold        *int64 // Input token threshold (default 200K)
+	LongContextThreshold        *int64   // Input token threshold (default 200K)
+	WebSearchPerThousand        *float64 // USD per 1,000 web searches, nil for DefaultWebSearchPerThousand
+	WebFetchPerThousand         *float64 // USD per 1,000 web fetches, nil when free
 	IsDefault                   bool
 	CreatedAt                   time.Time
+	EffectiveFrom               time.Time  // Start of the period these rates apply to
+	EffectiveTo                 *time.Time // End of the period, nil for the current rates
 }
 
+// ModelAlias maps a short model name, as reported by sub-agents or typed by
+// users, to the model ID its pricing is stored under.
+type ModelAlias struct {
+	Alias     string // e.g., "haiku"
+	ModelID   string
+	CreatedAt time.Time
+}
+
+// PricingChanges is a set of pricing writes applied together, such as those
+// of a pricing file import.
+type PricingChanges struct {
+	Added       []*ModelPricing // Models without pricing yet
+	Updated     []*ModelPricing // Current versions changed in place
+	NewVersions []*ModelPricing // Versions taking over from the current ones
+	DefaultID   string          // Model to make the default, "" to keep it
+	Aliases     []*ModelAlias
+}
+
+// DefaultWebSearchPerThousand is what web search costs on every model, for
+// pricing that doesn't set its own rate.
+const DefaultWebSearchPerThousand = 10.0
+
+// PricingEpoch is the effective start of a model's first pricing, so the
+// first rates configured for a model also apply to sessions recorded earlier.
+var PricingEpoch = time.Unix(0, 0).UTC()
+
+// CalculateCost prices the usage of a single API request. The long-context
+// premium applies when the request's input (including cache reads and writes)
+// exceeds LongContextThreshold, so callers must not pass session totals here.
 func (p *ModelPricing) CalculateCost(input, output, cacheRead, cacheWrite int64) float64 {
 	// Calculate total input tokens (including cache operations) for threshold check
 	totalInputTokens := input + cacheRead + cacheWrite
+	return p.calculateCost(input, output, cacheRead, cacheWrite, p.isLongContext(totalInputTokens))
+}
 
-	// Determine if long context pricing applies
-	useLongContext := p.LongContextThreshold != nil &&
+// isLongContext reports whether a request with the given input, cache
+// included, is priced at long-context rates.
+func (p *ModelPricing) isLongContext(totalInputTokens int64) bool {
+	return p.LongContextThreshold != nil &&
 		p.LongContextInputPerMillion != nil &&
 		p.LongContextOutputPerMillion != nil &&
 		totalInputTokens > *p.LongContextThreshold
+}
 
+// CalculateStandardCost prices usage at the standard rates, never applying the
+// long-context premium. It is meant for usage summed over several requests
+// (such as sub-agent totals), where the per-request threshold can't be checked.
+func (p *ModelPricing) CalculateStandardCost(input, output, cacheRead, cacheWrite int64) float64 {
+	return p.calculateCost(input, output, cacheRead, cacheWrite, false)
+}
+
+// CalculateRequestCost prices a recorded request, using standard rates for
+// aggregated usage and per-request long-context pricing otherwise, plus the
+// fees of the server tools it used. Grouped requests pay the long-context
+// premium if their largest one does.
+func (p *ModelPricing) CalculateRequestCost(r *RequestUsage) float64 {
+	cost := p.CalculateServerToolCost(r.WebSearchRequests, r.WebFetchRequests)
+	if r.Aggregate {
+		return cost + p.CalculateStandardCost(r.TokenInput, r.TokenOutput, r.TokenCacheRead, r.TokenCacheWrite)
+	}
+	if r.Requests > 1 {
+		return cost + p.calculateCost(r.TokenInput, r.TokenOutput, r.TokenCacheRead, r.TokenCacheWrite, p.isLongContext(r.LargestInput))
+	}
+	return cost + p.CalculateCost(r.TokenInput, r.TokenOutput, r.TokenCacheRead, r.TokenCacheWrite)
+}
+
+// CalculateServerToolCost prices web search and web fetch requests. Their
+// tokens, such as search results added to the context, are priced as input.
+func (p *ModelPricing) CalculateServerToolCost(webSearches, webFetches int64) float64 {
+	searchRate := DefaultWebSearchPerThousand
+	if p.WebSearchPerThousand != nil {
+		searchRate = *p.WebSearchPerThousand
+	}
+	cost := float64(webSearches) * searchRate / 1000
+	if p.WebFetchPerThousand != nil {
+		cost += float64(webFetches) * *p.WebFetchPerThousand / 1000
+	}
+	return cost
+}
+
+func (p *ModelPricing) calculateCost(input, output, cacheRead, cacheWrite int64, useLongContext bool) float64 {
 	var inputRate, outputRate float64
 	if useLongContext {
 		inputRate = *p.LongContextInputPerMillion
@@ -59,3 +134,50 @@ func (p *ModelPricing) CalculateCost(input, output, cacheRead, cacheWrite int64)
 
 	return cost
 }
+
+// CostRecomputeFilter selects the sessions to re-price. Nil fields match
+// every session; Since and Until bound the session creation time (RFC3339).
+type CostRecomputeFilter struct {
+	Since        string
+	Until        string
+	ProjectID    *string
+	ExperimentID *string
+	ModelID      *string
+}
+
+// SessionCostTarget is a session's stored usage and cost, as loaded for
+// re-pricing.
+type SessionCostTarget struct {
+	SessionID string
+	StartedAt *time.Time
+	Metrics   SessionMetrics
+}
+
+// SessionCostUpdate holds the before/after cost of a re-priced session and
+// the child rows whose costs change with it.
+type SessionCostUpdate struct {
+	SessionID string
+	Source    string // "transcript" when re-parsed from the archive, "stored" otherwise
+	OldCost   *float64
+	NewCost   *float64
+	// ModelUsage replaces the session's per-model usage, since re-parsing may
+	// attribute it to other models.
+	ModelUsage []*SessionModelUsage
+	Subagents  []SubagentCostUpdate
+	// Turns holds the re-priced turns when the transcript was re-parsed.
+	Turns []*SessionTurn
+}
+
+type SubagentCostUpdate struct {
+	ID      int64
+	OldCost *float64
+	NewCost *float64
+}
+
+// CostRecomputeResult summarizes a recompute run.
+type CostRecomputeResult struct {
+	Updates  []*SessionCostUpdate
+	OldTotal float64
+	NewTotal float64
+	DryRun   bool
+}
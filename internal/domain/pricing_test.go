@@ -179,6 +179,122 @@ func TestModelPricing_CalculateCost_Opus46(t *testing.T) {
 	}
 }
 
+func TestModelPricing_CalculateRequestCost_PerRequestThreshold(t *testing.T) {
+	longInput := 6.00
+	longOutput := 22.50
+	threshold := int64(200000)
+
+	pricing := &ModelPricing{
+		ID:                          "claude-sonnet-4-20250514",
+		InputPerMillion:             3.00,
+		OutputPerMillion:            15.00,
+		LongContextInputPerMillion:  &longInput,
+		LongContextOutputPerMillion: &longOutput,
+		LongContextThreshold:        &threshold,
+	}
+
+	// Three 150K requests add up to 450K, but none crosses the threshold on
+	// its own, so all are billed at standard rates
+	// 3 * (150000 * $3/MTok + 1000 * $15/MTok) = 3 * $0.465 = $1.395
+	var cost float64
+	for range 3 {
+		cost += pricing.CalculateRequestCost(&RequestUsage{TokenInput: 150000, TokenOutput: 1000})
+	}
+	if !floatEquals(cost, 1.395) {
+		t.Errorf("Expected cost %.6f, got %.6f", 1.395, cost)
+	}
+
+	// A single 250K request pays the premium
+	// 250000 * $6/MTok + 1000 * $22.50/MTok = $1.5225
+	cost = pricing.CalculateRequestCost(&RequestUsage{TokenInput: 250000, TokenOutput: 1000})
+	if !floatEquals(cost, 1.5225) {
+		t.Errorf("Expected cost %.6f, got %.6f", 1.5225, cost)
+	}
+}
+
+func TestModelPricing_CalculateRequestCost_AggregateUsesStandardRates(t *testing.T) {
+	longInput := 6.00
+	longOutput := 22.50
+	threshold := int64(200000)
+
+	pricing := &ModelPricing{
+		ID:                          "claude-sonnet-4-20250514",
+		InputPerMillion:             3.00,
+		OutputPerMillion:            15.00,
+		LongContextInputPerMillion:  &longInput,
+		LongContextOutputPerMillion: &longOutput,
+		LongContextThreshold:        &threshold,
+	}
+
+	// Sub-agent totals over the threshold stay at standard rates
+	// 250000 * $3/MTok + 1000 * $15/MTok = $0.765
+	cost := pricing.CalculateRequestCost(&RequestUsage{TokenInput: 250000, TokenOutput: 1000, Aggregate: true})
+	if !floatEquals(cost, 0.765) {
+		t.Errorf("Expected cost %.6f, got %.6f", 0.765, cost)
+	}
+}
+
+func TestModelPricing_CalculateRequestCost_GroupedByLargestRequest(t *testing.T) {
+	longInput := 6.00
+	longOutput := 22.50
+	threshold := int64(200000)
+
+	pricing := &ModelPricing{
+		ID:                          "claude-sonnet-4-20250514",
+		InputPerMillion:             3.00,
+		OutputPerMillion:            15.00,
+		LongContextInputPerMillion:  &longInput,
+		LongContextOutputPerMillion: &longOutput,
+		LongContextThreshold:        &threshold,
+	}
+
+	// Two 150K requests total more than the threshold but neither exceeds it
+	// 300000 * $3/MTok + 2000 * $15/MTok = $0.93
+	cost := pricing.CalculateRequestCost(&RequestUsage{TokenInput: 300000, TokenOutput: 2000, Requests: 2, LargestInput: 150000})
+	if !floatEquals(cost, 0.93) {
+		t.Errorf("Expected cost %.6f, got %.6f", 0.93, cost)
+	}
+
+	// Two 250K requests pay the premium
+	// 500000 * $6/MTok + 2000 * $22.50/MTok = $3.045
+	cost = pricing.CalculateRequestCost(&RequestUsage{TokenInput: 500000, TokenOutput: 2000, Requests: 2, LargestInput: 250000})
+	if !floatEquals(cost, 3.045) {
+		t.Errorf("Expected cost %.6f, got %.6f", 3.045, cost)
+	}
+}
+
 func floatEquals(a, b float64) bool {
 	return math.Abs(a-b) < 0.000001
 }
+
+func TestModelPricing_CalculateRequestCost_ServerTools(t *testing.T) {
+	fetch := 1.0
+	pricing := &ModelPricing{
+		InputPerMillion:     3.00,
+		OutputPerMillion:    15.00,
+		WebFetchPerThousand: &fetch,
+	}
+
+	// 1000 input, 500 output = $0.0105, plus 3 searches at the default
+	// $10/1000 = $0.03 and 2 fetches at $1/1000 = $0.002
+	cost := pricing.CalculateRequestCost(&RequestUsage{
+		TokenInput:        1000,
+		TokenOutput:       500,
+		WebSearchRequests: 3,
+		WebFetchRequests:  2,
+	})
+	expected := 0.0105 + 0.03 + 0.002
+
+	if !floatEquals(cost, expected) {
+		t.Errorf("Expected cost %.6f, got %.6f", expected, cost)
+	}
+}
+
+func TestModelPricing_CalculateServerToolCost_CustomRate(t *testing.T) {
+	free := 0.0
+	pricing := &ModelPricing{WebSearchPerThousand: &free}
+
+	if cost := pricing.CalculateServerToolCost(5, 5); cost != 0 {
+		t.Errorf("Expected no cost with free search and no fetch rate, got %.6f", cost)
+	}
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// RecordJobMaxAttempts is how many times a queued recording is tried before
+// it's left for 'mclaude queue retry'.
+const RecordJobMaxAttempts = 5
+
+// RecordJob is a hook input spooled on disk until its session is recorded.
+type RecordJob struct {
+	Input         HookInput `json:"input"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	EnqueuedAt    time.Time `json:"enqueued_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// Failed reports whether the job has used up its automatic retries.
+func (j *RecordJob) Failed() bool {
+	return j.Attempts >= RecordJobMaxAttempts
+}
+
+// Due reports whether the job should be tried again at the given time.
+func (j *RecordJob) Due(now time.Time) bool {
+	return !j.Failed() && !now.Before(j.NextAttemptAt)
+}
+
+// Status describes the job as "pending", "retrying" or "failed".
+func (j *RecordJob) Status() string {
+	switch {
+	case j.Failed():
+		return "failed"
+	case j.Attempts > 0:
+		return "retrying"
+	default:
+		return "pending"
+	}
+}
@@ -15,6 +15,11 @@ type Session struct {
 	EndedAt              *time.Time
 	DurationSeconds      *int64
 	CreatedAt            time.Time
+	// Git state of Cwd when the session was recorded; nil outside a repository
+	GitBranch     *string // nil on a detached HEAD
+	GitHeadBefore *string
+	GitHeadAfter  *string
+	GitDirty      *bool
 }
 
 type SessionMetrics struct {
@@ -29,6 +34,31 @@ type SessionMetrics struct {
 	TokenCacheWrite       int64
 	CostEstimateUSD       *float64
 	ErrorCount            int64
+	LinesAdded            int64 // By edits and writes
+	LinesRemoved          int64
+	CompactionCount       int64 // Times the context was compacted
+	// TokenCacheWasted estimates the cache-write tokens that no later request
+	// read, e.g. because the cache expired or the context was compacted.
+	TokenCacheWasted int64
+	// ThinkingTokens estimates the output tokens spent in ThinkingBlocks,
+	// from their text; the API counts them as output without telling them
+	// apart.
+	ThinkingBlocks    int64
+	ThinkingTokens    int64
+	WebSearchRequests int64 // Server-side web searches
+	WebFetchRequests  int64
+	// Interruptions counts prompts the user stopped with Esc. ToolRejections
+	// counts tool calls the user rejected in a permission prompt, and
+	// PermissionDenials those refused by permission settings; both are also
+	// counted as errors.
+	Interruptions     int64
+	ToolRejections    int64
+	PermissionDenials int64
+	// TranscriptLines counts the lines of the session transcript read, and
+	// ParserVersion is the parser.StateVersion that read them; 0 for sessions
+	// recorded before parse anomalies were kept.
+	TranscriptLines int64
+	ParserVersion   int64
 }
 
 type SessionTool struct {
@@ -38,31 +68,110 @@ type SessionTool struct {
 	InvocationCount int64
 	TotalDurationMs *int64
 	ErrorCount      int64
+	// MCPServer and MCPTool split mcp__<server>__<tool> names; both are
+	// empty for built-in tools.
+	MCPServer string
+	MCPTool   string
+	// ResultTokens estimates the tokens the tool's results added to the
+	// context.
+	ResultTokens int64
+	// Calls rejected by the user or denied by permission settings, included
+	// in ErrorCount.
+	RejectionCount        int64
+	PermissionDenialCount int64
 }
 
 type SessionFile struct {
 	ID             int64
 	SessionID      string
 	FilePath       string
-	Operation      string // "read", "write", "edit"
+	Operation      string // "read", "write", "edit", "search" or "delete"
 	OperationCount int64
+	LinesAdded     int64
+	LinesRemoved   int64
+	Language       string // From the file name, empty if unknown
 }
 
+// Command status constants, from the Bash tool result
+const (
+	CommandSucceeded   = "succeeded"
+	CommandFailed      = "failed"
+	CommandInterrupted = "interrupted"
+)
+
 type SessionCommand struct {
 	ID         int64
 	SessionID  string
 	Command    string
 	ExitCode   *int
 	ExecutedAt *time.Time
+	ToolUseID  string
+	Status     string // Empty until the command's result is read
+	DurationMs *int64
+}
+
+// Slash command kinds
+const (
+	SlashCommandBuiltin = "builtin"
+	SlashCommandCustom  = "custom" // From .claude/commands or a plugin
+	SlashCommandMCP     = "mcp"    // A prompt of an MCP server
+)
+
+// SessionSlashCommand counts the runs of one slash command in a session.
+type SessionSlashCommand struct {
+	ID              int64
+	SessionID       string
+	Command         string // With its leading slash, e.g. "/compact"
+	Kind            string
+	InvocationCount int64
+}
+
+// SessionHook counts the runs of one hook in a session. Hooks are told apart
+// by event and matcher, and by command when the transcript names it.
+type SessionHook struct {
+	ID             int64
+	SessionID      string
+	Event          string // e.g. "PreToolUse", "Stop"
+	HookName       string // Event and matcher, e.g. "PreToolUse:Bash"
+	Command        string
+	ExecutionCount int64
+	ErrorCount     int64 // Runs that failed or exited with a non-blocking error
+	BlockedCount   int64 // Runs that blocked the action or stopped Claude
+}
+
+// Parse anomaly kinds
+const (
+	AnomalyMalformedLine      = "malformed_line"       // Detail is the decoding error
+	AnomalyUnknownEntryType   = "unknown_entry_type"   // Detail is the type
+	AnomalyUnknownContentType = "unknown_content_type" // Detail is the block type
+	AnomalyMissingUsage       = "missing_usage"        // Detail is the model
+)
+
+// SessionParseAnomaly counts the transcript lines of a session the parser
+// skipped or only partly understood, e.g. after a change to Claude Code's
+// transcript format.
+type SessionParseAnomaly struct {
+	ID              int64
+	SessionID       string
+	Kind            string
+	Detail          string
+	OccurrenceCount int64
+	FirstLine       int64 // Line of the first occurrence, counting from 1
 }
 
 type SessionSubagent struct {
 	ID              int64
 	SessionID       string
-	AgentType       string   // subagent_type for Task (e.g. "Explore", "Bash"), skill name for Skill (e.g. "commit")
-	AgentKind       string   // "task" or "skill"
-	Description     *string  // short description from Task input
-	Model           *string  // model alias (e.g. "haiku", "sonnet") or nil
+	ToolUseID       string  // Task or Skill call that started the agent
+	ParentToolUseID *string // Call that started the parent agent, nil for the session's own
+	AgentID         *string // Claude Code's ID for the agent, naming its transcript
+	AgentType       string  // subagent_type for Task (e.g. "Explore", "Bash"), skill name for Skill (e.g. "commit")
+	AgentKind       string  // "task" or "skill"
+	Description     *string // short description from Task input
+	Model           *string // model alias (e.g. "haiku", "sonnet") or nil
+	// ModelID is the model the agent's usage is counted under: Model, or the
+	// model the session was on when it ran. nil for older recordings.
+	ModelID         *string
 	TotalTokens     int64
 	TokenInput      int64
 	TokenOutput     int64
@@ -70,5 +179,87 @@ type SessionSubagent struct {
 	TokenCacheWrite int64
 	TotalDurationMs *int64
 	ToolUseCount    int64
+	ErrorCount      int64 // Failed tool calls, from the agent's transcript
+	CostEstimateUSD *float64
+	// Tools and Files are read from the agent's own transcript, if found.
+	Tools []*SessionTool
+	Files []*SessionFile
+}
+
+// SessionModelUsage holds the token usage and cost attributed to a single
+// model within a session. Sessions that switch models (e.g. via /model) or
+// delegate to sub-agents on a different model have one row per model.
+type SessionModelUsage struct {
+	ID              int64
+	SessionID       string
+	ModelID         string
+	MessageCount    int64
+	TokenInput      int64
+	TokenOutput     int64
+	TokenCacheRead  int64
+	TokenCacheWrite int64
 	CostEstimateUSD *float64
 }
+
+// SessionTurn is one user prompt and the assistant work that answered it,
+// up to the next prompt. Turns are numbered from 1 in transcript order.
+type SessionTurn struct {
+	ID              int64
+	SessionID       string
+	TurnIndex       int64
+	Prompt          string // Start of the prompt's text, empty for work before the first prompt
+	StartedAt       *time.Time
+	EndedAt         *time.Time
+	DurationMs      *int64
+	MessageCount    int64 // Assistant messages
+	ToolCalls       int64
+	TokenInput      int64
+	TokenOutput     int64
+	TokenCacheRead  int64
+	TokenCacheWrite int64
+	CostEstimateUSD *float64
+}
+
+// RequestUsage is the token usage reported for a single API request. Pricing
+// is applied per request because the long-context premium depends on the
+// size of each request, not on the session total.
+type RequestUsage struct {
+	ModelID         string
+	Timestamp       *time.Time
+	TokenInput      int64
+	TokenOutput     int64
+	TokenCacheRead  int64
+	TokenCacheWrite int64
+	// Aggregate marks usage summed over several requests, such as the totals
+	// a sub-agent reports on completion.
+	Aggregate bool
+	// Requests is how many requests of known size the usage sums, once
+	// grouped, and LargestInput the most input (cache included) any of them
+	// sent, which decides whether they pay the long-context premium.
+	Requests     int64
+	LargestInput int64
+	// Turn is the index of the turn the request was made in, 0 if none.
+	Turn int64
+	// Server tool requests the API ran while answering, billed per request.
+	WebSearchRequests int64
+	WebFetchRequests  int64
+}
+
+// SessionRecording is a session together with everything parsed from its
+// transcript. It is saved as a unit: recording a session again replaces all
+// of its rows.
+type SessionRecording struct {
+	Session    *Session
+	Metrics    *SessionMetrics
+	Tools      []*SessionTool
+	Files      []*SessionFile
+	Commands   []*SessionCommand
+	ModelUsage []*SessionModelUsage
+	Subagents  []*SessionSubagent
+	Commits    []*SessionCommit
+	Turns      []*SessionTurn
+	// SlashCommands and Hooks are in order of first use.
+	SlashCommands []*SessionSlashCommand
+	Hooks         []*SessionHook
+	Anomalies     []*SessionParseAnomaly
+}
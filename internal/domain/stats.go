@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // AggregateStats holds summary statistics across sessions.
 type AggregateStats struct {
 	SessionCount           int64
@@ -12,6 +14,28 @@ type AggregateStats struct {
 	TotalTokenCacheWrite   int64
 	TotalCostUsd           float64
 	TotalErrors            int64
+	TotalLinesAdded        int64
+	TotalLinesRemoved      int64
+	TotalCompactions       int64
+	TotalTokenCacheWasted  int64
+}
+
+// CacheHitRatio is the share of input tokens read from the prompt cache, out
+// of all input tokens including those written to it.
+func CacheHitRatio(input, cacheRead, cacheWrite int64) float64 {
+	total := input + cacheRead + cacheWrite
+	if total == 0 {
+		return 0
+	}
+	return float64(cacheRead) / float64(total)
+}
+
+// CacheWasteRatio is the share of cache-write tokens that were never read.
+func CacheWasteRatio(cacheWrite, cacheWasted int64) float64 {
+	if cacheWrite == 0 {
+		return 0
+	}
+	return float64(cacheWasted) / float64(cacheWrite)
 }
 
 // ToolUsageStats holds usage data for a single tool.
@@ -19,6 +43,119 @@ type ToolUsageStats struct {
 	ToolName         string
 	TotalInvocations int64
 	TotalErrors      int64
+	// AvgDurationMs is the mean time between a call and its result, nil if
+	// no call was timed.
+	AvgDurationMs *float64
+}
+
+// LanguageStats holds the lines changed in files of one language.
+type LanguageStats struct {
+	Language     string
+	FileCount    int64
+	LinesAdded   int64
+	LinesRemoved int64
+}
+
+// MCPServerStats holds usage aggregated over the tools of one MCP server.
+type MCPServerStats struct {
+	Server           string
+	ToolCount        int64
+	SessionCount     int64
+	TotalInvocations int64
+	TotalErrors      int64
+	ResultTokens     int64 // Estimated tokens the server's results added to context
+}
+
+// MCPToolStats holds usage for a single tool of an MCP server.
+type MCPToolStats struct {
+	Server           string
+	Tool             string
+	TotalInvocations int64
+	TotalErrors      int64
+	ResultTokens     int64
+}
+
+// SlashCommandStats holds how often a slash command was run and in how many
+// sessions.
+type SlashCommandStats struct {
+	Command          string
+	Kind             string // SlashCommandBuiltin, SlashCommandCustom or SlashCommandMCP
+	SessionCount     int64
+	TotalInvocations int64
+	LastUsedAt       time.Time // Start of the last session that ran it
+}
+
+// SkillStats holds how often a skill was invoked through the Skill tool.
+type SkillStats struct {
+	Skill            string
+	SessionCount     int64
+	TotalInvocations int64
+	LastUsedAt       time.Time
+}
+
+// HookStats holds the runs of one hook across sessions.
+type HookStats struct {
+	Event           string
+	HookName        string
+	Command         string // Empty when transcripts don't name it
+	SessionCount    int64
+	TotalExecutions int64
+	TotalErrors     int64
+	TotalBlocked    int64
+}
+
+// ParseDiagnostics reports how well recorded transcripts were understood.
+type ParseDiagnostics struct {
+	SessionCount   int64
+	UncheckedCount int64 // Sessions recorded before parse anomalies were kept
+	Anomalies      []ParseAnomalyStats
+	Sessions       []AnomalousSession
+}
+
+// ParseAnomalyStats holds one kind of parse anomaly across sessions. A type
+// first seen recently usually means Claude Code changed its format.
+type ParseAnomalyStats struct {
+	Kind             string
+	Detail           string
+	SessionCount     int64
+	TotalOccurrences int64
+	FirstSeenAt      time.Time
+	LastSeenAt       time.Time
+}
+
+// AnomalousSession is a session with parse anomalies, or whose assistant
+// messages carried no tokens at all.
+type AnomalousSession struct {
+	SessionID         string
+	TranscriptPath    string
+	RecordedAt        time.Time
+	TranscriptLines   int64
+	AssistantMessages int64
+	TotalTokens       int64
+	MalformedLines    int64
+	UnknownTypes      int64 // Unknown entry and content block types
+	MissingUsage      int64
+}
+
+// CommandStats holds run counts and timings for a single Bash command.
+type CommandStats struct {
+	Command       string
+	RunCount      int64
+	FailureCount  int64
+	AvgDurationMs *float64 // nil if no run was timed
+	MaxDurationMs *float64
+}
+
+// ModelUsageStats holds token usage and cost aggregated per model.
+type ModelUsageStats struct {
+	ModelID              string
+	SessionCount         int64
+	TotalMessages        int64
+	TotalTokenInput      int64
+	TotalTokenOutput     int64
+	TotalTokenCacheRead  int64
+	TotalTokenCacheWrite int64
+	TotalCostUsd         float64
 }
 
 // ExperimentStats holds aggregate stats for a specific experiment.
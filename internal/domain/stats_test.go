@@ -0,0 +1,22 @@
+package domain
+
+import "testing"
+
+func TestCacheHitRatio(t *testing.T) {
+	if r := CacheHitRatio(0, 0, 0); r != 0 {
+		t.Errorf("Expected 0 without tokens, got %f", r)
+	}
+	// 600 of 1000 input tokens read from the cache
+	if r := CacheHitRatio(100, 600, 300); !floatEquals(r, 0.6) {
+		t.Errorf("Expected 0.6, got %f", r)
+	}
+}
+
+func TestCacheWasteRatio(t *testing.T) {
+	if r := CacheWasteRatio(0, 0); r != 0 {
+		t.Errorf("Expected 0 without cache writes, got %f", r)
+	}
+	if r := CacheWasteRatio(400, 100); !floatEquals(r, 0.25) {
+		t.Errorf("Expected 0.25, got %f", r)
+	}
+}
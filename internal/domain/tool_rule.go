@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// ToolRule maps a tool to the file operation its calls perform and the
+// input fields that name the files, e.g. an MCP tool writing to "path".
+type ToolRule struct {
+	Tool      string // Tool name, or a prefix ending in "*" (e.g. "mcp__notes__*")
+	Operation string // e.g., "read", "write", "edit", "search", "delete"
+	Fields    []string
+	CreatedAt time.Time
+}
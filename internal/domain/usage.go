@@ -14,6 +14,9 @@ type UsageLimit struct {
 type UsageSummary struct {
 	TotalTokens float64
 	TotalCost   float64
+	// LiveTokens is the part of TotalTokens used by sessions still running.
+	LiveTokens   float64
+	LiveSessions int
 }
 
 type PlanConfig struct {
@@ -0,0 +1,247 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ports"
+)
+
+// The fakes embed the port they implement, so a call the Recorder isn't
+// expected to make panics.
+
+type fakeProjects struct {
+	ports.ProjectRepository
+}
+
+func (f *fakeProjects) GetOrCreate(_ context.Context, path string) (*domain.Project, error) {
+	return &domain.Project{ID: "project-" + path, Path: path, Name: filepath.Base(path)}, nil
+}
+
+type fakeExperiments struct {
+	ports.ExperimentRepository
+	active *domain.Experiment
+}
+
+func (f *fakeExperiments) GetActive(_ context.Context) (*domain.Experiment, error) {
+	return f.active, nil
+}
+
+// fakePricing has no models, so sessions are recorded unpriced.
+type fakePricing struct {
+	ports.PricingRepository
+}
+
+func (f *fakePricing) GetDefault(_ context.Context) (*domain.ModelPricing, error) { return nil, nil }
+func (f *fakePricing) ListAliases(_ context.Context) ([]*domain.ModelAlias, error) {
+	return nil, nil
+}
+func (f *fakePricing) GetByID(_ context.Context, _ string) (*domain.ModelPricing, error) {
+	return nil, nil
+}
+func (f *fakePricing) GetByIDAt(_ context.Context, _ string, _ time.Time) (*domain.ModelPricing, error) {
+	return nil, nil
+}
+
+type fakeRecordings struct {
+	saved []*domain.SessionRecording
+}
+
+func (f *fakeRecordings) Save(_ context.Context, rec *domain.SessionRecording) error {
+	f.saved = append(f.saved, rec)
+	return nil
+}
+
+// fakeLive saves optimistically like the turso repository. interleave, if
+// set, runs between a hook's read and its write, standing in for a hook
+// that fires concurrently.
+type fakeLive struct {
+	sessions   map[string]domain.LiveSession
+	interleave func()
+}
+
+func (f *fakeLive) Get(_ context.Context, sessionID string) (*domain.LiveSession, error) {
+	live, ok := f.sessions[sessionID]
+	if !ok {
+		return nil, nil
+	}
+	return &live, nil
+}
+
+func (f *fakeLive) List(_ context.Context) ([]*domain.LiveSession, error) {
+	var sessions []*domain.LiveSession
+	for _, live := range f.sessions {
+		sessions = append(sessions, &live)
+	}
+	return sessions, nil
+}
+
+func (f *fakeLive) Create(_ context.Context, live *domain.LiveSession) (bool, error) {
+	f.runInterleaved()
+	if _, ok := f.sessions[live.SessionID]; ok {
+		return false, nil
+	}
+	f.sessions[live.SessionID] = *live
+	return true, nil
+}
+
+func (f *fakeLive) Update(_ context.Context, live *domain.LiveSession, fromOffset int64) (bool, error) {
+	f.runInterleaved()
+	stored, ok := f.sessions[live.SessionID]
+	if !ok || stored.ByteOffset != fromOffset {
+		return false, nil
+	}
+	f.sessions[live.SessionID] = *live
+	return true, nil
+}
+
+func (f *fakeLive) Delete(_ context.Context, sessionID string) error {
+	delete(f.sessions, sessionID)
+	return nil
+}
+
+func (f *fakeLive) DeleteStale(_ context.Context, before time.Time) (int64, error) {
+	var n int64
+	for id, live := range f.sessions {
+		if live.UpdatedAt.Before(before) {
+			delete(f.sessions, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeLive) runInterleaved() {
+	if f.interleave != nil {
+		interleave := f.interleave
+		f.interleave = nil
+		interleave()
+	}
+}
+
+type fakeStates struct {
+	states map[string]domain.TranscriptState
+}
+
+func (f *fakeStates) Get(_ context.Context, sessionID string) (*domain.TranscriptState, error) {
+	state, ok := f.states[sessionID]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (f *fakeStates) Save(_ context.Context, state *domain.TranscriptState) error {
+	f.states[state.SessionID] = *state
+	return nil
+}
+
+func (f *fakeStates) Delete(_ context.Context, sessionID string) error {
+	delete(f.states, sessionID)
+	return nil
+}
+
+type fakeToolRules struct {
+	ports.ToolRuleRepository
+}
+
+func (f *fakeToolRules) List(_ context.Context) ([]*domain.ToolRule, error) { return nil, nil }
+
+// fakePlanConfig records the usage window resets it's asked to check.
+type fakePlanConfig struct {
+	ports.PlanConfigRepository
+	resets []string
+}
+
+func (f *fakePlanConfig) ResetWindowIfExpired(_ context.Context, _ time.Time) (bool, error) {
+	f.resets = append(f.resets, "5h")
+	return true, nil
+}
+
+func (f *fakePlanConfig) ResetWeeklyWindowIfExpired(_ context.Context, _ time.Time) (bool, error) {
+	f.resets = append(f.resets, "weekly")
+	return true, nil
+}
+
+type fakeGit struct {
+	calls int
+	until time.Time
+}
+
+func (f *fakeGit) Inspect(_ context.Context, _ string, _, until time.Time) (*domain.GitContext, error) {
+	f.calls++
+	f.until = until
+	dirty := false
+	return &domain.GitContext{Branch: "main", HeadBefore: "abc", HeadAfter: "abc", Dirty: &dirty}, nil
+}
+
+type fakes struct {
+	experiments *fakeExperiments
+	recordings  *fakeRecordings
+	live        *fakeLive
+	states      *fakeStates
+	planConfig  *fakePlanConfig
+	git         *fakeGit
+}
+
+func newTestRecorder() (*Recorder, *fakes) {
+	f := &fakes{
+		experiments: &fakeExperiments{},
+		recordings:  &fakeRecordings{},
+		live:        &fakeLive{sessions: map[string]domain.LiveSession{}},
+		states:      &fakeStates{states: map[string]domain.TranscriptState{}},
+		planConfig:  &fakePlanConfig{},
+		git:         &fakeGit{},
+	}
+	r := NewRecorder(Deps{
+		Projects:    &fakeProjects{},
+		Experiments: f.experiments,
+		Pricing:     &fakePricing{},
+		Recordings:  f.recordings,
+		Live:        f.live,
+		States:      f.states,
+		ToolRules:   &fakeToolRules{},
+		PlanConfig:  f.planConfig,
+		Git:         f.git,
+	})
+	return r, f
+}
+
+// transcriptLine returns an assistant entry with the given usage.
+func transcriptLine(messageID string, at time.Time, input, output int64) string {
+	return fmt.Sprintf(`{"type":"assistant","timestamp":%q,"message":{"id":%q,"role":"assistant","model":"claude-sonnet-4-5-20250929","content":[{"type":"text","text":"Working."}],"usage":{"input_tokens":%d,"output_tokens":%d}}}`+"\n",
+		at.UTC().Format(time.RFC3339), messageID, input, output)
+}
+
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", path, err)
+	}
+	return info.Size()
+}
+
+func assertEqual[T comparable](t *testing.T, name string, expected, actual T) {
+	t.Helper()
+	if expected != actual {
+		t.Errorf("%s: expected %v, got %v", name, expected, actual)
+	}
+}
@@ -0,0 +1,84 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/pricing"
+)
+
+// Track updates the live usage of a running session with what its transcript
+// gained since the last hook, so usage windows include sessions that haven't
+// ended yet. It returns nil if another hook updated the session concurrently,
+// as that hook's update covers the same lines, or if the session was recorded
+// with all the lines read so far, as when a hook arrives after SessionEnd. A
+// recorded session that's resumed gets live usage again, which stands in for
+// the recording until the session is recorded once more.
+func (r *Recorder) Track(ctx context.Context, input *domain.HookInput) (*domain.LiveSession, error) {
+	live, err := r.deps.Live.Get(ctx, input.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	exists := live != nil
+	var fromOffset int64
+	if exists {
+		fromOffset = live.ByteOffset
+	}
+
+	p, err := r.parse(ctx, input, false)
+	if err != nil {
+		return nil, err
+	}
+	parsed := p.Result()
+	pricing.PriceTranscript(pricing.NewPricer(ctx, r.deps.Pricing), parsed)
+
+	live = &domain.LiveSession{
+		SessionID:       input.SessionID,
+		TranscriptPath:  input.TranscriptPath,
+		Cwd:             input.Cwd,
+		LastEvent:       input.HookEventName,
+		ByteOffset:      p.Offset(),
+		TranscriptLines: parsed.Metrics.TranscriptLines,
+		ModelID:         parsed.ModelID,
+		TokenInput:      parsed.Metrics.TokenInput,
+		TokenOutput:     parsed.Metrics.TokenOutput,
+		TokenCacheRead:  parsed.Metrics.TokenCacheRead,
+		TokenCacheWrite: parsed.Metrics.TokenCacheWrite,
+		CostEstimateUSD: parsed.Metrics.CostEstimateUSD,
+		StartedAt:       parsed.StartedAt,
+		UpdatedAt:       time.Now().UTC(),
+	}
+
+	var saved bool
+	if exists {
+		saved, err = r.deps.Live.Update(ctx, live, fromOffset)
+	} else {
+		saved, err = r.deps.Live.Create(ctx, live)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save live session: %w", err)
+	}
+	if !saved {
+		return nil, nil
+	}
+
+	// Only the hook that won the update saves its parser state, so the state
+	// never runs ahead of a concurrent hook's. It's a cache: the next hook
+	// parses from the start without it.
+	_ = r.saveState(ctx, input, p)
+
+	// A new session may start a new usage window. Window resets are best
+	// effort here: SessionEnd checks them again. Live usage of sessions that
+	// stopped without a SessionEnd is dropped once it's too old to count.
+	if !exists {
+		if live.StartedAt != nil {
+			_, _ = r.deps.PlanConfig.ResetWindowIfExpired(ctx, *live.StartedAt)
+			_, _ = r.deps.PlanConfig.ResetWeeklyWindowIfExpired(ctx, *live.StartedAt)
+		}
+		_, _ = r.deps.Live.DeleteStale(ctx, time.Now().Add(-domain.LiveSessionExpiry))
+	}
+
+	return live, nil
+}
@@ -0,0 +1,103 @@
+package ingest
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+func TestTrack(t *testing.T) {
+	ctx := context.Background()
+	r, f := newTestRecorder()
+	startedAt := time.Date(2025, 1, 17, 10, 0, 0, 0, time.UTC)
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	appendFile(t, path, transcriptLine("msg1", startedAt, 1000, 200))
+	input := &domain.HookInput{
+		SessionID:      "session-1",
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "PostToolUse",
+	}
+
+	live, err := r.Track(ctx, input)
+	if err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+	assertEqual(t, "TotalTokens", int64(1200), live.TotalTokens())
+	assertEqual(t, "ByteOffset", fileSize(t, path), live.ByteOffset)
+	assertEqual(t, "state ByteOffset", live.ByteOffset, f.states.states["session-1"].ByteOffset)
+
+	// Only a new session can start a new usage window
+	assertEqual(t, "resets", "5h,weekly", strings.Join(f.planConfig.resets, ","))
+
+	appendFile(t, path, transcriptLine("msg2", startedAt.Add(time.Minute), 500, 100))
+	input.HookEventName = "Stop"
+	live, err = r.Track(ctx, input)
+	if err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+	assertEqual(t, "TotalTokens", int64(1800), live.TotalTokens())
+	assertEqual(t, "LastEvent", "Stop", f.live.sessions["session-1"].LastEvent)
+	assertEqual(t, "state ByteOffset", fileSize(t, path), f.states.states["session-1"].ByteOffset)
+	assertEqual(t, "resets", "5h,weekly", strings.Join(f.planConfig.resets, ","))
+}
+
+func TestTrack_LostUpdate(t *testing.T) {
+	ctx := context.Background()
+	startedAt := time.Date(2025, 1, 17, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		existing bool // The session already had live usage
+	}{
+		{"create", false},
+		{"update", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, f := newTestRecorder()
+			path := filepath.Join(t.TempDir(), "session.jsonl")
+			appendFile(t, path, transcriptLine("msg1", startedAt, 1000, 200))
+			input := &domain.HookInput{
+				SessionID:      "session-1",
+				TranscriptPath: path,
+				Cwd:            "/test/project",
+				HookEventName:  "PostToolUse",
+			}
+
+			if tt.existing {
+				if _, err := r.Track(ctx, input); err != nil {
+					t.Fatalf("Track failed: %v", err)
+				}
+				appendFile(t, path, transcriptLine("msg2", startedAt.Add(time.Minute), 500, 100))
+			}
+			stateBefore, hadState := f.states.states["session-1"]
+			resetsBefore := strings.Join(f.planConfig.resets, ",")
+
+			// Another hook saves the session while this one parses
+			winner := domain.LiveSession{SessionID: "session-1", LastEvent: "Stop", ByteOffset: fileSize(t, path)}
+			f.live.interleave = func() { f.live.sessions["session-1"] = winner }
+
+			live, err := r.Track(ctx, input)
+			if err != nil {
+				t.Fatalf("Track failed: %v", err)
+			}
+			if live != nil {
+				t.Errorf("Expected no live session from the losing hook, got %+v", live)
+			}
+			assertEqual(t, "live session", winner, f.live.sessions["session-1"])
+
+			// The loser's parser state and window checks are left to the winner
+			stateAfter, hasState := f.states.states["session-1"]
+			assertEqual(t, "state saved", hadState, hasState)
+			assertEqual(t, "state ByteOffset", stateBefore.ByteOffset, stateAfter.ByteOffset)
+			assertEqual(t, "resets", resetsBefore, strings.Join(f.planConfig.resets, ","))
+		})
+	}
+}
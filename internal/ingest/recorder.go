@@ -0,0 +1,297 @@
+// Package ingest records Claude Code sessions: it parses a session's
+// transcript, prices it and saves the session with all of its metrics.
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/parser"
+	"github.com/emiliopalmerini/mclaude/internal/ports"
+	"github.com/emiliopalmerini/mclaude/internal/pricing"
+)
+
+// Deps holds the ports a Recorder reads and writes through. Transcripts and
+// Git may be nil, in which case transcripts aren't archived and no git
+// context is recorded.
+type Deps struct {
+	Projects    ports.ProjectRepository
+	Experiments ports.ExperimentRepository
+	Pricing     ports.PricingRepository
+	Recordings  ports.SessionRecordingRepository
+	Live        ports.LiveSessionRepository
+	States      ports.TranscriptStateRepository
+	ToolRules   ports.ToolRuleRepository
+	PlanConfig  ports.PlanConfigRepository
+	Transcripts ports.TranscriptStorage
+	Git         ports.GitInspector
+}
+
+// Recorder saves sessions reported by the SessionEnd hook or found on disk,
+// and tracks running sessions from the hooks that fire while they last.
+type Recorder struct {
+	deps Deps
+}
+
+func NewRecorder(deps Deps) *Recorder {
+	return &Recorder{deps: deps}
+}
+
+// Options adjusts how a session is recorded.
+type Options struct {
+	// Historical marks sessions recorded after the fact, e.g. by an import:
+	// they are dated by their start time, aren't assigned to the active
+	// experiment and don't reset usage windows.
+	Historical bool
+}
+
+// Result summarizes a recorded session.
+type Result struct {
+	SessionID    string
+	ProjectID    string
+	ExperimentID *string
+	Metrics      *domain.SessionMetrics
+	ModelUsage   []*domain.SessionModelUsage
+	Subagents    int
+	Commits      int    // Commits made during the session
+	StoredPath   string // Archived transcript copy, empty if not archived
+
+	WindowReset       bool // The 5-hour usage window was reset
+	WeeklyWindowReset bool
+	// Warnings lists non-fatal problems, such as a failed transcript archive.
+	Warnings []string
+}
+
+// Record parses the transcript named by the hook input and records the session.
+// Parsing resumes from the state saved by the last hook of the session, if any.
+func (r *Recorder) Record(ctx context.Context, input *domain.HookInput, opts Options) (*Result, error) {
+	p, err := r.parse(ctx, input, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.RecordParsed(ctx, input, p.Result(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// A continued session resumes from here. Losing the state only costs a
+	// full parse, so failures are only reported.
+	if err := r.saveState(ctx, input, p); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+	return result, nil
+}
+
+// parse reads what the transcript gained since the saved parser state. State
+// that's missing, saved for another transcript path or by another parser
+// version is discarded and the transcript is parsed from the start. A final
+// read also parses a last line without a trailing newline, which a running
+// session may still be writing.
+func (r *Recorder) parse(ctx context.Context, input *domain.HookInput, final bool) (*parser.Parser, error) {
+	var p *parser.Parser
+	state, err := r.deps.States.Get(ctx, input.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil && state.TranscriptPath == input.TranscriptPath {
+		p, _ = parser.ResumeParser(input.SessionID, state.State)
+	}
+	if p == nil {
+		p = parser.NewParser(input.SessionID)
+	}
+
+	classifier, err := r.Classifier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.SetClassifier(classifier)
+
+	parseFile := p.ParseFile
+	if final {
+		parseFile = p.ParseFileFinal
+	}
+	if err := parseFile(input.TranscriptPath); err != nil {
+		return nil, fmt.Errorf("failed to parse transcript: %w", err)
+	}
+	return p, nil
+}
+
+// Classifier returns the parser's built-in tool rules with the user's rules
+// from the tool_rules table on top.
+func (r *Recorder) Classifier(ctx context.Context) (*parser.Classifier, error) {
+	rules, err := r.deps.ToolRules.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	classifier := parser.DefaultClassifier()
+	for _, rule := range rules {
+		classifier.Register(rule.Tool, parser.FieldClassifier{Operation: rule.Operation, Fields: rule.Fields})
+	}
+	return classifier, nil
+}
+
+func (r *Recorder) saveState(ctx context.Context, input *domain.HookInput, p *parser.Parser) error {
+	data, err := p.MarshalState()
+	if err != nil {
+		return err
+	}
+	return r.deps.States.Save(ctx, &domain.TranscriptState{
+		SessionID:      input.SessionID,
+		TranscriptPath: input.TranscriptPath,
+		ByteOffset:     p.Offset(),
+		State:          data,
+		UpdatedAt:      time.Now().UTC(),
+	})
+}
+
+// RecordParsed records a session from an already parsed transcript, so
+// callers can parse transcripts concurrently and save them one at a time.
+// Recording a session again replaces its rows (continued sessions).
+func (r *Recorder) RecordParsed(ctx context.Context, input *domain.HookInput, parsed *parser.ParsedTranscript, opts Options) (*Result, error) {
+	result := &Result{SessionID: input.SessionID}
+
+	// Get or create project
+	project, err := r.deps.Projects.GetOrCreate(ctx, input.Cwd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get/create project: %w", err)
+	}
+	result.ProjectID = project.ID
+
+	// Get active experiment (if any). Historical sessions predate it.
+	if !opts.Historical {
+		activeExperiment, err := r.deps.Experiments.GetActive(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get active experiment: %w", err)
+		}
+		if activeExperiment != nil {
+			result.ExperimentID = &activeExperiment.ID
+		}
+	}
+
+	// Reset usage windows if expired
+	if parsed.StartedAt != nil && !opts.Historical {
+		if reset, err := r.deps.PlanConfig.ResetWindowIfExpired(ctx, *parsed.StartedAt); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to check window reset: %v", err))
+		} else {
+			result.WindowReset = reset
+		}
+		if reset, err := r.deps.PlanConfig.ResetWeeklyWindowIfExpired(ctx, *parsed.StartedAt); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to check weekly window reset: %v", err))
+		} else {
+			result.WeeklyWindowReset = reset
+		}
+	}
+
+	// Store transcript copy. Not critical, so failures are only reported.
+	if r.deps.Transcripts != nil {
+		storedPath, err := r.deps.Transcripts.Store(ctx, input.SessionID, input.TranscriptPath)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to store transcript copy: %v", err))
+		}
+		result.StoredPath = storedPath
+	}
+
+	// Set model ID from parsed transcript
+	parsed.Metrics.ModelID = parsed.ModelID
+
+	// Price each request on its own, grouped by model, so mixed-model sessions
+	// aren't billed at a single rate and long-context pricing is per request
+	pricing.PriceTranscript(pricing.NewPricer(ctx, r.deps.Pricing), parsed)
+
+	// Calculate duration
+	var durationSeconds *int64
+	if parsed.StartedAt != nil && parsed.EndedAt != nil {
+		dur := int64(parsed.EndedAt.Sub(*parsed.StartedAt).Seconds())
+		durationSeconds = &dur
+	}
+
+	// Build session
+	session := &domain.Session{
+		ID:              input.SessionID,
+		ProjectID:       project.ID,
+		ExperimentID:    result.ExperimentID,
+		TranscriptPath:  input.TranscriptPath,
+		Cwd:             input.Cwd,
+		PermissionMode:  input.PermissionMode,
+		ExitReason:      input.Reason,
+		StartedAt:       parsed.StartedAt,
+		EndedAt:         parsed.EndedAt,
+		DurationSeconds: durationSeconds,
+		CreatedAt:       time.Now().UTC(),
+	}
+
+	// Date historical sessions by when they ran, so stats place them correctly
+	if opts.Historical && parsed.StartedAt != nil {
+		session.CreatedAt = parsed.StartedAt.UTC()
+	}
+
+	if result.StoredPath != "" {
+		session.TranscriptStoredPath = &result.StoredPath
+	}
+
+	// Read the git state of the working directory up to the session's end,
+	// as a queued session may be recorded hours later. Historical sessions
+	// are skipped since the repository has moved on since they ran.
+	var commits []*domain.SessionCommit
+	if r.deps.Git != nil && !opts.Historical && parsed.StartedAt != nil {
+		until := time.Now()
+		if parsed.EndedAt != nil {
+			until = *parsed.EndedAt
+		}
+		gc, err := r.deps.Git.Inspect(ctx, input.Cwd, *parsed.StartedAt, until)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to read git context: %v", err))
+		} else if gc != nil {
+			setGitContext(session, gc)
+			commits = gc.Commits
+		}
+	}
+
+	// Save the session and everything parsed from it in one transaction.
+	// Rows from an earlier recording (continued sessions) and quality data,
+	// which is stale afterwards, are replaced.
+	err = r.deps.Recordings.Save(ctx, &domain.SessionRecording{
+		Session:       session,
+		Metrics:       parsed.Metrics,
+		Tools:         parsed.Tools,
+		Files:         parsed.Files,
+		Commands:      parsed.Commands,
+		ModelUsage:    parsed.ModelUsage,
+		Subagents:     parsed.Subagents,
+		Commits:       commits,
+		Turns:         parsed.Turns,
+		SlashCommands: parsed.SlashCommands,
+		Hooks:         parsed.Hooks,
+		Anomalies:     parsed.Anomalies,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	result.Metrics = parsed.Metrics
+	result.ModelUsage = parsed.ModelUsage
+	result.Subagents = len(parsed.Subagents)
+	result.Commits = len(commits)
+	return result, nil
+}
+
+func setGitContext(session *domain.Session, gc *domain.GitContext) {
+	if gc.Branch != "" {
+		session.GitBranch = &gc.Branch
+	}
+	// HeadBefore is empty when the session made the repository's first commit
+	if gc.HeadBefore != "" {
+		session.GitHeadBefore = &gc.HeadBefore
+	}
+	if gc.HeadAfter != "" {
+		session.GitHeadAfter = &gc.HeadAfter
+	}
+	session.GitDirty = gc.Dirty
+	for _, commit := range gc.Commits {
+		commit.SessionID = session.ID
+	}
+}
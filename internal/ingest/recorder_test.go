@@ -0,0 +1,114 @@
+package ingest
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+func TestRecord_Historical(t *testing.T) {
+	startedAt := time.Date(2025, 1, 17, 10, 0, 0, 0, time.UTC)
+	endedAt := startedAt.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		historical bool
+		experiment bool   // Assigned to the active experiment
+		resets     string // Usage windows checked for a reset
+		gitCalls   int
+	}{
+		{"live", false, true, "5h,weekly", 1},
+		{"historical", true, false, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			r, f := newTestRecorder()
+			f.experiments.active = &domain.Experiment{ID: "exp-1", Name: "baseline"}
+
+			path := filepath.Join(t.TempDir(), "session.jsonl")
+			appendFile(t, path, transcriptLine("msg1", startedAt, 1000, 200))
+			appendFile(t, path, transcriptLine("msg2", endedAt, 500, 100))
+			input := &domain.HookInput{
+				SessionID:      "session-1",
+				TranscriptPath: path,
+				Cwd:            "/test/project",
+				HookEventName:  "SessionEnd",
+			}
+
+			result, err := r.Record(ctx, input, Options{Historical: tt.historical})
+			if err != nil {
+				t.Fatalf("Record failed: %v", err)
+			}
+			assertEqual(t, "TokenInput", int64(1500), result.Metrics.TokenInput)
+			assertEqual(t, "experiment", tt.experiment, result.ExperimentID != nil)
+			assertEqual(t, "WindowReset", !tt.historical, result.WindowReset)
+			assertEqual(t, "resets", tt.resets, strings.Join(f.planConfig.resets, ","))
+			assertEqual(t, "git calls", tt.gitCalls, f.git.calls)
+			if tt.gitCalls > 0 {
+				// Commits after the session aren't its own, however late it's recorded
+				assertEqual(t, "git until", endedAt, f.git.until)
+			}
+
+			assertEqual(t, "recordings", 1, len(f.recordings.saved))
+			session := f.recordings.saved[0].Session
+			assertEqual(t, "ProjectID", "project-/test/project", session.ProjectID)
+			assertEqual(t, "git context", !tt.historical, session.GitBranch != nil)
+			if tt.historical {
+				assertEqual(t, "CreatedAt", startedAt, session.CreatedAt)
+			} else if !session.CreatedAt.After(startedAt) {
+				t.Errorf("Expected session dated when recorded, got %v", session.CreatedAt)
+			}
+
+			// Either way a continued session resumes where this one stopped
+			state, err := f.states.Get(ctx, input.SessionID)
+			if err != nil || state == nil {
+				t.Fatalf("Expected transcript state, got %v (err: %v)", state, err)
+			}
+			assertEqual(t, "state ByteOffset", fileSize(t, path), state.ByteOffset)
+		})
+	}
+}
+
+func TestRecord_ResumesFromState(t *testing.T) {
+	ctx := context.Background()
+	r, f := newTestRecorder()
+	startedAt := time.Date(2025, 1, 17, 10, 0, 0, 0, time.UTC)
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	appendFile(t, path, transcriptLine("msg1", startedAt, 1000, 200))
+	input := &domain.HookInput{
+		SessionID:      "session-1",
+		TranscriptPath: path,
+		Cwd:            "/test/project",
+		HookEventName:  "Stop",
+	}
+	if _, err := r.Track(ctx, input); err != nil {
+		t.Fatalf("Track failed: %v", err)
+	}
+
+	// State saved for another transcript path is discarded
+	f.states.states["session-2"] = f.states.states["session-1"]
+	otherPath := filepath.Join(t.TempDir(), "other.jsonl")
+	appendFile(t, otherPath, transcriptLine("msg1", startedAt, 300, 30))
+
+	appendFile(t, path, transcriptLine("msg2", startedAt.Add(time.Minute), 500, 100))
+	input.HookEventName = "SessionEnd"
+	result, err := r.Record(ctx, input, Options{})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	assertEqual(t, "TokenInput", int64(1500), result.Metrics.TokenInput)
+
+	other := &domain.HookInput{SessionID: "session-2", TranscriptPath: otherPath, Cwd: "/test/project"}
+	result, err = r.Record(ctx, other, Options{})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	assertEqual(t, "other TokenInput", int64(300), result.Metrics.TokenInput)
+}
@@ -0,0 +1,76 @@
+package parser
+
+import "github.com/emiliopalmerini/mclaude/internal/domain"
+
+// compactBoundarySubtype marks the system entry Claude Code writes where it
+// compacted the context.
+const compactBoundarySubtype = "compact_boundary"
+
+// countCompaction counts a compaction from either of the entries Claude Code
+// writes for one: the system boundary and the summary that follows it as a
+// user message. Older transcripts only have the summary, so a summary counts
+// unless a boundary was already counted for it.
+func (p *Parser) countCompaction(entry TranscriptEntry) {
+	switch {
+	case entry.Type == "system" && entry.Subtype == compactBoundarySubtype:
+		p.result.Metrics.CompactionCount++
+		p.compactPending = true
+	case entry.IsCompactSummary:
+		if !p.compactPending {
+			p.result.Metrics.CompactionCount++
+		}
+		p.compactPending = false
+	}
+}
+
+// cacheWaste estimates how many cache-write tokens no later request read.
+func cacheWaste(requests []*domain.RequestUsage) int64 {
+	var cache cacheTracker
+	for _, req := range requests {
+		cache.add(req)
+	}
+	return cache.waste()
+}
+
+// cacheTracker estimates cache waste one request at a time, so a resumed
+// parser doesn't need the requests it already read.
+//
+// Each model has its own cache, holding the prompt prefix of its last
+// request. The tokens a request writes are read when the next request on the
+// same model reads past the prefix the writer itself read; whatever it
+// doesn't read was wasted, because the cache expired, the prompt changed
+// (e.g. after a compaction) or the session ended. Sub-agent totals are
+// skipped since their requests aren't in the transcript.
+type cacheTracker struct {
+	// Last is the cache read and write of each model's last request.
+	Last   map[string]cacheUse `json:"last,omitempty"`
+	Wasted int64               `json:"wasted"`
+}
+
+type cacheUse struct {
+	Read  int64 `json:"read"`
+	Write int64 `json:"write"`
+}
+
+func (c *cacheTracker) add(req *domain.RequestUsage) {
+	if req.Aggregate {
+		return
+	}
+	if c.Last == nil {
+		c.Last = make(map[string]cacheUse)
+	}
+	if prev, ok := c.Last[req.ModelID]; ok {
+		read := max(req.TokenCacheRead-prev.Read, 0)
+		c.Wasted += max(prev.Write-read, 0)
+	}
+	c.Last[req.ModelID] = cacheUse{Read: req.TokenCacheRead, Write: req.TokenCacheWrite}
+}
+
+// waste returns the tokens wasted so far, counting the last writes as unread.
+func (c *cacheTracker) waste() int64 {
+	wasted := c.Wasted
+	for _, use := range c.Last {
+		wasted += use.Write
+	}
+	return wasted
+}
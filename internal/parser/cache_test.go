@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+func TestCacheWaste(t *testing.T) {
+	req := func(model string, read, write int64) *domain.RequestUsage {
+		return &domain.RequestUsage{ModelID: model, TokenCacheRead: read, TokenCacheWrite: write}
+	}
+
+	tests := []struct {
+		name     string
+		requests []*domain.RequestUsage
+		want     int64
+	}{
+		{"no requests", nil, 0},
+		// Each write is read by the next request, except the last one's
+		{"all read", []*domain.RequestUsage{req("a", 0, 1000), req("a", 1000, 200), req("a", 1200, 0)}, 0},
+		{"last write unread", []*domain.RequestUsage{req("a", 0, 1000), req("a", 1000, 300)}, 300},
+		// The cache expired: the second request writes everything again
+		{"expired", []*domain.RequestUsage{req("a", 0, 1000), req("a", 0, 1100), req("a", 1100, 0)}, 1000},
+		{"partly read", []*domain.RequestUsage{req("a", 100, 500), req("a", 400, 0)}, 200},
+		// Models have their own caches
+		{"per model", []*domain.RequestUsage{req("a", 0, 1000), req("b", 0, 500), req("a", 1000, 0), req("b", 500, 0)}, 0},
+		{"aggregates skipped", []*domain.RequestUsage{req("a", 0, 1000), {ModelID: "a", TokenCacheWrite: 9000, Aggregate: true}, req("a", 1000, 0)}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertEqual(t, "cacheWaste", tt.want, cacheWaste(tt.requests))
+		})
+	}
+}
+
+func TestParseTranscript_Compactions(t *testing.T) {
+	// A compaction with both a boundary and a summary, then one from an older
+	// transcript with only the summary
+	content := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Start"}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:01Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Ok"}],"usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":0,"cache_creation_input_tokens":1000}}}
+{"type":"system","subtype":"compact_boundary","timestamp":"2025-01-17T10:05:00Z","content":"Conversation compacted","compactMetadata":{"trigger":"auto","preTokens":150000}}
+{"type":"user","timestamp":"2025-01-17T10:05:00Z","isCompactSummary":true,"message":{"role":"user","content":"This session is being continued from a previous conversation."}}
+{"type":"assistant","timestamp":"2025-01-17T10:05:05Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Continuing"}],"usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":0,"cache_creation_input_tokens":800}}}
+{"type":"user","timestamp":"2025-01-17T10:10:00Z","isCompactSummary":true,"message":{"role":"user","content":"This session is being continued from a previous conversation."}}
+{"type":"assistant","timestamp":"2025-01-17T10:10:05Z","message":{"id":"msg_3","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Done"}],"usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":800,"cache_creation_input_tokens":0}}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	assertEqual(t, "CompactionCount", int64(2), result.Metrics.CompactionCount)
+	// The first write was lost to the compaction, the second was read
+	assertEqual(t, "TokenCacheWasted", int64(1000), result.Metrics.TokenCacheWasted)
+	// Summaries aren't prompts
+	assertEqual(t, "len(Turns)", 1, len(result.Turns))
+}
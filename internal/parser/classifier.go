@@ -0,0 +1,391 @@
+package parser
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// File operation kinds recorded in session_files.
+const (
+	OperationRead   = "read"
+	OperationWrite  = "write"
+	OperationEdit   = "edit"
+	OperationSearch = "search"
+	OperationDelete = "delete"
+)
+
+// FileOp is a file or directory a tool call touched, and how.
+type FileOp struct {
+	Path      string
+	Operation string
+}
+
+// ToolClassifier tells which files a call of a tool touches, from its input.
+type ToolClassifier interface {
+	Classify(tool string, input json.RawMessage) []FileOp
+}
+
+// FieldClassifier reads paths from the named input fields, each holding a
+// path or a list of paths, and reports them all with the same operation.
+type FieldClassifier struct {
+	Operation string
+	Fields    []string
+}
+
+func (c FieldClassifier) Classify(tool string, input json.RawMessage) []FileOp {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return nil
+	}
+
+	var ops []FileOp
+	for _, name := range c.Fields {
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		var path string
+		if json.Unmarshal(raw, &path) == nil {
+			if path != "" {
+				ops = append(ops, FileOp{Path: path, Operation: c.Operation})
+			}
+			continue
+		}
+		var paths []string
+		if json.Unmarshal(raw, &paths) == nil {
+			for _, path := range paths {
+				if path != "" {
+					ops = append(ops, FileOp{Path: path, Operation: c.Operation})
+				}
+			}
+		}
+	}
+	return ops
+}
+
+// Classifier maps tool names to the classifier for their calls. A name
+// ending in "*" matches every tool with that prefix; exact names win over
+// prefixes, and longer prefixes over shorter ones.
+type Classifier struct {
+	tools    map[string]ToolClassifier
+	prefixes map[string]ToolClassifier
+}
+
+func NewClassifier() *Classifier {
+	return &Classifier{
+		tools:    make(map[string]ToolClassifier),
+		prefixes: make(map[string]ToolClassifier),
+	}
+}
+
+// DefaultClassifier returns a classifier with rules for Claude Code's
+// built-in tools, Bash commands and MCP tools. Rules registered on it
+// replace the built-in ones for the same name.
+func DefaultClassifier() *Classifier {
+	c := NewClassifier()
+	c.Register("Read", FieldClassifier{Operation: OperationRead, Fields: []string{"file_path"}})
+	c.Register("NotebookRead", FieldClassifier{Operation: OperationRead, Fields: []string{"notebook_path"}})
+	c.Register("Write", FieldClassifier{Operation: OperationWrite, Fields: []string{"file_path"}})
+	c.Register("Edit", FieldClassifier{Operation: OperationEdit, Fields: []string{"file_path"}})
+	c.Register("MultiEdit", FieldClassifier{Operation: OperationEdit, Fields: []string{"file_path"}})
+	c.Register("NotebookEdit", FieldClassifier{Operation: OperationEdit, Fields: []string{"notebook_path"}})
+	c.Register("Glob", FieldClassifier{Operation: OperationSearch, Fields: []string{"path"}})
+	c.Register("Grep", FieldClassifier{Operation: OperationSearch, Fields: []string{"path"}})
+	c.Register("LS", FieldClassifier{Operation: OperationSearch, Fields: []string{"path"}})
+	c.Register("Bash", BashClassifier{})
+	c.Register("mcp__*", MCPClassifier{})
+	return c
+}
+
+// Register sets the classifier for a tool name or "prefix*" pattern.
+func (c *Classifier) Register(tool string, classifier ToolClassifier) {
+	if prefix, ok := strings.CutSuffix(tool, "*"); ok {
+		c.prefixes[prefix] = classifier
+		return
+	}
+	c.tools[tool] = classifier
+}
+
+// Classify returns the files a tool call touched, or nil for tools that
+// don't touch files.
+func (c *Classifier) Classify(tool string, input json.RawMessage) []FileOp {
+	if len(input) == 0 {
+		return nil
+	}
+	if classifier := c.lookup(tool); classifier != nil {
+		return classifier.Classify(tool, input)
+	}
+	return nil
+}
+
+func (c *Classifier) lookup(tool string) ToolClassifier {
+	if classifier, ok := c.tools[tool]; ok {
+		return classifier
+	}
+
+	prefixes := make([]string, 0, len(c.prefixes))
+	for prefix := range c.prefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(tool, prefix) {
+			return c.prefixes[prefix]
+		}
+	}
+	return nil
+}
+
+// MCPClassifier handles tools served over MCP, named mcp__<server>__<tool>.
+// The operation is guessed from the tool's own name (e.g. read_file,
+// write_file, search_files) and paths are read from the usual input fields.
+type MCPClassifier struct{}
+
+var mcpPathFields = []string{"path", "paths", "file_path", "filePath", "filename", "notebook_path", "source", "destination"}
+
+// mcpOperations lists name fragments per operation, checked in order.
+var mcpOperations = []struct {
+	operation string
+	fragments []string
+}{
+	{OperationDelete, []string{"delete", "remove"}},
+	{OperationEdit, []string{"edit", "replace", "patch", "update", "move", "rename"}},
+	{OperationWrite, []string{"write", "create", "save", "put"}},
+	{OperationSearch, []string{"search", "find", "grep", "glob", "list", "tree"}},
+	{OperationRead, []string{"read", "get", "view", "open", "cat"}},
+}
+
+// ParseMCPToolName splits an MCP tool name, mcp__<server>__<tool>, into
+// its server and tool. ok is false for names that aren't MCP tools.
+func ParseMCPToolName(name string) (server, tool string, ok bool) {
+	rest, ok := strings.CutPrefix(name, "mcp__")
+	if !ok {
+		return "", "", false
+	}
+	server, tool, ok = strings.Cut(rest, "__")
+	if !ok || server == "" || tool == "" {
+		return "", "", false
+	}
+	return server, tool, true
+}
+
+func (MCPClassifier) Classify(tool string, input json.RawMessage) []FileOp {
+	name := tool
+	if _, mcpTool, ok := ParseMCPToolName(tool); ok {
+		name = mcpTool
+	}
+	name = strings.ToLower(name)
+	for _, op := range mcpOperations {
+		for _, fragment := range op.fragments {
+			if strings.Contains(name, fragment) {
+				return FieldClassifier{Operation: op.operation, Fields: mcpPathFields}.Classify(tool, input)
+			}
+		}
+	}
+	return nil
+}
+
+// BashClassifier finds the files a shell command reads, writes or deletes
+// through common commands (cat, rm, cp, sed -i...) and output redirections.
+// Arguments it can't resolve to a literal path, such as globs or variables,
+// are skipped.
+type BashClassifier struct{}
+
+// bashCommands maps commands whose arguments are files to their operation.
+var bashCommands = map[string]string{
+	"cat":   OperationRead,
+	"head":  OperationRead,
+	"tail":  OperationRead,
+	"less":  OperationRead,
+	"more":  OperationRead,
+	"wc":    OperationRead,
+	"diff":  OperationRead,
+	"touch": OperationWrite,
+	"tee":   OperationWrite,
+	"rm":    OperationDelete,
+}
+
+func (BashClassifier) Classify(tool string, input json.RawMessage) []FileOp {
+	var in ToolInput
+	if err := json.Unmarshal(input, &in); err != nil || in.Command == "" {
+		return nil
+	}
+
+	var ops []FileOp
+	for _, segment := range splitShellCommand(in.Command) {
+		ops = append(ops, classifyShellSegment(segment)...)
+	}
+	return ops
+}
+
+// splitShellCommand splits a command line into the words of each simple
+// command, separated by ;, &&, ||, | and newlines.
+func splitShellCommand(command string) [][]string {
+	replacer := strings.NewReplacer("&&", "\n", "||", "\n", ";", "\n", "|", "\n")
+	var segments [][]string
+	for _, line := range strings.Split(replacer.Replace(command), "\n") {
+		if words := strings.Fields(line); len(words) > 0 {
+			segments = append(segments, words)
+		}
+	}
+	return segments
+}
+
+func classifyShellSegment(words []string) []FileOp {
+	var ops []FileOp
+
+	// Output redirections write their target, whatever the command
+	var args []string
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if target, ok := redirectTarget(word); ok {
+			if target == "" && i+1 < len(words) {
+				i++
+				target = words[i]
+			}
+			if path, ok := literalPath(target); ok && path != "/dev/null" {
+				ops = append(ops, FileOp{Path: path, Operation: OperationWrite})
+			}
+			continue
+		}
+		args = append(args, word)
+	}
+
+	// Skip environment assignments and sudo before the command name
+	for len(args) > 0 && (args[0] == "sudo" || (strings.Contains(args[0], "=") && !strings.HasPrefix(args[0], "-"))) {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return ops
+	}
+
+	name := args[0]
+	var operands []string
+	inPlace, script := false, false
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			for _, arg := range args[i+1:] {
+				if path, ok := literalPath(arg); ok {
+					operands = append(operands, path)
+				}
+			}
+			break
+		}
+		if strings.HasPrefix(arg, "-") {
+			options, takesValue := parseOptions(name, arg)
+			if name == "sed" {
+				inPlace = inPlace || strings.Contains(options, "i") || strings.HasPrefix(arg, "--in-place")
+				script = script || strings.ContainsAny(options, "ef") ||
+					strings.HasPrefix(arg, "--expression") || strings.HasPrefix(arg, "--file")
+			}
+			if takesValue {
+				i++
+			}
+			continue
+		}
+		if path, ok := literalPath(arg); ok {
+			operands = append(operands, path)
+		}
+	}
+
+	switch {
+	case name == "sed":
+		// Without -e or -f, the first operand is the script
+		if !script && len(operands) > 0 {
+			operands = operands[1:]
+		}
+		if inPlace {
+			for _, path := range operands {
+				ops = append(ops, FileOp{Path: path, Operation: OperationEdit})
+			}
+		}
+	case name == "cp" || name == "mv":
+		if len(operands) < 2 {
+			break
+		}
+		source := OperationRead
+		if name == "mv" {
+			source = OperationDelete
+		}
+		last := len(operands) - 1
+		for _, path := range operands[:last] {
+			ops = append(ops, FileOp{Path: path, Operation: source})
+		}
+		ops = append(ops, FileOp{Path: operands[last], Operation: OperationWrite})
+	default:
+		if operation, ok := bashCommands[name]; ok {
+			for _, path := range operands {
+				ops = append(ops, FileOp{Path: path, Operation: operation})
+			}
+		}
+	}
+	return ops
+}
+
+// bashOptionValues lists, per command, the options that take a value, so
+// the value isn't taken for a file: short options whose value may be the
+// next word (head -n 50) or attached (head -n50), short options whose value
+// can only be attached (sed -i.bak) and long options.
+var bashOptionValues = map[string]struct {
+	short    string
+	attached string
+	long     []string
+}{
+	"head":  {"nc", "", []string{"--lines", "--bytes"}},
+	"tail":  {"ncs", "", []string{"--lines", "--bytes", "--sleep-interval", "--pid", "--max-unchanged-stats"}},
+	"sed":   {"efl", "i", []string{"--expression", "--file", "--line-length"}},
+	"diff":  {"CUxXIL", "", []string{"--exclude", "--exclude-from", "--ignore-matching-lines", "--label"}},
+	"touch": {"drt", "", []string{"--date", "--reference"}},
+	"cp":    {"S", "", []string{"--suffix"}},
+	"mv":    {"S", "", []string{"--suffix"}},
+}
+
+// parseOptions returns the letters of a group of short options, up to the
+// first that takes a value, and whether the value is the next word. For a
+// long option, which has no letters, the value is the next word unless
+// given as --option=value.
+func parseOptions(command, arg string) (letters string, takesValue bool) {
+	options := bashOptionValues[command]
+	if strings.HasPrefix(arg, "--") {
+		return "", !strings.Contains(arg, "=") && slices.Contains(options.long, arg)
+	}
+	group := arg[1:]
+	for i, letter := range group {
+		if strings.ContainsRune(options.attached, letter) {
+			return group[:i+1], false
+		}
+		if strings.ContainsRune(options.short, letter) {
+			return group[:i+1], i == len(group)-1
+		}
+	}
+	return group, false
+}
+
+// redirectTarget reports whether word is an output redirection (>, >>, 2>,
+// &>...), returning its target when attached to the operator.
+func redirectTarget(word string) (string, bool) {
+	i := strings.Index(word, ">")
+	if i < 0 || strings.Trim(word[:i], "0123456789&") != "" {
+		return "", false
+	}
+	target := strings.TrimPrefix(word[i+1:], ">")
+	if strings.HasPrefix(target, "&") {
+		// Duplicates a descriptor, e.g. 2>&1
+		return "", false
+	}
+	return target, true
+}
+
+// literalPath unquotes a shell word, rejecting words the shell would expand.
+func literalPath(word string) (string, bool) {
+	if len(word) >= 2 && (word[0] == '"' || word[0] == '\'') && word[len(word)-1] == word[0] {
+		word = word[1 : len(word)-1]
+	}
+	if word == "" || strings.ContainsAny(word, "*?[]$`(){}<>~\"'") {
+		return "", false
+	}
+	return word, true
+}
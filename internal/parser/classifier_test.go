@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDefaultClassifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		tool  string
+		input any
+		want  []FileOp
+	}{
+		{"read", "Read", map[string]any{"file_path": "/a.go"}, []FileOp{{"/a.go", OperationRead}}},
+		{"multi edit", "MultiEdit", map[string]any{"file_path": "/a.go", "edits": []any{}}, []FileOp{{"/a.go", OperationEdit}}},
+		{"notebook edit", "NotebookEdit", map[string]any{"notebook_path": "/n.ipynb"}, []FileOp{{"/n.ipynb", OperationEdit}}},
+		{"grep", "Grep", map[string]any{"pattern": "TODO", "path": "/src"}, []FileOp{{"/src", OperationSearch}}},
+		{"glob without path", "Glob", map[string]any{"pattern": "**/*.go"}, nil},
+		{"unknown tool", "WebFetch", map[string]any{"url": "https://example.com"}, nil},
+		{"mcp read", "mcp__filesystem__read_multiple_files", map[string]any{"paths": []string{"/a", "/b"}}, []FileOp{{"/a", OperationRead}, {"/b", OperationRead}}},
+		{"mcp write", "mcp__filesystem__write_file", map[string]any{"path": "/a", "content": "x"}, []FileOp{{"/a", OperationWrite}}},
+		{"mcp without files", "mcp__github__create_issue", map[string]any{"title": "bug"}, nil},
+		{"bash read", "Bash", map[string]any{"command": "cat README.md | head -5"}, []FileOp{{"README.md", OperationRead}}},
+		{"bash redirect", "Bash", map[string]any{"command": "go test ./... > out.txt 2>&1"}, []FileOp{{"out.txt", OperationWrite}}},
+		{"bash sed in place", "Bash", map[string]any{"command": "sed -i 's/a/b/' main.go"}, []FileOp{{"main.go", OperationEdit}}},
+		{"bash sed to stdout", "Bash", map[string]any{"command": "sed 's/a/b/' main.go"}, nil},
+		{"bash sed script option", "Bash", map[string]any{"command": "sed -i.bak -e 's/a/b/' main.go util.go"}, []FileOp{{"main.go", OperationEdit}, {"util.go", OperationEdit}}},
+		{"bash option values", "Bash", map[string]any{"command": "head -n 50 foo.go && tail -qn 20 --sleep-interval 1 app.log"}, []FileOp{{"foo.go", OperationRead}, {"app.log", OperationRead}}},
+		{"bash attached option value", "Bash", map[string]any{"command": "head -n50 --lines=10 foo.go"}, []FileOp{{"foo.go", OperationRead}}},
+		{"bash end of options", "Bash", map[string]any{"command": "rm -f -- -n notes.txt"}, []FileOp{{"-n", OperationDelete}, {"notes.txt", OperationDelete}}},
+		{"bash mv", "Bash", map[string]any{"command": "mkdir -p dst && mv old.go dst/new.go"}, []FileOp{{"old.go", OperationDelete}, {"dst/new.go", OperationWrite}}},
+		{"bash rm with glob", "Bash", map[string]any{"command": "rm -f build.log *.tmp"}, []FileOp{{"build.log", OperationDelete}}},
+		{"bash quoted path", "Bash", map[string]any{"command": `FOO=1 cat "notes.txt"`}, []FileOp{{"notes.txt", OperationRead}}},
+	}
+
+	classifier := DefaultClassifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, _ := json.Marshal(tt.input)
+			got := classifier.Classify(tt.tool, input)
+			if !reflect.DeepEqual(tt.want, got) {
+				t.Errorf("Classify(%s, %s) = %v, want %v", tt.tool, input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifier_Register(t *testing.T) {
+	classifier := DefaultClassifier()
+	classifier.Register("mcp__notes__*", FieldClassifier{Operation: OperationWrite, Fields: []string{"note"}})
+	classifier.Register("Read", FieldClassifier{Operation: OperationSearch, Fields: []string{"file_path"}})
+
+	input := json.RawMessage(`{"note":"/notes/today.md","path":"/ignored"}`)
+	want := []FileOp{{"/notes/today.md", OperationWrite}}
+	if got := classifier.Classify("mcp__notes__get_note", input); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected the longer prefix to win, got %v", got)
+	}
+
+	input = json.RawMessage(`{"file_path":"/a.go"}`)
+	want = []FileOp{{"/a.go", OperationSearch}}
+	if got := classifier.Classify("Read", input); !reflect.DeepEqual(want, got) {
+		t.Errorf("Expected the registered rule to replace the built-in one, got %v", got)
+	}
+}
+
+func TestParseMCPToolName(t *testing.T) {
+	tests := []struct {
+		name, server, tool string
+		ok                 bool
+	}{
+		{"mcp__github__create_issue", "github", "create_issue", true},
+		{"mcp__claude_ai_Gmail__search__threads", "claude_ai_Gmail", "search__threads", true},
+		{"mcp__github", "", "", false},
+		{"mcp____tool", "", "", false},
+		{"Read", "", "", false},
+	}
+	for _, tt := range tests {
+		server, tool, ok := ParseMCPToolName(tt.name)
+		if server != tt.server || tool != tt.tool || ok != tt.ok {
+			t.Errorf("ParseMCPToolName(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.name, server, tool, ok, tt.server, tt.tool, tt.ok)
+		}
+	}
+}
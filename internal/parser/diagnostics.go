@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// knownEntryTypes are the transcript entry types Claude Code writes, whether
+// or not anything is read from them.
+var knownEntryTypes = map[string]bool{
+	"user":                  true,
+	"human":                 true,
+	"assistant":             true,
+	"result":                true,
+	"system":                true,
+	"summary":               true,
+	"attachment":            true,
+	"progress":              true,
+	"file-history-snapshot": true,
+	"queue-operation":       true,
+	"custom-title":          true,
+}
+
+// knownContentTypes are the content block types of messages, including those
+// of server tools the API runs itself.
+var knownContentTypes = map[string]bool{
+	"text":                                   true,
+	"image":                                  true,
+	"document":                               true,
+	"thinking":                               true,
+	"redacted_thinking":                      true,
+	"tool_use":                               true,
+	"tool_result":                            true,
+	"server_tool_use":                        true,
+	"web_search_tool_result":                 true,
+	"web_fetch_tool_result":                  true,
+	"code_execution_tool_result":             true,
+	"bash_code_execution_tool_result":        true,
+	"text_editor_code_execution_tool_result": true,
+	"mcp_tool_use":                           true,
+	"mcp_tool_result":                        true,
+	"container_upload":                       true,
+	"search_result":                          true,
+	"tool_reference":                         true,
+}
+
+// checkEntry records what the parser doesn't understand about an entry, so
+// a change to the transcript format shows up as anomalies rather than as
+// metrics that silently stop adding up. Assistant messages without usage
+// leave the session's tokens short; Claude Code's own synthetic messages
+// never have any.
+func (p *Parser) checkEntry(entry TranscriptEntry) {
+	if !knownEntryTypes[entry.Type] {
+		p.addAnomaly(domain.AnomalyUnknownEntryType, entry.Type)
+	}
+	if entry.Message == nil {
+		return
+	}
+	for _, content := range entry.Message.Content {
+		if !knownContentTypes[content.Type] {
+			p.addAnomaly(domain.AnomalyUnknownContentType, content.Type)
+		}
+	}
+	if entry.Type == "assistant" && entry.Usage == nil && entry.Message.Usage == nil {
+		if model := entryModel(entry); model != syntheticModelID {
+			p.addAnomaly(domain.AnomalyMissingUsage, model)
+		}
+	}
+}
+
+// addAnomaly counts an anomaly on the line being parsed.
+func (p *Parser) addAnomaly(kind, detail string) {
+	for _, anomaly := range p.result.Anomalies {
+		if anomaly.Kind == kind && anomaly.Detail == detail {
+			anomaly.OccurrenceCount++
+			return
+		}
+	}
+	p.result.Anomalies = append(p.result.Anomalies, &domain.SessionParseAnomaly{
+		SessionID:       p.sessionID,
+		Kind:            kind,
+		Detail:          detail,
+		OccurrenceCount: 1,
+		FirstLine:       p.result.Metrics.TranscriptLines,
+	})
+}
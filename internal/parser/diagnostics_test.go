@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+func TestParseTranscript_Anomalies(t *testing.T) {
+	// A truncated line, an entry type and a content block type the parser
+	// doesn't know, two assistant lines without usage and a synthetic error
+	// message, which never has any
+	content := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Fix it"}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:01Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"On it"}],"usage":{"input_tokens":100,"output_tokens":10}}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:02Z","message":{"id":"msg_2","role":"ass
+{"type":"checkpoint","timestamp":"2025-01-17T10:00:03Z"}
+{"type":"assistant","timestamp":"2025-01-17T10:00:04Z","message":{"id":"msg_3","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"plan","text":"1. Read"}]}}
+
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"msg_4","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Done"}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:06Z","message":{"id":"msg_5","role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error"}]}}
+{"type":"file-history-snapshot","messageId":"msg_5","snapshot":{}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	assertEqual(t, "TranscriptLines", int64(9), result.Metrics.TranscriptLines)
+	assertEqual(t, "ParserVersion", int64(StateVersion), result.Metrics.ParserVersion)
+	assertEqual(t, "TokenInput", int64(100), result.Metrics.TokenInput)
+
+	type anomaly struct {
+		kind, detail string
+		count, line  int64
+	}
+	want := []anomaly{
+		{domain.AnomalyMalformedLine, "unexpected end of JSON input", 1, 3},
+		{domain.AnomalyUnknownEntryType, "checkpoint", 1, 4},
+		{domain.AnomalyUnknownContentType, "plan", 1, 5},
+		{domain.AnomalyMissingUsage, "claude-sonnet-4-20250514", 2, 5},
+	}
+	assertEqual(t, "anomalies", len(want), len(result.Anomalies))
+	for i, a := range result.Anomalies {
+		if i >= len(want) {
+			break
+		}
+		got := anomaly{a.Kind, a.Detail, a.OccurrenceCount, a.FirstLine}
+		assertEqual(t, "anomaly", want[i], got)
+		assertEqual(t, "anomaly session", "test-session", a.SessionID)
+	}
+}
+
+func TestParser_AnomaliesSurviveResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	first := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Fix it"}}
+{"type":"checkpoint"}
+`
+	if err := os.WriteFile(path, []byte(first), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	p := NewParser("test-session")
+	if err := p.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	state, err := p.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState failed: %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open test transcript: %v", err)
+	}
+	f.WriteString("{\"type\":\"checkpoint\"}\n{\"type\":\"rewind\"}\n")
+	f.Close()
+
+	resumed, err := ResumeParser("test-session", state)
+	if err != nil {
+		t.Fatalf("ResumeParser failed: %v", err)
+	}
+	if err := resumed.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	result := resumed.Result()
+	assertEqual(t, "TranscriptLines", int64(4), result.Metrics.TranscriptLines)
+	assertEqual(t, "anomalies", 2, len(result.Anomalies))
+	assertEqual(t, "checkpoint count", int64(2), result.Anomalies[0].OccurrenceCount)
+	assertEqual(t, "checkpoint line", int64(2), result.Anomalies[0].FirstLine)
+	assertEqual(t, "rewind line", int64(4), result.Anomalies[1].FirstLine)
+}
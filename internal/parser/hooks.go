@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// hookEntry holds the fields of the entries Claude Code writes when hooks
+// run. Newer versions attach each run's outcome to the transcript, and
+// summarize Stop hooks in a system entry; older ones write a system entry
+// with a status line.
+type hookEntry struct {
+	Content    json.RawMessage `json:"content,omitempty"`
+	Attachment *struct {
+		Type      string `json:"type"`
+		HookName  string `json:"hookName,omitempty"`
+		HookEvent string `json:"hookEvent,omitempty"`
+		Command   string `json:"command,omitempty"`
+	} `json:"attachment,omitempty"`
+	HookInfos []struct {
+		Command string `json:"command"`
+	} `json:"hookInfos,omitempty"`
+	HookErrors            []json.RawMessage `json:"hookErrors,omitempty"`
+	PreventedContinuation bool              `json:"preventedContinuation,omitempty"`
+}
+
+// stopHookSummarySubtype marks the system entry summarizing the Stop hooks
+// that ran at the end of a response.
+const stopHookSummarySubtype = "stop_hook_summary"
+
+// Outcomes of a hook run, from the type of its attachment.
+const (
+	hookSucceeded = iota
+	hookFailed
+	hookBlocked
+)
+
+var hookAttachmentOutcomes = map[string]int{
+	"hook_success":                hookSucceeded,
+	"hook_non_blocking_error":     hookFailed,
+	"hook_error_during_execution": hookFailed,
+	"hook_cancelled":              hookFailed,
+	"hook_blocking_error":         hookBlocked,
+	"hook_stopped_continuation":   hookBlocked,
+}
+
+var (
+	// ansiPattern matches the terminal escapes around hook names in status lines.
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+	// hookStatusPattern matches a status line, e.g. "PostToolUse:Edit
+	// [~/.claude/hooks/fmt.sh] completed successfully".
+	hookStatusPattern = regexp.MustCompile(`^(\w+(?::[^\s\[]*)?) \[(.+?)\] (completed successfully|failed with (non-)?blocking status code)`)
+	// blockingHookPattern matches the result of a tool call a hook blocked.
+	blockingHookPattern = regexp.MustCompile(`^(\w+(?::[^\s\[]*)?) hook error: \[(.+?)\]`)
+)
+
+// countHooks counts the hook runs recorded by a system or attachment entry.
+func (p *Parser) countHooks(entry TranscriptEntry, line []byte) {
+	if entry.Type != "system" && entry.Type != "attachment" {
+		return
+	}
+	var he hookEntry
+	if json.Unmarshal(line, &he) != nil {
+		return
+	}
+
+	switch {
+	case he.Attachment != nil:
+		outcome, ok := hookAttachmentOutcomes[he.Attachment.Type]
+		if !ok || he.Attachment.HookName == "" {
+			return
+		}
+		p.addHook(he.Attachment.HookEvent, he.Attachment.HookName, he.Attachment.Command, outcome)
+	case entry.Subtype == stopHookSummarySubtype:
+		// Errors and blocks aren't tied to a hook, so they're only counted
+		// when a single hook ran
+		for _, info := range he.HookInfos {
+			outcome := hookSucceeded
+			if len(he.HookInfos) == 1 && he.PreventedContinuation {
+				outcome = hookBlocked
+			} else if len(he.HookInfos) == 1 && len(he.HookErrors) > 0 {
+				outcome = hookFailed
+			}
+			p.addHook("Stop", "Stop", info.Command, outcome)
+		}
+	default:
+		var content string
+		if json.Unmarshal(he.Content, &content) != nil {
+			return
+		}
+		m := hookStatusPattern.FindStringSubmatch(ansiPattern.ReplaceAllString(content, ""))
+		if m == nil {
+			return
+		}
+		outcome := hookSucceeded
+		if strings.HasPrefix(m[3], "failed") {
+			outcome = hookFailed
+			if m[4] == "" {
+				outcome = hookBlocked
+			}
+		}
+		p.addHook("", m[1], m[2], outcome)
+	}
+}
+
+// countBlockingHook counts a hook that blocked a tool call, as reported by
+// the call's result.
+func (p *Parser) countBlockingHook(result string) {
+	if m := blockingHookPattern.FindStringSubmatch(result); m != nil {
+		p.addHook("", m[1], m[2], hookBlocked)
+	}
+}
+
+// addHook counts a run of a hook. The event defaults to the hook name up to
+// the matcher, e.g. "PreToolUse" for "PreToolUse:Bash".
+func (p *Parser) addHook(event, name, command string, outcome int) {
+	if event == "" {
+		event, _, _ = strings.Cut(name, ":")
+	}
+	var hook *domain.SessionHook
+	for _, h := range p.result.Hooks {
+		if h.HookName == name && h.Command == command {
+			hook = h
+			break
+		}
+	}
+	if hook == nil {
+		hook = &domain.SessionHook{SessionID: p.sessionID, Event: event, HookName: name, Command: command}
+		p.result.Hooks = append(p.result.Hooks, hook)
+	}
+	hook.ExecutionCount++
+	switch outcome {
+	case hookFailed:
+		hook.ErrorCount++
+	case hookBlocked:
+		hook.BlockedCount++
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTranscript_Hooks(t *testing.T) {
+	// Hook runs as attachments, a Stop hook summary, a status line from an
+	// older version and a tool call blocked by a hook
+	content := `{"type":"attachment","timestamp":"2025-01-17T10:00:00Z","attachment":{"type":"hook_success","hookName":"SessionStart:startup","hookEvent":"SessionStart","content":""}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:01Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"edit1","name":"Edit","input":{"file_path":"/a.go"}},{"type":"tool_use","id":"bash1","name":"Bash","input":{"command":"rm -rf /"}}]}}
+{"type":"attachment","timestamp":"2025-01-17T10:00:02Z","attachment":{"type":"hook_success","hookName":"PostToolUse:Edit","hookEvent":"PostToolUse","toolUseID":"edit1"}}
+{"type":"attachment","timestamp":"2025-01-17T10:00:02Z","attachment":{"type":"hook_non_blocking_error","hookName":"PostToolUse:Edit","hookEvent":"PostToolUse","toolUseID":"edit1","stderr":"gofmt: not found"}}
+{"type":"attachment","timestamp":"2025-01-17T10:00:02Z","attachment":{"type":"file","filename":"/a.go"}}
+{"type":"user","timestamp":"2025-01-17T10:00:03Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"edit1","content":"ok"},{"type":"tool_result","tool_use_id":"bash1","content":"PreToolUse:Bash hook error: [~/.claude/hooks/guard.sh]: rm -rf is not allowed","is_error":true}]}}
+{"type":"system","timestamp":"2025-01-17T10:00:04Z","content":"\u001b[1mPostToolUse:Write\u001b[22m [~/.claude/hooks/fmt.sh] completed successfully","level":"info"}
+{"type":"system","timestamp":"2025-01-17T10:00:05Z","content":"\u001b[1mPostToolUse:Write\u001b[22m [~/.claude/hooks/fmt.sh] failed with non-blocking status code 1: bad input","level":"warning"}
+{"type":"system","subtype":"stop_hook_summary","timestamp":"2025-01-17T10:00:06Z","hookCount":1,"hookInfos":[{"command":"~/.claude/hooks/notify.sh"}],"hookErrors":[],"preventedContinuation":false,"level":"suggestion"}
+{"type":"system","subtype":"stop_hook_summary","timestamp":"2025-01-17T10:00:07Z","hookCount":1,"hookInfos":[{"command":"~/.claude/hooks/notify.sh"}],"hookErrors":["exit 1"],"preventedContinuation":false,"level":"suggestion"}
+{"type":"system","timestamp":"2025-01-17T10:00:08Z","content":"Conversation compacted","level":"info"}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	type hookCounts struct {
+		event                       string
+		executions, errors, blocked int64
+	}
+	want := map[string]hookCounts{
+		"SessionStart:startup":                     {"SessionStart", 1, 0, 0},
+		"PostToolUse:Edit":                         {"PostToolUse", 2, 1, 0},
+		"PreToolUse:Bash ~/.claude/hooks/guard.sh": {"PreToolUse", 1, 0, 1},
+		"PostToolUse:Write ~/.claude/hooks/fmt.sh": {"PostToolUse", 2, 1, 0},
+		"Stop ~/.claude/hooks/notify.sh":           {"Stop", 2, 1, 0},
+	}
+	if len(result.Hooks) != len(want) {
+		t.Fatalf("Expected %d hooks, got %d", len(want), len(result.Hooks))
+	}
+	for _, hook := range result.Hooks {
+		key := hook.HookName
+		if hook.Command != "" {
+			key += " " + hook.Command
+		}
+		w, ok := want[key]
+		if !ok {
+			t.Errorf("Unexpected hook %q", key)
+			continue
+		}
+		assertEqual(t, key+" event", w.event, hook.Event)
+		assertEqual(t, key+" executions", w.executions, hook.ExecutionCount)
+		assertEqual(t, key+" errors", w.errors, hook.ErrorCount)
+		assertEqual(t, key+" blocked", w.blocked, hook.BlockedCount)
+	}
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// interruptedText starts the user message Claude Code writes when the user
+// stops a response with Esc, with " for tool use]" or "]" after it.
+const interruptedText = "[Request interrupted by user"
+
+// Results Claude Code gives a tool call the user rejected in a permission
+// prompt, with or without telling Claude what to do instead.
+var rejectedResultText = []string{
+	"The user doesn't want to proceed with this tool use",
+	"The user doesn't want to take this action right now",
+}
+
+// deniedResultPattern matches the result of a tool call refused by
+// permission settings: by a deny rule, or in non-interactive mode where
+// there's nobody to ask.
+var deniedResultPattern = regexp.MustCompile(`^(Permission to use .+ has been denied|Claude requested permissions to .+ but you haven't granted it yet)`)
+
+// countInterruption counts a user entry left by an interruption. Rejecting a
+// tool call also stops the response, so it usually counts as both.
+func (p *Parser) countInterruption(entry TranscriptEntry) {
+	if entry.Message == nil {
+		return
+	}
+	for _, content := range entry.Message.Content {
+		if content.Type == "text" && strings.HasPrefix(strings.TrimSpace(content.Text), interruptedText) {
+			p.result.Metrics.Interruptions++
+			return
+		}
+	}
+}
+
+// countRefusal counts a failed tool call whose result says the user rejected
+// it or permission settings denied it.
+func (p *Parser) countRefusal(tool *domain.SessionTool, result string) {
+	switch {
+	case hasAnyPrefix(result, rejectedResultText):
+		tool.RejectionCount++
+		p.result.Metrics.ToolRejections++
+	case deniedResultPattern.MatchString(result):
+		tool.PermissionDenialCount++
+		p.result.Metrics.PermissionDenials++
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTranscript_InterruptionsAndRefusals(t *testing.T) {
+	// An Edit rejected in the permission prompt, which also stops the
+	// response; a Bash call denied by a rule; a Bash call that failed on its
+	// own; then a response interrupted with Esc
+	content := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Fix it"}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:01Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"edit1","name":"Edit","input":{"file_path":"/a.go"}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:05Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"edit1","content":"The user doesn't want to proceed with this tool use. The tool use was rejected (eg. if it was a file edit, the new_string was NOT written to the file). STOP what you are doing and wait for the user to tell you how to proceed.","is_error":true}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:05Z","message":{"role":"user","content":[{"type":"text","text":"[Request interrupted by user for tool use]"}]}}
+{"type":"user","timestamp":"2025-01-17T10:01:00Z","message":{"role":"user","content":"Run the tests instead"}}
+{"type":"assistant","timestamp":"2025-01-17T10:01:01Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"bash1","name":"Bash","input":{"command":"rm -rf build"}},{"type":"tool_use","id":"bash2","name":"Bash","input":{"command":"go test ./..."}}]}}
+{"type":"user","timestamp":"2025-01-17T10:01:02Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"bash1","content":"Permission to use Bash with command rm -rf build has been denied.","is_error":true},{"type":"tool_result","tool_use_id":"bash2","content":"Exit code 1\nFAIL","is_error":true}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:01:10Z","message":{"id":"msg_3","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Looking at the failure"}]}}
+{"type":"user","timestamp":"2025-01-17T10:01:12Z","message":{"role":"user","content":"[Request interrupted by user]"}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	m := result.Metrics
+	assertEqual(t, "Interruptions", int64(2), m.Interruptions)
+	assertEqual(t, "ToolRejections", int64(1), m.ToolRejections)
+	assertEqual(t, "PermissionDenials", int64(1), m.PermissionDenials)
+	assertEqual(t, "ErrorCount", int64(3), m.ErrorCount)
+	// Interruption notes don't start turns
+	assertEqual(t, "turns", 2, len(result.Turns))
+
+	for _, tool := range result.Tools {
+		switch tool.ToolName {
+		case "Edit":
+			assertEqual(t, "Edit rejections", int64(1), tool.RejectionCount)
+			assertEqual(t, "Edit denials", int64(0), tool.PermissionDenialCount)
+		case "Bash":
+			assertEqual(t, "Bash rejections", int64(0), tool.RejectionCount)
+			assertEqual(t, "Bash denials", int64(1), tool.PermissionDenialCount)
+			assertEqual(t, "Bash errors", int64(2), tool.ErrorCount)
+		}
+	}
+}
+
+func TestParseTranscript_NonInteractiveDenial(t *testing.T) {
+	content := `{"type":"assistant","timestamp":"2025-01-17T10:00:01Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"write1","name":"Write","input":{"file_path":"/a.go"}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:02Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"write1","content":"Claude requested permissions to write to /a.go, but you haven't granted it yet.","is_error":true}]}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	assertEqual(t, "PermissionDenials", int64(1), result.Metrics.PermissionDenials)
+	assertEqual(t, "ToolRejections", int64(0), result.Metrics.ToolRejections)
+	assertEqual(t, "Interruptions", int64(0), result.Metrics.Interruptions)
+}
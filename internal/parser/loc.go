@@ -0,0 +1,190 @@
+package parser
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// LineChange is the number of lines a tool call added to and removed from a
+// file.
+type LineChange struct {
+	Path    string
+	Added   int64
+	Removed int64
+}
+
+// editInput holds the input fields of the tools that change file contents.
+type editInput struct {
+	FilePath     string `json:"file_path"`
+	NotebookPath string `json:"notebook_path"`
+	Content      string `json:"content"`
+	OldString    string `json:"old_string"`
+	NewString    string `json:"new_string"`
+	Edits        []struct {
+		OldString string `json:"old_string"`
+		NewString string `json:"new_string"`
+	} `json:"edits"`
+	NewSource string `json:"new_source"`
+	EditMode  string `json:"edit_mode"`
+}
+
+// measureLines counts the lines an Edit, MultiEdit, Write or NotebookEdit
+// call changed, from its input. ok is false for other tools.
+//
+// A Write is counted as adding all of its lines, since the content it
+// replaces isn't in the transcript. An edit counts the lines between the
+// first and last line that differ, so unchanged context around a change
+// isn't counted.
+func measureLines(tool string, input json.RawMessage) (LineChange, bool) {
+	if len(input) == 0 {
+		return LineChange{}, false
+	}
+	var in editInput
+	if err := json.Unmarshal(input, &in); err != nil {
+		return LineChange{}, false
+	}
+
+	change := LineChange{Path: in.FilePath}
+	switch tool {
+	case "Write":
+		change.Added = countLines(in.Content)
+	case "Edit":
+		change.Added, change.Removed = diffLines(in.OldString, in.NewString)
+	case "MultiEdit":
+		for _, edit := range in.Edits {
+			added, removed := diffLines(edit.OldString, edit.NewString)
+			change.Added += added
+			change.Removed += removed
+		}
+	case "NotebookEdit":
+		change.Path = in.NotebookPath
+		if in.EditMode != "delete" {
+			change.Added = countLines(in.NewSource)
+		}
+	default:
+		return LineChange{}, false
+	}
+	if change.Path == "" {
+		return LineChange{}, false
+	}
+	return change, true
+}
+
+// countLines returns the number of lines in s; a final line without a
+// trailing newline counts.
+func countLines(s string) int64 {
+	if s == "" {
+		return 0
+	}
+	n := int64(strings.Count(s, "\n"))
+	if !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
+
+// diffLines counts the lines removed from old and added in new, once the
+// lines both share at the start and end are set aside.
+func diffLines(old, new string) (added, removed int64) {
+	oldLines := splitLines(old)
+	newLines := splitLines(new)
+
+	start := 0
+	for start < len(oldLines) && start < len(newLines) && oldLines[start] == newLines[start] {
+		start++
+	}
+	oldEnd, newEnd := len(oldLines), len(newLines)
+	for oldEnd > start && newEnd > start && oldLines[oldEnd-1] == newLines[newEnd-1] {
+		oldEnd--
+		newEnd--
+	}
+	return int64(newEnd - start), int64(oldEnd - start)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// languages maps file extensions, and a few well-known file names, to the
+// language reported for them.
+var languages = map[string]string{
+	".go":         "Go",
+	".py":         "Python",
+	".js":         "JavaScript",
+	".jsx":        "JavaScript",
+	".mjs":        "JavaScript",
+	".cjs":        "JavaScript",
+	".ts":         "TypeScript",
+	".tsx":        "TypeScript",
+	".rs":         "Rust",
+	".java":       "Java",
+	".kt":         "Kotlin",
+	".swift":      "Swift",
+	".c":          "C",
+	".h":          "C",
+	".cc":         "C++",
+	".cpp":        "C++",
+	".hpp":        "C++",
+	".cs":         "C#",
+	".rb":         "Ruby",
+	".php":        "PHP",
+	".scala":      "Scala",
+	".ex":         "Elixir",
+	".exs":        "Elixir",
+	".erl":        "Erlang",
+	".hs":         "Haskell",
+	".lua":        "Lua",
+	".nix":        "Nix",
+	".sh":         "Shell",
+	".bash":       "Shell",
+	".zsh":        "Shell",
+	".fish":       "Shell",
+	".sql":        "SQL",
+	".html":       "HTML",
+	".templ":      "Templ",
+	".css":        "CSS",
+	".scss":       "CSS",
+	".vue":        "Vue",
+	".svelte":     "Svelte",
+	".json":       "JSON",
+	".yaml":       "YAML",
+	".yml":        "YAML",
+	".toml":       "TOML",
+	".xml":        "XML",
+	".md":         "Markdown",
+	".mdx":        "Markdown",
+	".proto":      "Protobuf",
+	".tf":         "Terraform",
+	".ipynb":      "Jupyter Notebook",
+	"Dockerfile":  "Dockerfile",
+	"Makefile":    "Makefile",
+	"go.mod":      "Go",
+	".cmake":      "CMake",
+	".gradle":     "Gradle",
+	".dart":       "Dart",
+	".zig":        "Zig",
+	".ml":         "OCaml",
+	".clj":        "Clojure",
+	".r":          "R",
+	".jl":         "Julia",
+	".pl":         "Perl",
+	".gleam":      "Gleam",
+	".sol":        "Solidity",
+	".graphql":    "GraphQL",
+	".gql":        "GraphQL",
+	".dockerfile": "Dockerfile",
+}
+
+// Language returns the language of a file from its name, or "" when it
+// isn't known.
+func Language(path string) string {
+	base := filepath.Base(path)
+	if language, ok := languages[base]; ok {
+		return language
+	}
+	return languages[strings.ToLower(filepath.Ext(base))]
+}
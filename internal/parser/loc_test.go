@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMeasureLines(t *testing.T) {
+	tests := []struct {
+		name           string
+		tool           string
+		input          string
+		added, removed int64
+		ok             bool
+	}{
+		{"write", "Write", `{"file_path":"/a.go","content":"package a\n\nfunc A() {}\n"}`, 3, 0, true},
+		{"write without trailing newline", "Write", `{"file_path":"/a.go","content":"a\nb"}`, 2, 0, true},
+		{"edit with shared context", "Edit", `{"file_path":"/a.go","old_string":"a\nb\nc\nd","new_string":"a\nB\nB2\nd"}`, 2, 2, true},
+		{"pure insertion", "Edit", `{"file_path":"/a.go","old_string":"a\nd","new_string":"a\nb\nc\nd"}`, 2, 0, true},
+		{"multi edit", "MultiEdit", `{"file_path":"/a.go","edits":[{"old_string":"x","new_string":"y"},{"old_string":"p\nq","new_string":""}]}`, 1, 3, true},
+		{"notebook insert", "NotebookEdit", `{"notebook_path":"/n.ipynb","new_source":"x = 1\ny = 2","edit_mode":"insert"}`, 2, 0, true},
+		{"notebook delete", "NotebookEdit", `{"notebook_path":"/n.ipynb","new_source":"","edit_mode":"delete"}`, 0, 0, true},
+		{"read", "Read", `{"file_path":"/a.go"}`, 0, 0, false},
+		{"no path", "Write", `{"content":"a"}`, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change, ok := measureLines(tt.tool, json.RawMessage(tt.input))
+			assertEqual(t, "ok", tt.ok, ok)
+			assertEqual(t, "added", tt.added, change.Added)
+			assertEqual(t, "removed", tt.removed, change.Removed)
+		})
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := map[string]string{
+		"/src/main.go":         "Go",
+		"/web/App.TSX":         "TypeScript",
+		"/repo/Dockerfile":     "Dockerfile",
+		"/repo/README.md":      "Markdown",
+		"/repo/data.unknown":   "",
+		"/repo/no-extension":   "",
+		"/repo/scripts/run.sh": "Shell",
+	}
+	for path, want := range tests {
+		assertEqual(t, path, want, Language(path))
+	}
+}
+
+func TestParseTranscript_LineChanges(t *testing.T) {
+	// A new file written, then edited twice, and a read that changes nothing
+	content := `{"type":"assistant","timestamp":"2025-01-17T10:00:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"w1","name":"Write","input":{"file_path":"/src/a.go","content":"package a\n\nfunc A() {}\n"}}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:01Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"e1","name":"Edit","input":{"file_path":"/src/a.go","old_string":"func A() {}","new_string":"func A() {\n\treturn\n}"}},{"type":"tool_use","id":"e2","name":"Edit","input":{"file_path":"/src/a.go","old_string":"package a\n","new_string":""}}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:02Z","message":{"id":"msg_3","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"r1","name":"Read","input":{"file_path":"/src/a.go"}}]}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	assertEqual(t, "metrics.LinesAdded", int64(3+3), result.Metrics.LinesAdded)
+	assertEqual(t, "metrics.LinesRemoved", int64(1+1), result.Metrics.LinesRemoved)
+
+	for _, file := range result.Files {
+		assertEqual(t, file.Operation+" language", "Go", file.Language)
+		switch file.Operation {
+		case OperationWrite:
+			assertEqual(t, "write.LinesAdded", int64(3), file.LinesAdded)
+		case OperationEdit:
+			assertEqual(t, "edit.OperationCount", int64(2), file.OperationCount)
+			assertEqual(t, "edit.LinesAdded", int64(3), file.LinesAdded)
+			assertEqual(t, "edit.LinesRemoved", int64(2), file.LinesRemoved)
+		case OperationRead:
+			assertEqual(t, "read.LinesAdded", int64(0), file.LinesAdded)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SessionInfo identifies the session a transcript belongs to.
+type SessionInfo struct {
+	SessionID string
+	Cwd       string
+}
+
+// ReadSessionInfo reads the session ID and working directory recorded on the
+// entries of a transcript, stopping at the first entry that has both. The
+// session ID falls back to the file name, which Claude Code sets to it. Cwd is
+// empty when no entry records one.
+func ReadSessionInfo(path string) (*SessionInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	info := &SessionInfo{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		var entry struct {
+			SessionID string `json:"sessionId"`
+			Cwd       string `json:"cwd"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if info.SessionID == "" {
+			info.SessionID = entry.SessionID
+		}
+		if info.Cwd == "" {
+			info.Cwd = entry.Cwd
+		}
+		if info.SessionID != "" && info.Cwd != "" {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	if info.SessionID == "" {
+		info.SessionID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return info, nil
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// commandNamePattern matches the tag Claude Code puts in the user message of
+// a slash command, e.g. <command-name>/compact</command-name>. Older
+// versions leave out the slash.
+var commandNamePattern = regexp.MustCompile(`<command-name>\s*(.*?)\s*</command-name>`)
+
+// builtinSlashCommands are the commands Claude Code ships with. Any other
+// command comes from a .claude/commands directory or a plugin, except for
+// MCP prompts, which are named /mcp__<server>__<prompt>.
+var builtinSlashCommands = map[string]bool{
+	"/add-dir": true, "/agents": true, "/bashes": true, "/bug": true,
+	"/clear": true, "/compact": true, "/config": true, "/context": true,
+	"/cost": true, "/doctor": true, "/exit": true, "/export": true,
+	"/help": true, "/hooks": true, "/ide": true, "/init": true,
+	"/install-github-app": true, "/login": true, "/logout": true, "/mcp": true,
+	"/memory": true, "/model": true, "/output-style": true, "/permissions": true,
+	"/plugin": true, "/pr-comments": true, "/privacy-settings": true,
+	"/release-notes": true, "/resume": true, "/review": true, "/rewind": true,
+	"/sandbox": true, "/security-review": true, "/status": true,
+	"/statusline": true, "/terminal-setup": true, "/todos": true,
+	"/upgrade": true, "/usage": true, "/vim": true,
+}
+
+// countSlashCommands counts the slash commands named in a user entry.
+func (p *Parser) countSlashCommands(entry TranscriptEntry) {
+	if entry.Message == nil {
+		return
+	}
+	for _, content := range entry.Message.Content {
+		if content.Type != "text" {
+			continue
+		}
+		for _, m := range commandNamePattern.FindAllStringSubmatch(content.Text, -1) {
+			if name := m[1]; name != "" {
+				p.addSlashCommand("/" + strings.TrimPrefix(name, "/"))
+			}
+		}
+	}
+}
+
+func (p *Parser) addSlashCommand(name string) {
+	for _, cmd := range p.result.SlashCommands {
+		if cmd.Command == name {
+			cmd.InvocationCount++
+			return
+		}
+	}
+	p.result.SlashCommands = append(p.result.SlashCommands, &domain.SessionSlashCommand{
+		SessionID:       p.sessionID,
+		Command:         name,
+		Kind:            slashCommandKind(name),
+		InvocationCount: 1,
+	})
+}
+
+func slashCommandKind(name string) string {
+	switch {
+	case strings.HasPrefix(name, "/mcp__"):
+		return domain.SlashCommandMCP
+	case builtinSlashCommands[name]:
+		return domain.SlashCommandBuiltin
+	default:
+		return domain.SlashCommandCustom
+	}
+}
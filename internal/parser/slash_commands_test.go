@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+func TestParseTranscript_SlashCommands(t *testing.T) {
+	// A custom command run twice, /compact, an MCP prompt and a command from
+	// an older transcript without the slash
+	content := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"<command-message>review-pr is running…</command-message>\n<command-name>/review-pr</command-name>\n<command-args>123</command-args>"}}
+{"type":"user","timestamp":"2025-01-17T10:00:01Z","isMeta":true,"message":{"role":"user","content":[{"type":"text","text":"Review pull request 123"}]}}
+{"type":"user","timestamp":"2025-01-17T10:05:00Z","message":{"role":"user","content":"<command-name>/compact</command-name>\n<command-message>compact</command-message>\n<command-args></command-args>"}}
+{"type":"user","timestamp":"2025-01-17T10:06:00Z","message":{"role":"user","content":"<command-message>review-pr is running…</command-message>\n<command-name>/review-pr</command-name>\n<command-args>124</command-args>"}}
+{"type":"user","timestamp":"2025-01-17T10:07:00Z","message":{"role":"user","content":"<command-name>/mcp__github__triage</command-name>"}}
+{"type":"user","timestamp":"2025-01-17T10:08:00Z","message":{"role":"user","content":[{"type":"text","text":"<command-name>model</command-name>"}]}}
+{"type":"user","timestamp":"2025-01-17T10:09:00Z","message":{"role":"user","content":"What does <command-name> do?"}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	want := []domain.SessionSlashCommand{
+		{Command: "/review-pr", Kind: domain.SlashCommandCustom, InvocationCount: 2},
+		{Command: "/compact", Kind: domain.SlashCommandBuiltin, InvocationCount: 1},
+		{Command: "/mcp__github__triage", Kind: domain.SlashCommandMCP, InvocationCount: 1},
+		{Command: "/model", Kind: domain.SlashCommandBuiltin, InvocationCount: 1},
+	}
+	if len(result.SlashCommands) != len(want) {
+		t.Fatalf("Expected %d slash commands, got %d", len(want), len(result.SlashCommands))
+	}
+	for i, w := range want {
+		got := result.SlashCommands[i]
+		assertEqual(t, "command", w.Command, got.Command)
+		assertEqual(t, w.Command+" kind", w.Kind, got.Kind)
+		assertEqual(t, w.Command+" invocations", w.InvocationCount, got.InvocationCount)
+		assertEqual(t, w.Command+" session", "test-session", got.SessionID)
+	}
+}
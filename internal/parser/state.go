@@ -0,0 +1,205 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// StateVersion identifies the layout and meaning of saved parser state. Bump
+// it whenever parsing changes, so saved state is discarded and transcripts
+// are parsed again from the start.
+const StateVersion = 16
+
+// ErrStateVersion is returned when resuming state saved by another version
+// of the parser.
+var ErrStateVersion = errors.New("parser state version mismatch")
+
+// savedState is the serialized form of a Parser.
+type savedState struct {
+	Version          int                            `json:"version"`
+	SessionID        string                         `json:"session_id"`
+	Offset           int64                          `json:"offset"`
+	Metrics          *domain.SessionMetrics         `json:"metrics"`
+	Tools            map[string]*domain.SessionTool `json:"tools"`
+	Files            map[string]*domain.SessionFile `json:"files"`
+	Commands         []*domain.SessionCommand       `json:"commands"`
+	Subagents        []*domain.SessionSubagent      `json:"subagents"`
+	PendingSubagents []*pendingSubagent             `json:"pending_subagents"`
+	PendingTools     map[string]*pendingTool        `json:"pending_tools"`
+	Sidechains       map[string]json.RawMessage     `json:"sidechains,omitempty"`
+	Depth            int                            `json:"depth,omitempty"`
+	ModelUsage       []*domain.SessionModelUsage    `json:"model_usage"`
+	Requests         []*domain.RequestUsage         `json:"requests"`
+	Cache            cacheTracker                   `json:"cache"`
+	Turns            []*domain.SessionTurn          `json:"turns"`
+	SlashCommands    []*domain.SessionSlashCommand  `json:"slash_commands"`
+	Hooks            []*domain.SessionHook          `json:"hooks"`
+	Anomalies        []*domain.SessionParseAnomaly  `json:"anomalies"`
+	SeenMessages     []string                       `json:"seen_messages"` // oldest first
+	FirstTimestamp   *time.Time                     `json:"first_timestamp,omitempty"`
+	LastTimestamp    *time.Time                     `json:"last_timestamp,omitempty"`
+	ModelID          *string                        `json:"model_id,omitempty"`
+	CurrentModel     string                         `json:"current_model"`
+	CompactPending   bool                           `json:"compact_pending,omitempty"`
+}
+
+// MarshalState serializes everything the parser has accumulated. Requests are
+// saved grouped, so the state grows with the turns of the session rather than
+// with every request.
+func (p *Parser) MarshalState() ([]byte, error) {
+	sidechains := make(map[string]json.RawMessage, len(p.sidechains))
+	for key, sidechain := range p.sidechains {
+		data, err := sidechain.MarshalState()
+		if err != nil {
+			return nil, err
+		}
+		sidechains[key] = data
+	}
+
+	data, err := json.Marshal(savedState{
+		Version:          StateVersion,
+		SessionID:        p.sessionID,
+		Offset:           p.offset,
+		Metrics:          p.result.Metrics,
+		Tools:            p.toolCounts,
+		Files:            p.fileCounts,
+		Commands:         p.result.Commands,
+		Subagents:        p.result.Subagents,
+		PendingSubagents: p.pendingSubagents,
+		PendingTools:     p.pendingTools,
+		Sidechains:       sidechains,
+		Depth:            p.depth,
+		ModelUsage:       p.result.ModelUsage,
+		Requests:         groupRequests(p.result.Requests),
+		Cache:            p.cache,
+		Turns:            p.result.Turns,
+		SlashCommands:    p.result.SlashCommands,
+		Hooks:            p.result.Hooks,
+		Anomalies:        p.result.Anomalies,
+		SeenMessages:     p.seenOrder,
+		FirstTimestamp:   p.firstTimestamp,
+		LastTimestamp:    p.lastTimestamp,
+		ModelID:          p.modelID,
+		CurrentModel:     p.currentModel,
+		CompactPending:   p.compactPending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode parser state: %w", err)
+	}
+	return data, nil
+}
+
+// ResumeParser restores a parser from state saved by MarshalState.
+func ResumeParser(sessionID string, state []byte) (*Parser, error) {
+	var saved savedState
+	if err := json.Unmarshal(state, &saved); err != nil {
+		return nil, fmt.Errorf("failed to decode parser state: %w", err)
+	}
+	if saved.Version != StateVersion {
+		return nil, ErrStateVersion
+	}
+	if saved.SessionID != sessionID {
+		return nil, fmt.Errorf("parser state is for session %s", saved.SessionID)
+	}
+
+	p := NewParser(sessionID)
+	p.offset = saved.Offset
+	p.depth = saved.Depth
+	if saved.Metrics != nil {
+		p.result.Metrics = saved.Metrics
+	}
+	p.result.Commands = append(p.result.Commands, saved.Commands...)
+	p.result.Subagents = append(p.result.Subagents, saved.Subagents...)
+	p.result.ModelUsage = append(p.result.ModelUsage, saved.ModelUsage...)
+	p.result.Requests = append(p.result.Requests, saved.Requests...)
+	p.result.Turns = append(p.result.Turns, saved.Turns...)
+	p.result.SlashCommands = append(p.result.SlashCommands, saved.SlashCommands...)
+	p.result.Hooks = append(p.result.Hooks, saved.Hooks...)
+	p.result.Anomalies = append(p.result.Anomalies, saved.Anomalies...)
+	for key, tool := range saved.Tools {
+		p.toolCounts[key] = tool
+	}
+	for key, file := range saved.Files {
+		p.fileCounts[key] = file
+	}
+	p.pendingSubagents = saved.PendingSubagents
+	for id, pending := range saved.PendingTools {
+		p.pendingTools[id] = pending
+	}
+	for key, data := range saved.Sidechains {
+		sidechain, err := ResumeParser(sessionID, data)
+		if err != nil {
+			return nil, err
+		}
+		sidechain.agent = true
+		p.sidechains[key] = sidechain
+	}
+	for _, mu := range p.result.ModelUsage {
+		p.modelUsage[mu.ModelID] = mu
+	}
+	for _, id := range saved.SeenMessages {
+		p.seeMessage(id)
+	}
+	p.cache = saved.Cache
+	p.firstTimestamp = saved.FirstTimestamp
+	p.lastTimestamp = saved.LastTimestamp
+	p.modelID = saved.ModelID
+	p.compactPending = saved.CompactPending
+	if saved.CurrentModel != "" {
+		p.currentModel = saved.CurrentModel
+	}
+	return p, nil
+}
+
+// requestGroupInput is the range of input sizes, cache included, whose
+// requests are saved as one group. Long-context thresholds are multiples of
+// it, so either all the requests of a group pay the premium or none do.
+const requestGroupInput = 10_000
+
+// groupRequests sums requests by model, turn and input size, which is all
+// that pricing needs to know of them.
+func groupRequests(requests []*domain.RequestUsage) []*domain.RequestUsage {
+	type groupKey struct {
+		model     string
+		turn      int64
+		aggregate bool
+		size      int64
+	}
+	groups := make([]*domain.RequestUsage, 0)
+	index := make(map[groupKey]*domain.RequestUsage)
+	for _, req := range requests {
+		count, largest := req.Requests, req.LargestInput
+		if count == 0 && !req.Aggregate {
+			count = 1
+			largest = req.TokenInput + req.TokenCacheRead + req.TokenCacheWrite
+		}
+		key := groupKey{model: req.ModelID, turn: req.Turn, aggregate: req.Aggregate}
+		if !req.Aggregate {
+			// Sizes just over a multiple of requestGroupInput start a group
+			key.size = (largest - 1) / requestGroupInput
+		}
+
+		group, ok := index[key]
+		if !ok {
+			g := *req
+			g.Requests = count
+			g.LargestInput = largest
+			index[key] = &g
+			groups = append(groups, &g)
+			continue
+		}
+		group.TokenInput += req.TokenInput
+		group.TokenOutput += req.TokenOutput
+		group.TokenCacheRead += req.TokenCacheRead
+		group.TokenCacheWrite += req.TokenCacheWrite
+		group.WebSearchRequests += req.WebSearchRequests
+		group.WebFetchRequests += req.WebFetchRequests
+		group.Requests += count
+		group.LargestInput = max(group.LargestInput, largest)
+	}
+	return groups
+}
@@ -0,0 +1,291 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// stateTranscript splits a response over two lines sharing a message ID and
+// starts a Task before its result, so resuming between them must remember
+// both the counted message and the pending sub-agent.
+var stateTranscript = []string{
+	`{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":[{"type":"text","text":"Hello"}]}}`,
+	`{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Searching."}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}`,
+	`{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"description":"Find files","subagent_type":"Explore","prompt":"Search"}}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}`,
+	`{"type":"user","timestamp":"2025-01-17T10:00:10Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":"Found files"}]},"toolUseResult":{"status":"completed","totalDurationMs":3000,"totalTokens":5000,"totalToolUseCount":3,"usage":{"input_tokens":4000,"output_tokens":500,"cache_read_input_tokens":400,"cache_creation_input_tokens":100}}}`,
+	`{"type":"assistant","timestamp":"2025-01-17T10:00:15Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"read1","name":"Read","input":{"file_path":"/a.go"}}],"usage":{"input_tokens":10,"output_tokens":20,"cache_read_input_tokens":150,"cache_creation_input_tokens":5}}}`,
+}
+
+func writeTranscript(t *testing.T, path string, lines []string, partial string) int64 {
+	t.Helper()
+	content := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content+partial), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+	return int64(len(content))
+}
+
+func TestParser_ResumeMatchesFullParse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+
+	// The trailing half line is still being written and must be left unread
+	next := stateTranscript[2]
+	offset := writeTranscript(t, path, stateTranscript[:2], next[:len(next)/2])
+
+	p := NewParser("test-session")
+	if err := p.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	assertEqual(t, "offset", offset, p.Offset())
+
+	state, err := p.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState failed: %v", err)
+	}
+	resumed, err := ResumeParser("test-session", state)
+	if err != nil {
+		t.Fatalf("ResumeParser failed: %v", err)
+	}
+
+	writeTranscript(t, path, stateTranscript, "")
+	if err := resumed.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	got := resumed.Result()
+
+	want, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	assertEqual(t, "metrics.TokenInput", want.Metrics.TokenInput, got.Metrics.TokenInput)
+	assertEqual(t, "metrics.TokenOutput", want.Metrics.TokenOutput, got.Metrics.TokenOutput)
+	assertEqual(t, "metrics.TokenCacheRead", want.Metrics.TokenCacheRead, got.Metrics.TokenCacheRead)
+	assertEqual(t, "metrics.MessageCountUser", want.Metrics.MessageCountUser, got.Metrics.MessageCountUser)
+	assertEqual(t, "metrics.MessageCountAssistant", want.Metrics.MessageCountAssistant, got.Metrics.MessageCountAssistant)
+	assertEqual(t, "metrics.TurnCount", want.Metrics.TurnCount, got.Metrics.TurnCount)
+	assertEqual(t, "requests", len(want.Requests), len(got.Requests))
+	assertEqual(t, "tools", len(want.Tools), len(got.Tools))
+	assertEqual(t, "files", len(want.Files), len(got.Files))
+	assertEqual(t, "model usage", len(want.ModelUsage), len(got.ModelUsage))
+	if len(got.Subagents) != 1 {
+		t.Fatalf("Expected 1 subagent, got %d", len(got.Subagents))
+	}
+	assertEqual(t, "subagent.AgentType", "Explore", got.Subagents[0].AgentType)
+	if !got.StartedAt.Equal(*want.StartedAt) || !got.EndedAt.Equal(*want.EndedAt) {
+		t.Errorf("Expected %v-%v, got %v-%v", want.StartedAt, want.EndedAt, got.StartedAt, got.EndedAt)
+	}
+}
+
+func TestParser_ParseFileFinal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	last := stateTranscript[len(stateTranscript)-1]
+	writeTranscript(t, path, stateTranscript[:len(stateTranscript)-1], last)
+
+	// A running session may still be writing the last line
+	p := NewParser("test-session")
+	if err := p.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	assertEqual(t, "tools while running", 1, len(p.Result().Tools))
+
+	// Once it ended, the line is complete without its newline
+	if err := p.ParseFileFinal(path); err != nil {
+		t.Fatalf("ParseFileFinal failed: %v", err)
+	}
+	got := p.Result()
+
+	want, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+	assertEqual(t, "offset", int64(len(strings.Join(stateTranscript, "\n"))), p.Offset())
+	assertEqual(t, "tools", 2, len(got.Tools))
+	assertEqual(t, "metrics.TokenInput", want.Metrics.TokenInput, got.Metrics.TokenInput)
+	assertEqual(t, "requests", len(want.Requests), len(got.Requests))
+}
+
+func TestParser_RewrittenTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	writeTranscript(t, path, stateTranscript, "")
+
+	p := NewParser("test-session")
+	if err := p.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+
+	// A shorter file was rewritten, so it's parsed again from the start
+	offset := writeTranscript(t, path, stateTranscript[:1], "")
+	if err := p.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	assertEqual(t, "offset", offset, p.Offset())
+	result := p.Result()
+	assertEqual(t, "metrics.MessageCountUser", 1, result.Metrics.MessageCountUser)
+	assertEqual(t, "metrics.TokenInput", int64(0), result.Metrics.TokenInput)
+}
+
+func TestResumeParser_VersionMismatch(t *testing.T) {
+	state := []byte(`{"version":0,"session_id":"test-session"}`)
+	if _, err := ResumeParser("test-session", state); err != ErrStateVersion {
+		t.Errorf("Expected ErrStateVersion, got %v", err)
+	}
+}
+
+func TestParser_ResumeKeepsSidechains(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	lines := []string{
+		`{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"m1","role":"assistant","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"subagent_type":"Explore","prompt":"A"}}]}}`,
+		`{"type":"assistant","isSidechain":true,"timestamp":"2025-01-17T10:00:10Z","message":{"id":"s1","role":"assistant","content":[{"type":"tool_use","id":"read1","name":"Read","input":{"file_path":"/a.go"}}]}}`,
+		`{"type":"user","timestamp":"2025-01-17T10:01:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":"Done"}]},"toolUseResult":{"status":"completed","totalTokens":1000,"usage":{"input_tokens":800,"output_tokens":200,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}`,
+	}
+	writeTranscript(t, path, lines[:2], "")
+
+	p := NewParser("test-session")
+	if err := p.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	state, err := p.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState failed: %v", err)
+	}
+	resumed, err := ResumeParser("test-session", state)
+	if err != nil {
+		t.Fatalf("ResumeParser failed: %v", err)
+	}
+
+	writeTranscript(t, path, lines, "")
+	if err := resumed.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	got := resumed.Result()
+
+	if len(got.Subagents) != 1 {
+		t.Fatalf("Expected 1 subagent, got %d", len(got.Subagents))
+	}
+	if len(got.Subagents[0].Tools) != 1 || got.Subagents[0].Tools[0].ToolName != "Read" {
+		t.Errorf("Expected the agent's Read call to survive resuming, got %+v", got.Subagents[0].Tools)
+	}
+}
+
+func TestParser_ResumeGroupsRequests(t *testing.T) {
+	assistant := func(id string, input, cacheRead, cacheWrite int64) string {
+		return fmt.Sprintf(`{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":%q,"role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Working."}],"usage":{"input_tokens":%d,"output_tokens":100,"cache_read_input_tokens":%d,"cache_creation_input_tokens":%d}}}`,
+			id, input, cacheRead, cacheWrite)
+	}
+	// Requests on either side of the long-context threshold, with a cache
+	// write left unread when the state is saved
+	lines := []string{
+		`{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":[{"type":"text","text":"Hello"}]}}`,
+		assistant("msg_1", 150000, 0, 1000),
+		assistant("msg_2", 150000, 1000, 2000),
+		assistant("msg_3", 195000, 3000, 0),
+		assistant("msg_4", 250000, 0, 500),
+		`{"type":"user","timestamp":"2025-01-17T10:01:00Z","message":{"role":"user","content":[{"type":"text","text":"Again"}]}}`,
+		assistant("msg_5", 10, 500, 0),
+	}
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	writeTranscript(t, path, lines[:5], "")
+
+	p := NewParser("test-session")
+	if err := p.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	state, err := p.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState failed: %v", err)
+	}
+	resumed, err := ResumeParser("test-session", state)
+	if err != nil {
+		t.Fatalf("ResumeParser failed: %v", err)
+	}
+	writeTranscript(t, path, lines, "")
+	if err := resumed.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	got := resumed.Result()
+
+	want, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+	// The two requests under 200K are saved as one
+	assertEqual(t, "requests", len(want.Requests)-1, len(got.Requests))
+	assertEqual(t, "TokenCacheWasted", want.Metrics.TokenCacheWasted, got.Metrics.TokenCacheWasted)
+
+	cacheRead, cacheWrite := 0.30, 3.75
+	longInput, longOutput, threshold := 6.00, 22.50, int64(200000)
+	pricing := &domain.ModelPricing{
+		InputPerMillion:             3.00,
+		OutputPerMillion:            15.00,
+		CacheReadPerMillion:         &cacheRead,
+		CacheWritePerMillion:        &cacheWrite,
+		LongContextInputPerMillion:  &longInput,
+		LongContextOutputPerMillion: &longOutput,
+		LongContextThreshold:        &threshold,
+	}
+	cost := func(requests []*domain.RequestUsage) float64 {
+		var total float64
+		for _, req := range requests {
+			total += pricing.CalculateRequestCost(req)
+		}
+		return total
+	}
+	if math.Abs(cost(want.Requests)-cost(got.Requests)) > 0.000001 {
+		t.Errorf("Expected cost %.6f, got %.6f", cost(want.Requests), cost(got.Requests))
+	}
+}
+
+func TestParser_SeenMessagesBounded(t *testing.T) {
+	var lines []string
+	for i := range maxSeenMessages + 10 {
+		lines = append(lines, fmt.Sprintf(`{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"msg_%d","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Working."}],"usage":{"input_tokens":10,"output_tokens":1}}}`, i))
+	}
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	writeTranscript(t, path, lines, "")
+
+	p := NewParser("test-session")
+	if err := p.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	assertEqual(t, "seen messages", maxSeenMessages, len(p.seenMessages))
+
+	state, err := p.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState failed: %v", err)
+	}
+	resumed, err := ResumeParser("test-session", state)
+	if err != nil {
+		t.Fatalf("ResumeParser failed: %v", err)
+	}
+
+	// The last message repeated on a later line is still counted once
+	writeTranscript(t, path, append(lines, lines[len(lines)-1]), "")
+	if err := resumed.ParseFile(path); err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	assertEqual(t, "TokenInput", int64(10*len(lines)), resumed.Result().Metrics.TokenInput)
+	assertEqual(t, "seen messages", maxSeenMessages, len(resumed.seenOrder))
+}
+
+func TestParser_ResumeKeepsDepth(t *testing.T) {
+	agent := NewParser("test-session").newSubagentParser()
+	agent.sidechains["task1"] = agent.newSubagentParser()
+
+	state, err := agent.MarshalState()
+	if err != nil {
+		t.Fatalf("MarshalState failed: %v", err)
+	}
+	resumed, err := ResumeParser("test-session", state)
+	if err != nil {
+		t.Fatalf("ResumeParser failed: %v", err)
+	}
+	assertEqual(t, "depth", 1, resumed.depth)
+	assertEqual(t, "sidechain depth", 2, resumed.sidechains["task1"].depth)
+}
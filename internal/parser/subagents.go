@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// maxSubagentDepth bounds how deep sub-agents started by sub-agents are
+// followed into their transcripts.
+const maxSubagentDepth = 4
+
+// pendingSubagent is a Task or Skill call waiting for its result.
+type pendingSubagent struct {
+	ToolUseID   string
+	AgentType   string
+	AgentKind   string // "task" or "skill"
+	Description *string
+	Model       *string
+}
+
+// completeSubagent pairs a user entry carrying a toolUseResult with the
+// sub-agent call it answers, and returns the finished sub-agent.
+//
+// Calls are matched by the tool_use_id of the entry's tool_result, so agents
+// running in parallel get their own results whatever order they finish in.
+// Older transcripts don't name the call; their result goes to the oldest
+// pending agent, if it looks like an agent's result at all.
+func (p *Parser) completeSubagent(entry TranscriptEntry) *domain.SessionSubagent {
+	var toolUseResult ToolUseResult
+	if err := json.Unmarshal(entry.ToolUseResultData, &toolUseResult); err != nil {
+		return nil
+	}
+
+	i := -1
+	paired := false
+	for _, content := range entry.Message.Content {
+		if content.Type != "tool_result" || content.ToolUseIDRef == "" {
+			continue
+		}
+		paired = true
+		if i = p.pendingSubagentIndex(content.ToolUseIDRef); i >= 0 {
+			break
+		}
+	}
+	if !paired && len(p.pendingSubagents) > 0 && toolUseResult.fromAgent() {
+		i = 0
+	}
+	if i < 0 {
+		return nil
+	}
+	pending := p.pendingSubagents[i]
+	p.pendingSubagents = append(p.pendingSubagents[:i], p.pendingSubagents[i+1:]...)
+
+	subagent := &domain.SessionSubagent{
+		SessionID:       p.sessionID,
+		ToolUseID:       pending.ToolUseID,
+		AgentType:       pending.AgentType,
+		AgentKind:       pending.AgentKind,
+		Description:     pending.Description,
+		Model:           pending.Model,
+		TotalTokens:     toolUseResult.TotalTokens,
+		ToolUseCount:    toolUseResult.TotalToolUseCount,
+		TotalDurationMs: toolUseResult.TotalDurationMs,
+	}
+	if toolUseResult.AgentID != "" {
+		agentID := toolUseResult.AgentID
+		subagent.AgentID = &agentID
+	}
+	if toolUseResult.Usage != nil {
+		subagent.TokenInput = toolUseResult.Usage.InputTokens
+		subagent.TokenOutput = toolUseResult.Usage.OutputTokens
+		subagent.TokenCacheRead = toolUseResult.Usage.CacheReadInputTokens
+		subagent.TokenCacheWrite = toolUseResult.Usage.CacheCreationInputTokens
+	}
+	return subagent
+}
+
+func (p *Parser) pendingSubagentIndex(toolUseID string) int {
+	for i, pending := range p.pendingSubagents {
+		if pending.ToolUseID == toolUseID {
+			return i
+		}
+	}
+	return -1
+}
+
+// fromAgent reports whether a toolUseResult carries what a finished agent
+// reports, as opposed to the result of another tool.
+func (r ToolUseResult) fromAgent() bool {
+	return r.AgentID != "" || r.Usage != nil || r.TotalTokens > 0
+}
+
+// recordSubagent adds a finished sub-agent to the result, along with the
+// sub-agents it started, read from its own transcript when one is found.
+// Each agent's reported usage is its own, so all of them count towards the
+// session's tokens.
+func (p *Parser) recordSubagent(sa *domain.SessionSubagent, at *time.Time) {
+	nested := p.readSubagentTranscript(sa)
+	for _, agent := range append([]*domain.SessionSubagent{sa}, nested...) {
+		p.result.Subagents = append(p.result.Subagents, agent)
+		p.addSubagentUsage(agent, at)
+	}
+}
+
+// addSubagentUsage counts a sub-agent's tokens towards the session, the
+// model it ran on (the parent's current model when the Task didn't override
+// it) and the current turn.
+func (p *Parser) addSubagentUsage(sa *domain.SessionSubagent, at *time.Time) {
+	usage := &Usage{
+		InputTokens:              sa.TokenInput,
+		OutputTokens:             sa.TokenOutput,
+		CacheReadInputTokens:     sa.TokenCacheRead,
+		CacheCreationInputTokens: sa.TokenCacheWrite,
+	}
+	metrics := p.result.Metrics
+	metrics.TokenInput += usage.InputTokens
+	metrics.TokenOutput += usage.OutputTokens
+	metrics.TokenCacheRead += usage.CacheReadInputTokens
+	metrics.TokenCacheWrite += usage.CacheCreationInputTokens
+
+	model := p.currentModel
+	if sa.Model != nil {
+		model = *sa.Model
+	}
+	sa.ModelID = &model
+	addModelUsage(p.result, p.modelUsage, p.sessionID, model, usage, 0)
+	turn := p.turn(at)
+	addTurnUsage(turn, usage)
+	p.addRequest(&domain.RequestUsage{
+		ModelID:         model,
+		Timestamp:       at,
+		TokenInput:      usage.InputTokens,
+		TokenOutput:     usage.OutputTokens,
+		TokenCacheRead:  usage.CacheReadInputTokens,
+		TokenCacheWrite: usage.CacheCreationInputTokens,
+		Aggregate:       true,
+		Turn:            turn.TurnIndex,
+	})
+}
+
+// readSubagentTranscript sets a sub-agent's tools, files and errors from its
+// own transcript and returns the sub-agents it started, parent first. Claude
+// Code writes the transcript to a file per agent; older versions write the
+// agent's entries into the session transcript, marked as sidechain.
+func (p *Parser) readSubagentTranscript(sa *domain.SessionSubagent) []*domain.SessionSubagent {
+	if p.depth >= maxSubagentDepth {
+		return nil
+	}
+
+	var child *Parser
+	if sa.AgentID != nil {
+		if path := p.subagentTranscriptPath(*sa.AgentID); path != "" {
+			child = p.newSubagentParser()
+			if child.parseAll(path) != nil {
+				child = nil
+			}
+		}
+	}
+	if child == nil {
+		keys := []string{sa.ToolUseID}
+		if sa.AgentID != nil {
+			keys = append(keys, *sa.AgentID)
+		}
+		for _, key := range keys {
+			if sidechain, ok := p.sidechains[key]; ok && child == nil {
+				child = sidechain
+				delete(p.sidechains, key)
+			}
+		}
+	}
+	if child == nil {
+		return nil
+	}
+
+	parsed := child.Result()
+	sa.Tools = parsed.Tools
+	sa.Files = parsed.Files
+	sa.ErrorCount = parsed.Metrics.ErrorCount
+	for _, nested := range parsed.Subagents {
+		if nested.ParentToolUseID == nil {
+			parent := sa.ToolUseID
+			nested.ParentToolUseID = &parent
+		}
+	}
+	return parsed.Subagents
+}
+
+// subagentTranscriptPath finds the transcript of an agent started from the
+// transcript being parsed: in the session's subagents directory, or next to
+// it for older versions and agents started by agents. It returns "" when
+// there's none, e.g. for transcripts read from an archive.
+func (p *Parser) subagentTranscriptPath(agentID string) string {
+	if p.path == "" {
+		return ""
+	}
+	name := "agent-" + agentID + ".jsonl"
+	candidates := []string{
+		filepath.Join(strings.TrimSuffix(p.path, filepath.Ext(p.path)), "subagents", name),
+		filepath.Join(filepath.Dir(p.path), name),
+	}
+	for _, path := range candidates {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+func (p *Parser) newSubagentParser() *Parser {
+	child := NewParser(p.sessionID)
+	child.classifier = p.classifier
+	child.agent = true
+	child.depth = p.depth + 1
+	return child
+}
+
+// parseSidechain hands a sidechain entry of the session transcript to the
+// parser of the agent that wrote it. Entries that don't name their agent go
+// to the oldest running one.
+func (p *Parser) parseSidechain(entry TranscriptEntry, line []byte) {
+	key := entry.AgentID
+	if key == "" {
+		if len(p.pendingSubagents) == 0 {
+			return
+		}
+		key = p.pendingSubagents[0].ToolUseID
+	}
+	child, ok := p.sidechains[key]
+	if !ok {
+		child = p.newSubagentParser()
+		p.sidechains[key] = child
+	}
+	child.parseLine(line)
+}
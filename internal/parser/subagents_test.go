@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create transcript directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+}
+
+func TestParseTranscript_ParallelSubagents(t *testing.T) {
+	// Two agents run in parallel and finish in reverse order. A Bash result
+	// arriving while they run must not be taken for an agent's.
+	content := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Explore"}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"description":"First","subagent_type":"Explore","prompt":"A"}},{"type":"tool_use","id":"task2","name":"Task","input":{"description":"Second","subagent_type":"Plan","prompt":"B"}},{"type":"tool_use","id":"bash1","name":"Bash","input":{"command":"ls"}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:06Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"bash1","content":"README.md"}]},"toolUseResult":{"stdout":"README.md","stderr":"","interrupted":false}}
+{"type":"user","timestamp":"2025-01-17T10:00:10Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task2","content":"Planned"}]},"toolUseResult":{"status":"completed","totalTokens":200,"usage":{"input_tokens":150,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:00:20Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":"Explored"}]},"toolUseResult":{"status":"completed","totalTokens":1000,"usage":{"input_tokens":800,"output_tokens":200,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	writeFile(t, path, content)
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	if len(result.Subagents) != 2 {
+		t.Fatalf("Expected 2 subagents, got %d", len(result.Subagents))
+	}
+	assertEqual(t, "first finished", "Plan", result.Subagents[0].AgentType)
+	assertEqual(t, "first finished ToolUseID", "task2", result.Subagents[0].ToolUseID)
+	assertEqual(t, "first finished TotalTokens", int64(200), result.Subagents[0].TotalTokens)
+	assertEqual(t, "second finished", "Explore", result.Subagents[1].AgentType)
+	assertEqual(t, "second finished ToolUseID", "task1", result.Subagents[1].ToolUseID)
+	assertEqual(t, "second finished TotalTokens", int64(1000), result.Subagents[1].TotalTokens)
+}
+
+func TestParseTranscript_SubagentFallbackInCallOrder(t *testing.T) {
+	// Older transcripts don't name the call a result answers: results go to
+	// the agents in the order they were started
+	content := `{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"subagent_type":"Explore","prompt":"A"}},{"type":"tool_use","id":"task2","name":"Task","input":{"subagent_type":"Plan","prompt":"B"}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:10Z","message":{"role":"user","content":[{"type":"text","text":"Explored"}]},"toolUseResult":{"status":"completed","totalTokens":1000,"usage":{"input_tokens":800,"output_tokens":200,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:00:20Z","message":{"role":"user","content":[{"type":"text","text":"Planned"}]},"toolUseResult":{"status":"completed","totalTokens":200,"usage":{"input_tokens":150,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	writeFile(t, path, content)
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	if len(result.Subagents) != 2 {
+		t.Fatalf("Expected 2 subagents, got %d", len(result.Subagents))
+	}
+	assertEqual(t, "first", "Explore", result.Subagents[0].AgentType)
+	assertEqual(t, "first TotalTokens", int64(1000), result.Subagents[0].TotalTokens)
+	assertEqual(t, "second", "Plan", result.Subagents[1].AgentType)
+	assertEqual(t, "second TotalTokens", int64(200), result.Subagents[1].TotalTokens)
+}
+
+func TestParseTranscript_SubagentTranscripts(t *testing.T) {
+	// The session starts agent a1, whose transcript is in the session's
+	// subagents directory; a1 starts agent a2, whose transcript is next to it
+	session := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Explore"}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"subagent_type":"Explore","prompt":"A"}}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:01:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":"Done"}]},"toolUseResult":{"status":"completed","agentId":"a1","totalTokens":1000,"totalToolUseCount":4,"usage":{"input_tokens":800,"output_tokens":200,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`
+	agent := `{"type":"user","isSidechain":true,"agentId":"a1","timestamp":"2025-01-17T10:00:06Z","message":{"role":"user","content":"A"}}
+{"type":"assistant","isSidechain":true,"agentId":"a1","timestamp":"2025-01-17T10:00:10Z","message":{"id":"s1","role":"assistant","content":[{"type":"tool_use","id":"read1","name":"Read","input":{"file_path":"/repo/main.go"}},{"type":"tool_use","id":"edit1","name":"Edit","input":{"file_path":"/repo/main.go","old_string":"a","new_string":"b\nc"}}],"usage":{"input_tokens":400,"output_tokens":100,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","isSidechain":true,"agentId":"a1","timestamp":"2025-01-17T10:00:11Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"read1","content":"package main"},{"type":"tool_result","tool_use_id":"edit1","content":"String not found","is_error":true}]}}
+{"type":"assistant","isSidechain":true,"agentId":"a1","timestamp":"2025-01-17T10:00:20Z","message":{"id":"s2","role":"assistant","content":[{"type":"tool_use","id":"task2","name":"Task","input":{"subagent_type":"Bash","prompt":"B"}}]}}
+{"type":"user","isSidechain":true,"agentId":"a1","timestamp":"2025-01-17T10:00:50Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task2","content":"Ran"}]},"toolUseResult":{"status":"completed","agentId":"a2","totalTokens":300,"usage":{"input_tokens":250,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`
+	nested := `{"type":"assistant","isSidechain":true,"agentId":"a2","timestamp":"2025-01-17T10:00:30Z","message":{"id":"n1","role":"assistant","content":[{"type":"tool_use","id":"grep1","name":"Grep","input":{"pattern":"TODO","path":"/repo"}}]}}
+{"type":"user","isSidechain":true,"agentId":"a2","timestamp":"2025-01-17T10:00:31Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"grep1","content":"none"}]}}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.jsonl")
+	writeFile(t, path, session)
+	writeFile(t, filepath.Join(dir, "session", "subagents", "agent-a1.jsonl"), agent)
+	writeFile(t, filepath.Join(dir, "session", "subagents", "agent-a2.jsonl"), nested)
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	if len(result.Subagents) != 2 {
+		t.Fatalf("Expected 2 subagents, got %d", len(result.Subagents))
+	}
+
+	a1 := result.Subagents[0]
+	assertEqual(t, "a1.ToolUseID", "task1", a1.ToolUseID)
+	if a1.AgentID == nil || *a1.AgentID != "a1" {
+		t.Errorf("Expected agent ID a1, got %v", a1.AgentID)
+	}
+	if a1.ParentToolUseID != nil {
+		t.Errorf("Expected no parent for a1, got %v", *a1.ParentToolUseID)
+	}
+	assertEqual(t, "a1.ErrorCount", int64(1), a1.ErrorCount)
+	assertEqual(t, "a1 tools", 3, len(a1.Tools))
+	for _, tool := range a1.Tools {
+		if tool.ToolName == "Edit" {
+			assertEqual(t, "a1 Edit errors", int64(1), tool.ErrorCount)
+		}
+	}
+	var edited bool
+	for _, file := range a1.Files {
+		if file.FilePath == "/repo/main.go" && file.Operation == OperationEdit {
+			edited = true
+			assertEqual(t, "a1 lines added", int64(2), file.LinesAdded)
+		}
+	}
+	if !edited {
+		t.Errorf("Expected a1 to have edited /repo/main.go, got %+v", a1.Files)
+	}
+
+	a2 := result.Subagents[1]
+	assertEqual(t, "a2.ToolUseID", "task2", a2.ToolUseID)
+	assertEqual(t, "a2.AgentType", "Bash", a2.AgentType)
+	if a2.ParentToolUseID == nil || *a2.ParentToolUseID != "task1" {
+		t.Errorf("Expected a2 parent task1, got %v", a2.ParentToolUseID)
+	}
+	assertEqual(t, "a2 tools", 1, len(a2.Tools))
+	assertEqual(t, "a2 tool", "Grep", a2.Tools[0].ToolName)
+
+	// The session's own tools don't include its agents' tools; each agent's
+	// reported tokens count once
+	assertEqual(t, "session tools", 1, len(result.Tools))
+	assertEqual(t, "metrics.TokenInput", int64(100+800+250), result.Metrics.TokenInput)
+	assertEqual(t, "metrics.TokenOutput", int64(50+200+50), result.Metrics.TokenOutput)
+	assertEqual(t, "metrics.ErrorCount", int64(0), result.Metrics.ErrorCount)
+}
+
+func TestParseTranscript_SidechainEntries(t *testing.T) {
+	// Older versions write an agent's entries into the session transcript,
+	// without naming the agent
+	content := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Explore"}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"m1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"subagent_type":"Explore","prompt":"A"}}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","isSidechain":true,"timestamp":"2025-01-17T10:00:06Z","message":{"role":"user","content":"A"}}
+{"type":"assistant","isSidechain":true,"timestamp":"2025-01-17T10:00:10Z","message":{"id":"s1","role":"assistant","content":[{"type":"tool_use","id":"glob1","name":"Glob","input":{"pattern":"*.go","path":"/repo"}}],"usage":{"input_tokens":400,"output_tokens":100,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","isSidechain":true,"timestamp":"2025-01-17T10:00:11Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"glob1","content":"main.go"}]}}
+{"type":"user","timestamp":"2025-01-17T10:01:00Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":"Done"}]},"toolUseResult":{"status":"completed","totalTokens":1000,"usage":{"input_tokens":800,"output_tokens":200,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	writeFile(t, path, content)
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	if len(result.Subagents) != 1 {
+		t.Fatalf("Expected 1 subagent, got %d", len(result.Subagents))
+	}
+	sa := result.Subagents[0]
+	assertEqual(t, "agent tools", 1, len(sa.Tools))
+	assertEqual(t, "agent tool", "Glob", sa.Tools[0].ToolName)
+	assertEqual(t, "agent files", 1, len(sa.Files))
+
+	// Sidechain entries are neither session messages nor session usage
+	assertEqual(t, "session tools", 1, len(result.Tools))
+	assertEqual(t, "metrics.MessageCountUser", int64(2), result.Metrics.MessageCountUser)
+	assertEqual(t, "metrics.TokenInput", int64(100+800), result.Metrics.TokenInput)
+}
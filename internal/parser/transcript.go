@@ -4,7 +4,11 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/emiliopalmerini/mclaude/internal/domain"
@@ -19,11 +23,35 @@ type ParsedTranscript struct {
 	Files     []*domain.SessionFile
 	Commands  []*domain.SessionCommand
 	Subagents []*domain.SessionSubagent
+	// ModelUsage breaks token usage down by model, in order of first appearance.
+	ModelUsage []*domain.SessionModelUsage
+	// Requests holds the usage of each API request, plus one aggregate entry
+	// per completed sub-agent, so costs can be priced request by request.
+	Requests []*domain.RequestUsage
+	// Turns splits the session at each user prompt, in transcript order.
+	Turns []*domain.SessionTurn
+	// SlashCommands and Hooks count each command and hook, in order of first use.
+	SlashCommands []*domain.SessionSlashCommand
+	Hooks         []*domain.SessionHook
+	// Anomalies counts the lines that were skipped or only partly understood.
+	Anomalies []*domain.SessionParseAnomaly
 }
 
+// unknownModelID labels usage recorded before any assistant message named its model.
+const unknownModelID = "unknown"
+
+// syntheticModelID is used by Claude Code for locally generated assistant
+// messages (e.g. API errors); it never carries billable usage.
+const syntheticModelID = "<synthetic>"
+
 type TranscriptEntry struct {
 	Type              string          `json:"type"`
 	Timestamp         string          `json:"timestamp,omitempty"`
+	Subtype           string          `json:"subtype,omitempty"`
+	IsMeta            bool            `json:"isMeta,omitempty"`
+	IsCompactSummary  bool            `json:"isCompactSummary,omitempty"`
+	IsSidechain       bool            `json:"isSidechain,omitempty"`
+	AgentID           string          `json:"agentId,omitempty"`
 	Model             string          `json:"model,omitempty"`
 	Message           *Message        `json:"message,omitempty"`
 	Usage             *Usage          `json:"usage,omitempty"`
@@ -32,25 +60,57 @@ type TranscriptEntry struct {
 }
 
 type Message struct {
-	Role    string    `json:"role"`
-	Content []Content `json:"content"`
-	Usage   *Usage    `json:"usage,omitempty"`
+	ID      string         `json:"id,omitempty"`
+	Role    string         `json:"role"`
+	Model   string         `json:"model,omitempty"`
+	Content MessageContent `json:"content"`
+	Usage   *Usage         `json:"usage,omitempty"`
+}
+
+// MessageContent is a message's list of content blocks. Claude Code writes
+// typed prompts as a plain string, which is read as a single text block.
+type MessageContent []Content
+
+func (c *MessageContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		*c = MessageContent{{Type: "text", Text: text}}
+		return nil
+	}
+	var blocks []Content
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return err
+	}
+	*c = blocks
+	return nil
 }
 
 type Content struct {
 	Type         string          `json:"type"`
 	Text         string          `json:"text,omitempty"`
+	Thinking     string          `json:"thinking,omitempty"` // In thinking blocks
 	ToolUseID    string          `json:"id,omitempty"`
 	ToolUseIDRef string          `json:"tool_use_id,omitempty"` // In tool_result entries
 	Name         string          `json:"name,omitempty"`
 	Input        json.RawMessage `json:"input,omitempty"`
+	// In tool_result entries: the result as a string or a list of text blocks
+	ResultContent json.RawMessage `json:"content,omitempty"`
+	IsError       bool            `json:"is_error,omitempty"`
 }
 
 type Usage struct {
-	InputTokens              int64 `json:"input_tokens"`
-	OutputTokens             int64 `json:"output_tokens"`
-	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
-	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	InputTokens              int64          `json:"input_tokens"`
+	OutputTokens             int64          `json:"output_tokens"`
+	CacheReadInputTokens     int64          `json:"cache_read_input_tokens"`
+	CacheCreationInputTokens int64          `json:"cache_creation_input_tokens"`
+	ServerToolUse            *ServerToolUse `json:"server_tool_use,omitempty"`
+}
+
+// ServerToolUse counts the tool requests the API ran itself while answering,
+// which are billed per request.
+type ServerToolUse struct {
+	WebSearchRequests int64 `json:"web_search_requests"`
+	WebFetchRequests  int64 `json:"web_fetch_requests"`
 }
 
 type ToolInput struct {
@@ -62,6 +122,11 @@ type ToolResult struct {
 	ExitCode *int `json:"exit_code,omitempty"`
 }
 
+// BashToolResult is the toolUseResult Claude Code writes for a Bash call.
+type BashToolResult struct {
+	Interrupted bool `json:"interrupted,omitempty"`
+}
+
 type SubagentToolInput struct {
 	Description  string `json:"description,omitempty"`
 	SubagentType string `json:"subagent_type,omitempty"`
@@ -77,127 +142,563 @@ type ToolUseResult struct {
 	TotalTokens       int64  `json:"totalTokens,omitempty"`
 	TotalToolUseCount int64  `json:"totalToolUseCount,omitempty"`
 	Usage             *Usage `json:"usage,omitempty"`
+	AgentID           string `json:"agentId,omitempty"`
 }
 
-type pendingSubagent struct {
-	agentType   string
-	agentKind   string // "task" or "skill"
-	description *string
-	model       *string
+// pendingTool is a tool_use waiting for its tool_result.
+type pendingTool struct {
+	Name      string
+	StartedAt *time.Time
 }
 
+// ParseTranscript parses a whole transcript, along with the transcripts of
+// the sub-agents it started.
 func ParseTranscript(sessionID, path string) (*ParsedTranscript, error) {
+	p := NewParser(sessionID)
+	if err := p.parseAll(path); err != nil {
+		return nil, err
+	}
+	return p.Result(), nil
+}
+
+// parseAll parses a whole transcript file, including a last line without a
+// trailing newline.
+func (p *Parser) parseAll(path string) error {
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open transcript: %w", err)
+		return fmt.Errorf("failed to open transcript: %w", err)
 	}
 	defer file.Close()
 
-	result := &ParsedTranscript{
-		Metrics: &domain.SessionMetrics{
-			SessionID: sessionID,
+	p.path = path
+	return p.read(file, true)
+}
+
+// ParseTranscriptReader parses a transcript from r, e.g. an archived copy
+// decompressed from transcript storage. Sub-agents' own transcripts aren't
+// read, except for the entries written into the session transcript.
+func ParseTranscriptReader(sessionID string, r io.Reader) (*ParsedTranscript, error) {
+	p := NewParser(sessionID)
+	if err := p.read(r, true); err != nil {
+		return nil, err
+	}
+	return p.Result(), nil
+}
+
+// Parser parses a transcript incrementally. It keeps the aggregates of the
+// lines read so far and the byte offset after them, so a transcript that
+// grows can be parsed again from where the last read stopped. Its state can
+// be saved and resumed by another process.
+type Parser struct {
+	sessionID string
+	path      string
+	offset    int64
+	// agent is set when parsing a sub-agent's transcript, whose entries are
+	// all sidechain entries; depth counts the agents above it.
+	agent bool
+	depth int
+
+	// result accumulates metrics, commands, sub-agents, per-model usage and
+	// requests; tools and files are kept in the maps below until Result.
+	result           *ParsedTranscript
+	toolCounts       map[string]*domain.SessionTool
+	fileCounts       map[string]*domain.SessionFile // key: filepath:operation
+	pendingSubagents []*pendingSubagent             // in call order
+	pendingTools     map[string]*pendingTool        // key: tool_use ID
+	classifier       *Classifier
+	modelUsage       map[string]*domain.SessionModelUsage
+	cache            cacheTracker
+	// seenMessages holds the IDs of the last messages whose usage was
+	// counted, oldest first in seenOrder.
+	seenMessages map[string]bool
+	seenOrder    []string
+
+	// sidechains parses the sidechain entries of the session transcript, per
+	// agent ID (or Task tool_use ID when entries don't name their agent).
+	sidechains map[string]*Parser
+
+	firstTimestamp *time.Time
+	lastTimestamp  *time.Time
+	modelID        *string
+	currentModel   string
+	// compactPending is set between a compaction boundary and its summary.
+	compactPending bool
+}
+
+func NewParser(sessionID string) *Parser {
+	return &Parser{
+		sessionID: sessionID,
+		result: &ParsedTranscript{
+			Metrics: &domain.SessionMetrics{
+				SessionID: sessionID,
+			},
+			Commands:      make([]*domain.SessionCommand, 0),
+			Subagents:     make([]*domain.SessionSubagent, 0),
+			ModelUsage:    make([]*domain.SessionModelUsage, 0),
+			Requests:      make([]*domain.RequestUsage, 0),
+			Turns:         make([]*domain.SessionTurn, 0),
+			SlashCommands: make([]*domain.SessionSlashCommand, 0),
+			Hooks:         make([]*domain.SessionHook, 0),
+			Anomalies:     make([]*domain.SessionParseAnomaly, 0),
 		},
-		Tools:     make([]*domain.SessionTool, 0),
-		Files:     make([]*domain.SessionFile, 0),
-		Commands:  make([]*domain.SessionCommand, 0),
-		Subagents: make([]*domain.SessionSubagent, 0),
+		toolCounts:   make(map[string]*domain.SessionTool),
+		fileCounts:   make(map[string]*domain.SessionFile),
+		pendingTools: make(map[string]*pendingTool),
+		classifier:   defaultClassifier,
+		modelUsage:   make(map[string]*domain.SessionModelUsage),
+		seenMessages: make(map[string]bool),
+		sidechains:   make(map[string]*Parser),
+		currentModel: unknownModelID,
 	}
+}
 
-	toolCounts := make(map[string]*domain.SessionTool)
-	fileCounts := make(map[string]*domain.SessionFile) // key: filepath:operation
-	pendingSubagents := make(map[string]*pendingSubagent)
+// maxSeenMessages bounds how many message IDs a parser remembers. The lines
+// repeating a message's usage are written close together, so only recent
+// messages can repeat.
+const maxSeenMessages = 100
+
+// seeMessage remembers that a message's usage was counted, forgetting the
+// oldest message once maxSeenMessages are remembered.
+func (p *Parser) seeMessage(id string) {
+	if len(p.seenOrder) == maxSeenMessages {
+		delete(p.seenMessages, p.seenOrder[0])
+		p.seenOrder = p.seenOrder[1:]
+	}
+	p.seenMessages[id] = true
+	p.seenOrder = append(p.seenOrder, id)
+}
 
-	scanner := bufio.NewScanner(file)
-	// Increase buffer size for large lines
-	buf := make([]byte, 0, 1024*1024)
-	scanner.Buffer(buf, 10*1024*1024)
+// addRequest adds the usage of an API request, or of a sub-agent's requests.
+func (p *Parser) addRequest(req *domain.RequestUsage) {
+	p.result.Requests = append(p.result.Requests, req)
+	p.cache.add(req)
+}
 
-	var firstTimestamp, lastTimestamp *time.Time
-	var modelID *string
+// defaultClassifier is shared by parsers that aren't given another one.
+var defaultClassifier = DefaultClassifier()
 
-	for scanner.Scan() {
-		line := scanner.Bytes()
-		if len(line) == 0 {
-			continue
-		}
+// SetClassifier sets how tool calls are mapped to file operations, e.g. to
+// add user-defined rules to the defaults.
+func (p *Parser) SetClassifier(c *Classifier) {
+	p.classifier = c
+	for _, sidechain := range p.sidechains {
+		sidechain.SetClassifier(c)
+	}
+}
 
-		var entry TranscriptEntry
-		if err := json.Unmarshal(line, &entry); err != nil {
-			// Skip malformed lines
-			continue
+// Offset returns the number of transcript bytes parsed so far.
+func (p *Parser) Offset() int64 {
+	return p.offset
+}
+
+// ParseFile parses the lines appended to the transcript since the last read.
+// A trailing line without a newline is still being written and is left for
+// the next read. If the file is shorter than what was already parsed, it was
+// rewritten and is parsed again from the start.
+func (p *Parser) ParseFile(path string) error {
+	return p.parseFile(path, false)
+}
+
+// ParseFileFinal is ParseFile for a transcript that is complete, e.g. once
+// the session ended: a trailing line without a newline is parsed as well.
+func (p *Parser) ParseFileFinal(path string) error {
+	return p.parseFile(path, true)
+}
+
+func (p *Parser) parseFile(path string, final bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat transcript: %w", err)
+	}
+	if p.offset > info.Size() {
+		classifier := p.classifier
+		*p = *NewParser(p.sessionID)
+		p.classifier = classifier
+	}
+	p.path = path
+
+	if _, err := file.Seek(p.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek transcript: %w", err)
+	}
+	return p.read(file, final)
+}
+
+// read parses the lines of r. The last line is only parsed without a
+// trailing newline if final is set.
+func (p *Parser) read(r io.Reader, final bool) error {
+	reader := bufio.NewReaderSize(r, 1024*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if final && len(line) > 0 {
+				p.offset += int64(len(line))
+				p.parseLine(line)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading transcript: %w", err)
 		}
+		p.offset += int64(len(line))
+		p.parseLine(line[:len(line)-1])
+	}
+}
 
-		// Track timestamps
-		if entry.Timestamp != "" {
-			t, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
-			if err == nil {
-				if firstTimestamp == nil {
-					firstTimestamp = &t
-				}
-				lastTimestamp = &t
+func (p *Parser) parseLine(line []byte) {
+	p.result.Metrics.TranscriptLines++
+	if len(line) == 0 {
+		return
+	}
+
+	var entry TranscriptEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		// Skip malformed lines, keeping why they couldn't be read
+		p.addAnomaly(domain.AnomalyMalformedLine, err.Error())
+		return
+	}
+	p.checkEntry(entry)
+
+	// Sub-agents' entries belong to their own transcript, and their usage is
+	// counted from what the agent reports when it finishes
+	if entry.IsSidechain && !p.agent {
+		p.parseSidechain(entry, line)
+		return
+	}
+
+	sessionID := p.sessionID
+	result := p.result
+
+	// Track timestamps
+	var entryTime *time.Time
+	if entry.Timestamp != "" {
+		t, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err == nil {
+			if p.firstTimestamp == nil {
+				p.firstTimestamp = &t
 			}
+			p.lastTimestamp = &t
+			entryTime = &t
 		}
+	}
 
-		// Process based on entry type
-		switch entry.Type {
-		case "user", "human":
-			result.Metrics.MessageCountUser++
-			// Check for toolUseResult (sub-agent completion data)
-			if len(entry.ToolUseResultData) > 0 && entry.Message != nil {
-				processSubagentResult(entry, sessionID, pendingSubagents, result)
+	p.countCompaction(entry)
+	p.countHooks(entry, line)
+
+	// Process based on entry type
+	switch entry.Type {
+	case "user", "human":
+		result.Metrics.MessageCountUser++
+		p.countInterruption(entry)
+		p.countSlashCommands(entry)
+		if prompt, ok := promptText(entry); ok {
+			p.startTurn(prompt, entryTime)
+		}
+		if entry.Message != nil {
+			p.pairToolResults(entry, entryTime)
+		}
+		// Check for toolUseResult (sub-agent completion data)
+		if len(entry.ToolUseResultData) > 0 && entry.Message != nil {
+			if sa := p.completeSubagent(entry); sa != nil {
+				p.recordSubagent(sa, entryTime)
 			}
-		case "assistant":
-			result.Metrics.MessageCountAssistant++
+		}
+	case "assistant":
+		result.Metrics.MessageCountAssistant++
+		if m := entryModel(entry); m != "" && m != syntheticModelID {
+			p.currentModel = m
 			// Capture model ID from assistant messages (use first occurrence)
-			if modelID == nil && entry.Model != "" {
-				m := entry.Model
-				modelID = &m
+			if p.modelID == nil {
+				p.modelID = &m
 			}
-			if entry.Message != nil {
-				processAssistantMessage(entry.Message, sessionID, toolCounts, fileCounts, pendingSubagents, result)
+		}
+		turn := p.turn(entryTime)
+		turn.MessageCount++
+		if entry.Message != nil {
+			p.processAssistantMessage(entry.Message, entryTime)
+			for _, content := range entry.Message.Content {
+				switch {
+				case content.Type == "tool_use" && content.Name != "":
+					turn.ToolCalls++
+					if content.ToolUseID != "" {
+						p.pendingTools[content.ToolUseID] = &pendingTool{Name: content.Name, StartedAt: entryTime}
+					}
+				case content.Type == "thinking" || content.Type == "redacted_thinking":
+					// Redacted blocks are encrypted, so only their count is known
+					result.Metrics.ThinkingBlocks++
+					result.Metrics.ThinkingTokens += estimateTokens(content.Thinking)
+				}
 			}
-		case "result":
-			// Tool results - check for errors
-			processToolResult(entry, result)
 		}
+	case "result":
+		// Tool results - check for errors
+		processToolResult(entry, result)
+	}
 
-		// Accumulate token usage (check both top-level and message-level usage)
-		usage := entry.Usage
-		if usage == nil && entry.Message != nil {
-			usage = entry.Message.Usage
+	// Accumulate token usage (check both top-level and message-level usage)
+	usage := entry.Usage
+	if usage == nil && entry.Message != nil {
+		usage = entry.Message.Usage
+	}
+	// Claude Code writes one line per content block of a response, each
+	// repeating the message's usage; count each API message once
+	if usage != nil && entry.Message != nil && entry.Message.ID != "" {
+		if p.seenMessages[entry.Message.ID] {
+			usage = nil
+		} else {
+			p.seeMessage(entry.Message.ID)
+		}
+	}
+	if usage != nil {
+		result.Metrics.TokenInput += usage.InputTokens
+		result.Metrics.TokenOutput += usage.OutputTokens
+		result.Metrics.TokenCacheRead += usage.CacheReadInputTokens
+		result.Metrics.TokenCacheWrite += usage.CacheCreationInputTokens
+		var webSearches, webFetches int64
+		if usage.ServerToolUse != nil {
+			webSearches = usage.ServerToolUse.WebSearchRequests
+			webFetches = usage.ServerToolUse.WebFetchRequests
+			result.Metrics.WebSearchRequests += webSearches
+			result.Metrics.WebFetchRequests += webFetches
 		}
-		if usage != nil {
-			result.Metrics.TokenInput += usage.InputTokens
-			result.Metrics.TokenOutput += usage.OutputTokens
-			result.Metrics.TokenCacheRead += usage.CacheReadInputTokens
-			result.Metrics.TokenCacheWrite += usage.CacheCreationInputTokens
+
+		var messages int64
+		if entry.Type == "assistant" {
+			messages = 1
 		}
+		addModelUsage(result, p.modelUsage, sessionID, p.currentModel, usage, messages)
+		turn := p.turn(entryTime)
+		addTurnUsage(turn, usage)
+		p.addRequest(&domain.RequestUsage{
+			ModelID:           p.currentModel,
+			Timestamp:         entryTime,
+			TokenInput:        usage.InputTokens,
+			TokenOutput:       usage.OutputTokens,
+			TokenCacheRead:    usage.CacheReadInputTokens,
+			TokenCacheWrite:   usage.CacheCreationInputTokens,
+			Turn:              turn.TurnIndex,
+			WebSearchRequests: webSearches,
+			WebFetchRequests:  webFetches,
+		})
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading transcript: %w", err)
+	// A turn lasts until the last entry before the next prompt
+	if entryTime != nil && len(result.Turns) > 0 {
+		result.Turns[len(result.Turns)-1].EndedAt = entryTime
+	}
+}
+
+// pairToolResults matches the tool_result blocks of a user message with the
+// tool_use they answer, adding the time in between to the tool's duration and
+// counting failed calls as errors of the tool and of the session. Bash
+// commands also get their own duration and how they ended.
+func (p *Parser) pairToolResults(entry TranscriptEntry, at *time.Time) {
+	for _, content := range entry.Message.Content {
+		if content.Type != "tool_result" || content.ToolUseIDRef == "" {
+			continue
+		}
+		pending, ok := p.pendingTools[content.ToolUseIDRef]
+		if !ok {
+			continue
+		}
+		delete(p.pendingTools, content.ToolUseIDRef)
+
+		tool, ok := p.toolCounts[pending.Name]
+		if !ok {
+			continue
+		}
+		var duration *int64
+		if pending.StartedAt != nil && at != nil && !at.Before(*pending.StartedAt) {
+			ms := at.Sub(*pending.StartedAt).Milliseconds()
+			duration = &ms
+			total := ms
+			if tool.TotalDurationMs != nil {
+				total += *tool.TotalDurationMs
+			}
+			tool.TotalDurationMs = &total
+		}
+		text := resultText(content.ResultContent)
+		if content.IsError {
+			tool.ErrorCount++
+			p.result.Metrics.ErrorCount++
+			p.countRefusal(tool, text)
+			p.countBlockingHook(text)
+		}
+		tool.ResultTokens += estimateTokens(text)
+
+		if pending.Name == "Bash" {
+			if cmd := p.command(content.ToolUseIDRef); cmd != nil {
+				cmd.DurationMs = duration
+				setCommandResult(cmd, content, entry.ToolUseResultData)
+			}
+		}
+	}
+}
+
+// command returns the Bash command started by the given tool_use.
+func (p *Parser) command(toolUseID string) *domain.SessionCommand {
+	commands := p.result.Commands
+	for i := len(commands) - 1; i >= 0; i-- {
+		if commands[i].ToolUseID == toolUseID {
+			return commands[i]
+		}
+	}
+	return nil
+}
+
+// exitCodePattern matches how Claude Code reports a failed Bash command.
+var exitCodePattern = regexp.MustCompile(`^Exit code (\d+)`)
+
+// setCommandResult sets how a Bash command ended from its tool_result. A
+// command without an error exited with 0; a failed one reports its exit code
+// at the start of the result, unless it never ran (e.g. it was rejected).
+func setCommandResult(cmd *domain.SessionCommand, content Content, toolUseResult json.RawMessage) {
+	var bash BashToolResult
+	if len(toolUseResult) > 0 && json.Unmarshal(toolUseResult, &bash) == nil && bash.Interrupted {
+		cmd.Status = domain.CommandInterrupted
+		return
+	}
+
+	if !content.IsError {
+		exitCode := 0
+		cmd.ExitCode = &exitCode
+		cmd.Status = domain.CommandSucceeded
+		return
+	}
+
+	cmd.Status = domain.CommandFailed
+	if m := exitCodePattern.FindStringSubmatch(resultText(content.ResultContent)); m != nil {
+		if exitCode, err := strconv.Atoi(m[1]); err == nil {
+			cmd.ExitCode = &exitCode
+		}
+	}
+}
+
+// resultText returns the text of a tool_result's content, which is either a
+// string or a list of content blocks.
+func resultText(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	var blocks []Content
+	if json.Unmarshal(raw, &blocks) == nil {
+		var texts []string
+		for _, block := range blocks {
+			if block.Type == "text" {
+				texts = append(texts, block.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
+
+// estimateTokens approximates the token count of text at four characters
+// per token. Tool results aren't tokenized separately in the transcript, so
+// this is only meant for comparing tools with each other.
+func estimateTokens(text string) int64 {
+	return int64((len(text) + 3) / 4)
+}
+
+// Result returns the transcript parsed so far. It's a copy: callers may
+// modify it (e.g. when pricing) and keep parsing with p.
+func (p *Parser) Result() *ParsedTranscript {
+	metrics := *p.result.Metrics
+	result := &ParsedTranscript{
+		StartedAt:     p.firstTimestamp,
+		EndedAt:       p.lastTimestamp,
+		ModelID:       p.modelID,
+		Metrics:       &metrics,
+		Tools:         make([]*domain.SessionTool, 0, len(p.toolCounts)),
+		Files:         make([]*domain.SessionFile, 0, len(p.fileCounts)),
+		Commands:      copyAll(p.result.Commands),
+		Subagents:     copyAll(p.result.Subagents),
+		ModelUsage:    copyAll(p.result.ModelUsage),
+		Requests:      copyAll(p.result.Requests),
+		Turns:         copyAll(p.result.Turns),
+		SlashCommands: copyAll(p.result.SlashCommands),
+		Hooks:         copyAll(p.result.Hooks),
+		Anomalies:     copyAll(p.result.Anomalies),
+	}
+	for _, turn := range result.Turns {
+		if turn.StartedAt != nil && turn.EndedAt != nil {
+			ms := turn.EndedAt.Sub(*turn.StartedAt).Milliseconds()
+			turn.DurationMs = &ms
+		}
 	}
 
 	// Calculate turn count (pairs of user-assistant messages)
 	result.Metrics.TurnCount = min(result.Metrics.MessageCountUser, result.Metrics.MessageCountAssistant)
-
-	// Set timestamps and model
-	result.StartedAt = firstTimestamp
-	result.EndedAt = lastTimestamp
-	result.ModelID = modelID
+	result.Metrics.TokenCacheWasted = p.cache.waste()
+	result.Metrics.ParserVersion = StateVersion
 
 	// Convert maps to slices
-	for _, tool := range toolCounts {
-		result.Tools = append(result.Tools, tool)
+	for _, tool := range p.toolCounts {
+		t := *tool
+		result.Tools = append(result.Tools, &t)
+	}
+	for _, file := range p.fileCounts {
+		f := *file
+		result.Files = append(result.Files, &f)
+	}
+
+	return result
+}
+
+func copyAll[T any](items []*T) []*T {
+	copies := make([]*T, len(items))
+	for i, item := range items {
+		c := *item
+		copies[i] = &c
+	}
+	return copies
+}
+
+// entryModel returns the model that produced an assistant entry. Claude Code
+// records it on the message; older transcripts carry it at the top level.
+func entryModel(entry TranscriptEntry) string {
+	if entry.Model != "" {
+		return entry.Model
 	}
-	for _, file := range fileCounts {
-		result.Files = append(result.Files, file)
+	if entry.Message != nil {
+		return entry.Message.Model
+	}
+	return ""
+}
+
+// addModelUsage accumulates usage into the per-model breakdown, preserving
+// the order in which models first appear.
+func addModelUsage(result *ParsedTranscript, index map[string]*domain.SessionModelUsage, sessionID, model string, usage *Usage, messages int64) {
+	if usage.InputTokens == 0 && usage.OutputTokens == 0 &&
+		usage.CacheReadInputTokens == 0 && usage.CacheCreationInputTokens == 0 && messages == 0 {
+		return
 	}
 
-	return result, nil
+	mu, ok := index[model]
+	if !ok {
+		mu = &domain.SessionModelUsage{
+			SessionID: sessionID,
+			ModelID:   model,
+		}
+		index[model] = mu
+		result.ModelUsage = append(result.ModelUsage, mu)
+	}
+	mu.MessageCount += messages
+	mu.TokenInput += usage.InputTokens
+	mu.TokenOutput += usage.OutputTokens
+	mu.TokenCacheRead += usage.CacheReadInputTokens
+	mu.TokenCacheWrite += usage.CacheCreationInputTokens
 }
 
-func processAssistantMessage(msg *Message, sessionID string, toolCounts map[string]*domain.SessionTool, fileCounts map[string]*domain.SessionFile, pendingSubs map[string]*pendingSubagent, result *ParsedTranscript) {
+func (p *Parser) processAssistantMessage(msg *Message, at *time.Time) {
+	sessionID := p.sessionID
+	toolCounts, fileCounts, result := p.toolCounts, p.fileCounts, p.result
 	for _, content := range msg.Content {
 		if content.Type != "tool_use" {
 			continue
@@ -212,142 +713,86 @@ func processAssistantMessage(msg *Message, sessionID string, toolCounts map[stri
 		if existing, ok := toolCounts[toolName]; ok {
 			existing.InvocationCount++
 		} else {
-			toolCounts[toolName] = &domain.SessionTool{
+			tool := &domain.SessionTool{
 				SessionID:       sessionID,
 				ToolName:        toolName,
 				InvocationCount: 1,
 			}
+			tool.MCPServer, tool.MCPTool, _ = ParseMCPToolName(toolName)
+			toolCounts[toolName] = tool
 		}
 
 		// Detect sub-agent invocations (Task or Skill tool_use)
 		if (toolName == "Task" || toolName == "Skill") && len(content.Input) > 0 && content.ToolUseID != "" {
 			var subInput SubagentToolInput
 			if err := json.Unmarshal(content.Input, &subInput); err == nil {
-				pending := &pendingSubagent{}
+				pending := &pendingSubagent{ToolUseID: content.ToolUseID}
 				if toolName == "Task" {
-					pending.agentKind = "task"
-					pending.agentType = subInput.SubagentType
-					if pending.agentType == "" {
-						pending.agentType = "unknown"
+					pending.AgentKind = "task"
+					pending.AgentType = subInput.SubagentType
+					if pending.AgentType == "" {
+						pending.AgentType = "unknown"
 					}
 					if subInput.Description != "" {
 						desc := subInput.Description
-						pending.description = &desc
+						pending.Description = &desc
 					}
 					if subInput.Model != "" {
 						m := subInput.Model
-						pending.model = &m
+						pending.Model = &m
 					}
 				} else { // Skill
-					pending.agentKind = "skill"
-					pending.agentType = subInput.Skill
-					if pending.agentType == "" {
-						pending.agentType = "unknown"
+					pending.AgentKind = "skill"
+					pending.AgentType = subInput.Skill
+					if pending.AgentType == "" {
+						pending.AgentType = "unknown"
 					}
 				}
-				pendingSubs[content.ToolUseID] = pending
+				p.pendingSubagents = append(p.pendingSubagents, pending)
 			}
 		}
 
-		// Parse tool input for file operations and bash commands
-		if len(content.Input) > 0 {
-			var input ToolInput
-			if err := json.Unmarshal(content.Input, &input); err == nil {
-				// Track file operations
-				if input.FilePath != "" {
-					operation := getFileOperation(toolName)
-					if operation != "" {
-						key := fmt.Sprintf("%s:%s", input.FilePath, operation)
-						if existing, ok := fileCounts[key]; ok {
-							existing.OperationCount++
-						} else {
-							fileCounts[key] = &domain.SessionFile{
-								SessionID:      sessionID,
-								FilePath:       input.FilePath,
-								Operation:      operation,
-								OperationCount: 1,
-							}
-						}
-					}
-				}
-
-				// Track bash commands
-				if input.Command != "" && toolName == "Bash" {
-					result.Commands = append(result.Commands, &domain.SessionCommand{
-						SessionID: sessionID,
-						Command:   input.Command,
-					})
-				}
-			}
+		// Track the files the call touched, and the lines it changed in them
+		change, changed := measureLines(toolName, content.Input)
+		if changed {
+			result.Metrics.LinesAdded += change.Added
+			result.Metrics.LinesRemoved += change.Removed
 		}
-	}
-}
-
-func processSubagentResult(entry TranscriptEntry, sessionID string, pendingSubs map[string]*pendingSubagent, result *ParsedTranscript) {
-	// Parse the toolUseResult
-	var toolUseResult ToolUseResult
-	if err := json.Unmarshal(entry.ToolUseResultData, &toolUseResult); err != nil {
-		return
-	}
-
-	// Find matching tool_use_id from the user message content
-	var matchedToolUseID string
-	if entry.Message != nil {
-		for _, content := range entry.Message.Content {
-			if content.Type == "tool_result" && content.ToolUseIDRef != "" {
-				if _, ok := pendingSubs[content.ToolUseIDRef]; ok {
-					matchedToolUseID = content.ToolUseIDRef
-					break
+		for _, op := range p.classifier.Classify(toolName, content.Input) {
+			key := fmt.Sprintf("%s:%s", op.Path, op.Operation)
+			file, ok := fileCounts[key]
+			if ok {
+				file.OperationCount++
+			} else {
+				file = &domain.SessionFile{
+					SessionID:      sessionID,
+					FilePath:       op.Path,
+					Operation:      op.Operation,
+					OperationCount: 1,
+					Language:       Language(op.Path),
 				}
+				fileCounts[key] = file
+			}
+			if changed && op.Path == change.Path && (op.Operation == OperationWrite || op.Operation == OperationEdit) {
+				file.LinesAdded += change.Added
+				file.LinesRemoved += change.Removed
+				changed = false
 			}
 		}
-	}
 
-	// If no match found via content, try to match with the most recent pending sub-agent
-	if matchedToolUseID == "" {
-		for id := range pendingSubs {
-			matchedToolUseID = id
-			break
+		// Track bash commands
+		if toolName == "Bash" && len(content.Input) > 0 {
+			var input ToolInput
+			if err := json.Unmarshal(content.Input, &input); err == nil && input.Command != "" {
+				result.Commands = append(result.Commands, &domain.SessionCommand{
+					SessionID:  sessionID,
+					Command:    input.Command,
+					ToolUseID:  content.ToolUseID,
+					ExecutedAt: at,
+				})
+			}
 		}
 	}
-
-	if matchedToolUseID == "" {
-		return
-	}
-
-	pending, ok := pendingSubs[matchedToolUseID]
-	if !ok {
-		return
-	}
-
-	subagent := &domain.SessionSubagent{
-		SessionID:   sessionID,
-		AgentType:   pending.agentType,
-		AgentKind:   pending.agentKind,
-		Description: pending.description,
-		Model:       pending.model,
-		TotalTokens: toolUseResult.TotalTokens,
-		ToolUseCount: toolUseResult.TotalToolUseCount,
-		TotalDurationMs: toolUseResult.TotalDurationMs,
-	}
-
-	if toolUseResult.Usage != nil {
-		subagent.TokenInput = toolUseResult.Usage.InputTokens
-		subagent.TokenOutput = toolUseResult.Usage.OutputTokens
-		subagent.TokenCacheRead = toolUseResult.Usage.CacheReadInputTokens
-		subagent.TokenCacheWrite = toolUseResult.Usage.CacheCreationInputTokens
-
-		// Accumulate sub-agent tokens into session totals
-		result.Metrics.TokenInput += toolUseResult.Usage.InputTokens
-		result.Metrics.TokenOutput += toolUseResult.Usage.OutputTokens
-		result.Metrics.TokenCacheRead += toolUseResult.Usage.CacheReadInputTokens
-		result.Metrics.TokenCacheWrite += toolUseResult.Usage.CacheCreationInputTokens
-	}
-
-	result.Subagents = append(result.Subagents, subagent)
-
-	// Remove from pending
-	delete(pendingSubs, matchedToolUseID)
 }
 
 func processToolResult(entry TranscriptEntry, result *ParsedTranscript) {
@@ -364,25 +809,18 @@ func processToolResult(entry TranscriptEntry, result *ParsedTranscript) {
 		if resultData.IsError {
 			result.Metrics.ErrorCount++
 		}
-		// Update last bash command with exit code
+		// Older transcripts report a command's exit code in an entry right
+		// after it, without naming the tool_use. Commands paired with their
+		// tool_result already have theirs.
 		if resultData.ExitCode != nil && len(result.Commands) > 0 {
 			lastCmd := result.Commands[len(result.Commands)-1]
-			if lastCmd.ExitCode == nil {
+			if lastCmd.Status == "" {
 				lastCmd.ExitCode = resultData.ExitCode
+				lastCmd.Status = domain.CommandSucceeded
+				if *resultData.ExitCode != 0 {
+					lastCmd.Status = domain.CommandFailed
+				}
 			}
 		}
 	}
 }
-
-func getFileOperation(toolName string) string {
-	switch toolName {
-	case "Read":
-		return "read"
-	case "Write":
-		return "write"
-	case "Edit":
-		return "edit"
-	default:
-		return ""
-	}
-}
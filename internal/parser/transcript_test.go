@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
 )
 
 func TestParseTranscript_SubagentDetection(t *testing.T) {
@@ -93,9 +95,273 @@ func TestParseTranscript_NoSubagents(t *testing.T) {
 	}
 }
 
+func TestParseTranscript_ModelUsage(t *testing.T) {
+	// Session starts on sonnet, switches to opus (model on the message, as
+	// Claude Code writes it), and runs a haiku sub-agent
+	content := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":[{"type":"text","text":"Hello"}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Hi!"}],"usage":{"input_tokens":100,"output_tokens":50,"cache_read_input_tokens":10,"cache_creation_input_tokens":5}}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:06Z","message":{"role":"assistant","content":[{"type":"text","text":"More."}],"usage":{"input_tokens":20,"output_tokens":10,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:07Z","message":{"role":"assistant","model":"<synthetic>","content":[{"type":"text","text":"API Error"}],"usage":{"input_tokens":0,"output_tokens":0,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:01:00Z","message":{"role":"user","content":[{"type":"text","text":"/model opus"}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:01:05Z","message":{"role":"assistant","model":"claude-opus-4-6-20260206","content":[{"type":"tool_use","id":"task1","name":"Task","input":{"description":"Find","subagent_type":"Explore","model":"haiku","prompt":"Search"}}],"usage":{"input_tokens":300,"output_tokens":80,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:01:10Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"task1","content":"Found"}]},"toolUseResult":{"status":"completed","totalTokens":700,"usage":{"input_tokens":600,"output_tokens":100,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	if result.ModelID == nil || *result.ModelID != "claude-sonnet-4-20250514" {
+		t.Errorf("Expected first model as session model, got %v", result.ModelID)
+	}
+
+	if len(result.ModelUsage) != 3 {
+		t.Fatalf("Expected 3 model usage entries, got %d", len(result.ModelUsage))
+	}
+
+	sonnet := result.ModelUsage[0]
+	assertEqual(t, "sonnet.ModelID", "claude-sonnet-4-20250514", sonnet.ModelID)
+	assertEqual(t, "sonnet.MessageCount", int64(3), sonnet.MessageCount)
+	assertEqual(t, "sonnet.TokenInput", int64(120), sonnet.TokenInput)
+	assertEqual(t, "sonnet.TokenOutput", int64(60), sonnet.TokenOutput)
+	assertEqual(t, "sonnet.TokenCacheRead", int64(10), sonnet.TokenCacheRead)
+
+	opus := result.ModelUsage[1]
+	assertEqual(t, "opus.ModelID", "claude-opus-4-6-20260206", opus.ModelID)
+	assertEqual(t, "opus.MessageCount", int64(1), opus.MessageCount)
+	assertEqual(t, "opus.TokenInput", int64(300), opus.TokenInput)
+
+	haiku := result.ModelUsage[2]
+	assertEqual(t, "haiku.ModelID", "haiku", haiku.ModelID)
+	assertEqual(t, "haiku.MessageCount", int64(0), haiku.MessageCount)
+	assertEqual(t, "haiku.TokenInput", int64(600), haiku.TokenInput)
+	assertEqual(t, "haiku.TokenOutput", int64(100), haiku.TokenOutput)
+
+	// Per-model usage adds up to the session totals
+	var input, output int64
+	for _, mu := range result.ModelUsage {
+		input += mu.TokenInput
+		output += mu.TokenOutput
+	}
+	assertEqual(t, "sum input", result.Metrics.TokenInput, input)
+	assertEqual(t, "sum output", result.Metrics.TokenOutput, output)
+}
+
+func TestParseTranscript_Requests(t *testing.T) {
+	// The first response is split over two lines sharing a message ID and
+	// usage; it must be counted as one request
+	content := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":[{"type":"text","text":"Hello"}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Reading."}],"usage":{"input_tokens":150000,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"read1","name":"Read","input":{"file_path":"/a.go"}}],"usage":{"input_tokens":150000,"output_tokens":50,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:00:06Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"read1","content":"package a"}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:10Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Done."}],"usage":{"input_tokens":10,"output_tokens":20,"cache_read_input_tokens":150000,"cache_creation_input_tokens":5}}}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	if len(result.Requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(result.Requests))
+	}
+
+	req0 := result.Requests[0]
+	assertEqual(t, "req0.ModelID", "claude-sonnet-4-20250514", req0.ModelID)
+	assertEqual(t, "req0.TokenInput", int64(150000), req0.TokenInput)
+	assertEqual(t, "req0.Aggregate", false, req0.Aggregate)
+	if req0.Timestamp == nil {
+		t.Error("Expected request timestamp to be set")
+	}
+
+	req1 := result.Requests[1]
+	assertEqual(t, "req1.TokenCacheRead", int64(150000), req1.TokenCacheRead)
+	assertEqual(t, "req1.TokenCacheWrite", int64(5), req1.TokenCacheWrite)
+
+	assertEqual(t, "metrics.TokenInput", int64(150010), result.Metrics.TokenInput)
+	assertEqual(t, "metrics.TokenOutput", int64(70), result.Metrics.TokenOutput)
+}
+
+func TestParseTranscript_ToolDurationsAndErrors(t *testing.T) {
+	// Two Bash calls answered in one message, one of them failing, and a
+	// Read that never gets a result
+	content := `{"type":"assistant","timestamp":"2025-01-17T10:00:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"bash1","name":"Bash","input":{"command":"go build"}},{"type":"tool_use","id":"bash2","name":"Bash","input":{"command":"go vet"}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:02.5Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"bash1","content":"ok"},{"type":"tool_result","tool_use_id":"bash2","content":"exit status 1","is_error":true}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:03Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"read1","name":"Read","input":{"file_path":"/a.go"}}]}}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	tools := make(map[string]*domain.SessionTool)
+	for _, tool := range result.Tools {
+		tools[tool.ToolName] = tool
+	}
+
+	bash := tools["Bash"]
+	if bash == nil {
+		t.Fatal("Expected Bash tool")
+	}
+	assertEqual(t, "bash.InvocationCount", int64(2), bash.InvocationCount)
+	assertEqual(t, "bash.ErrorCount", int64(1), bash.ErrorCount)
+	if bash.TotalDurationMs == nil || *bash.TotalDurationMs != 5000 {
+		t.Errorf("Expected bash duration 5000, got %v", bash.TotalDurationMs)
+	}
+
+	read := tools["Read"]
+	if read == nil {
+		t.Fatal("Expected Read tool")
+	}
+	if read.TotalDurationMs != nil {
+		t.Errorf("Expected no duration for unanswered Read, got %v", *read.TotalDurationMs)
+	}
+	assertEqual(t, "metrics.ErrorCount", int64(1), result.Metrics.ErrorCount)
+}
+
+func TestParseTranscript_MCPTools(t *testing.T) {
+	// Two calls of one MCP tool, one failing, next to a built-in tool
+	content := `{"type":"assistant","timestamp":"2025-01-17T10:00:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"mcp1","name":"mcp__github__get_issue","input":{"number":1}},{"type":"tool_use","id":"mcp2","name":"mcp__github__get_issue","input":{"number":2}},{"type":"tool_use","id":"read1","name":"Read","input":{"file_path":"/a.go"}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:01Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"mcp1","content":[{"type":"text","text":"0123456789abcdef"}]},{"type":"tool_result","tool_use_id":"mcp2","content":"not found","is_error":true},{"type":"tool_result","tool_use_id":"read1","content":"package a"}]}}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	tools := make(map[string]*domain.SessionTool)
+	for _, tool := range result.Tools {
+		tools[tool.ToolName] = tool
+	}
+
+	mcp := tools["mcp__github__get_issue"]
+	if mcp == nil {
+		t.Fatal("Expected MCP tool")
+	}
+	assertEqual(t, "mcp.MCPServer", "github", mcp.MCPServer)
+	assertEqual(t, "mcp.MCPTool", "get_issue", mcp.MCPTool)
+	assertEqual(t, "mcp.InvocationCount", int64(2), mcp.InvocationCount)
+	assertEqual(t, "mcp.ErrorCount", int64(1), mcp.ErrorCount)
+	// 16 characters and 9 characters, at four characters per token
+	assertEqual(t, "mcp.ResultTokens", int64(4+3), mcp.ResultTokens)
+
+	read := tools["Read"]
+	if read == nil {
+		t.Fatal("Expected Read tool")
+	}
+	assertEqual(t, "read.MCPServer", "", read.MCPServer)
+	assertEqual(t, "read.ResultTokens", int64(3), read.ResultTokens)
+}
+
+func TestParseTranscript_CommandResults(t *testing.T) {
+	// Three commands answered out of order: one succeeds, one fails with an
+	// exit code in a list of content blocks, one is interrupted
+	content := `{"type":"assistant","timestamp":"2025-01-17T10:00:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"bash1","name":"Bash","input":{"command":"go test ./..."}},{"type":"tool_use","id":"bash2","name":"Bash","input":{"command":"go vet ./..."}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:01Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"bash2","content":[{"type":"text","text":"Exit code 2\nvet: failed"}],"is_error":true}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:04Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"bash1","content":"ok"}]},"toolUseResult":{"stdout":"ok","stderr":"","interrupted":false}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"bash3","name":"Bash","input":{"command":"sleep 100"}}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:15Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"bash3","content":"Command was interrupted","is_error":true}]},"toolUseResult":{"stdout":"","stderr":"","interrupted":true}}
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+	if len(result.Commands) != 3 {
+		t.Fatalf("Expected 3 commands, got %d", len(result.Commands))
+	}
+
+	tests := []struct {
+		command    string
+		status     string
+		exitCode   *int
+		durationMs int64
+	}{
+		{"go test ./...", domain.CommandSucceeded, intPtr(0), 4000},
+		{"go vet ./...", domain.CommandFailed, intPtr(2), 1000},
+		{"sleep 100", domain.CommandInterrupted, nil, 10000},
+	}
+	for i, tt := range tests {
+		cmd := result.Commands[i]
+		assertEqual(t, "command", tt.command, cmd.Command)
+		assertEqual(t, tt.command+" status", tt.status, cmd.Status)
+		if (tt.exitCode == nil) != (cmd.ExitCode == nil) || (tt.exitCode != nil && *tt.exitCode != *cmd.ExitCode) {
+			t.Errorf("%s: expected exit code %v, got %v", tt.command, tt.exitCode, cmd.ExitCode)
+		}
+		if cmd.DurationMs == nil || *cmd.DurationMs != tt.durationMs {
+			t.Errorf("%s: expected duration %d, got %v", tt.command, tt.durationMs, cmd.DurationMs)
+		}
+		if cmd.ExecutedAt == nil {
+			t.Errorf("%s: expected start time to be set", tt.command)
+		}
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
 func assertEqual[T comparable](t *testing.T, name string, expected, actual T) {
 	t.Helper()
 	if expected != actual {
 		t.Errorf("%s: expected %v, got %v", name, expected, actual)
 	}
 }
+
+func TestParseTranscript_ThinkingAndServerTools(t *testing.T) {
+	// A thinking block, a redacted one, and a response that searched the web
+	// twice and fetched a page
+	content := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Look it up"}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:05Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"thinking","thinking":"I should search for this first."},{"type":"redacted_thinking","data":"abc"}],"usage":{"input_tokens":100,"output_tokens":40,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:20Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Found it."}],"usage":{"input_tokens":200,"output_tokens":10,"cache_read_input_tokens":0,"cache_creation_input_tokens":0,"server_tool_use":{"web_search_requests":2,"web_fetch_requests":1}}}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	assertEqual(t, "ThinkingBlocks", int64(2), result.Metrics.ThinkingBlocks)
+	assertEqual(t, "ThinkingTokens", estimateTokens("I should search for this first."), result.Metrics.ThinkingTokens)
+	assertEqual(t, "WebSearchRequests", int64(2), result.Metrics.WebSearchRequests)
+	assertEqual(t, "WebFetchRequests", int64(1), result.Metrics.WebFetchRequests)
+
+	if len(result.Requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(result.Requests))
+	}
+	assertEqual(t, "req0.WebSearchRequests", int64(0), result.Requests[0].WebSearchRequests)
+	assertEqual(t, "req1.WebSearchRequests", int64(2), result.Requests[1].WebSearchRequests)
+	assertEqual(t, "req1.WebFetchRequests", int64(1), result.Requests[1].WebFetchRequests)
+}
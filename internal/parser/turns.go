@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"strings"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// promptPreviewLength is how many characters of a prompt are kept with its
+// turn, enough to tell turns apart in a timeline.
+const promptPreviewLength = 200
+
+// Text Claude Code writes as user messages that the user didn't type, such
+// as the output of local commands or the note left by an interruption.
+var generatedUserText = []string{
+	"<local-command-stdout>",
+	"<local-command-stderr>",
+	interruptedText,
+}
+
+// promptText returns the text of a user entry that starts a turn: one the
+// user typed, as opposed to tool results and messages Claude Code adds, such
+// as compaction summaries.
+func promptText(entry TranscriptEntry) (string, bool) {
+	if entry.IsMeta || entry.IsCompactSummary || entry.Message == nil {
+		return "", false
+	}
+	var texts []string
+	for _, content := range entry.Message.Content {
+		switch content.Type {
+		case "tool_result":
+			return "", false
+		case "text":
+			if text := strings.TrimSpace(content.Text); text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	if len(texts) == 0 {
+		return "", false
+	}
+	text := strings.Join(texts, "\n")
+	for _, prefix := range generatedUserText {
+		if strings.HasPrefix(text, prefix) {
+			return "", false
+		}
+	}
+	return truncateRunes(text, promptPreviewLength), true
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// startTurn opens a new turn for a prompt sent at the given time.
+func (p *Parser) startTurn(prompt string, at *time.Time) *domain.SessionTurn {
+	turn := &domain.SessionTurn{
+		SessionID: p.sessionID,
+		TurnIndex: int64(len(p.result.Turns) + 1),
+		Prompt:    prompt,
+		StartedAt: at,
+		EndedAt:   at,
+	}
+	p.result.Turns = append(p.result.Turns, turn)
+	return turn
+}
+
+// turn returns the turn in progress. Work logged before the first prompt,
+// e.g. in a resumed session, gets a turn without a prompt.
+func (p *Parser) turn(at *time.Time) *domain.SessionTurn {
+	if n := len(p.result.Turns); n > 0 {
+		return p.result.Turns[n-1]
+	}
+	return p.startTurn("", at)
+}
+
+func addTurnUsage(turn *domain.SessionTurn, usage *Usage) {
+	turn.TokenInput += usage.InputTokens
+	turn.TokenOutput += usage.OutputTokens
+	turn.TokenCacheRead += usage.CacheReadInputTokens
+	turn.TokenCacheWrite += usage.CacheCreationInputTokens
+}
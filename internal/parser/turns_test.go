@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTranscript_Turns(t *testing.T) {
+	// Two prompts, the first typed as a plain string. The tool result, the
+	// interruption note and the meta message don't start turns.
+	content := `{"type":"user","timestamp":"2025-01-17T10:00:00Z","message":{"role":"user","content":"Fix the failing test"}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:02Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"tool_use","id":"b1","name":"Bash","input":{"command":"go test ./..."}}],"usage":{"input_tokens":100,"output_tokens":10,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:00:05Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"b1","content":"ok"}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:00:09Z","message":{"id":"msg_2","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Fixed"}],"usage":{"input_tokens":200,"output_tokens":20,"cache_read_input_tokens":50,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:00:10Z","message":{"role":"user","content":[{"type":"text","text":"[Request interrupted by user]"}]}}
+{"type":"user","timestamp":"2025-01-17T10:00:10Z","isMeta":true,"message":{"role":"user","content":"Caveat: local commands"}}
+{"type":"user","timestamp":"2025-01-17T10:01:00Z","message":{"role":"user","content":[{"type":"text","text":"Now commit it"}]}}
+{"type":"assistant","timestamp":"2025-01-17T10:01:03Z","message":{"id":"msg_3","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Done"}],"usage":{"input_tokens":300,"output_tokens":30,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	if len(result.Turns) != 2 {
+		t.Fatalf("Expected 2 turns, got %d", len(result.Turns))
+	}
+
+	first := result.Turns[0]
+	assertEqual(t, "first.TurnIndex", int64(1), first.TurnIndex)
+	assertEqual(t, "first.Prompt", "Fix the failing test", first.Prompt)
+	assertEqual(t, "first.MessageCount", int64(2), first.MessageCount)
+	assertEqual(t, "first.ToolCalls", int64(1), first.ToolCalls)
+	assertEqual(t, "first.TokenInput", int64(300), first.TokenInput)
+	assertEqual(t, "first.TokenCacheRead", int64(50), first.TokenCacheRead)
+	if first.DurationMs == nil {
+		t.Fatal("Expected first turn duration")
+	}
+	assertEqual(t, "first.DurationMs", int64(10000), *first.DurationMs)
+
+	second := result.Turns[1]
+	assertEqual(t, "second.TurnIndex", int64(2), second.TurnIndex)
+	assertEqual(t, "second.Prompt", "Now commit it", second.Prompt)
+	assertEqual(t, "second.TokenOutput", int64(30), second.TokenOutput)
+	assertEqual(t, "second.DurationMs", int64(3000), *second.DurationMs)
+
+	// Requests are tagged with their turn for pricing
+	for i, want := range []int64{1, 1, 2} {
+		assertEqual(t, "request turn", want, result.Requests[i].Turn)
+	}
+}
+
+func TestParseTranscript_WorkBeforeFirstPrompt(t *testing.T) {
+	content := `{"type":"assistant","timestamp":"2025-01-17T10:00:00Z","message":{"id":"msg_1","role":"assistant","model":"claude-sonnet-4-20250514","content":[{"type":"text","text":"Resuming"}],"usage":{"input_tokens":100,"output_tokens":10,"cache_read_input_tokens":0,"cache_creation_input_tokens":0}}}
+{"type":"user","timestamp":"2025-01-17T10:00:05Z","message":{"role":"user","content":"Continue"}}
+`
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test transcript: %v", err)
+	}
+
+	result, err := ParseTranscript("test-session", path)
+	if err != nil {
+		t.Fatalf("ParseTranscript failed: %v", err)
+	}
+
+	if len(result.Turns) != 2 {
+		t.Fatalf("Expected 2 turns, got %d", len(result.Turns))
+	}
+	assertEqual(t, "first.Prompt", "", result.Turns[0].Prompt)
+	assertEqual(t, "first.TokenInput", int64(100), result.Turns[0].TokenInput)
+	assertEqual(t, "second.Prompt", "Continue", result.Turns[1].Prompt)
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// GitInspector reads the git repository holding a directory.
+type GitInspector interface {
+	// Inspect returns the commits the repository's user made on HEAD between
+	// since and until and the repository's state at until, as far as it can
+	// still be told. It returns nil if dir isn't inside a git repository.
+	Inspect(ctx context.Context, dir string, since, until time.Time) (*domain.GitContext, error)
+}
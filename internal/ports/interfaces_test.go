@@ -3,6 +3,8 @@ package ports_test
 import (
 	"testing"
 
+	"github.com/emiliopalmerini/mclaude/internal/adapters/git"
+	"github.com/emiliopalmerini/mclaude/internal/adapters/storage"
 	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
 	"github.com/emiliopalmerini/mclaude/internal/ports"
 )
@@ -34,6 +36,34 @@ func TestSessionSubagentRepositoryConformance(t *testing.T) {
 	var _ ports.SessionSubagentRepository = (*turso.SessionSubagentRepository)(nil)
 }
 
+func TestSessionRecordingRepositoryConformance(t *testing.T) {
+	var _ ports.SessionRecordingRepository = (*turso.SessionRecordingRepository)(nil)
+}
+
+func TestLiveSessionRepositoryConformance(t *testing.T) {
+	var _ ports.LiveSessionRepository = (*turso.LiveSessionRepository)(nil)
+}
+
+func TestToolRuleRepositoryConformance(t *testing.T) {
+	var _ ports.ToolRuleRepository = (*turso.ToolRuleRepository)(nil)
+}
+
+func TestTranscriptStateRepositoryConformance(t *testing.T) {
+	var _ ports.TranscriptStateRepository = (*turso.TranscriptStateRepository)(nil)
+}
+
+func TestSessionModelUsageRepositoryConformance(t *testing.T) {
+	var _ ports.SessionModelUsageRepository = (*turso.SessionModelUsageRepository)(nil)
+}
+
+func TestSessionParseAnomalyRepositoryConformance(t *testing.T) {
+	var _ ports.SessionParseAnomalyRepository = (*turso.SessionParseAnomalyRepository)(nil)
+}
+
+func TestSessionCostRepositoryConformance(t *testing.T) {
+	var _ ports.SessionCostRepository = (*turso.SessionCostRepository)(nil)
+}
+
 func TestExperimentRepositoryConformance(t *testing.T) {
 	var _ ports.ExperimentRepository = (*turso.ExperimentRepository)(nil)
 }
@@ -57,3 +87,11 @@ func TestPlanConfigRepositoryConformance(t *testing.T) {
 func TestStatsRepositoryConformance(t *testing.T) {
 	var _ ports.StatsRepository = (*turso.StatsRepository)(nil)
 }
+
+func TestRecordQueueConformance(t *testing.T) {
+	var _ ports.RecordQueue = (*storage.RecordQueue)(nil)
+}
+
+func TestGitInspectorConformance(t *testing.T) {
+	var _ ports.GitInspector = (*git.Inspector)(nil)
+}
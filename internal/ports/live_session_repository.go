@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// LiveSessionRepository stores the usage of sessions still running. Hooks
+// can fire concurrently, so writes only succeed against the state they were
+// computed from and report false when another process got there first.
+type LiveSessionRepository interface {
+	Get(ctx context.Context, sessionID string) (*domain.LiveSession, error)
+	List(ctx context.Context) ([]*domain.LiveSession, error)
+	// Create saves live unless the session already has live usage, or has
+	// been recorded with at least as many transcript lines.
+	Create(ctx context.Context, live *domain.LiveSession) (bool, error)
+	// Update saves live only if the stored session is still at fromOffset.
+	Update(ctx context.Context, live *domain.LiveSession, fromOffset int64) (bool, error)
+	Delete(ctx context.Context, sessionID string) error
+	// DeleteStale deletes live usage not updated since before, along with the
+	// saved parser state of sessions that were never recorded.
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
@@ -2,16 +2,37 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/emiliopalmerini/mclaude/internal/domain"
 )
 
+// PricingRepository stores model pricing as versions with effective date
+// ranges, along with the aliases that map short model names to model IDs.
+// GetByID, GetDefault and List only see the current versions.
 type PricingRepository interface {
 	Create(ctx context.Context, pricing *domain.ModelPricing) error
+	AddVersion(ctx context.Context, pricing *domain.ModelPricing) error
 	GetByID(ctx context.Context, id string) (*domain.ModelPricing, error)
+	GetByIDAt(ctx context.Context, id string, at time.Time) (*domain.ModelPricing, error)
 	GetDefault(ctx context.Context) (*domain.ModelPricing, error)
 	List(ctx context.Context) ([]*domain.ModelPricing, error)
+	ListHistory(ctx context.Context) ([]*domain.ModelPricing, error)
 	Update(ctx context.Context, pricing *domain.ModelPricing) error
 	SetDefault(ctx context.Context, id string) error
 	Delete(ctx context.Context, id string) error
+
+	SetAlias(ctx context.Context, alias, modelID string) error
+	GetAlias(ctx context.Context, alias string) (*domain.ModelAlias, error)
+	ListAliases(ctx context.Context) ([]*domain.ModelAlias, error)
+	DeleteAlias(ctx context.Context, alias string) error
+	// Apply writes all changes in a single transaction.
+	Apply(ctx context.Context, changes *domain.PricingChanges) error
+}
+
+// SessionCostRepository loads stored session costs and writes re-priced
+// costs back. ApplyUpdates writes all updates in a single transaction.
+type SessionCostRepository interface {
+	ListForRecompute(ctx context.Context, filter domain.CostRecomputeFilter) ([]*domain.SessionCostTarget, error)
+	ApplyUpdates(ctx context.Context, updates []*domain.SessionCostUpdate) error
 }
@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+// RecordQueue spools hook inputs until their sessions are recorded, so a
+// recording that fails in the background can be retried later.
+type RecordQueue interface {
+	Enqueue(ctx context.Context, input *domain.HookInput) error
+	List(ctx context.Context) ([]*domain.RecordJob, error)
+	// Claim locks a job for processing. It returns false if the job is gone
+	// or another process holds it.
+	Claim(ctx context.Context, job *domain.RecordJob) (bool, error)
+	// Complete removes a claimed job after its session was recorded, unless
+	// the session was queued again meanwhile.
+	Complete(ctx context.Context, job *domain.RecordJob) error
+	// Fail records the error of a claimed job, schedules its next attempt
+	// and releases it.
+	Fail(ctx context.Context, job *domain.RecordJob, cause error) error
+	Remove(ctx context.Context, sessionID string) error
+}
@@ -20,9 +20,16 @@ type SessionRepository interface {
 }
 
 type ListSessionsOptions struct {
-	Limit        int
+	Limit        int // 0 uses the default page size, a negative value lists all sessions
 	ProjectID    *string
 	ExperimentID *string
+	Branch       *string // Git branch the session was recorded on
+}
+
+// SessionRecordingRepository saves a recorded session and its child rows in
+// a single transaction, replacing whatever was recorded for it before.
+type SessionRecordingRepository interface {
+	Save(ctx context.Context, rec *domain.SessionRecording) error
 }
 
 type SessionMetricsRepository interface {
@@ -49,3 +56,14 @@ type SessionSubagentRepository interface {
 	CreateBatch(ctx context.Context, subagents []*domain.SessionSubagent) error
 	ListBySessionID(ctx context.Context, sessionID string) ([]*domain.SessionSubagent, error)
 }
+
+type SessionModelUsageRepository interface {
+	CreateBatch(ctx context.Context, usage []*domain.SessionModelUsage) error
+	ListBySessionID(ctx context.Context, sessionID string) ([]*domain.SessionModelUsage, error)
+}
+
+// SessionParseAnomalyRepository reads the parse anomalies saved with each
+// session recording.
+type SessionParseAnomalyRepository interface {
+	ListBySessionID(ctx context.Context, sessionID string) ([]*domain.SessionParseAnomaly, error)
+}
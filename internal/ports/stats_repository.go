@@ -11,5 +11,17 @@ type StatsRepository interface {
 	GetAggregateByExperiment(ctx context.Context, experimentID string, since string) (*domain.AggregateStats, error)
 	GetAggregateByProject(ctx context.Context, projectID string, since string) (*domain.AggregateStats, error)
 	GetTopTools(ctx context.Context, since string, limit int) ([]domain.ToolUsageStats, error)
+	GetLinesByLanguageByExperiment(ctx context.Context, experimentID string, since string) ([]domain.LanguageStats, error)
+	GetMCPServers(ctx context.Context, since string) ([]domain.MCPServerStats, error)
+	GetMCPServersByExperiment(ctx context.Context, experimentID string, since string) ([]domain.MCPServerStats, error)
+	GetMCPTools(ctx context.Context, since string) ([]domain.MCPToolStats, error)
+	GetMCPToolsByExperiment(ctx context.Context, experimentID string, since string) ([]domain.MCPToolStats, error)
+	GetSlashCommands(ctx context.Context, since string) ([]domain.SlashCommandStats, error)
+	GetSkills(ctx context.Context, since string) ([]domain.SkillStats, error)
+	GetHooks(ctx context.Context, since string) ([]domain.HookStats, error)
+	GetSlowestCommands(ctx context.Context, since string, limit int) ([]domain.CommandStats, error)
+	GetFailingCommands(ctx context.Context, since string, limit int) ([]domain.CommandStats, error)
+	GetModelUsage(ctx context.Context, since string) ([]domain.ModelUsageStats, error)
+	GetParseDiagnostics(ctx context.Context, since string, limit int) (*domain.ParseDiagnostics, error)
 	GetAllExperimentStats(ctx context.Context) ([]domain.ExperimentStats, error)
 }
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+type ToolRuleRepository interface {
+	Set(ctx context.Context, rule *domain.ToolRule) error
+	Get(ctx context.Context, tool string) (*domain.ToolRule, error)
+	List(ctx context.Context) ([]*domain.ToolRule, error)
+	Delete(ctx context.Context, tool string) error
+}
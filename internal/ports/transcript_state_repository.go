@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+)
+
+type TranscriptStateRepository interface {
+	Get(ctx context.Context, sessionID string) (*domain.TranscriptState, error)
+	Save(ctx context.Context, state *domain.TranscriptState) error
+	Delete(ctx context.Context, sessionID string) error
+}
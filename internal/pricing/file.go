@@ -0,0 +1,447 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ports"
+)
+
+// Pricing file formats
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+const defaultLongContextThreshold = 200000
+
+// File is the pricing file read by 'mclaude cost import' and written by
+// 'mclaude cost export'. Token rates are USD per 1M tokens, server tool rates
+// USD per 1,000 requests.
+//
+//	models:
+//	  - id: claude-sonnet-4-5-20250929
+//	    name: Claude Sonnet 4.5
+//	    input: 3.00
+//	    output: 15.00
+//	    cache_read: 0.30              # optional
+//	    cache_write: 3.75             # optional
+//	    long_context:                 # optional
+//	      input: 6.00
+//	      output: 22.50
+//	      threshold: 200000           # defaults to 200000
+//	    web_search: 10.00             # optional, defaults to 10.00
+//	    web_fetch: 0.00               # optional
+//	    default: true                 # at most one model
+//	    effective_from: 2026-01-01    # optional, YYYY-MM-DD or RFC 3339
+//	aliases:
+//	  sonnet: claude-sonnet-4-5-20250929
+type File struct {
+	Models  []FileModel       `json:"models" yaml:"models"`
+	Aliases map[string]string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+}
+
+type FileModel struct {
+	ID            string           `json:"id" yaml:"id"`
+	Name          string           `json:"name,omitempty" yaml:"name,omitempty"`
+	Input         float64          `json:"input" yaml:"input"`
+	Output        float64          `json:"output" yaml:"output"`
+	CacheRead     *float64         `json:"cache_read,omitempty" yaml:"cache_read,omitempty"`
+	CacheWrite    *float64         `json:"cache_write,omitempty" yaml:"cache_write,omitempty"`
+	LongContext   *FileLongContext `json:"long_context,omitempty" yaml:"long_context,omitempty"`
+	WebSearch     *float64         `json:"web_search,omitempty" yaml:"web_search,omitempty"`
+	WebFetch      *float64         `json:"web_fetch,omitempty" yaml:"web_fetch,omitempty"`
+	Default       bool             `json:"default,omitempty" yaml:"default,omitempty"`
+	EffectiveFrom string           `json:"effective_from,omitempty" yaml:"effective_from,omitempty"`
+}
+
+type FileLongContext struct {
+	Input     float64 `json:"input" yaml:"input"`
+	Output    float64 `json:"output" yaml:"output"`
+	Threshold int64   `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+}
+
+// FormatFromPath picks the file format from a file extension, defaulting to YAML.
+func FormatFromPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+	return FormatYAML
+}
+
+// DecodeFile reads and validates a pricing file.
+func DecodeFile(r io.Reader, format string) (*File, error) {
+	var f File
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&f); err != nil {
+			return nil, fmt.Errorf("failed to parse pricing file: %w", err)
+		}
+	case FormatYAML:
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(&f); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse pricing file: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported pricing file format %q", format)
+	}
+
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Encode writes the pricing file in the given format.
+func (f *File) Encode(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(f)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(f); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unsupported pricing file format %q", format)
+	}
+}
+
+func (f *File) validate() error {
+	seen := make(map[string]bool)
+	defaults := 0
+	for i, m := range f.Models {
+		if m.ID == "" {
+			return fmt.Errorf("model %d: id is required", i+1)
+		}
+		if seen[m.ID] {
+			return fmt.Errorf("model %s: listed more than once", m.ID)
+		}
+		seen[m.ID] = true
+
+		if m.Input < 0 || m.Output < 0 ||
+			(m.CacheRead != nil && *m.CacheRead < 0) ||
+			(m.CacheWrite != nil && *m.CacheWrite < 0) ||
+			(m.WebSearch != nil && *m.WebSearch < 0) ||
+			(m.WebFetch != nil && *m.WebFetch < 0) ||
+			(m.LongContext != nil && (m.LongContext.Input < 0 || m.LongContext.Output < 0 || m.LongContext.Threshold < 0)) {
+			return fmt.Errorf("model %s: rates can't be negative", m.ID)
+		}
+		if _, err := m.effectiveFrom(); err != nil {
+			return fmt.Errorf("model %s: %w", m.ID, err)
+		}
+		if m.Default {
+			defaults++
+		}
+	}
+	if defaults > 1 {
+		return fmt.Errorf("only one model can be the default, found %d", defaults)
+	}
+
+	// Aliases are matched in lowercase, as when set from the CLI or web UI
+	aliases := make(map[string]string, len(f.Aliases))
+	for alias, modelID := range f.Aliases {
+		key := strings.ToLower(strings.TrimSpace(alias))
+		if key == "" || modelID == "" {
+			return fmt.Errorf("alias %q: alias and model ID are required", alias)
+		}
+		if _, ok := aliases[key]; ok {
+			return fmt.Errorf("alias %s: listed more than once", key)
+		}
+		aliases[key] = modelID
+	}
+	if f.Aliases != nil {
+		f.Aliases = aliases
+	}
+	return nil
+}
+
+func (f *File) defaultModel() string {
+	for _, m := range f.Models {
+		if m.Default {
+			return m.ID
+		}
+	}
+	return ""
+}
+
+// effectiveFrom returns the parsed effective_from, or the zero time when unset.
+func (m FileModel) effectiveFrom() (time.Time, error) {
+	if m.EffectiveFrom == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, m.EffectiveFrom); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", m.EffectiveFrom, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid effective_from %q (expected YYYY-MM-DD or RFC 3339)", m.EffectiveFrom)
+	}
+	return t.UTC(), nil
+}
+
+func (m FileModel) toPricing(now time.Time) *domain.ModelPricing {
+	p := &domain.ModelPricing{
+		ID:                   m.ID,
+		DisplayName:          m.Name,
+		InputPerMillion:      m.Input,
+		OutputPerMillion:     m.Output,
+		CacheReadPerMillion:  m.CacheRead,
+		CacheWritePerMillion: m.CacheWrite,
+		WebSearchPerThousand: m.WebSearch,
+		WebFetchPerThousand:  m.WebFetch,
+		CreatedAt:            now,
+	}
+	if p.DisplayName == "" {
+		p.DisplayName = m.ID
+	}
+	if lc := m.LongContext; lc != nil {
+		threshold := lc.Threshold
+		if threshold == 0 {
+			threshold = defaultLongContextThreshold
+		}
+		p.LongContextInputPerMillion = &lc.Input
+		p.LongContextOutputPerMillion = &lc.Output
+		p.LongContextThreshold = &threshold
+	}
+	p.EffectiveFrom, _ = m.effectiveFrom()
+	return p
+}
+
+func fileModelFromPricing(p *domain.ModelPricing) FileModel {
+	m := FileModel{
+		ID:         p.ID,
+		Name:       p.DisplayName,
+		Input:      p.InputPerMillion,
+		Output:     p.OutputPerMillion,
+		CacheRead:  p.CacheReadPerMillion,
+		CacheWrite: p.CacheWritePerMillion,
+		WebSearch:  p.WebSearchPerThousand,
+		WebFetch:   p.WebFetchPerThousand,
+		Default:    p.IsDefault,
+	}
+	if p.LongContextInputPerMillion != nil && p.LongContextOutputPerMillion != nil {
+		m.LongContext = &FileLongContext{
+			Input:  *p.LongContextInputPerMillion,
+			Output: *p.LongContextOutputPerMillion,
+		}
+		if p.LongContextThreshold != nil {
+			m.LongContext.Threshold = *p.LongContextThreshold
+		}
+	}
+	if p.EffectiveFrom.After(domain.PricingEpoch) {
+		m.EffectiveFrom = p.EffectiveFrom.UTC().Format(time.RFC3339)
+	}
+	return m
+}
+
+// Export builds a pricing file from the current pricing and aliases.
+func Export(ctx context.Context, repo ports.PricingRepository) (*File, error) {
+	pricing, err := repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := repo.ListAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{Models: make([]FileModel, 0, len(pricing))}
+	for _, p := range pricing {
+		f.Models = append(f.Models, fileModelFromPricing(p))
+	}
+	sort.Slice(f.Models, func(i, j int) bool { return f.Models[i].ID < f.Models[j].ID })
+
+	if len(aliases) > 0 {
+		f.Aliases = make(map[string]string, len(aliases))
+		for _, a := range aliases {
+			f.Aliases[a.Alias] = a.ModelID
+		}
+	}
+	return f, nil
+}
+
+// Kinds of change reported by Import
+const (
+	ChangeAdded      = "added"
+	ChangeUpdated    = "updated"
+	ChangeNewVersion = "new version"
+	ChangeDefault    = "default"
+	ChangeAlias      = "alias"
+)
+
+// ImportChange describes one change Import made, or would make on a dry run.
+type ImportChange struct {
+	Kind    string
+	Subject string
+	Detail  string
+}
+
+// Import upserts the models and aliases of a pricing file. Models not in the
+// file are left alone. A model whose rates changed gets a new pricing version
+// when the file gives a later effective_from, and is updated in place
+// otherwise. Every change is checked before any is written, and all of them
+// are written together or not at all; with dryRun set nothing is written.
+func Import(ctx context.Context, repo ports.PricingRepository, f *File, dryRun bool) ([]ImportChange, error) {
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	var changes []ImportChange
+	apply := &domain.PricingChanges{}
+
+	for _, m := range f.Models {
+		p := m.toPricing(now)
+		existing, err := repo.GetByID(ctx, m.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing == nil {
+			changes = append(changes, ImportChange{ChangeAdded, m.ID, describeRates(p)})
+			apply.Added = append(apply.Added, p)
+			continue
+		}
+
+		diff := diffPricing(existing, p)
+		if diff == "" {
+			continue
+		}
+		p.IsDefault = existing.IsDefault
+
+		switch {
+		case p.EffectiveFrom.IsZero() || p.EffectiveFrom.Equal(existing.EffectiveFrom):
+			changes = append(changes, ImportChange{ChangeUpdated, m.ID, diff})
+			apply.Updated = append(apply.Updated, p)
+		case p.EffectiveFrom.After(existing.EffectiveFrom):
+			detail := fmt.Sprintf("from %s: %s", p.EffectiveFrom.Local().Format("2006-01-02"), diff)
+			changes = append(changes, ImportChange{ChangeNewVersion, m.ID, detail})
+			apply.NewVersions = append(apply.NewVersions, p)
+		default:
+			return nil, fmt.Errorf("model %s: effective_from %s is before the current pricing (from %s)",
+				m.ID, m.EffectiveFrom, existing.EffectiveFrom.Local().Format("2006-01-02"))
+		}
+	}
+
+	if id := f.defaultModel(); id != "" {
+		current, err := repo.GetDefault(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil || current.ID != id {
+			detail := "previously none"
+			if current != nil {
+				detail = "previously " + current.ID
+			}
+			changes = append(changes, ImportChange{ChangeDefault, id, detail})
+			apply.DefaultID = id
+		}
+	}
+
+	existingAliases, err := repo.ListAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliasTargets := make(map[string]string, len(existingAliases))
+	for _, a := range existingAliases {
+		aliasTargets[a.Alias] = a.ModelID
+	}
+
+	aliases := make([]string, 0, len(f.Aliases))
+	for alias := range f.Aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		modelID := f.Aliases[alias]
+		old, ok := aliasTargets[alias]
+		if ok && old == modelID {
+			continue
+		}
+		detail := "-> " + modelID
+		if ok {
+			detail = old + " -> " + modelID
+		}
+		changes = append(changes, ImportChange{ChangeAlias, alias, detail})
+		apply.Aliases = append(apply.Aliases, &domain.ModelAlias{Alias: alias, ModelID: modelID})
+	}
+
+	if dryRun {
+		return changes, nil
+	}
+	if err := repo.Apply(ctx, apply); err != nil {
+		return nil, fmt.Errorf("failed to import pricing: %w", err)
+	}
+	return changes, nil
+}
+
+func describeRates(p *domain.ModelPricing) string {
+	return fmt.Sprintf("input %s, output %s, cache read %s, cache write %s",
+		formatRate(&p.InputPerMillion), formatRate(&p.OutputPerMillion),
+		formatRate(p.CacheReadPerMillion), formatRate(p.CacheWritePerMillion))
+}
+
+// diffPricing lists the fields that differ between two pricing entries,
+// e.g. "input $3 -> $2.5", or returns "" when they match.
+func diffPricing(old, new *domain.ModelPricing) string {
+	var diffs []string
+	if old.DisplayName != new.DisplayName {
+		diffs = append(diffs, fmt.Sprintf("name %q -> %q", old.DisplayName, new.DisplayName))
+	}
+	rates := []struct {
+		name     string
+		old, new *float64
+	}{
+		{"input", &old.InputPerMillion, &new.InputPerMillion},
+		{"output", &old.OutputPerMillion, &new.OutputPerMillion},
+		{"cache read", old.CacheReadPerMillion, new.CacheReadPerMillion},
+		{"cache write", old.CacheWritePerMillion, new.CacheWritePerMillion},
+		{"long input", old.LongContextInputPerMillion, new.LongContextInputPerMillion},
+		{"long output", old.LongContextOutputPerMillion, new.LongContextOutputPerMillion},
+		{"web search", old.WebSearchPerThousand, new.WebSearchPerThousand},
+		{"web fetch", old.WebFetchPerThousand, new.WebFetchPerThousand},
+	}
+	for _, r := range rates {
+		if formatRate(r.old) != formatRate(r.new) {
+			diffs = append(diffs, fmt.Sprintf("%s %s -> %s", r.name, formatRate(r.old), formatRate(r.new)))
+		}
+	}
+	if formatThreshold(old.LongContextThreshold) != formatThreshold(new.LongContextThreshold) {
+		diffs = append(diffs, fmt.Sprintf("long threshold %s -> %s",
+			formatThreshold(old.LongContextThreshold), formatThreshold(new.LongContextThreshold)))
+	}
+	return strings.Join(diffs, ", ")
+}
+
+func formatRate(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return "$" + strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+func formatThreshold(v *int64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *v)
+}
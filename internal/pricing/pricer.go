@@ -0,0 +1,195 @@
+// Package pricing prices parsed sessions against the configured model pricing
+// and re-prices stored sessions when pricing changes.
+package pricing
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/parser"
+	"github.com/emiliopalmerini/mclaude/internal/ports"
+)
+
+// UnknownModelID is the label the parser gives usage it can't attribute to a model.
+const UnknownModelID = "unknown"
+
+// Pricer resolves model aliases and pricing per model, caching lookups for
+// its lifetime. Models without their own pricing use the default pricing.
+type Pricer struct {
+	ctx      context.Context
+	repo     ports.PricingRepository
+	aliases  map[string]string
+	current  map[string]*domain.ModelPricing
+	versions map[string][]*domain.ModelPricing
+	fallback *domain.ModelPricing
+}
+
+func NewPricer(ctx context.Context, repo ports.PricingRepository) *Pricer {
+	fallback, _ := repo.GetDefault(ctx)
+
+	aliases := make(map[string]string)
+	if list, err := repo.ListAliases(ctx); err == nil {
+		for _, a := range list {
+			aliases[a.Alias] = a.ModelID
+		}
+	}
+
+	return &Pricer{
+		ctx:      ctx,
+		repo:     repo,
+		aliases:  aliases,
+		current:  make(map[string]*domain.ModelPricing),
+		versions: make(map[string][]*domain.ModelPricing),
+		fallback: fallback,
+	}
+}
+
+// ResolveAlias maps a model alias such as "haiku" to its configured model ID.
+// Anything that isn't an alias is returned as-is.
+func (p *Pricer) ResolveAlias(model string) string {
+	if id, ok := p.aliases[model]; ok {
+		return id
+	}
+	if id, ok := p.aliases[strings.ToLower(model)]; ok {
+		return id
+	}
+	return model
+}
+
+// LookupAt returns the pricing for a model ID or alias that was in force at
+// the given time, or the current pricing when at is nil. Models without
+// pricing at that time fall back to the default model's pricing. It returns
+// nil when neither the model nor a default is configured.
+func (p *Pricer) LookupAt(model string, at *time.Time) *domain.ModelPricing {
+	modelID := p.ResolveAlias(model)
+	if pricing := p.lookup(modelID, at); pricing != nil {
+		return pricing
+	}
+	if p.fallback == nil {
+		return nil
+	}
+	if pricing := p.lookup(p.fallback.ID, at); pricing != nil {
+		return pricing
+	}
+	return p.fallback
+}
+
+func (p *Pricer) lookup(modelID string, at *time.Time) *domain.ModelPricing {
+	if at == nil {
+		if pricing, ok := p.current[modelID]; ok {
+			return pricing
+		}
+		pricing, _ := p.repo.GetByID(p.ctx, modelID)
+		p.current[modelID] = pricing
+		return pricing
+	}
+
+	for _, v := range p.versions[modelID] {
+		if !at.Before(v.EffectiveFrom) && (v.EffectiveTo == nil || at.Before(*v.EffectiveTo)) {
+			return v
+		}
+	}
+	pricing, _ := p.repo.GetByIDAt(p.ctx, modelID, *at)
+	if pricing != nil {
+		p.versions[modelID] = append(p.versions[modelID], pricing)
+	}
+	return pricing
+}
+
+// MergeModelUsage resolves model aliases (sub-agents report "haiku" rather
+// than a full ID) and merges rows that end up naming the same model.
+func (p *Pricer) MergeModelUsage(usage []*domain.SessionModelUsage) []*domain.SessionModelUsage {
+	merged := make([]*domain.SessionModelUsage, 0, len(usage))
+	index := make(map[string]*domain.SessionModelUsage)
+	for _, mu := range usage {
+		modelID := p.ResolveAlias(mu.ModelID)
+		existing, ok := index[modelID]
+		if !ok {
+			mu.ModelID = modelID
+			index[modelID] = mu
+			merged = append(merged, mu)
+			continue
+		}
+		existing.MessageCount += mu.MessageCount
+		existing.TokenInput += mu.TokenInput
+		existing.TokenOutput += mu.TokenOutput
+		existing.TokenCacheRead += mu.TokenCacheRead
+		existing.TokenCacheWrite += mu.TokenCacheWrite
+	}
+	return merged
+}
+
+// PriceTranscript prices every request in a parsed transcript at the rates in
+// force when it was made, and fills in the cost estimates of the session
+// metrics, turns, per-model usage and sub-agents.
+func PriceTranscript(pricer *Pricer, parsed *parser.ParsedTranscript) {
+	parsed.ModelUsage = pricer.MergeModelUsage(parsed.ModelUsage)
+
+	priced := pricer.fallback != nil
+	modelCosts := make(map[string]float64)
+	turnCosts := make(map[int64]float64)
+	for _, req := range parsed.Requests {
+		at := req.Timestamp
+		if at == nil {
+			at = parsed.StartedAt
+		}
+		pricing := pricer.LookupAt(req.ModelID, at)
+		if pricing == nil {
+			continue
+		}
+		cost := pricing.CalculateRequestCost(req)
+		modelCosts[pricer.ResolveAlias(req.ModelID)] += cost
+		turnCosts[req.Turn] += cost
+		priced = true
+	}
+
+	var total float64
+	for _, mu := range parsed.ModelUsage {
+		if pricer.LookupAt(mu.ModelID, parsed.StartedAt) == nil {
+			continue
+		}
+		cost := modelCosts[mu.ModelID]
+		mu.CostEstimateUSD = &cost
+		total += cost
+	}
+	parsed.Metrics.CostEstimateUSD = nil
+	if priced {
+		parsed.Metrics.CostEstimateUSD = &total
+	}
+	for _, turn := range parsed.Turns {
+		turn.CostEstimateUSD = nil
+		if priced {
+			cost := turnCosts[turn.TurnIndex]
+			turn.CostEstimateUSD = &cost
+		}
+	}
+
+	// Sub-agents are priced at the model their usage was counted under, so
+	// their costs add up with the per-model ones. Their usage is a total over
+	// many requests, so it's priced at standard rates.
+	sessionModel := UnknownModelID
+	if parsed.ModelID != nil {
+		sessionModel = *parsed.ModelID
+	}
+	for _, sa := range parsed.Subagents {
+		if pricing := pricer.LookupAt(SubagentModel(sa, sessionModel), parsed.StartedAt); pricing != nil {
+			cost := pricing.CalculateStandardCost(sa.TokenInput, sa.TokenOutput, sa.TokenCacheRead, sa.TokenCacheWrite)
+			sa.CostEstimateUSD = &cost
+		}
+	}
+}
+
+// SubagentModel returns the model a sub-agent's usage was counted under. For
+// sub-agents recorded before that was kept, it falls back to the model the
+// agent's call asked for, or else the session's model.
+func SubagentModel(sa *domain.SessionSubagent, sessionModel string) string {
+	if sa.ModelID != nil {
+		return *sa.ModelID
+	}
+	if sa.Model != nil {
+		return *sa.Model
+	}
+	return sessionModel
+}
@@ -0,0 +1,235 @@
+package pricing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/parser"
+	"github.com/emiliopalmerini/mclaude/internal/ports"
+)
+
+const (
+	SourceTranscript = "transcript"
+	SourceStored     = "stored"
+)
+
+// Recomputer re-prices stored sessions with the model pricing in force when
+// they ran.
+// Sessions with an archived transcript are re-parsed so every request is
+// priced on its own, unless the archive no longer adds up to what was
+// recorded; the rest are re-priced from their stored token counts.
+type Recomputer struct {
+	costs      ports.SessionCostRepository
+	modelUsage ports.SessionModelUsageRepository
+	subagents  ports.SessionSubagentRepository
+	pricing    ports.PricingRepository
+	storage    ports.TranscriptStorage
+}
+
+// NewRecomputer creates a Recomputer. storage may be nil, in which case
+// every session is re-priced from stored token counts.
+func NewRecomputer(
+	costs ports.SessionCostRepository,
+	modelUsage ports.SessionModelUsageRepository,
+	subagents ports.SessionSubagentRepository,
+	pricing ports.PricingRepository,
+	storage ports.TranscriptStorage,
+) *Recomputer {
+	return &Recomputer{
+		costs:      costs,
+		modelUsage: modelUsage,
+		subagents:  subagents,
+		pricing:    pricing,
+		storage:    storage,
+	}
+}
+
+// Recompute re-prices the sessions matching filter. Unless dryRun is set, all
+// new costs are written in a single transaction.
+func (r *Recomputer) Recompute(ctx context.Context, filter domain.CostRecomputeFilter, dryRun bool) (*domain.CostRecomputeResult, error) {
+	targets, err := r.costs.ListForRecompute(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	pricer := NewPricer(ctx, r.pricing)
+	result := &domain.CostRecomputeResult{
+		Updates: make([]*domain.SessionCostUpdate, 0, len(targets)),
+		DryRun:  dryRun,
+	}
+
+	for _, target := range targets {
+		update, err := r.recomputeSession(ctx, pricer, target)
+		if err != nil {
+			return nil, err
+		}
+		if update.OldCost != nil {
+			result.OldTotal += *update.OldCost
+		}
+		if update.NewCost != nil {
+			result.NewTotal += *update.NewCost
+		}
+		result.Updates = append(result.Updates, update)
+	}
+
+	if !dryRun && len(result.Updates) > 0 {
+		if err := r.costs.ApplyUpdates(ctx, result.Updates); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (r *Recomputer) recomputeSession(ctx context.Context, pricer *Pricer, target *domain.SessionCostTarget) (*domain.SessionCostUpdate, error) {
+	update := &domain.SessionCostUpdate{
+		SessionID: target.SessionID,
+		OldCost:   target.Metrics.CostEstimateUSD,
+	}
+
+	storedSubagents, err := r.subagents.ListBySessionID(ctx, target.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionModel := UnknownModelID
+	if target.Metrics.ModelID != nil {
+		sessionModel = *target.Metrics.ModelID
+	}
+
+	var parsedSubagents []*domain.SessionSubagent
+	parsed := r.parseArchived(ctx, target.SessionID)
+	if parsed != nil && !matchesRecording(parsed, &target.Metrics, storedSubagents) {
+		parsed = nil
+	}
+	if parsed != nil {
+		PriceTranscript(pricer, parsed)
+		update.Source = SourceTranscript
+		update.NewCost = parsed.Metrics.CostEstimateUSD
+		update.ModelUsage = parsed.ModelUsage
+		update.Turns = parsed.Turns
+		parsedSubagents = parsed.Subagents
+	} else {
+		update.Source = SourceStored
+		if err := r.repriceStoredUsage(ctx, pricer, target, sessionModel, update); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, sa := range storedSubagents {
+		saUpdate := domain.SubagentCostUpdate{ID: sa.ID, OldCost: sa.CostEstimateUSD}
+		if parsedSubagents != nil {
+			saUpdate.NewCost = parsedSubagents[i].CostEstimateUSD
+		} else {
+			if p := pricer.LookupAt(SubagentModel(sa, sessionModel), target.StartedAt); p != nil {
+				cost := p.CalculateStandardCost(sa.TokenInput, sa.TokenOutput, sa.TokenCacheRead, sa.TokenCacheWrite)
+				saUpdate.NewCost = &cost
+			}
+		}
+		update.Subagents = append(update.Subagents, saUpdate)
+	}
+
+	return update, nil
+}
+
+// repriceStoredUsage prices the stored per-model totals, or the session totals
+// for sessions recorded before per-model usage was tracked. Per-request sizes
+// are unknown here, so standard rates apply. Which model ran each server tool
+// request isn't stored either, so their fees are priced at the session
+// model's rates and only added to the session total.
+func (r *Recomputer) repriceStoredUsage(ctx context.Context, pricer *Pricer, target *domain.SessionCostTarget, sessionModel string, update *domain.SessionCostUpdate) error {
+	usage, err := r.modelUsage.ListBySessionID(ctx, target.SessionID)
+	if err != nil {
+		return err
+	}
+
+	m := target.Metrics
+	var serverToolCost float64
+	sessionPricing := pricer.LookupAt(sessionModel, target.StartedAt)
+	if sessionPricing != nil {
+		serverToolCost = sessionPricing.CalculateServerToolCost(m.WebSearchRequests, m.WebFetchRequests)
+	}
+
+	if len(usage) == 0 {
+		if sessionPricing != nil {
+			cost := serverToolCost + sessionPricing.CalculateStandardCost(m.TokenInput, m.TokenOutput, m.TokenCacheRead, m.TokenCacheWrite)
+			update.NewCost = &cost
+		}
+		return nil
+	}
+
+	var total float64
+	priced := false
+	for _, mu := range usage {
+		p := pricer.LookupAt(mu.ModelID, target.StartedAt)
+		if p == nil {
+			mu.CostEstimateUSD = nil
+			continue
+		}
+		cost := p.CalculateStandardCost(mu.TokenInput, mu.TokenOutput, mu.TokenCacheRead, mu.TokenCacheWrite)
+		mu.CostEstimateUSD = &cost
+		total += cost
+		priced = true
+	}
+	if priced {
+		total += serverToolCost
+		update.NewCost = &total
+	}
+	update.ModelUsage = usage
+	return nil
+}
+
+// matchesRecording reports whether a re-parsed archive accounts for
+// everything that was recorded. The archive holds the session transcript
+// only, so sub-agents read from their own transcripts while recording, and
+// their tokens, are missing from it; so is whatever a transcript that was
+// rewritten since lost. Stored sub-agents are in transcript order, so they
+// line up with the re-parsed ones when both count the same.
+func matchesRecording(parsed *parser.ParsedTranscript, recorded *domain.SessionMetrics, subagents []*domain.SessionSubagent) bool {
+	m := parsed.Metrics
+	return len(parsed.Subagents) == len(subagents) &&
+		m.TokenInput == recorded.TokenInput &&
+		m.TokenOutput == recorded.TokenOutput &&
+		m.TokenCacheRead == recorded.TokenCacheRead &&
+		m.TokenCacheWrite == recorded.TokenCacheWrite
+}
+
+// parseArchived re-parses the archived transcript of a session, returning nil
+// when there is none or it can't be read.
+func (r *Recomputer) parseArchived(ctx context.Context, sessionID string) *parser.ParsedTranscript {
+	if r.storage == nil {
+		return nil
+	}
+	if exists, err := r.storage.Exists(ctx, sessionID); err != nil || !exists {
+		return nil
+	}
+
+	data, err := r.storage.Get(ctx, sessionID)
+	if err != nil {
+		return nil
+	}
+
+	parsed, err := parser.ParseTranscriptReader(sessionID, bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	return parsed
+}
+
+// FormatCostDiff renders the change between two optional costs, e.g. "+$0.0120".
+func FormatCostDiff(oldCost, newCost *float64) string {
+	var o, n float64
+	if oldCost != nil {
+		o = *oldCost
+	}
+	if newCost != nil {
+		n = *newCost
+	}
+	diff := n - o
+	if diff >= 0 {
+		return fmt.Sprintf("+$%.4f", diff)
+	}
+	return fmt.Sprintf("-$%.4f", -diff)
+}
@@ -41,6 +41,28 @@ func FormatTokensInt(n int64) string {
 	return fmt.Sprintf("%.1fM", float64(n)/1000000)
 }
 
+// FormatDurationMs formats a duration in milliseconds for readability.
+// Examples: 350 -> "350ms", 1500 -> "1.5s", 125000 -> "2m05s"
+func FormatDurationMs(ms float64) string {
+	if ms < 1000 {
+		return fmt.Sprintf("%.0fms", ms)
+	}
+	if ms < 60000 {
+		return fmt.Sprintf("%.1fs", ms/1000)
+	}
+	secs := int64(ms / 1000)
+	return fmt.Sprintf("%dm%02ds", secs/60, secs%60)
+}
+
+// AverageMs returns total/count, or nil when count is zero.
+func AverageMs(total, count float64) *float64 {
+	if count <= 0 {
+		return nil
+	}
+	avg := total / count
+	return &avg
+}
+
 // FormatDateISO formats an RFC3339 timestamp string to ISO date format (2006-01-02).
 // Returns the original string if parsing fails.
 func FormatDateISO(s string) string {
@@ -0,0 +1,83 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/util"
+	"github.com/emiliopalmerini/mclaude/internal/web/templates"
+)
+
+func (s *Server) handleCommands(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	period := r.URL.Query().Get("period")
+	startDate := util.GetStartDateForPeriod(period)
+
+	commands, err := s.statsRepo.GetSlashCommands(ctx, startDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	skills, err := s.statsRepo.GetSkills(ctx, startDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	hooks, err := s.statsRepo.GetHooks(ctx, startDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data := templates.CommandsData{
+		FilterPeriod:  period,
+		SlashCommands: make([]templates.SlashCommandUsage, len(commands)),
+		Skills:        make([]templates.SkillUsage, len(skills)),
+		Hooks:         hookUsage(hooks),
+	}
+	for i, c := range commands {
+		data.SlashCommands[i] = templates.SlashCommandUsage{
+			Command:  c.Command,
+			Kind:     c.Kind,
+			Sessions: c.SessionCount,
+			Runs:     c.TotalInvocations,
+			LastUsed: formatLastUsed(c.LastUsedAt),
+		}
+	}
+	for i, sk := range skills {
+		data.Skills[i] = templates.SkillUsage{
+			Skill:    sk.Skill,
+			Sessions: sk.SessionCount,
+			Runs:     sk.TotalInvocations,
+			LastUsed: formatLastUsed(sk.LastUsedAt),
+		}
+	}
+
+	templates.CommandsPage(data).Render(ctx, w)
+}
+
+func hookUsage(hooks []domain.HookStats) []templates.HookUsage {
+	usage := make([]templates.HookUsage, len(hooks))
+	for i, h := range hooks {
+		usage[i] = templates.HookUsage{
+			Event:    h.Event,
+			Name:     h.HookName,
+			Command:  h.Command,
+			Sessions: h.SessionCount,
+			Runs:     h.TotalExecutions,
+			Errors:   h.TotalErrors,
+			Blocked:  h.TotalBlocked,
+		}
+	}
+	return usage
+}
+
+// formatLastUsed returns when a command was last used as RFC3339, or "" if
+// it's unknown.
+func formatLastUsed(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -42,6 +42,9 @@ func (s *Server) fetchDashboardData(ctx context.Context, filters dashboardFilter
 		activeExp      sqlc.Experiment
 		defaultModel   sqlc.ModelPricing
 		tools          []sqlc.GetTopToolsUsageRow
+		slowCommands   []domain.CommandStats
+		failingCmds    []domain.CommandStats
+		mcpServers     []domain.MCPServerStats
 		sessions       []sqlc.ListSessionsWithMetricsRow
 		qualityStats   sqlc.GetOverallQualityStatsRow
 		qualityStatsOK bool
@@ -143,13 +146,33 @@ func (s *Server) fetchDashboardData(ctx context.Context, filters dashboardFilter
 		return nil
 	})
 
-	// 8. Recent sessions
+	// 8. Slowest and most failing commands
+	g.Go(func() error {
+		slowCommands, _ = s.statsRepo.GetSlowestCommands(gctx, startDate, 5)
+		return nil
+	})
+	g.Go(func() error {
+		failingCmds, _ = s.statsRepo.GetFailingCommands(gctx, startDate, 5)
+		return nil
+	})
+
+	// MCP servers, for the experiment when filtered by one
+	g.Go(func() error {
+		if filters.Experiment != "" {
+			mcpServers, _ = s.statsRepo.GetMCPServersByExperiment(gctx, filters.Experiment, startDate)
+		} else {
+			mcpServers, _ = s.statsRepo.GetMCPServers(gctx, startDate)
+		}
+		return nil
+	})
+
+	// 9. Recent sessions
 	g.Go(func() error {
 		sessions, _ = queries.ListSessionsWithMetrics(gctx, 5)
 		return nil
 	})
 
-	// 9. Quality stats
+	// 10. Quality stats
 	g.Go(func() error {
 		var err error
 		qualityStats, err = queries.GetOverallQualityStats(gctx)
@@ -198,12 +221,17 @@ func (s *Server) fetchDashboardData(ctx context.Context, filters dashboardFilter
 	for _, t := range tools {
 		if t.TotalInvocations.Valid {
 			topTools = append(topTools, templates.ToolUsage{
-				Name:  t.ToolName,
-				Count: int64(t.TotalInvocations.Float64),
+				Name:          t.ToolName,
+				Count:         int64(t.TotalInvocations.Float64),
+				Errors:        int64(t.TotalErrors.Float64),
+				AvgDurationMs: util.AverageMs(t.TotalDurationMs.Float64, t.TimedInvocations.Float64),
 			})
 		}
 	}
 	stats.TopTools = topTools
+	stats.SlowCommands = commandUsage(slowCommands)
+	stats.FailingCommands = commandUsage(failingCmds)
+	stats.MCPServers = mcpServerUsage(mcpServers)
 
 	recentSessions := make([]templates.SessionSummary, 0, len(sessions))
 	for _, sess := range sessions {
@@ -232,3 +260,31 @@ func (s *Server) fetchDashboardData(ctx context.Context, filters dashboardFilter
 
 	return stats
 }
+
+func mcpServerUsage(servers []domain.MCPServerStats) []templates.MCPServerUsage {
+	usage := make([]templates.MCPServerUsage, len(servers))
+	for i, s := range servers {
+		usage[i] = templates.MCPServerUsage{
+			Server:       s.Server,
+			Tools:        s.ToolCount,
+			Sessions:     s.SessionCount,
+			Calls:        s.TotalInvocations,
+			Errors:       s.TotalErrors,
+			ResultTokens: s.ResultTokens,
+		}
+	}
+	return usage
+}
+
+func commandUsage(commands []domain.CommandStats) []templates.CommandUsage {
+	usage := make([]templates.CommandUsage, len(commands))
+	for i, c := range commands {
+		usage[i] = templates.CommandUsage{
+			Command:       c.Command,
+			Runs:          c.RunCount,
+			Failures:      c.FailureCount,
+			AvgDurationMs: c.AvgDurationMs,
+		}
+	}
+	return usage
+}
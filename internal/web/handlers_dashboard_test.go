@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/emiliopalmerini/mclaude/internal/adapters/turso"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
 	"github.com/emiliopalmerini/mclaude/internal/migrate"
+	"github.com/emiliopalmerini/mclaude/internal/pricing"
 	sqlc "github.com/emiliopalmerini/mclaude/sqlc/generated"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -71,6 +73,17 @@ func testServer(t *testing.T, db *sql.DB) *Server {
 		repos.Quality, repos.PlanConfig, repos.Experiments,
 		repos.Pricing, repos.Sessions, repos.Metrics,
 		repos.Stats, repos.Projects,
+		pricing.NewRecomputer(repos.Costs, repos.ModelUsage, repos.Subagents, repos.Pricing, nil),
+		ingest.NewRecorder(ingest.Deps{
+			Projects:    repos.Projects,
+			Experiments: repos.Experiments,
+			Pricing:     repos.Pricing,
+			Recordings:  repos.Recordings,
+			Live:        repos.Live,
+			States:      repos.States,
+			ToolRules:   repos.ToolRules,
+			PlanConfig:  repos.PlanConfig,
+		}),
 	)
 }
 
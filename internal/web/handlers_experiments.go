@@ -113,6 +113,14 @@ func (s *Server) handleExperimentDetail(w http.ResponseWriter, r *http.Request)
 			detail.TokensPerSession = detail.TotalTokens / statsRow.SessionCount
 			detail.CostPerSession = detail.TotalCost / float64(statsRow.SessionCount)
 		}
+		detail.LinesAdded = util.ToInt64(statsRow.TotalLinesAdded)
+		detail.LinesRemoved = util.ToInt64(statsRow.TotalLinesRemoved)
+		detail.LinesPerSession, detail.LinesPerDollar = lineRates(detail.LinesAdded+detail.LinesRemoved, statsRow.SessionCount, detail.TotalCost)
+		detail.CacheHitRatio = domain.CacheHitRatio(detail.TokenInput, detail.CacheRead, detail.CacheWrite)
+		detail.CacheWasteRatio = domain.CacheWasteRatio(detail.CacheWrite, util.ToInt64(statsRow.TotalTokenCacheWasted))
+		if statsRow.SessionCount > 0 {
+			detail.CompactionsPerSession = float64(util.ToInt64(statsRow.TotalCompactions)) / float64(statsRow.SessionCount)
+		}
 	}
 
 	// Get top tools for this experiment
@@ -123,12 +131,18 @@ func (s *Server) handleExperimentDetail(w http.ResponseWriter, r *http.Request)
 	for _, t := range tools {
 		if t.TotalInvocations.Valid {
 			detail.TopTools = append(detail.TopTools, templates.ToolUsage{
-				Name:  t.ToolName,
-				Count: int64(t.TotalInvocations.Float64),
+				Name:          t.ToolName,
+				Count:         int64(t.TotalInvocations.Float64),
+				Errors:        int64(t.TotalErrors.Float64),
+				AvgDurationMs: util.AverageMs(t.TotalDurationMs.Float64, t.TimedInvocations.Float64),
 			})
 		}
 	}
 
+	// Get MCP server usage for this experiment
+	mcpServers, _ := s.statsRepo.GetMCPServersByExperiment(ctx, exp.ID, "1970-01-01T00:00:00Z")
+	detail.MCPServers = mcpServerUsage(mcpServers)
+
 	// Get recent sessions for this experiment (with metrics in single query)
 	sessions, _ := queries.ListSessionsWithMetricsByExperiment(ctx, sqlc.ListSessionsWithMetricsByExperimentParams{
 		ExperimentID: util.NullString(exp.ID),
@@ -225,6 +239,14 @@ func (s *Server) handleExperimentCompare(w http.ResponseWriter, r *http.Request)
 				item.TokensPerSession = item.TotalTokens / statsRow.SessionCount
 				item.CostPerSession = item.TotalCost / float64(statsRow.SessionCount)
 			}
+			item.LinesAdded = util.ToInt64(statsRow.TotalLinesAdded)
+			item.LinesRemoved = util.ToInt64(statsRow.TotalLinesRemoved)
+			item.LinesPerSession, item.LinesPerDollar = lineRates(item.LinesAdded+item.LinesRemoved, statsRow.SessionCount, item.TotalCost)
+			item.CacheHitRatio = domain.CacheHitRatio(item.TokenInput, item.CacheRead, item.CacheWrite)
+			item.CacheWasteRatio = domain.CacheWasteRatio(item.CacheWrite, util.ToInt64(statsRow.TotalTokenCacheWasted))
+			if statsRow.SessionCount > 0 {
+				item.CompactionsPerSession = float64(util.ToInt64(statsRow.TotalCompactions)) / float64(statsRow.SessionCount)
+			}
 		}
 
 		// Get quality stats
@@ -396,3 +418,16 @@ func splitIDs(s string) []string {
 	}
 	return ids
 }
+
+// lineRates returns the lines changed per session and per dollar spent. The
+// rate per dollar is nil when nothing was spent.
+func lineRates(lines, sessions int64, cost float64) (perSession int64, perDollar *float64) {
+	if sessions > 0 {
+		perSession = lines / sessions
+	}
+	if cost > 0 {
+		rate := float64(lines) / cost
+		perDollar = &rate
+	}
+	return perSession, perDollar
+}
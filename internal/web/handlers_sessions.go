@@ -1,11 +1,13 @@
 package web
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
 	"github.com/emiliopalmerini/mclaude/internal/parser"
 	"github.com/emiliopalmerini/mclaude/internal/ports"
 	"github.com/emiliopalmerini/mclaude/internal/util"
@@ -20,6 +22,7 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 	// Read filter params
 	experimentFilter := r.URL.Query().Get("experiment")
 	projectFilter := r.URL.Query().Get("project")
+	branchFilter := r.URL.Query().Get("branch")
 	limitStr := r.URL.Query().Get("limit")
 
 	limit := 50
@@ -37,6 +40,9 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 	if projectFilter != "" {
 		opts.ProjectID = &projectFilter
 	}
+	if branchFilter != "" {
+		opts.Branch = &branchFilter
+	}
 
 	domainSessions, _ := s.sessionRepo.List(ctx, opts)
 
@@ -109,6 +115,7 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 		Sessions:         sessionList,
 		FilterExperiment: experimentFilter,
 		FilterProject:    projectFilter,
+		FilterBranch:     branchFilter,
 		FilterLimit:      limit,
 		MaxTokens:        maxTokens,
 	}
@@ -120,6 +127,12 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 		pageData.Projects = append(pageData.Projects, templates.FilterOption{ID: id, Name: name})
 	}
 
+	if branches, err := queries.ListGitBranches(ctx); err == nil {
+		for _, b := range branches {
+			pageData.Branches = append(pageData.Branches, b.String)
+		}
+	}
+
 	templates.SessionsPage(pageData).Render(ctx, w)
 }
 
@@ -143,10 +156,17 @@ func (s *Server) handleSessionDetail(w http.ResponseWriter, r *http.Request) {
 	// Get tools
 	tools, _ := queries.ListSessionToolsBySessionID(ctx, id)
 	for _, t := range tools {
-		detail.Tools = append(detail.Tools, templates.ToolUsage{
-			Name:  t.ToolName,
-			Count: t.InvocationCount,
-		})
+		tool := templates.ToolUsage{
+			Name:       t.ToolName,
+			Count:      t.InvocationCount,
+			Errors:     t.ErrorCount,
+			Rejections: t.RejectionCount,
+			Denials:    t.PermissionDenialCount,
+		}
+		if t.TotalDurationMs.Valid {
+			tool.AvgDurationMs = util.AverageMs(float64(t.TotalDurationMs.Int64), float64(t.InvocationCount))
+		}
+		detail.Tools = append(detail.Tools, tool)
 	}
 
 	// Get files
@@ -172,6 +192,41 @@ func (s *Server) handleSessionDetail(w http.ResponseWriter, r *http.Request) {
 		detail.Subagents = append(detail.Subagents, usage)
 	}
 
+	// Get each sub-agent run, nested under the agent that started it
+	if subagents, err := queries.ListSessionSubagentsBySessionID(ctx, id); err == nil {
+		subagentTools, _ := queries.ListSessionSubagentToolsBySessionID(ctx, id)
+		subagentFiles, _ := queries.ListSessionSubagentFilesBySessionID(ctx, id)
+		detail.SubagentRuns = buildSubagentTree(subagents, subagentTools, subagentFiles)
+	}
+
+	// Get per-model usage
+	modelUsage, _ := queries.ListSessionModelUsageBySessionID(ctx, id)
+	for _, mu := range modelUsage {
+		detail.Models = append(detail.Models, templates.ModelUsage{
+			ModelID:    mu.ModelID,
+			Messages:   mu.MessageCount,
+			Input:      mu.TokenInput,
+			Output:     mu.TokenOutput,
+			CacheRead:  mu.TokenCacheRead,
+			CacheWrite: mu.TokenCacheWrite,
+			Cost:       mu.CostEstimateUsd.Float64,
+		})
+	}
+
+	// Get commits made during the session
+	commits, _ := queries.ListSessionCommitsBySessionID(ctx, id)
+	for _, c := range commits {
+		detail.Commits = append(detail.Commits, templates.SessionCommit{
+			SHA:          c.Sha,
+			Subject:      c.Subject,
+			Author:       c.Author,
+			CommittedAt:  c.CommittedAt,
+			FilesChanged: c.FilesChanged,
+			Insertions:   c.Insertions,
+			Deletions:    c.Deletions,
+		})
+	}
+
 	// Get quality
 	if q, err := queries.GetSessionQualityBySessionID(ctx, id); err == nil {
 		quality := templates.SessionQuality{
@@ -245,6 +300,24 @@ func (s *Server) handleSessionReview(w http.ResponseWriter, r *http.Request) {
 		Transcript:    transcriptMessages,
 	}
 
+	// Get the cost of each prompt
+	turns, _ := queries.ListSessionTurnsBySessionID(ctx, id)
+	for _, t := range turns {
+		turn := templates.SessionTurn{
+			Index:     t.TurnIndex,
+			Prompt:    t.Prompt,
+			StartedAt: t.StartedAt.String,
+			ToolCalls: t.ToolCalls,
+			Tokens:    t.TokenInput + t.TokenOutput + t.TokenCacheRead + t.TokenCacheWrite,
+			Cost:      t.CostEstimateUsd.Float64,
+		}
+		if t.DurationMs.Valid {
+			turn.DurationMs = &t.DurationMs.Int64
+		}
+		viewData.MaxTurnCost = max(viewData.MaxTurnCost, turn.Cost)
+		viewData.Turns = append(viewData.Turns, turn)
+	}
+
 	templates.SessionReviewPage(viewData).Render(ctx, w)
 }
 
@@ -374,6 +447,66 @@ func (s *Server) handleAPICleanupSessions(w http.ResponseWriter, r *http.Request
 	w.WriteHeader(http.StatusOK)
 }
 
+// handleAPIRecordSession records a session from a SessionEnd hook payload,
+// so hooks can post to a running server instead of running 'mclaude record'.
+// It's only served to localhost, since the server reads the transcript path
+// it's given.
+func (s *Server) handleAPIRecordSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input domain.HookInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid hook input", http.StatusBadRequest)
+		return
+	}
+	if input.SessionID == "" || input.TranscriptPath == "" {
+		http.Error(w, "session_id and transcript_path are required", http.StatusBadRequest)
+		return
+	}
+
+	if domain.LiveHookEvents[input.HookEventName] {
+		live, err := s.recorder.Track(ctx, &input)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if live == nil {
+			// Another hook already covered these lines
+			json.NewEncoder(w).Encode(map[string]any{"session_id": input.SessionID, "live": true})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"session_id":        live.SessionID,
+			"live":              true,
+			"total_tokens":      live.TotalTokens(),
+			"cost_estimate_usd": live.CostEstimateUSD,
+		})
+		return
+	}
+
+	result, err := s.recorder.Record(ctx, &input, ingest.Options{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"session_id":          result.SessionID,
+		"project_id":          result.ProjectID,
+		"experiment_id":       result.ExperimentID,
+		"token_input":         result.Metrics.TokenInput,
+		"token_output":        result.Metrics.TokenOutput,
+		"cost_estimate_usd":   result.Metrics.CostEstimateUSD,
+		"models":              len(result.ModelUsage),
+		"subagents":           result.Subagents,
+		"window_reset":        result.WindowReset,
+		"weekly_window_reset": result.WeeklyWindowReset,
+		"warnings":            result.Warnings,
+	})
+}
+
 func convertDomainQualityToTemplate(q *domain.SessionQuality) templates.SessionQuality {
 	tq := templates.SessionQuality{
 		SessionID: q.SessionID,
@@ -1,12 +1,14 @@
 package web
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/emiliopalmerini/mclaude/internal/domain"
+	"github.com/emiliopalmerini/mclaude/internal/pricing"
 	"github.com/emiliopalmerini/mclaude/internal/web/templates"
 )
 
@@ -17,7 +19,9 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 	pricing, _ := s.pricingRepo.List(ctx)
 
 	models := make([]templates.ModelPricing, 0, len(pricing))
+	pricedIDs := make(map[string]struct{}, len(pricing))
 	for _, p := range pricing {
+		pricedIDs[p.ID] = struct{}{}
 		model := templates.ModelPricing{
 			ID:              p.ID,
 			DisplayName:     p.DisplayName,
@@ -50,6 +54,29 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 		PlanConfig: planView,
 	}
 
+	if aliases, err := s.pricingRepo.ListAliases(ctx); err == nil {
+		for _, a := range aliases {
+			_, priced := pricedIDs[a.ModelID]
+			pageData.Aliases = append(pageData.Aliases, templates.ModelAlias{
+				Alias:   a.Alias,
+				ModelID: a.ModelID,
+				Priced:  priced,
+			})
+		}
+	}
+
+	// Filter options for cost recompute
+	if experiments, err := s.experimentRepo.List(ctx); err == nil {
+		for _, e := range experiments {
+			pageData.Experiments = append(pageData.Experiments, templates.FilterOption{ID: e.ID, Name: e.Name})
+		}
+	}
+	if projects, err := s.projectRepo.List(ctx); err == nil {
+		for _, p := range projects {
+			pageData.Projects = append(pageData.Projects, templates.FilterOption{ID: p.ID, Name: p.Name})
+		}
+	}
+
 	templates.SettingsPage(pageData).Render(ctx, w)
 }
 
@@ -98,12 +125,17 @@ func (s *Server) handleAPICreatePricing(w http.ResponseWriter, r *http.Request)
 		pricing.CacheWritePerMillion = &v
 	}
 
-	// Check if model exists (update) or create new
+	// Check if model exists (new pricing version from now on) or create new
 	existing, _ := s.pricingRepo.GetByID(ctx, modelID)
 	if existing != nil {
 		pricing.IsDefault = existing.IsDefault
-		pricing.CreatedAt = existing.CreatedAt
-		if err := s.pricingRepo.Update(ctx, pricing); err != nil {
+		pricing.LongContextInputPerMillion = existing.LongContextInputPerMillion
+		pricing.LongContextOutputPerMillion = existing.LongContextOutputPerMillion
+		pricing.LongContextThreshold = existing.LongContextThreshold
+		pricing.WebSearchPerThousand = existing.WebSearchPerThousand
+		pricing.WebFetchPerThousand = existing.WebFetchPerThousand
+		pricing.EffectiveFrom = pricing.CreatedAt.Truncate(time.Second)
+		if err := s.pricingRepo.AddVersion(ctx, pricing); err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -149,6 +181,110 @@ func (s *Server) handleAPIDeletePricing(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+func (s *Server) handleAPISetAlias(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	alias := strings.ToLower(strings.TrimSpace(r.FormValue("alias")))
+	modelID := strings.TrimSpace(r.FormValue("model_id"))
+	if alias == "" || modelID == "" {
+		http.Error(w, "Alias and model are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.pricingRepo.SetAlias(ctx, alias, modelID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/settings")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleAPIDeleteAlias(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	alias := r.PathValue("alias")
+
+	if err := s.pricingRepo.DeleteAlias(ctx, alias); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/settings")
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleAPIRecomputeCosts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	filter := domain.CostRecomputeFilter{}
+	if v := r.FormValue("since"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			http.Error(w, "Invalid date format (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		filter.Since = t.Format(time.RFC3339)
+	}
+	if v := r.FormValue("until"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			http.Error(w, "Invalid date format (use YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		filter.Until = t.AddDate(0, 0, 1).Format(time.RFC3339)
+	}
+	if v := r.FormValue("project"); v != "" {
+		filter.ProjectID = &v
+	}
+	if v := r.FormValue("experiment"); v != "" {
+		filter.ExperimentID = &v
+	}
+	if v := r.FormValue("model"); v != "" {
+		filter.ModelID = &v
+	}
+	dryRun := r.FormValue("apply") != "true"
+
+	result, err := s.recomputer.Recompute(ctx, filter, dryRun)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	view := templates.CostRecomputeView{
+		DryRun:   result.DryRun,
+		OldTotal: result.OldTotal,
+		NewTotal: result.NewTotal,
+	}
+	for _, u := range result.Updates {
+		view.Sessions = append(view.Sessions, templates.CostRecomputeRow{
+			SessionID: u.SessionID,
+			Source:    u.Source,
+			OldCost:   formatOptionalCost(u.OldCost),
+			NewCost:   formatOptionalCost(u.NewCost),
+			Diff:      pricing.FormatCostDiff(u.OldCost, u.NewCost),
+		})
+	}
+
+	templates.CostRecomputeResult(view).Render(ctx, w)
+}
+
+func formatOptionalCost(cost *float64) string {
+	if cost == nil {
+		return "-"
+	}
+	return fmt.Sprintf("$%.4f", *cost)
+}
+
 func (s *Server) handleAPISetPlan(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/emiliopalmerini/mclaude/internal/util"
 	"github.com/emiliopalmerini/mclaude/internal/web/templates"
 	sqlc "github.com/emiliopalmerini/mclaude/sqlc/generated"
 )
@@ -63,6 +64,13 @@ func buildSessionDetail(session sqlc.Session, metrics *sqlc.SessionMetric) templ
 	if session.DurationSeconds.Valid {
 		detail.DurationSeconds = session.DurationSeconds.Int64
 	}
+	detail.GitBranch = session.GitBranch.String
+	detail.GitHeadBefore = session.GitHeadBefore.String
+	detail.GitHeadAfter = session.GitHeadAfter.String
+	if session.GitDirty.Valid {
+		dirty := session.GitDirty.Int64 == 1
+		detail.GitDirty = &dirty
+	}
 
 	if metrics != nil {
 		detail.MessageCountUser = metrics.MessageCountUser
@@ -73,6 +81,13 @@ func buildSessionDetail(session sqlc.Session, metrics *sqlc.SessionMetric) templ
 		detail.TokenCacheRead = metrics.TokenCacheRead
 		detail.TokenCacheWrite = metrics.TokenCacheWrite
 		detail.ErrorCount = metrics.ErrorCount
+		detail.ThinkingBlocks = metrics.ThinkingBlockCount
+		detail.ThinkingTokens = metrics.ThinkingTokens
+		detail.WebSearchRequests = metrics.WebSearchRequests
+		detail.WebFetchRequests = metrics.WebFetchRequests
+		detail.Interruptions = metrics.InterruptionCount
+		detail.ToolRejections = metrics.ToolRejectionCount
+		detail.PermissionDenials = metrics.PermissionDenialCount
 		if metrics.CostEstimateUsd.Valid {
 			detail.CostEstimateUsd = metrics.CostEstimateUsd.Float64
 		}
@@ -80,3 +95,75 @@ func buildSessionDetail(session sqlc.Session, metrics *sqlc.SessionMetric) templ
 
 	return detail
 }
+
+// buildSubagentTree orders a session's sub-agents so each one is followed by
+// the agents it started, with their tools and files. Agents whose parent
+// isn't recorded are shown at the top level.
+func buildSubagentTree(subagents []sqlc.SessionSubagent, tools []sqlc.SessionSubagentTool, files []sqlc.SessionSubagentFile) []templates.SubagentRun {
+	recorded := make(map[string]bool, len(subagents))
+	for _, sa := range subagents {
+		if sa.ToolUseID.Valid {
+			recorded[sa.ToolUseID.String] = true
+		}
+	}
+	children := make(map[string][]sqlc.SessionSubagent)
+	var roots []sqlc.SessionSubagent
+	for _, sa := range subagents {
+		if sa.ParentToolUseID.Valid && recorded[sa.ParentToolUseID.String] {
+			children[sa.ParentToolUseID.String] = append(children[sa.ParentToolUseID.String], sa)
+		} else {
+			roots = append(roots, sa)
+		}
+	}
+
+	toolsByAgent := make(map[string][]templates.ToolUsage)
+	for _, t := range tools {
+		tool := templates.ToolUsage{Name: t.ToolName, Count: t.InvocationCount, Errors: t.ErrorCount}
+		if t.TotalDurationMs.Valid {
+			tool.AvgDurationMs = util.AverageMs(float64(t.TotalDurationMs.Int64), float64(t.InvocationCount))
+		}
+		toolsByAgent[t.SubagentToolUseID] = append(toolsByAgent[t.SubagentToolUseID], tool)
+	}
+	filesByAgent := make(map[string][]sqlc.SessionSubagentFile)
+	for _, f := range files {
+		filesByAgent[f.SubagentToolUseID] = append(filesByAgent[f.SubagentToolUseID], f)
+	}
+
+	var runs []templates.SubagentRun
+	var visit func(sa sqlc.SessionSubagent, depth int)
+	visit = func(sa sqlc.SessionSubagent, depth int) {
+		run := templates.SubagentRun{
+			AgentType:   sa.AgentType,
+			AgentKind:   sa.AgentKind,
+			Description: sa.Description.String,
+			Model:       sa.Model.String,
+			Depth:       depth,
+			Tokens:      sa.TotalTokens,
+			Cost:        sa.CostEstimateUsd.Float64,
+			DurationMs:  sa.TotalDurationMs.Int64,
+			ToolCalls:   sa.ToolUseCount,
+			Errors:      sa.ErrorCount,
+		}
+		id := sa.ToolUseID.String
+		if sa.ToolUseID.Valid {
+			run.Tools = toolsByAgent[id]
+			paths := make(map[string]bool)
+			for _, f := range filesByAgent[id] {
+				paths[f.FilePath] = true
+				run.LinesAdded += f.LinesAdded
+				run.LinesRemoved += f.LinesRemoved
+			}
+			run.FilesTouched = len(paths)
+		}
+		runs = append(runs, run)
+		if sa.ToolUseID.Valid {
+			for _, child := range children[id] {
+				visit(child, depth+1)
+			}
+		}
+	}
+	for _, sa := range roots {
+		visit(sa, 0)
+	}
+	return runs
+}
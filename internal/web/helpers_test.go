@@ -115,6 +115,9 @@ func TestBuildSessionDetail_WithMetrics(t *testing.T) {
 		TokenOutput:     500,
 		ErrorCount:      1,
 		CostEstimateUsd: sql.NullFloat64{Float64: 0.05, Valid: true},
+
+		InterruptionCount:  2,
+		ToolRejectionCount: 1,
 	}
 
 	detail := buildSessionDetail(session, metrics)
@@ -128,6 +131,9 @@ func TestBuildSessionDetail_WithMetrics(t *testing.T) {
 	if detail.CostEstimateUsd != 0.05 {
 		t.Errorf("expected cost 0.05, got %f", detail.CostEstimateUsd)
 	}
+	if detail.Interruptions != 2 || detail.ToolRejections != 1 {
+		t.Errorf("expected 2 interruptions and 1 rejection, got %d and %d", detail.Interruptions, detail.ToolRejections)
+	}
 }
 
 func TestBuildSessionDetail_NullableSessionFields(t *testing.T) {
@@ -156,3 +162,46 @@ func TestBuildSessionDetail_NullableSessionFields(t *testing.T) {
 		t.Errorf("expected 1800s duration, got %d", detail.DurationSeconds)
 	}
 }
+
+func TestBuildSubagentTree(t *testing.T) {
+	id := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+	subagents := []sqlc.SessionSubagent{
+		{AgentType: "Explore", AgentKind: "task", ToolUseID: id("task1")},
+		{AgentType: "Plan", AgentKind: "task", ToolUseID: id("task2")},
+		{AgentType: "Bash", AgentKind: "task", ToolUseID: id("task3"), ParentToolUseID: id("task1")},
+		{AgentType: "general-purpose", AgentKind: "task", ToolUseID: id("task4"), ParentToolUseID: id("missing")},
+	}
+	tools := []sqlc.SessionSubagentTool{
+		{SubagentToolUseID: "task1", ToolName: "Read", InvocationCount: 3},
+		{SubagentToolUseID: "task3", ToolName: "Bash", InvocationCount: 1, ErrorCount: 1},
+	}
+	files := []sqlc.SessionSubagentFile{
+		{SubagentToolUseID: "task1", FilePath: "/a.go", Operation: "read", OperationCount: 2},
+		{SubagentToolUseID: "task1", FilePath: "/a.go", Operation: "edit", OperationCount: 1, LinesAdded: 4, LinesRemoved: 1},
+	}
+
+	runs := buildSubagentTree(subagents, tools, files)
+
+	if len(runs) != 4 {
+		t.Fatalf("expected 4 runs, got %d", len(runs))
+	}
+	wantOrder := []struct {
+		agentType string
+		depth     int
+	}{{"Explore", 0}, {"Bash", 1}, {"Plan", 0}, {"general-purpose", 0}}
+	for i, want := range wantOrder {
+		if runs[i].AgentType != want.agentType || runs[i].Depth != want.depth {
+			t.Errorf("run %d: expected %s at depth %d, got %s at depth %d",
+				i, want.agentType, want.depth, runs[i].AgentType, runs[i].Depth)
+		}
+	}
+	if len(runs[0].Tools) != 1 || runs[0].Tools[0].Name != "Read" {
+		t.Errorf("expected Explore to have used Read, got %+v", runs[0].Tools)
+	}
+	if runs[0].FilesTouched != 1 || runs[0].LinesAdded != 4 || runs[0].LinesRemoved != 1 {
+		t.Errorf("expected 1 file +4 -1, got %d files +%d -%d", runs[0].FilesTouched, runs[0].LinesAdded, runs[0].LinesRemoved)
+	}
+	if len(runs[2].Tools) != 0 {
+		t.Errorf("expected Plan to have no tools, got %+v", runs[2].Tools)
+	}
+}
@@ -6,10 +6,13 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/emiliopalmerini/mclaude/internal/ingest"
 	"github.com/emiliopalmerini/mclaude/internal/ports"
+	"github.com/emiliopalmerini/mclaude/internal/pricing"
 )
 
 //go:embed static/*
@@ -28,6 +31,8 @@ type Server struct {
 	metricsRepo       ports.SessionMetricsRepository
 	statsRepo         ports.StatsRepository
 	projectRepo       ports.ProjectRepository
+	recomputer        *pricing.Recomputer
+	recorder          *ingest.Recorder
 }
 
 func NewServer(
@@ -42,6 +47,8 @@ func NewServer(
 	mr ports.SessionMetricsRepository,
 	str ports.StatsRepository,
 	projr ports.ProjectRepository,
+	rc *pricing.Recomputer,
+	rec *ingest.Recorder,
 ) *Server {
 	s := &Server{
 		db:                db,
@@ -56,6 +63,8 @@ func NewServer(
 		metricsRepo:       mr,
 		statsRepo:         str,
 		projectRepo:       projr,
+		recomputer:        rc,
+		recorder:          rec,
 	}
 	s.setupRoutes()
 	return s
@@ -83,6 +92,7 @@ func (s *Server) setupRoutes() {
 	s.router.HandleFunc("GET /experiments", s.handleExperiments)
 	s.router.HandleFunc("GET /experiments/compare", s.handleExperimentCompare)
 	s.router.HandleFunc("GET /experiments/{id}", s.handleExperimentDetail)
+	s.router.HandleFunc("GET /commands", s.handleCommands)
 	s.router.HandleFunc("GET /settings", s.handleSettings)
 
 	// API endpoints (for HTMX)
@@ -103,11 +113,15 @@ func (s *Server) setupRoutes() {
 	// Session management
 	s.router.HandleFunc("DELETE /api/sessions/{id}", s.handleAPIDeleteSession)
 	s.router.HandleFunc("POST /api/sessions/cleanup", s.handleAPICleanupSessions)
+	s.router.HandleFunc("POST /api/sessions/record", localOnly(s.handleAPIRecordSession))
 
 	// Pricing management
 	s.router.HandleFunc("POST /api/pricing", s.handleAPICreatePricing)
 	s.router.HandleFunc("POST /api/pricing/{id}/default", s.handleAPISetDefaultPricing)
 	s.router.HandleFunc("DELETE /api/pricing/{id}", s.handleAPIDeletePricing)
+	s.router.HandleFunc("POST /api/pricing/recompute", s.handleAPIRecomputeCosts)
+	s.router.HandleFunc("POST /api/pricing/aliases", s.handleAPISetAlias)
+	s.router.HandleFunc("DELETE /api/pricing/aliases/{alias}", s.handleAPIDeleteAlias)
 
 	// Limits management
 	s.router.HandleFunc("POST /api/limits/plan", s.handleAPISetPlan)
@@ -121,6 +135,22 @@ func (s *Server) setupRoutes() {
 	s.router.HandleFunc("GET /api/realtime/usage", s.handleAPIRealtimeUsage)
 }
 
+// localOnly restricts a handler to hooks running on the same machine. The
+// server listens on all interfaces, so requests from other hosts are
+// rejected, as are requests from browsers, which send an Origin, so that web
+// pages can't post to the server either.
+func localOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		ip := net.ParseIP(host)
+		if err != nil || ip == nil || !ip.IsLoopback() || r.Header.Get("Origin") != "" {
+			http.Error(w, "Only available from localhost", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
 func (s *Server) Start(ctx context.Context) error {
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", s.port),
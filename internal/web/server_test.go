@@ -1,6 +1,8 @@
 package web
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/emiliopalmerini/mclaude/internal/ports"
@@ -12,3 +14,35 @@ func TestServerFieldsAreInterfaces(t *testing.T) {
 	var _ ports.SessionQualityRepository = s.qualityRepo
 	var _ ports.PlanConfigRepository = s.planConfigRepo
 }
+
+func TestLocalOnly(t *testing.T) {
+	handler := localOnly(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		origin     string
+		want       int
+	}{
+		{"ipv4 loopback", "127.0.0.1:52000", "", http.StatusOK},
+		{"ipv6 loopback", "[::1]:52000", "", http.StatusOK},
+		{"other host", "192.168.1.20:52000", "", http.StatusForbidden},
+		{"browser", "127.0.0.1:52000", "http://example.com", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/sessions/record", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, rec.Code)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package templates
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/a-h/templ"
@@ -12,6 +13,117 @@ func formatTokens(n int64) string {
 	return util.FormatTokensInt(n)
 }
 
+func formatDurationMs(ms float64) string {
+	return util.FormatDurationMs(ms)
+}
+
+// formatToolStats describes a tool's errors, with the calls among them that
+// were rejected or denied, and its average latency, if known.
+func formatToolStats(tool ToolUsage) string {
+	var s string
+	if tool.Errors > 0 {
+		s += fmt.Sprintf(" · %d errors", tool.Errors)
+		if tool.Rejections > 0 || tool.Denials > 0 {
+			s += " (" + formatRefusals(tool.Rejections, tool.Denials) + ")"
+		}
+	}
+	if tool.AvgDurationMs != nil {
+		s += " · avg " + formatDurationMs(*tool.AvgDurationMs)
+	}
+	return s
+}
+
+// formatRefusals describes tool calls the user rejected and ones permission
+// settings denied.
+func formatRefusals(rejected, denied int64) string {
+	return fmt.Sprintf("%d rejected / %d denied", rejected, denied)
+}
+
+// slashCommandKindBadge colors a slash command by where it comes from.
+func slashCommandKindBadge(kind string) string {
+	switch kind {
+	case "custom":
+		return "badge-green"
+	case "mcp":
+		return "badge-blue"
+	default:
+		return "badge-gray"
+	}
+}
+
+// formatHookStats describes a hook's runs, failures and blocked actions.
+func formatHookStats(h HookUsage) string {
+	s := fmt.Sprintf("%d runs", h.Runs)
+	if h.Sessions > 0 {
+		s += fmt.Sprintf(" in %d sessions", h.Sessions)
+	}
+	if h.Errors > 0 {
+		s += fmt.Sprintf(" · %d errors", h.Errors)
+	}
+	if h.Blocked > 0 {
+		s += fmt.Sprintf(" · %d blocked", h.Blocked)
+	}
+	return s
+}
+
+func formatMCPServerStats(s MCPServerUsage) string {
+	detail := fmt.Sprintf("%d calls", s.Calls)
+	if s.Errors > 0 {
+		detail += fmt.Sprintf(" · %d errors", s.Errors)
+	}
+	return detail + " · ~" + formatTokens(s.ResultTokens) + " result tokens"
+}
+
+func formatLinesPerDollar(lines *float64) string {
+	if lines == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f", *lines)
+}
+
+func formatAvgDuration(ms *float64) string {
+	if ms == nil {
+		return "-"
+	}
+	return "avg " + formatDurationMs(*ms)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// formatCommitRange shows the commits a session spans, or the single commit
+// HEAD stayed on.
+func formatCommitRange(before, after string) string {
+	if before == "" {
+		return "(root).." + shortSHA(after)
+	}
+	if before == after {
+		return shortSHA(after)
+	}
+	return shortSHA(before) + ".." + shortSHA(after)
+}
+
+func formatDirty(dirty bool) string {
+	if dirty {
+		return "uncommitted changes"
+	}
+	return "clean"
+}
+
+// formatThinking shows the thinking blocks of a session and the share of its
+// output tokens they're estimated to take.
+func formatThinking(blocks, tokens, output int64) string {
+	s := fmt.Sprintf("%d blocks, ~%s tokens", blocks, formatTokens(tokens))
+	if output > 0 {
+		s += fmt.Sprintf(" (%s of output)", formatPercent(float64(tokens)/float64(output)))
+	}
+	return s
+}
+
 func truncateID(id string) string {
 	if len(id) > 12 {
 		return id[:12]
@@ -83,6 +195,13 @@ func buildSessionsExportURL(format, experiment string, limit int) templ.SafeURL
 	return templ.SafeURL(url)
 }
 
+func buildCommandsURL(period string) templ.SafeURL {
+	if period == "" {
+		return "/commands"
+	}
+	return templ.SafeURL("/commands?period=" + period)
+}
+
 func buildDashboardURL(period, experiment, project string) templ.SafeURL {
 	url := "/?"
 	params := []string{}
@@ -112,6 +231,28 @@ func tokenBarWidth(tokens, maxTokens int64) string {
 	return fmt.Sprintf("width: %.0f%%", pct)
 }
 
+func costBarWidth(cost, maxCost float64) string {
+	if maxCost == 0 {
+		return "width: 0%"
+	}
+	return fmt.Sprintf("width: %.0f%%", cost/maxCost*100)
+}
+
+// subagentIndent indents a sub-agent run under the agent that started it.
+func subagentIndent(depth int) string {
+	return fmt.Sprintf("padding-left: %.1frem", float64(depth)*1.5)
+}
+
+// formatSubagentTools lists a sub-agent's tools with their call counts, e.g.
+// "Read 3 · Grep 1".
+func formatSubagentTools(tools []ToolUsage) string {
+	parts := make([]string, len(tools))
+	for i, tool := range tools {
+		parts[i] = fmt.Sprintf("%s %d", tool.Name, tool.Count)
+	}
+	return strings.Join(parts, " · ")
+}
+
 func planDisplayName(planType string) string {
 	switch planType {
 	case "pro":
@@ -13,6 +13,9 @@ type DashboardStats struct {
 	ActiveExperiment string
 	DefaultModel     string // Display name of the default model for cost calculations
 	TopTools         []ToolUsage
+	SlowCommands     []CommandUsage
+	FailingCommands  []CommandUsage
+	MCPServers       []MCPServerUsage
 	RecentSessions   []SessionSummary
 	// Quality stats
 	ReviewedCount int64
@@ -39,16 +42,37 @@ type SessionsPageData struct {
 	Sessions         []SessionSummary
 	FilterExperiment string
 	FilterProject    string
+	FilterBranch     string
 	FilterLimit      int
 	Experiments      []FilterOption
 	Projects         []FilterOption
+	Branches         []string
 	MaxTokens        int64
 }
 
 // SettingsPageData wraps pricing and plan config for the settings page.
 type SettingsPageData struct {
-	Pricing    []ModelPricing
-	PlanConfig *PlanConfigView
+	Pricing     []ModelPricing
+	Aliases     []ModelAlias
+	PlanConfig  *PlanConfigView
+	Experiments []FilterOption
+	Projects    []FilterOption
+}
+
+// CostRecomputeView shows the before/after costs of a recompute run.
+type CostRecomputeView struct {
+	DryRun   bool
+	Sessions []CostRecomputeRow
+	OldTotal float64
+	NewTotal float64
+}
+
+type CostRecomputeRow struct {
+	SessionID string
+	Source    string
+	OldCost   string
+	NewCost   string
+	Diff      string
 }
 
 // PlanConfigView for displaying plan config in settings.
@@ -90,8 +114,28 @@ type RealtimeUsageStats struct {
 }
 
 type ToolUsage struct {
-	Name  string
-	Count int64
+	Name          string
+	Count         int64
+	Errors        int64
+	Rejections    int64 // Included in Errors, like Denials
+	Denials       int64
+	AvgDurationMs *float64
+}
+
+type CommandUsage struct {
+	Command       string
+	Runs          int64
+	Failures      int64
+	AvgDurationMs *float64
+}
+
+type MCPServerUsage struct {
+	Server       string
+	Tools        int64
+	Sessions     int64
+	Calls        int64
+	Errors       int64
+	ResultTokens int64 // Estimated
 }
 
 type SessionSummary struct {
@@ -120,6 +164,35 @@ type SubagentUsage struct {
 	DurationMs int64
 }
 
+// SubagentRun is a single sub-agent of a session, placed in the tree of
+// agents started by agents. Depth is 0 for agents the session started.
+type SubagentRun struct {
+	AgentType    string
+	AgentKind    string
+	Description  string
+	Model        string
+	Depth        int
+	Tokens       int64
+	Cost         float64
+	DurationMs   int64
+	ToolCalls    int64
+	Errors       int64
+	Tools        []ToolUsage
+	FilesTouched int
+	LinesAdded   int64
+	LinesRemoved int64
+}
+
+type ModelUsage struct {
+	ModelID    string
+	Messages   int64
+	Input      int64
+	Output     int64
+	CacheRead  int64
+	CacheWrite int64
+	Cost       float64
+}
+
 type SessionDetail struct {
 	ID                    string
 	ProjectID             string
@@ -140,13 +213,38 @@ type SessionDetail struct {
 	TokenCacheWrite       int64
 	CostEstimateUsd       float64
 	ErrorCount            int64
+	ThinkingBlocks        int64
+	ThinkingTokens        int64
+	WebSearchRequests     int64
+	WebFetchRequests      int64
+	Interruptions         int64
+	ToolRejections        int64
+	PermissionDenials     int64
 	Tools                 []ToolUsage
 	Files                 []FileOperation
 	Subagents             []SubagentUsage
+	SubagentRuns          []SubagentRun
+	Models                []ModelUsage
+	// Git
+	GitBranch     string
+	GitHeadBefore string
+	GitHeadAfter  string
+	GitDirty      *bool
+	Commits       []SessionCommit
 	// Quality
 	Quality *SessionQuality
 }
 
+type SessionCommit struct {
+	SHA          string
+	Subject      string
+	Author       string
+	CommittedAt  string
+	FilesChanged int64
+	Insertions   int64
+	Deletions    int64
+}
+
 type FileOperation struct {
 	Path      string
 	Operation string
@@ -180,21 +278,30 @@ type ExperimentDetail struct {
 	IsActive    bool
 	CreatedAt   string
 	// Stats
-	SessionCount      int64
-	TotalTurns        int64
-	UserMessages      int64
-	AssistantMessages int64
-	TotalErrors       int64
-	TokenInput        int64
-	TokenOutput       int64
-	CacheRead         int64
-	CacheWrite        int64
-	TotalTokens       int64
-	TotalCost         float64
-	TokensPerSession  int64
-	CostPerSession    float64
+	SessionCount          int64
+	TotalTurns            int64
+	UserMessages          int64
+	AssistantMessages     int64
+	TotalErrors           int64
+	TokenInput            int64
+	TokenOutput           int64
+	CacheRead             int64
+	CacheWrite            int64
+	TotalTokens           int64
+	TotalCost             float64
+	TokensPerSession      int64
+	CostPerSession        float64
+	LinesAdded            int64
+	LinesRemoved          int64
+	LinesPerSession       int64
+	LinesPerDollar        *float64 // nil when nothing was spent
+	CacheHitRatio         float64
+	CacheWasteRatio       float64
+	CompactionsPerSession float64
 	// Top tools
 	TopTools []ToolUsage
+	// MCP servers
+	MCPServers []MCPServerUsage
 	// Recent sessions
 	RecentSessions []SessionSummary
 	// Quality stats
@@ -211,21 +318,28 @@ type ExperimentComparison struct {
 }
 
 type ExperimentCompareItem struct {
-	Name              string
-	IsActive          bool
-	SessionCount      int64
-	TotalTurns        int64
-	UserMessages      int64
-	AssistantMessages int64
-	TotalErrors       int64
-	TokenInput        int64
-	TokenOutput       int64
-	CacheRead         int64
-	CacheWrite        int64
-	TotalTokens       int64
-	TotalCost         float64
-	TokensPerSession  int64
-	CostPerSession    float64
+	Name                  string
+	IsActive              bool
+	SessionCount          int64
+	TotalTurns            int64
+	UserMessages          int64
+	AssistantMessages     int64
+	TotalErrors           int64
+	TokenInput            int64
+	TokenOutput           int64
+	CacheRead             int64
+	CacheWrite            int64
+	TotalTokens           int64
+	TotalCost             float64
+	TokensPerSession      int64
+	CostPerSession        float64
+	LinesAdded            int64
+	LinesRemoved          int64
+	LinesPerSession       int64
+	LinesPerDollar        *float64 // nil when nothing was spent
+	CacheHitRatio         float64
+	CacheWasteRatio       float64
+	CompactionsPerSession float64
 	// Quality metrics
 	ReviewedCount  int64
 	AvgOverall     *float64
@@ -245,6 +359,13 @@ type ModelPricing struct {
 	IsDefault            bool
 }
 
+// ModelAlias maps a short model name to a model ID
+type ModelAlias struct {
+	Alias   string
+	ModelID string
+	Priced  bool
+}
+
 // SessionQuality for review form and display
 type SessionQuality struct {
 	SessionID         string
@@ -271,11 +392,24 @@ type TranscriptToolUse struct {
 	Input string
 }
 
+// SessionTurn is one prompt of a session and what answering it cost
+type SessionTurn struct {
+	Index      int64
+	Prompt     string
+	StartedAt  string
+	DurationMs *int64
+	ToolCalls  int64
+	Tokens     int64
+	Cost       float64
+}
+
 // SessionReviewData combines session detail with quality and transcript
 type SessionReviewData struct {
 	SessionDetail
-	Quality    SessionQuality
-	Transcript []TranscriptMessage
+	Quality     SessionQuality
+	Turns       []SessionTurn
+	MaxTurnCost float64
+	Transcript  []TranscriptMessage
 }
 
 // QualityStats for experiment comparison
@@ -289,3 +423,36 @@ type QualityStats struct {
 	AvgHelpfulness *float64
 	AvgEfficiency  *float64
 }
+
+// CommandsData for the slash commands, skills and hooks page
+type CommandsData struct {
+	FilterPeriod  string
+	SlashCommands []SlashCommandUsage
+	Skills        []SkillUsage
+	Hooks         []HookUsage
+}
+
+type SlashCommandUsage struct {
+	Command  string
+	Kind     string // "builtin", "custom" or "mcp"
+	Sessions int64
+	Runs     int64
+	LastUsed string // RFC3339, empty on session pages
+}
+
+type SkillUsage struct {
+	Skill    string
+	Sessions int64
+	Runs     int64
+	LastUsed string
+}
+
+type HookUsage struct {
+	Event    string
+	Name     string // Event and matcher, e.g. "PreToolUse:Bash"
+	Command  string
+	Sessions int64
+	Runs     int64
+	Errors   int64
+	Blocked  int64
+}
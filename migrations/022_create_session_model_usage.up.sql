@@ -0,0 +1,15 @@
+CREATE TABLE session_model_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    model_id TEXT NOT NULL,
+    message_count INTEGER NOT NULL DEFAULT 0,
+    token_input INTEGER NOT NULL DEFAULT 0,
+    token_output INTEGER NOT NULL DEFAULT 0,
+    token_cache_read INTEGER NOT NULL DEFAULT 0,
+    token_cache_write INTEGER NOT NULL DEFAULT 0,
+    cost_estimate_usd REAL,
+    UNIQUE(session_id, model_id)
+);
+
+CREATE INDEX idx_session_model_usage_session_id ON session_model_usage(session_id);
+CREATE INDEX idx_session_model_usage_model_id ON session_model_usage(model_id);
@@ -0,0 +1,25 @@
+-- Keep only the current version of each model
+CREATE TABLE model_pricing_current (
+    id TEXT PRIMARY KEY,
+    display_name TEXT NOT NULL,
+    input_per_million REAL NOT NULL,
+    output_per_million REAL NOT NULL,
+    cache_read_per_million REAL,
+    cache_write_per_million REAL,
+    is_default INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    long_context_input_per_million REAL,
+    long_context_output_per_million REAL,
+    long_context_threshold INTEGER
+);
+
+INSERT INTO model_pricing_current (id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, is_default, created_at, long_context_input_per_million, long_context_output_per_million, long_context_threshold)
+SELECT id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, is_default, created_at, long_context_input_per_million, long_context_output_per_million, long_context_threshold
+FROM model_pricing
+WHERE effective_to IS NULL;
+
+DROP TABLE model_pricing;
+
+ALTER TABLE model_pricing_current RENAME TO model_pricing;
+
+CREATE INDEX idx_model_pricing_is_default ON model_pricing(is_default);
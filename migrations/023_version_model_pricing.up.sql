@@ -0,0 +1,31 @@
+-- Keep a history of pricing per model. Each row is the pricing in force from
+-- effective_from until effective_to, and the current row has no effective_to.
+-- Existing rows become the first version of each model, effective since the epoch.
+
+CREATE TABLE model_pricing_versioned (
+    id TEXT NOT NULL,
+    display_name TEXT NOT NULL,
+    input_per_million REAL NOT NULL,
+    output_per_million REAL NOT NULL,
+    cache_read_per_million REAL,
+    cache_write_per_million REAL,
+    long_context_input_per_million REAL,
+    long_context_output_per_million REAL,
+    long_context_threshold INTEGER,
+    is_default INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (datetime('now')),
+    effective_from TEXT NOT NULL DEFAULT '1970-01-01T00:00:00Z',
+    effective_to TEXT,
+    PRIMARY KEY (id, effective_from)
+);
+
+INSERT INTO model_pricing_versioned (id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, is_default, created_at)
+SELECT id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, is_default, created_at
+FROM model_pricing;
+
+DROP TABLE model_pricing;
+
+ALTER TABLE model_pricing_versioned RENAME TO model_pricing;
+
+CREATE INDEX idx_model_pricing_is_default ON model_pricing(is_default);
+CREATE INDEX idx_model_pricing_effective_to ON model_pricing(id, effective_to);
@@ -0,0 +1,8 @@
+-- Short model names (e.g. "haiku") mapped to the model ID used for pricing
+CREATE TABLE model_aliases (
+    alias TEXT PRIMARY KEY,
+    model_id TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_model_aliases_model_id ON model_aliases(model_id);
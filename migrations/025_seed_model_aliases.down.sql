@@ -0,0 +1 @@
+DELETE FROM model_aliases WHERE alias IN ('haiku', 'sonnet', 'opus');
@@ -0,0 +1,7 @@
+-- Seed the aliases sub-agents report instead of full model IDs. They point at
+-- models with configured pricing and can be changed with 'mclaude cost alias set'.
+INSERT OR IGNORE INTO model_aliases (alias, model_id)
+VALUES
+    ('haiku', 'claude-haiku-4-5-20250101'),
+    ('sonnet', 'claude-sonnet-4-5-20241022'),
+    ('opus', 'claude-opus-4-6-20260206');
@@ -0,0 +1,20 @@
+-- Sessions still running, tracked from Stop, PostToolUse, SubagentStop and
+-- UserPromptSubmit hooks until SessionEnd records them. byte_offset is where
+-- the next incremental read of the transcript starts.
+CREATE TABLE live_sessions (
+    session_id TEXT PRIMARY KEY,
+    transcript_path TEXT NOT NULL,
+    cwd TEXT NOT NULL,
+    last_event TEXT NOT NULL,
+    byte_offset INTEGER NOT NULL DEFAULT 0,
+    model_id TEXT,
+    token_input INTEGER NOT NULL DEFAULT 0,
+    token_output INTEGER NOT NULL DEFAULT 0,
+    token_cache_read INTEGER NOT NULL DEFAULT 0,
+    token_cache_write INTEGER NOT NULL DEFAULT 0,
+    cost_estimate_usd REAL,
+    started_at TEXT,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_live_sessions_started_at ON live_sessions(started_at);
@@ -0,0 +1,9 @@
+-- Saved parser state per session, so growing transcripts are parsed from
+-- where the last read stopped instead of from the start.
+CREATE TABLE transcript_states (
+    session_id TEXT PRIMARY KEY,
+    transcript_path TEXT NOT NULL,
+    byte_offset INTEGER NOT NULL,
+    state TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
@@ -0,0 +1,3 @@
+ALTER TABLE session_commands DROP COLUMN duration_ms;
+ALTER TABLE session_commands DROP COLUMN status;
+ALTER TABLE session_commands DROP COLUMN tool_use_id;
@@ -0,0 +1,5 @@
+-- Each Bash command is paired with its tool result: how it ended and how long
+-- it ran. status is 'succeeded', 'failed' or 'interrupted', NULL while unknown.
+ALTER TABLE session_commands ADD COLUMN tool_use_id TEXT;
+ALTER TABLE session_commands ADD COLUMN status TEXT;
+ALTER TABLE session_commands ADD COLUMN duration_ms INTEGER;
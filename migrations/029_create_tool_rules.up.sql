@@ -0,0 +1,9 @@
+-- User rules mapping a tool (or a "prefix*" pattern) to the file operation
+-- its calls perform and the input fields naming the files. They override
+-- the parser's built-in rules for the same tool.
+CREATE TABLE tool_rules (
+    tool TEXT PRIMARY KEY,
+    operation TEXT NOT NULL,
+    fields TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (datetime('now'))
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_session_tools_mcp_server;
+ALTER TABLE session_tools DROP COLUMN result_tokens;
+ALTER TABLE session_tools DROP COLUMN mcp_tool;
+ALTER TABLE session_tools DROP COLUMN mcp_server;
@@ -0,0 +1,13 @@
+-- MCP tools are named mcp__<server>__<tool>. Split the server and tool out so
+-- usage can be grouped per server, and estimate the tokens each tool's
+-- results add to the context.
+ALTER TABLE session_tools ADD COLUMN mcp_server TEXT;
+ALTER TABLE session_tools ADD COLUMN mcp_tool TEXT;
+ALTER TABLE session_tools ADD COLUMN result_tokens INTEGER NOT NULL DEFAULT 0;
+
+UPDATE session_tools
+SET mcp_server = substr(tool_name, 6, instr(substr(tool_name, 6), '__') - 1),
+    mcp_tool = substr(substr(tool_name, 6), instr(substr(tool_name, 6), '__') + 2)
+WHERE substr(tool_name, 1, 5) = 'mcp__' AND instr(substr(tool_name, 6), '__') > 1;
+
+CREATE INDEX idx_session_tools_mcp_server ON session_tools(mcp_server);
@@ -0,0 +1,5 @@
+ALTER TABLE session_metrics DROP COLUMN lines_removed;
+ALTER TABLE session_metrics DROP COLUMN lines_added;
+ALTER TABLE session_files DROP COLUMN language;
+ALTER TABLE session_files DROP COLUMN lines_removed;
+ALTER TABLE session_files DROP COLUMN lines_added;
@@ -0,0 +1,7 @@
+-- Lines added and removed by Edit, MultiEdit, Write and NotebookEdit calls,
+-- per file (with the file's language) and in total per session.
+ALTER TABLE session_files ADD COLUMN lines_added INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_files ADD COLUMN lines_removed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_files ADD COLUMN language TEXT;
+ALTER TABLE session_metrics ADD COLUMN lines_added INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_metrics ADD COLUMN lines_removed INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS session_commits;
+DROP INDEX IF EXISTS idx_sessions_git_branch;
+ALTER TABLE sessions DROP COLUMN git_dirty;
+ALTER TABLE sessions DROP COLUMN git_head_after;
+ALTER TABLE sessions DROP COLUMN git_head_before;
+ALTER TABLE sessions DROP COLUMN git_branch;
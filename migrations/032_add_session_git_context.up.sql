@@ -0,0 +1,23 @@
+-- Git state of the session's working directory when it was recorded, and
+-- the commits made on HEAD while the session ran.
+ALTER TABLE sessions ADD COLUMN git_branch TEXT;
+ALTER TABLE sessions ADD COLUMN git_head_before TEXT;
+ALTER TABLE sessions ADD COLUMN git_head_after TEXT;
+ALTER TABLE sessions ADD COLUMN git_dirty INTEGER;
+
+CREATE INDEX idx_sessions_git_branch ON sessions(git_branch);
+
+CREATE TABLE session_commits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    sha TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    author TEXT NOT NULL,
+    committed_at TEXT NOT NULL,
+    files_changed INTEGER NOT NULL DEFAULT 0,
+    insertions INTEGER NOT NULL DEFAULT 0,
+    deletions INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (session_id, sha)
+);
+
+CREATE INDEX idx_session_commits_session_id ON session_commits(session_id);
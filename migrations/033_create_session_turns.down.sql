@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_session_turns_session_id;
+DROP TABLE IF EXISTS session_turns;
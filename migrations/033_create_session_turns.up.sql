@@ -0,0 +1,21 @@
+-- A turn is a user prompt and the assistant work that follows it, up to the
+-- next prompt.
+CREATE TABLE session_turns (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    turn_index INTEGER NOT NULL,
+    prompt TEXT NOT NULL DEFAULT '',
+    started_at TEXT,
+    ended_at TEXT,
+    duration_ms INTEGER,
+    message_count INTEGER NOT NULL DEFAULT 0,
+    tool_calls INTEGER NOT NULL DEFAULT 0,
+    token_input INTEGER NOT NULL DEFAULT 0,
+    token_output INTEGER NOT NULL DEFAULT 0,
+    token_cache_read INTEGER NOT NULL DEFAULT 0,
+    token_cache_write INTEGER NOT NULL DEFAULT 0,
+    cost_estimate_usd REAL,
+    UNIQUE (session_id, turn_index)
+);
+
+CREATE INDEX idx_session_turns_session_id ON session_turns(session_id);
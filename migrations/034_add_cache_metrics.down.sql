@@ -0,0 +1,2 @@
+ALTER TABLE session_metrics DROP COLUMN token_cache_wasted;
+ALTER TABLE session_metrics DROP COLUMN compaction_count;
@@ -0,0 +1,4 @@
+-- Context compactions per session, and the cache-write tokens no later
+-- request read before the cache expired or was invalidated.
+ALTER TABLE session_metrics ADD COLUMN compaction_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_metrics ADD COLUMN token_cache_wasted INTEGER NOT NULL DEFAULT 0;
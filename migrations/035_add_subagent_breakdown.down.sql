@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_session_subagent_files_session_id;
+DROP TABLE IF EXISTS session_subagent_files;
+DROP INDEX IF EXISTS idx_session_subagent_tools_session_id;
+DROP TABLE IF EXISTS session_subagent_tools;
+ALTER TABLE session_subagents DROP COLUMN error_count;
+ALTER TABLE session_subagents DROP COLUMN parent_tool_use_id;
+ALTER TABLE session_subagents DROP COLUMN agent_id;
+ALTER TABLE session_subagents DROP COLUMN tool_use_id;
@@ -0,0 +1,32 @@
+-- Sub-agents are identified by the Task call that launched them, and nested
+-- sub-agents point to the call that launched their parent. The tools and
+-- files of each sub-agent come from its own transcript.
+ALTER TABLE session_subagents ADD COLUMN tool_use_id TEXT;
+ALTER TABLE session_subagents ADD COLUMN agent_id TEXT;
+ALTER TABLE session_subagents ADD COLUMN parent_tool_use_id TEXT;
+ALTER TABLE session_subagents ADD COLUMN error_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE session_subagent_tools (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    subagent_tool_use_id TEXT NOT NULL,
+    tool_name TEXT NOT NULL,
+    invocation_count INTEGER NOT NULL DEFAULT 0,
+    error_count INTEGER NOT NULL DEFAULT 0,
+    total_duration_ms INTEGER
+);
+
+CREATE INDEX idx_session_subagent_tools_session_id ON session_subagent_tools(session_id);
+
+CREATE TABLE session_subagent_files (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    subagent_tool_use_id TEXT NOT NULL,
+    file_path TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    operation_count INTEGER NOT NULL DEFAULT 0,
+    lines_added INTEGER NOT NULL DEFAULT 0,
+    lines_removed INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_session_subagent_files_session_id ON session_subagent_files(session_id);
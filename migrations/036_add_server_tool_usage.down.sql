@@ -0,0 +1,6 @@
+ALTER TABLE model_pricing DROP COLUMN web_fetch_per_thousand;
+ALTER TABLE model_pricing DROP COLUMN web_search_per_thousand;
+ALTER TABLE session_metrics DROP COLUMN web_fetch_requests;
+ALTER TABLE session_metrics DROP COLUMN web_search_requests;
+ALTER TABLE session_metrics DROP COLUMN thinking_tokens;
+ALTER TABLE session_metrics DROP COLUMN thinking_block_count;
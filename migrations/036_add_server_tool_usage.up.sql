@@ -0,0 +1,15 @@
+-- Thinking blocks and server-side tool requests (web search and fetch) per
+-- session. Thinking tokens are estimated from the text of the blocks, since
+-- the API counts them as output without reporting them apart.
+ALTER TABLE session_metrics ADD COLUMN thinking_block_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_metrics ADD COLUMN thinking_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_metrics ADD COLUMN web_search_requests INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_metrics ADD COLUMN web_fetch_requests INTEGER NOT NULL DEFAULT 0;
+
+-- Server tools are billed per request on top of tokens, in USD per 1,000
+-- requests. Web search costs $10 per 1,000 searches on every model, web
+-- fetch only costs the tokens of the fetched content.
+ALTER TABLE model_pricing ADD COLUMN web_search_per_thousand REAL;
+ALTER TABLE model_pricing ADD COLUMN web_fetch_per_thousand REAL;
+
+UPDATE model_pricing SET web_search_per_thousand = 10.00;
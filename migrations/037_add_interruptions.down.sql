@@ -0,0 +1,6 @@
+ALTER TABLE session_tools DROP COLUMN permission_denial_count;
+ALTER TABLE session_tools DROP COLUMN rejection_count;
+
+ALTER TABLE session_metrics DROP COLUMN permission_denial_count;
+ALTER TABLE session_metrics DROP COLUMN tool_rejection_count;
+ALTER TABLE session_metrics DROP COLUMN interruption_count;
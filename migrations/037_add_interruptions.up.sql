@@ -0,0 +1,9 @@
+-- Signs the user stopped or overruled the agent: prompts interrupted with
+-- Esc, tool calls rejected in a permission prompt and tool calls denied by
+-- permission settings. Rejected and denied calls also count as tool errors.
+ALTER TABLE session_metrics ADD COLUMN interruption_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_metrics ADD COLUMN tool_rejection_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_metrics ADD COLUMN permission_denial_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE session_tools ADD COLUMN rejection_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_tools ADD COLUMN permission_denial_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS session_hooks;
+DROP INDEX IF EXISTS idx_session_slash_commands_command;
+DROP TABLE IF EXISTS session_slash_commands;
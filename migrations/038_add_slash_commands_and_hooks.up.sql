@@ -0,0 +1,26 @@
+-- Slash commands typed in each session, built-in (/compact, /model), custom
+-- (from .claude/commands or plugins) or MCP prompts, and the hooks that ran.
+-- Hooks are told apart by event and matcher (e.g. PreToolUse:Bash) and by
+-- command, which is empty when the transcript doesn't name it.
+CREATE TABLE session_slash_commands (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    command TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    invocation_count INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (session_id, command)
+);
+
+CREATE INDEX idx_session_slash_commands_command ON session_slash_commands(command);
+
+CREATE TABLE session_hooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    hook_name TEXT NOT NULL,
+    command TEXT NOT NULL DEFAULT '',
+    execution_count INTEGER NOT NULL DEFAULT 0,
+    error_count INTEGER NOT NULL DEFAULT 0,
+    blocked_count INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (session_id, hook_name, command)
+);
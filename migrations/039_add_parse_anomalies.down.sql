@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS session_parse_anomalies;
+
+ALTER TABLE session_metrics DROP COLUMN parser_version;
+ALTER TABLE session_metrics DROP COLUMN transcript_line_count;
@@ -0,0 +1,17 @@
+-- What the parser couldn't read in each session's transcript: malformed
+-- lines, entry and content block types it doesn't know, and assistant
+-- messages without usage. A format change in Claude Code shows up here
+-- instead of as metrics that quietly drop to zero. parser_version is 0 for
+-- sessions recorded before anomalies were kept.
+ALTER TABLE session_metrics ADD COLUMN transcript_line_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE session_metrics ADD COLUMN parser_version INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE session_parse_anomalies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    occurrence_count INTEGER NOT NULL DEFAULT 0,
+    first_line INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (session_id, kind, detail)
+);
@@ -0,0 +1 @@
+ALTER TABLE session_subagents DROP COLUMN model_id;
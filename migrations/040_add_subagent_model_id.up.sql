@@ -0,0 +1,4 @@
+-- The model each sub-agent's usage was counted under when parsed: the model
+-- its call asked for, or the model the session was on when it ran. NULL for
+-- sub-agents recorded before it was kept.
+ALTER TABLE session_subagents ADD COLUMN model_id TEXT;
@@ -0,0 +1,5 @@
+-- How many transcript lines the live usage covers. A hook arriving after
+-- SessionEnd finds no more lines than were recorded and is ignored, while a
+-- resumed session, whose transcript grows past the recording, gets live
+-- usage again.
+ALTER TABLE live_sessions ADD COLUMN transcript_line_count INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,209 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: live_sessions.sql
+
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createLiveSession = `-- name: CreateLiveSession :execrows
+INSERT INTO live_sessions (session_id, transcript_path, cwd, last_event, byte_offset, model_id, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd, started_at, updated_at, transcript_line_count)
+SELECT ?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13, ?14
+WHERE NOT EXISTS (SELECT 1 FROM session_metrics WHERE session_id = ?1 AND transcript_line_count >= ?14)
+ON CONFLICT (session_id) DO NOTHING
+`
+
+type CreateLiveSessionParams struct {
+	SessionID           string          `json:"session_id"`
+	TranscriptPath      string          `json:"transcript_path"`
+	Cwd                 string          `json:"cwd"`
+	LastEvent           string          `json:"last_event"`
+	ByteOffset          int64           `json:"byte_offset"`
+	ModelID             sql.NullString  `json:"model_id"`
+	TokenInput          int64           `json:"token_input"`
+	TokenOutput         int64           `json:"token_output"`
+	TokenCacheRead      int64           `json:"token_cache_read"`
+	TokenCacheWrite     int64           `json:"token_cache_write"`
+	CostEstimateUsd     sql.NullFloat64 `json:"cost_estimate_usd"`
+	StartedAt           sql.NullString  `json:"started_at"`
+	UpdatedAt           string          `json:"updated_at"`
+	TranscriptLineCount int64           `json:"transcript_line_count"`
+}
+
+// A recorded session only gets live usage again once its transcript has
+// grown past the recording, i.e. when it's resumed.
+func (q *Queries) CreateLiveSession(ctx context.Context, arg CreateLiveSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createLiveSession,
+		arg.SessionID,
+		arg.TranscriptPath,
+		arg.Cwd,
+		arg.LastEvent,
+		arg.ByteOffset,
+		arg.ModelID,
+		arg.TokenInput,
+		arg.TokenOutput,
+		arg.TokenCacheRead,
+		arg.TokenCacheWrite,
+		arg.CostEstimateUsd,
+		arg.StartedAt,
+		arg.UpdatedAt,
+		arg.TranscriptLineCount,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteLiveSession = `-- name: DeleteLiveSession :exec
+DELETE FROM live_sessions WHERE session_id = ?
+`
+
+func (q *Queries) DeleteLiveSession(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteLiveSession, sessionID)
+	return err
+}
+
+const deleteStaleLiveSessions = `-- name: DeleteStaleLiveSessions :execrows
+DELETE FROM live_sessions WHERE datetime(updated_at) < datetime(?1)
+`
+
+func (q *Queries) DeleteStaleLiveSessions(ctx context.Context, before interface{}) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteStaleLiveSessions, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getLiveSession = `-- name: GetLiveSession :one
+SELECT session_id, transcript_path, cwd, last_event, byte_offset, model_id, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd, started_at, updated_at, transcript_line_count FROM live_sessions WHERE session_id = ?
+`
+
+func (q *Queries) GetLiveSession(ctx context.Context, sessionID string) (LiveSession, error) {
+	row := q.db.QueryRowContext(ctx, getLiveSession, sessionID)
+	var i LiveSession
+	err := row.Scan(
+		&i.SessionID,
+		&i.TranscriptPath,
+		&i.Cwd,
+		&i.LastEvent,
+		&i.ByteOffset,
+		&i.ModelID,
+		&i.TokenInput,
+		&i.TokenOutput,
+		&i.TokenCacheRead,
+		&i.TokenCacheWrite,
+		&i.CostEstimateUsd,
+		&i.StartedAt,
+		&i.UpdatedAt,
+		&i.TranscriptLineCount,
+	)
+	return i, err
+}
+
+const listLiveSessions = `-- name: ListLiveSessions :many
+SELECT session_id, transcript_path, cwd, last_event, byte_offset, model_id, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd, started_at, updated_at, transcript_line_count FROM live_sessions ORDER BY started_at ASC
+`
+
+func (q *Queries) ListLiveSessions(ctx context.Context) ([]LiveSession, error) {
+	rows, err := q.db.QueryContext(ctx, listLiveSessions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LiveSession{}
+	for rows.Next() {
+		var i LiveSession
+		if err := rows.Scan(
+			&i.SessionID,
+			&i.TranscriptPath,
+			&i.Cwd,
+			&i.LastEvent,
+			&i.ByteOffset,
+			&i.ModelID,
+			&i.TokenInput,
+			&i.TokenOutput,
+			&i.TokenCacheRead,
+			&i.TokenCacheWrite,
+			&i.CostEstimateUsd,
+			&i.StartedAt,
+			&i.UpdatedAt,
+			&i.TranscriptLineCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateLiveSession = `-- name: UpdateLiveSession :execrows
+UPDATE live_sessions SET
+    transcript_path = ?1,
+    cwd = ?2,
+    last_event = ?3,
+    byte_offset = ?4,
+    model_id = ?5,
+    token_input = ?6,
+    token_output = ?7,
+    token_cache_read = ?8,
+    token_cache_write = ?9,
+    cost_estimate_usd = ?10,
+    started_at = ?11,
+    updated_at = ?12,
+    transcript_line_count = ?13
+WHERE session_id = ?14 AND byte_offset = ?15
+`
+
+type UpdateLiveSessionParams struct {
+	TranscriptPath      string          `json:"transcript_path"`
+	Cwd                 string          `json:"cwd"`
+	LastEvent           string          `json:"last_event"`
+	ByteOffset          int64           `json:"byte_offset"`
+	ModelID             sql.NullString  `json:"model_id"`
+	TokenInput          int64           `json:"token_input"`
+	TokenOutput         int64           `json:"token_output"`
+	TokenCacheRead      int64           `json:"token_cache_read"`
+	TokenCacheWrite     int64           `json:"token_cache_write"`
+	CostEstimateUsd     sql.NullFloat64 `json:"cost_estimate_usd"`
+	StartedAt           sql.NullString  `json:"started_at"`
+	UpdatedAt           string          `json:"updated_at"`
+	TranscriptLineCount int64           `json:"transcript_line_count"`
+	SessionID           string          `json:"session_id"`
+	PrevByteOffset      int64           `json:"prev_byte_offset"`
+}
+
+func (q *Queries) UpdateLiveSession(ctx context.Context, arg UpdateLiveSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateLiveSession,
+		arg.TranscriptPath,
+		arg.Cwd,
+		arg.LastEvent,
+		arg.ByteOffset,
+		arg.ModelID,
+		arg.TokenInput,
+		arg.TokenOutput,
+		arg.TokenCacheRead,
+		arg.TokenCacheWrite,
+		arg.CostEstimateUsd,
+		arg.StartedAt,
+		arg.UpdatedAt,
+		arg.TranscriptLineCount,
+		arg.SessionID,
+		arg.PrevByteOffset,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -11,8 +11,8 @@ import (
 )
 
 const createSessionCommand = `-- name: CreateSessionCommand :exec
-INSERT INTO session_commands (session_id, command, exit_code, executed_at)
-VALUES (?, ?, ?, ?)
+INSERT INTO session_commands (session_id, command, exit_code, executed_at, tool_use_id, status, duration_ms)
+VALUES (?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateSessionCommandParams struct {
@@ -20,6 +20,9 @@ type CreateSessionCommandParams struct {
 	Command    string         `json:"command"`
 	ExitCode   sql.NullInt64  `json:"exit_code"`
 	ExecutedAt sql.NullString `json:"executed_at"`
+	ToolUseID  sql.NullString `json:"tool_use_id"`
+	Status     sql.NullString `json:"status"`
+	DurationMs sql.NullInt64  `json:"duration_ms"`
 }
 
 func (q *Queries) CreateSessionCommand(ctx context.Context, arg CreateSessionCommandParams) error {
@@ -28,22 +31,61 @@ func (q *Queries) CreateSessionCommand(ctx context.Context, arg CreateSessionCom
 		arg.Command,
 		arg.ExitCode,
 		arg.ExecutedAt,
+		arg.ToolUseID,
+		arg.Status,
+		arg.DurationMs,
+	)
+	return err
+}
+
+const createSessionCommit = `-- name: CreateSessionCommit :exec
+INSERT INTO session_commits (session_id, sha, subject, author, committed_at, files_changed, insertions, deletions)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (session_id, sha) DO NOTHING
+`
+
+type CreateSessionCommitParams struct {
+	SessionID    string `json:"session_id"`
+	Sha          string `json:"sha"`
+	Subject      string `json:"subject"`
+	Author       string `json:"author"`
+	CommittedAt  string `json:"committed_at"`
+	FilesChanged int64  `json:"files_changed"`
+	Insertions   int64  `json:"insertions"`
+	Deletions    int64  `json:"deletions"`
+}
+
+func (q *Queries) CreateSessionCommit(ctx context.Context, arg CreateSessionCommitParams) error {
+	_, err := q.db.ExecContext(ctx, createSessionCommit,
+		arg.SessionID,
+		arg.Sha,
+		arg.Subject,
+		arg.Author,
+		arg.CommittedAt,
+		arg.FilesChanged,
+		arg.Insertions,
+		arg.Deletions,
 	)
 	return err
 }
 
 const createSessionFile = `-- name: CreateSessionFile :exec
-INSERT INTO session_files (session_id, file_path, operation, operation_count)
-VALUES (?, ?, ?, ?)
+INSERT INTO session_files (session_id, file_path, operation, operation_count, lines_added, lines_removed, language)
+VALUES (?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT (session_id, file_path, operation) DO UPDATE SET
-    operation_count = operation_count + excluded.operation_count
+    operation_count = operation_count + excluded.operation_count,
+    lines_added = lines_added + excluded.lines_added,
+    lines_removed = lines_removed + excluded.lines_removed
 `
 
 type CreateSessionFileParams struct {
-	SessionID      string `json:"session_id"`
-	FilePath       string `json:"file_path"`
-	Operation      string `json:"operation"`
-	OperationCount int64  `json:"operation_count"`
+	SessionID      string         `json:"session_id"`
+	FilePath       string         `json:"file_path"`
+	Operation      string         `json:"operation"`
+	OperationCount int64          `json:"operation_count"`
+	LinesAdded     int64          `json:"lines_added"`
+	LinesRemoved   int64          `json:"lines_removed"`
+	Language       sql.NullString `json:"language"`
 }
 
 func (q *Queries) CreateSessionFile(ctx context.Context, arg CreateSessionFileParams) error {
@@ -52,13 +94,44 @@ func (q *Queries) CreateSessionFile(ctx context.Context, arg CreateSessionFilePa
 		arg.FilePath,
 		arg.Operation,
 		arg.OperationCount,
+		arg.LinesAdded,
+		arg.LinesRemoved,
+		arg.Language,
+	)
+	return err
+}
+
+const createSessionHook = `-- name: CreateSessionHook :exec
+INSERT INTO session_hooks (session_id, event, hook_name, command, execution_count, error_count, blocked_count)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateSessionHookParams struct {
+	SessionID      string `json:"session_id"`
+	Event          string `json:"event"`
+	HookName       string `json:"hook_name"`
+	Command        string `json:"command"`
+	ExecutionCount int64  `json:"execution_count"`
+	ErrorCount     int64  `json:"error_count"`
+	BlockedCount   int64  `json:"blocked_count"`
+}
+
+func (q *Queries) CreateSessionHook(ctx context.Context, arg CreateSessionHookParams) error {
+	_, err := q.db.ExecContext(ctx, createSessionHook,
+		arg.SessionID,
+		arg.Event,
+		arg.HookName,
+		arg.Command,
+		arg.ExecutionCount,
+		arg.ErrorCount,
+		arg.BlockedCount,
 	)
 	return err
 }
 
 const createSessionMetrics = `-- name: CreateSessionMetrics :exec
-INSERT OR REPLACE INTO session_metrics (session_id, model_id, message_count_user, message_count_assistant, turn_count, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd, error_count)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT OR REPLACE INTO session_metrics (session_id, model_id, message_count_user, message_count_assistant, turn_count, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd, error_count, lines_added, lines_removed, compaction_count, token_cache_wasted, thinking_block_count, thinking_tokens, web_search_requests, web_fetch_requests, interruption_count, tool_rejection_count, permission_denial_count, transcript_line_count, parser_version)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateSessionMetricsParams struct {
@@ -73,6 +146,19 @@ type CreateSessionMetricsParams struct {
 	TokenCacheWrite       int64           `json:"token_cache_write"`
 	CostEstimateUsd       sql.NullFloat64 `json:"cost_estimate_usd"`
 	ErrorCount            int64           `json:"error_count"`
+	LinesAdded            int64           `json:"lines_added"`
+	LinesRemoved          int64           `json:"lines_removed"`
+	CompactionCount       int64           `json:"compaction_count"`
+	TokenCacheWasted      int64           `json:"token_cache_wasted"`
+	ThinkingBlockCount    int64           `json:"thinking_block_count"`
+	ThinkingTokens        int64           `json:"thinking_tokens"`
+	WebSearchRequests     int64           `json:"web_search_requests"`
+	WebFetchRequests      int64           `json:"web_fetch_requests"`
+	InterruptionCount     int64           `json:"interruption_count"`
+	ToolRejectionCount    int64           `json:"tool_rejection_count"`
+	PermissionDenialCount int64           `json:"permission_denial_count"`
+	TranscriptLineCount   int64           `json:"transcript_line_count"`
+	ParserVersion         int64           `json:"parser_version"`
 }
 
 func (q *Queries) CreateSessionMetrics(ctx context.Context, arg CreateSessionMetricsParams) error {
@@ -88,13 +174,109 @@ func (q *Queries) CreateSessionMetrics(ctx context.Context, arg CreateSessionMet
 		arg.TokenCacheWrite,
 		arg.CostEstimateUsd,
 		arg.ErrorCount,
+		arg.LinesAdded,
+		arg.LinesRemoved,
+		arg.CompactionCount,
+		arg.TokenCacheWasted,
+		arg.ThinkingBlockCount,
+		arg.ThinkingTokens,
+		arg.WebSearchRequests,
+		arg.WebFetchRequests,
+		arg.InterruptionCount,
+		arg.ToolRejectionCount,
+		arg.PermissionDenialCount,
+		arg.TranscriptLineCount,
+		arg.ParserVersion,
+	)
+	return err
+}
+
+const createSessionModelUsage = `-- name: CreateSessionModelUsage :exec
+INSERT INTO session_model_usage (session_id, model_id, message_count, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (session_id, model_id) DO UPDATE SET
+    message_count = excluded.message_count,
+    token_input = excluded.token_input,
+    token_output = excluded.token_output,
+    token_cache_read = excluded.token_cache_read,
+    token_cache_write = excluded.token_cache_write,
+    cost_estimate_usd = excluded.cost_estimate_usd
+`
+
+type CreateSessionModelUsageParams struct {
+	SessionID       string          `json:"session_id"`
+	ModelID         string          `json:"model_id"`
+	MessageCount    int64           `json:"message_count"`
+	TokenInput      int64           `json:"token_input"`
+	TokenOutput     int64           `json:"token_output"`
+	TokenCacheRead  int64           `json:"token_cache_read"`
+	TokenCacheWrite int64           `json:"token_cache_write"`
+	CostEstimateUsd sql.NullFloat64 `json:"cost_estimate_usd"`
+}
+
+func (q *Queries) CreateSessionModelUsage(ctx context.Context, arg CreateSessionModelUsageParams) error {
+	_, err := q.db.ExecContext(ctx, createSessionModelUsage,
+		arg.SessionID,
+		arg.ModelID,
+		arg.MessageCount,
+		arg.TokenInput,
+		arg.TokenOutput,
+		arg.TokenCacheRead,
+		arg.TokenCacheWrite,
+		arg.CostEstimateUsd,
+	)
+	return err
+}
+
+const createSessionParseAnomaly = `-- name: CreateSessionParseAnomaly :exec
+INSERT INTO session_parse_anomalies (session_id, kind, detail, occurrence_count, first_line)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateSessionParseAnomalyParams struct {
+	SessionID       string `json:"session_id"`
+	Kind            string `json:"kind"`
+	Detail          string `json:"detail"`
+	OccurrenceCount int64  `json:"occurrence_count"`
+	FirstLine       int64  `json:"first_line"`
+}
+
+func (q *Queries) CreateSessionParseAnomaly(ctx context.Context, arg CreateSessionParseAnomalyParams) error {
+	_, err := q.db.ExecContext(ctx, createSessionParseAnomaly,
+		arg.SessionID,
+		arg.Kind,
+		arg.Detail,
+		arg.OccurrenceCount,
+		arg.FirstLine,
+	)
+	return err
+}
+
+const createSessionSlashCommand = `-- name: CreateSessionSlashCommand :exec
+INSERT INTO session_slash_commands (session_id, command, kind, invocation_count)
+VALUES (?, ?, ?, ?)
+`
+
+type CreateSessionSlashCommandParams struct {
+	SessionID       string `json:"session_id"`
+	Command         string `json:"command"`
+	Kind            string `json:"kind"`
+	InvocationCount int64  `json:"invocation_count"`
+}
+
+func (q *Queries) CreateSessionSlashCommand(ctx context.Context, arg CreateSessionSlashCommandParams) error {
+	_, err := q.db.ExecContext(ctx, createSessionSlashCommand,
+		arg.SessionID,
+		arg.Command,
+		arg.Kind,
+		arg.InvocationCount,
 	)
 	return err
 }
 
 const createSessionSubagent = `-- name: CreateSessionSubagent :exec
-INSERT INTO session_subagents (session_id, agent_type, agent_kind, description, model, total_tokens, token_input, token_output, token_cache_read, token_cache_write, total_duration_ms, tool_use_count, cost_estimate_usd)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO session_subagents (session_id, agent_type, agent_kind, description, model, total_tokens, token_input, token_output, token_cache_read, token_cache_write, total_duration_ms, tool_use_count, cost_estimate_usd, tool_use_id, agent_id, parent_tool_use_id, error_count, model_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateSessionSubagentParams struct {
@@ -111,6 +293,11 @@ type CreateSessionSubagentParams struct {
 	TotalDurationMs sql.NullInt64   `json:"total_duration_ms"`
 	ToolUseCount    int64           `json:"tool_use_count"`
 	CostEstimateUsd sql.NullFloat64 `json:"cost_estimate_usd"`
+	ToolUseID       sql.NullString  `json:"tool_use_id"`
+	AgentID         sql.NullString  `json:"agent_id"`
+	ParentToolUseID sql.NullString  `json:"parent_tool_use_id"`
+	ErrorCount      int64           `json:"error_count"`
+	ModelID         sql.NullString  `json:"model_id"`
 }
 
 func (q *Queries) CreateSessionSubagent(ctx context.Context, arg CreateSessionSubagentParams) error {
@@ -128,24 +315,92 @@ func (q *Queries) CreateSessionSubagent(ctx context.Context, arg CreateSessionSu
 		arg.TotalDurationMs,
 		arg.ToolUseCount,
 		arg.CostEstimateUsd,
+		arg.ToolUseID,
+		arg.AgentID,
+		arg.ParentToolUseID,
+		arg.ErrorCount,
+		arg.ModelID,
+	)
+	return err
+}
+
+const createSessionSubagentFile = `-- name: CreateSessionSubagentFile :exec
+INSERT INTO session_subagent_files (session_id, subagent_tool_use_id, file_path, operation, operation_count, lines_added, lines_removed)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateSessionSubagentFileParams struct {
+	SessionID         string `json:"session_id"`
+	SubagentToolUseID string `json:"subagent_tool_use_id"`
+	FilePath          string `json:"file_path"`
+	Operation         string `json:"operation"`
+	OperationCount    int64  `json:"operation_count"`
+	LinesAdded        int64  `json:"lines_added"`
+	LinesRemoved      int64  `json:"lines_removed"`
+}
+
+func (q *Queries) CreateSessionSubagentFile(ctx context.Context, arg CreateSessionSubagentFileParams) error {
+	_, err := q.db.ExecContext(ctx, createSessionSubagentFile,
+		arg.SessionID,
+		arg.SubagentToolUseID,
+		arg.FilePath,
+		arg.Operation,
+		arg.OperationCount,
+		arg.LinesAdded,
+		arg.LinesRemoved,
+	)
+	return err
+}
+
+const createSessionSubagentTool = `-- name: CreateSessionSubagentTool :exec
+INSERT INTO session_subagent_tools (session_id, subagent_tool_use_id, tool_name, invocation_count, error_count, total_duration_ms)
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateSessionSubagentToolParams struct {
+	SessionID         string        `json:"session_id"`
+	SubagentToolUseID string        `json:"subagent_tool_use_id"`
+	ToolName          string        `json:"tool_name"`
+	InvocationCount   int64         `json:"invocation_count"`
+	ErrorCount        int64         `json:"error_count"`
+	TotalDurationMs   sql.NullInt64 `json:"total_duration_ms"`
+}
+
+func (q *Queries) CreateSessionSubagentTool(ctx context.Context, arg CreateSessionSubagentToolParams) error {
+	_, err := q.db.ExecContext(ctx, createSessionSubagentTool,
+		arg.SessionID,
+		arg.SubagentToolUseID,
+		arg.ToolName,
+		arg.InvocationCount,
+		arg.ErrorCount,
+		arg.TotalDurationMs,
 	)
 	return err
 }
 
 const createSessionTool = `-- name: CreateSessionTool :exec
-INSERT INTO session_tools (session_id, tool_name, invocation_count, total_duration_ms, error_count)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO session_tools (session_id, tool_name, invocation_count, total_duration_ms, error_count, mcp_server, mcp_tool, result_tokens, rejection_count, permission_denial_count)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT (session_id, tool_name) DO UPDATE SET
     invocation_count = invocation_count + excluded.invocation_count,
-    error_count = error_count + excluded.error_count
+    total_duration_ms = COALESCE(total_duration_ms + excluded.total_duration_ms, total_duration_ms, excluded.total_duration_ms),
+    error_count = error_count + excluded.error_count,
+    result_tokens = result_tokens + excluded.result_tokens,
+    rejection_count = rejection_count + excluded.rejection_count,
+    permission_denial_count = permission_denial_count + excluded.permission_denial_count
 `
 
 type CreateSessionToolParams struct {
-	SessionID       string        `json:"session_id"`
-	ToolName        string        `json:"tool_name"`
-	InvocationCount int64         `json:"invocation_count"`
-	TotalDurationMs sql.NullInt64 `json:"total_duration_ms"`
-	ErrorCount      int64         `json:"error_count"`
+	SessionID             string         `json:"session_id"`
+	ToolName              string         `json:"tool_name"`
+	InvocationCount       int64          `json:"invocation_count"`
+	TotalDurationMs       sql.NullInt64  `json:"total_duration_ms"`
+	ErrorCount            int64          `json:"error_count"`
+	McpServer             sql.NullString `json:"mcp_server"`
+	McpTool               sql.NullString `json:"mcp_tool"`
+	ResultTokens          int64          `json:"result_tokens"`
+	RejectionCount        int64          `json:"rejection_count"`
+	PermissionDenialCount int64          `json:"permission_denial_count"`
 }
 
 func (q *Queries) CreateSessionTool(ctx context.Context, arg CreateSessionToolParams) error {
@@ -155,10 +410,163 @@ func (q *Queries) CreateSessionTool(ctx context.Context, arg CreateSessionToolPa
 		arg.InvocationCount,
 		arg.TotalDurationMs,
 		arg.ErrorCount,
+		arg.McpServer,
+		arg.McpTool,
+		arg.ResultTokens,
+		arg.RejectionCount,
+		arg.PermissionDenialCount,
 	)
 	return err
 }
 
+const createSessionTurn = `-- name: CreateSessionTurn :exec
+INSERT INTO session_turns (session_id, turn_index, prompt, started_at, ended_at, duration_ms, message_count, tool_calls, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type CreateSessionTurnParams struct {
+	SessionID       string          `json:"session_id"`
+	TurnIndex       int64           `json:"turn_index"`
+	Prompt          string          `json:"prompt"`
+	StartedAt       sql.NullString  `json:"started_at"`
+	EndedAt         sql.NullString  `json:"ended_at"`
+	DurationMs      sql.NullInt64   `json:"duration_ms"`
+	MessageCount    int64           `json:"message_count"`
+	ToolCalls       int64           `json:"tool_calls"`
+	TokenInput      int64           `json:"token_input"`
+	TokenOutput     int64           `json:"token_output"`
+	TokenCacheRead  int64           `json:"token_cache_read"`
+	TokenCacheWrite int64           `json:"token_cache_write"`
+	CostEstimateUsd sql.NullFloat64 `json:"cost_estimate_usd"`
+}
+
+func (q *Queries) CreateSessionTurn(ctx context.Context, arg CreateSessionTurnParams) error {
+	_, err := q.db.ExecContext(ctx, createSessionTurn,
+		arg.SessionID,
+		arg.TurnIndex,
+		arg.Prompt,
+		arg.StartedAt,
+		arg.EndedAt,
+		arg.DurationMs,
+		arg.MessageCount,
+		arg.ToolCalls,
+		arg.TokenInput,
+		arg.TokenOutput,
+		arg.TokenCacheRead,
+		arg.TokenCacheWrite,
+		arg.CostEstimateUsd,
+	)
+	return err
+}
+
+const deleteSessionCommands = `-- name: DeleteSessionCommands :exec
+DELETE FROM session_commands WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionCommands(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionCommands, sessionID)
+	return err
+}
+
+const deleteSessionCommits = `-- name: DeleteSessionCommits :exec
+DELETE FROM session_commits WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionCommits(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionCommits, sessionID)
+	return err
+}
+
+const deleteSessionFiles = `-- name: DeleteSessionFiles :exec
+DELETE FROM session_files WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionFiles(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionFiles, sessionID)
+	return err
+}
+
+const deleteSessionHooks = `-- name: DeleteSessionHooks :exec
+DELETE FROM session_hooks WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionHooks(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionHooks, sessionID)
+	return err
+}
+
+const deleteSessionModelUsage = `-- name: DeleteSessionModelUsage :exec
+DELETE FROM session_model_usage WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionModelUsage(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionModelUsage, sessionID)
+	return err
+}
+
+const deleteSessionParseAnomalies = `-- name: DeleteSessionParseAnomalies :exec
+DELETE FROM session_parse_anomalies WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionParseAnomalies(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionParseAnomalies, sessionID)
+	return err
+}
+
+const deleteSessionSlashCommands = `-- name: DeleteSessionSlashCommands :exec
+DELETE FROM session_slash_commands WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionSlashCommands(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionSlashCommands, sessionID)
+	return err
+}
+
+const deleteSessionSubagentFiles = `-- name: DeleteSessionSubagentFiles :exec
+DELETE FROM session_subagent_files WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionSubagentFiles(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionSubagentFiles, sessionID)
+	return err
+}
+
+const deleteSessionSubagentTools = `-- name: DeleteSessionSubagentTools :exec
+DELETE FROM session_subagent_tools WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionSubagentTools(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionSubagentTools, sessionID)
+	return err
+}
+
+const deleteSessionSubagents = `-- name: DeleteSessionSubagents :exec
+DELETE FROM session_subagents WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionSubagents(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionSubagents, sessionID)
+	return err
+}
+
+const deleteSessionTools = `-- name: DeleteSessionTools :exec
+DELETE FROM session_tools WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionTools(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionTools, sessionID)
+	return err
+}
+
+const deleteSessionTurns = `-- name: DeleteSessionTurns :exec
+DELETE FROM session_turns WHERE session_id = ?
+`
+
+func (q *Queries) DeleteSessionTurns(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionTurns, sessionID)
+	return err
+}
+
 const getAggregateStats = `-- name: GetAggregateStats :one
 SELECT
     COUNT(DISTINCT s.id) as session_count,
@@ -170,7 +578,11 @@ SELECT
     COALESCE(SUM(m.token_cache_read), 0) as total_token_cache_read,
     COALESCE(SUM(m.token_cache_write), 0) as total_token_cache_write,
     COALESCE(SUM(m.cost_estimate_usd), 0) as total_cost_usd,
-    COALESCE(SUM(m.error_count), 0) as total_errors
+    COALESCE(SUM(m.error_count), 0) as total_errors,
+    COALESCE(SUM(m.lines_added), 0) as total_lines_added,
+    COALESCE(SUM(m.lines_removed), 0) as total_lines_removed,
+    COALESCE(SUM(m.compaction_count), 0) as total_compactions,
+    COALESCE(SUM(m.token_cache_wasted), 0) as total_token_cache_wasted
 FROM sessions s
 LEFT JOIN session_metrics m ON s.id = m.session_id
 WHERE s.created_at >= ?
@@ -187,6 +599,10 @@ type GetAggregateStatsRow struct {
 	TotalTokenCacheWrite   interface{} `json:"total_token_cache_write"`
 	TotalCostUsd           interface{} `json:"total_cost_usd"`
 	TotalErrors            interface{} `json:"total_errors"`
+	TotalLinesAdded        interface{} `json:"total_lines_added"`
+	TotalLinesRemoved      interface{} `json:"total_lines_removed"`
+	TotalCompactions       interface{} `json:"total_compactions"`
+	TotalTokenCacheWasted  interface{} `json:"total_token_cache_wasted"`
 }
 
 func (q *Queries) GetAggregateStats(ctx context.Context, createdAt string) (GetAggregateStatsRow, error) {
@@ -203,6 +619,10 @@ func (q *Queries) GetAggregateStats(ctx context.Context, createdAt string) (GetA
 		&i.TotalTokenCacheWrite,
 		&i.TotalCostUsd,
 		&i.TotalErrors,
+		&i.TotalLinesAdded,
+		&i.TotalLinesRemoved,
+		&i.TotalCompactions,
+		&i.TotalTokenCacheWasted,
 	)
 	return i, err
 }
@@ -218,7 +638,11 @@ SELECT
     COALESCE(SUM(m.token_cache_read), 0) as total_token_cache_read,
     COALESCE(SUM(m.token_cache_write), 0) as total_token_cache_write,
     COALESCE(SUM(m.cost_estimate_usd), 0) as total_cost_usd,
-    COALESCE(SUM(m.error_count), 0) as total_errors
+    COALESCE(SUM(m.error_count), 0) as total_errors,
+    COALESCE(SUM(m.lines_added), 0) as total_lines_added,
+    COALESCE(SUM(m.lines_removed), 0) as total_lines_removed,
+    COALESCE(SUM(m.compaction_count), 0) as total_compactions,
+    COALESCE(SUM(m.token_cache_wasted), 0) as total_token_cache_wasted
 FROM sessions s
 LEFT JOIN session_metrics m ON s.id = m.session_id
 WHERE s.experiment_id = ? AND s.created_at >= ?
@@ -240,6 +664,10 @@ type GetAggregateStatsByExperimentRow struct {
 	TotalTokenCacheWrite   interface{} `json:"total_token_cache_write"`
 	TotalCostUsd           interface{} `json:"total_cost_usd"`
 	TotalErrors            interface{} `json:"total_errors"`
+	TotalLinesAdded        interface{} `json:"total_lines_added"`
+	TotalLinesRemoved      interface{} `json:"total_lines_removed"`
+	TotalCompactions       interface{} `json:"total_compactions"`
+	TotalTokenCacheWasted  interface{} `json:"total_token_cache_wasted"`
 }
 
 func (q *Queries) GetAggregateStatsByExperiment(ctx context.Context, arg GetAggregateStatsByExperimentParams) (GetAggregateStatsByExperimentRow, error) {
@@ -256,6 +684,10 @@ func (q *Queries) GetAggregateStatsByExperiment(ctx context.Context, arg GetAggr
 		&i.TotalTokenCacheWrite,
 		&i.TotalCostUsd,
 		&i.TotalErrors,
+		&i.TotalLinesAdded,
+		&i.TotalLinesRemoved,
+		&i.TotalCompactions,
+		&i.TotalTokenCacheWasted,
 	)
 	return i, err
 }
@@ -271,7 +703,11 @@ SELECT
     COALESCE(SUM(m.token_cache_read), 0) as total_token_cache_read,
     COALESCE(SUM(m.token_cache_write), 0) as total_token_cache_write,
     COALESCE(SUM(m.cost_estimate_usd), 0) as total_cost_usd,
-    COALESCE(SUM(m.error_count), 0) as total_errors
+    COALESCE(SUM(m.error_count), 0) as total_errors,
+    COALESCE(SUM(m.lines_added), 0) as total_lines_added,
+    COALESCE(SUM(m.lines_removed), 0) as total_lines_removed,
+    COALESCE(SUM(m.compaction_count), 0) as total_compactions,
+    COALESCE(SUM(m.token_cache_wasted), 0) as total_token_cache_wasted
 FROM sessions s
 LEFT JOIN session_metrics m ON s.id = m.session_id
 WHERE s.project_id = ? AND s.created_at >= ?
@@ -293,6 +729,10 @@ type GetAggregateStatsByProjectRow struct {
 	TotalTokenCacheWrite   interface{} `json:"total_token_cache_write"`
 	TotalCostUsd           interface{} `json:"total_cost_usd"`
 	TotalErrors            interface{} `json:"total_errors"`
+	TotalLinesAdded        interface{} `json:"total_lines_added"`
+	TotalLinesRemoved      interface{} `json:"total_lines_removed"`
+	TotalCompactions       interface{} `json:"total_compactions"`
+	TotalTokenCacheWasted  interface{} `json:"total_token_cache_wasted"`
 }
 
 func (q *Queries) GetAggregateStatsByProject(ctx context.Context, arg GetAggregateStatsByProjectParams) (GetAggregateStatsByProjectRow, error) {
@@ -309,10 +749,85 @@ func (q *Queries) GetAggregateStatsByProject(ctx context.Context, arg GetAggrega
 		&i.TotalTokenCacheWrite,
 		&i.TotalCostUsd,
 		&i.TotalErrors,
+		&i.TotalLinesAdded,
+		&i.TotalLinesRemoved,
+		&i.TotalCompactions,
+		&i.TotalTokenCacheWasted,
 	)
 	return i, err
 }
 
+const getAnomalousSessions = `-- name: GetAnomalousSessions :many
+SELECT
+    s.id,
+    s.transcript_path,
+    CAST(s.created_at AS TEXT) as recorded_at,
+    sm.transcript_line_count,
+    sm.message_count_assistant,
+    sm.token_input + sm.token_output + sm.token_cache_read + sm.token_cache_write as total_tokens,
+    CAST(COALESCE(SUM(CASE WHEN a.kind = 'malformed_line' THEN a.occurrence_count END), 0) AS INTEGER) as malformed_lines,
+    CAST(COALESCE(SUM(CASE WHEN a.kind IN ('unknown_entry_type', 'unknown_content_type') THEN a.occurrence_count END), 0) AS INTEGER) as unknown_types,
+    CAST(COALESCE(SUM(CASE WHEN a.kind = 'missing_usage' THEN a.occurrence_count END), 0) AS INTEGER) as missing_usage
+FROM sessions s
+JOIN session_metrics sm ON sm.session_id = s.id
+LEFT JOIN session_parse_anomalies a ON a.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY s.id
+HAVING COUNT(a.id) > 0 OR (sm.message_count_assistant > 0 AND total_tokens = 0)
+ORDER BY s.created_at DESC
+LIMIT ?
+`
+
+type GetAnomalousSessionsParams struct {
+	CreatedAt string `json:"created_at"`
+	Limit     int64  `json:"limit"`
+}
+
+type GetAnomalousSessionsRow struct {
+	ID                    string `json:"id"`
+	TranscriptPath        string `json:"transcript_path"`
+	RecordedAt            string `json:"recorded_at"`
+	TranscriptLineCount   int64  `json:"transcript_line_count"`
+	MessageCountAssistant int64  `json:"message_count_assistant"`
+	TotalTokens           int64  `json:"total_tokens"`
+	MalformedLines        int64  `json:"malformed_lines"`
+	UnknownTypes          int64  `json:"unknown_types"`
+	MissingUsage          int64  `json:"missing_usage"`
+}
+
+func (q *Queries) GetAnomalousSessions(ctx context.Context, arg GetAnomalousSessionsParams) ([]GetAnomalousSessionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAnomalousSessions, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAnomalousSessionsRow{}
+	for rows.Next() {
+		var i GetAnomalousSessionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TranscriptPath,
+			&i.RecordedAt,
+			&i.TranscriptLineCount,
+			&i.MessageCountAssistant,
+			&i.TotalTokens,
+			&i.MalformedLines,
+			&i.UnknownTypes,
+			&i.MissingUsage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getDailyStats = `-- name: GetDailyStats :many
 SELECT
     DATE(s.created_at) as date,
@@ -367,87 +882,383 @@ func (q *Queries) GetDailyStats(ctx context.Context, arg GetDailyStatsParams) ([
 	return items, nil
 }
 
-const getSessionMetricsBySessionID = `-- name: GetSessionMetricsBySessionID :one
-SELECT session_id, message_count_user, message_count_assistant, turn_count, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd, error_count, model_id FROM session_metrics WHERE session_id = ?
+const getHookUsage = `-- name: GetHookUsage :many
+SELECT
+    h.event,
+    h.hook_name,
+    h.command,
+    COUNT(DISTINCT h.session_id) as session_count,
+    SUM(h.execution_count) as total_executions,
+    SUM(h.error_count) as total_errors,
+    SUM(h.blocked_count) as total_blocked
+FROM session_hooks h
+JOIN sessions s ON h.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY h.event, h.hook_name, h.command
+ORDER BY total_executions DESC, h.hook_name ASC
 `
 
-func (q *Queries) GetSessionMetricsBySessionID(ctx context.Context, sessionID string) (SessionMetric, error) {
-	row := q.db.QueryRowContext(ctx, getSessionMetricsBySessionID, sessionID)
-	var i SessionMetric
-	err := row.Scan(
-		&i.SessionID,
-		&i.MessageCountUser,
-		&i.MessageCountAssistant,
-		&i.TurnCount,
-		&i.TokenInput,
-		&i.TokenOutput,
-		&i.TokenCacheRead,
-		&i.TokenCacheWrite,
-		&i.CostEstimateUsd,
-		&i.ErrorCount,
-		&i.ModelID,
-	)
-	return i, err
+type GetHookUsageRow struct {
+	Event           string          `json:"event"`
+	HookName        string          `json:"hook_name"`
+	Command         string          `json:"command"`
+	SessionCount    int64           `json:"session_count"`
+	TotalExecutions sql.NullFloat64 `json:"total_executions"`
+	TotalErrors     sql.NullFloat64 `json:"total_errors"`
+	TotalBlocked    sql.NullFloat64 `json:"total_blocked"`
 }
 
-const getStatsForAllExperiments = `-- name: GetStatsForAllExperiments :many
-SELECT
-    e.id as experiment_id,
-    e.name as experiment_name,
-    COUNT(DISTINCT s.id) as session_count,
-    COALESCE(SUM(m.message_count_user), 0) as total_user_messages,
-    COALESCE(SUM(m.message_count_assistant), 0) as total_assistant_messages,
-    COALESCE(SUM(m.turn_count), 0) as total_turns,
-    COALESCE(SUM(m.token_input), 0) as total_token_input,
-    COALESCE(SUM(m.token_output), 0) as total_token_output,
-    COALESCE(SUM(m.token_cache_read), 0) as total_token_cache_read,
-    COALESCE(SUM(m.token_cache_write), 0) as total_token_cache_write,
-    COALESCE(SUM(m.cost_estimate_usd), 0) as total_cost_usd,
-    COALESCE(SUM(m.error_count), 0) as total_errors
-FROM experiments e
-LEFT JOIN sessions s ON s.experiment_id = e.id
-LEFT JOIN session_metrics m ON s.id = m.session_id
-GROUP BY e.id, e.name
-ORDER BY e.created_at DESC
-`
-
-type GetStatsForAllExperimentsRow struct {
-	ExperimentID           string      `json:"experiment_id"`
-	ExperimentName         string      `json:"experiment_name"`
-	SessionCount           int64       `json:"session_count"`
-	TotalUserMessages      interface{} `json:"total_user_messages"`
-	TotalAssistantMessages interface{} `json:"total_assistant_messages"`
-	TotalTurns             interface{} `json:"total_turns"`
-	TotalTokenInput        interface{} `json:"total_token_input"`
-	TotalTokenOutput       interface{} `json:"total_token_output"`
-	TotalTokenCacheRead    interface{} `json:"total_token_cache_read"`
-	TotalTokenCacheWrite   interface{} `json:"total_token_cache_write"`
-	TotalCostUsd           interface{} `json:"total_cost_usd"`
-	TotalErrors            interface{} `json:"total_errors"`
+func (q *Queries) GetHookUsage(ctx context.Context, createdAt string) ([]GetHookUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getHookUsage, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetHookUsageRow{}
+	for rows.Next() {
+		var i GetHookUsageRow
+		if err := rows.Scan(
+			&i.Event,
+			&i.HookName,
+			&i.Command,
+			&i.SessionCount,
+			&i.TotalExecutions,
+			&i.TotalErrors,
+			&i.TotalBlocked,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *Queries) GetStatsForAllExperiments(ctx context.Context) ([]GetStatsForAllExperimentsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getStatsForAllExperiments)
+const getLineChangesByLanguageByExperiment = `-- name: GetLineChangesByLanguageByExperiment :many
+SELECT
+    sf.language,
+    COUNT(DISTINCT sf.file_path) as file_count,
+    SUM(sf.lines_added) as total_lines_added,
+    SUM(sf.lines_removed) as total_lines_removed
+FROM session_files sf
+JOIN sessions s ON sf.session_id = s.id
+WHERE s.experiment_id = ? AND s.created_at >= ? AND sf.language IS NOT NULL
+    AND (sf.lines_added > 0 OR sf.lines_removed > 0)
+GROUP BY sf.language
+ORDER BY total_lines_added + total_lines_removed DESC
+`
+
+type GetLineChangesByLanguageByExperimentParams struct {
+	ExperimentID sql.NullString `json:"experiment_id"`
+	CreatedAt    string         `json:"created_at"`
+}
+
+type GetLineChangesByLanguageByExperimentRow struct {
+	Language          sql.NullString  `json:"language"`
+	FileCount         int64           `json:"file_count"`
+	TotalLinesAdded   sql.NullFloat64 `json:"total_lines_added"`
+	TotalLinesRemoved sql.NullFloat64 `json:"total_lines_removed"`
+}
+
+func (q *Queries) GetLineChangesByLanguageByExperiment(ctx context.Context, arg GetLineChangesByLanguageByExperimentParams) ([]GetLineChangesByLanguageByExperimentRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLineChangesByLanguageByExperiment, arg.ExperimentID, arg.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetStatsForAllExperimentsRow{}
+	items := []GetLineChangesByLanguageByExperimentRow{}
 	for rows.Next() {
-		var i GetStatsForAllExperimentsRow
+		var i GetLineChangesByLanguageByExperimentRow
 		if err := rows.Scan(
-			&i.ExperimentID,
-			&i.ExperimentName,
+			&i.Language,
+			&i.FileCount,
+			&i.TotalLinesAdded,
+			&i.TotalLinesRemoved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMCPServerUsage = `-- name: GetMCPServerUsage :many
+SELECT
+    st.mcp_server,
+    COUNT(DISTINCT st.mcp_tool) as tool_count,
+    COUNT(DISTINCT st.session_id) as session_count,
+    SUM(st.invocation_count) as total_invocations,
+    SUM(st.error_count) as total_errors,
+    SUM(st.result_tokens) as total_result_tokens
+FROM session_tools st
+JOIN sessions s ON st.session_id = s.id
+WHERE s.created_at >= ? AND st.mcp_server IS NOT NULL
+GROUP BY st.mcp_server
+ORDER BY total_invocations DESC
+`
+
+type GetMCPServerUsageRow struct {
+	McpServer         sql.NullString  `json:"mcp_server"`
+	ToolCount         int64           `json:"tool_count"`
+	SessionCount      int64           `json:"session_count"`
+	TotalInvocations  sql.NullFloat64 `json:"total_invocations"`
+	TotalErrors       sql.NullFloat64 `json:"total_errors"`
+	TotalResultTokens sql.NullFloat64 `json:"total_result_tokens"`
+}
+
+func (q *Queries) GetMCPServerUsage(ctx context.Context, createdAt string) ([]GetMCPServerUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMCPServerUsage, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetMCPServerUsageRow{}
+	for rows.Next() {
+		var i GetMCPServerUsageRow
+		if err := rows.Scan(
+			&i.McpServer,
+			&i.ToolCount,
 			&i.SessionCount,
-			&i.TotalUserMessages,
-			&i.TotalAssistantMessages,
-			&i.TotalTurns,
+			&i.TotalInvocations,
+			&i.TotalErrors,
+			&i.TotalResultTokens,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMCPServerUsageByExperiment = `-- name: GetMCPServerUsageByExperiment :many
+SELECT
+    st.mcp_server,
+    COUNT(DISTINCT st.mcp_tool) as tool_count,
+    COUNT(DISTINCT st.session_id) as session_count,
+    SUM(st.invocation_count) as total_invocations,
+    SUM(st.error_count) as total_errors,
+    SUM(st.result_tokens) as total_result_tokens
+FROM session_tools st
+JOIN sessions s ON st.session_id = s.id
+WHERE s.experiment_id = ? AND s.created_at >= ? AND st.mcp_server IS NOT NULL
+GROUP BY st.mcp_server
+ORDER BY total_invocations DESC
+`
+
+type GetMCPServerUsageByExperimentParams struct {
+	ExperimentID sql.NullString `json:"experiment_id"`
+	CreatedAt    string         `json:"created_at"`
+}
+
+type GetMCPServerUsageByExperimentRow struct {
+	McpServer         sql.NullString  `json:"mcp_server"`
+	ToolCount         int64           `json:"tool_count"`
+	SessionCount      int64           `json:"session_count"`
+	TotalInvocations  sql.NullFloat64 `json:"total_invocations"`
+	TotalErrors       sql.NullFloat64 `json:"total_errors"`
+	TotalResultTokens sql.NullFloat64 `json:"total_result_tokens"`
+}
+
+func (q *Queries) GetMCPServerUsageByExperiment(ctx context.Context, arg GetMCPServerUsageByExperimentParams) ([]GetMCPServerUsageByExperimentRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMCPServerUsageByExperiment, arg.ExperimentID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetMCPServerUsageByExperimentRow{}
+	for rows.Next() {
+		var i GetMCPServerUsageByExperimentRow
+		if err := rows.Scan(
+			&i.McpServer,
+			&i.ToolCount,
+			&i.SessionCount,
+			&i.TotalInvocations,
+			&i.TotalErrors,
+			&i.TotalResultTokens,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMCPToolUsage = `-- name: GetMCPToolUsage :many
+SELECT
+    st.mcp_server,
+    st.mcp_tool,
+    SUM(st.invocation_count) as total_invocations,
+    SUM(st.error_count) as total_errors,
+    SUM(st.result_tokens) as total_result_tokens
+FROM session_tools st
+JOIN sessions s ON st.session_id = s.id
+WHERE s.created_at >= ? AND st.mcp_server IS NOT NULL
+GROUP BY st.mcp_server, st.mcp_tool
+ORDER BY st.mcp_server ASC, total_invocations DESC
+`
+
+type GetMCPToolUsageRow struct {
+	McpServer         sql.NullString  `json:"mcp_server"`
+	McpTool           sql.NullString  `json:"mcp_tool"`
+	TotalInvocations  sql.NullFloat64 `json:"total_invocations"`
+	TotalErrors       sql.NullFloat64 `json:"total_errors"`
+	TotalResultTokens sql.NullFloat64 `json:"total_result_tokens"`
+}
+
+func (q *Queries) GetMCPToolUsage(ctx context.Context, createdAt string) ([]GetMCPToolUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMCPToolUsage, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetMCPToolUsageRow{}
+	for rows.Next() {
+		var i GetMCPToolUsageRow
+		if err := rows.Scan(
+			&i.McpServer,
+			&i.McpTool,
+			&i.TotalInvocations,
+			&i.TotalErrors,
+			&i.TotalResultTokens,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMCPToolUsageByExperiment = `-- name: GetMCPToolUsageByExperiment :many
+SELECT
+    st.mcp_server,
+    st.mcp_tool,
+    SUM(st.invocation_count) as total_invocations,
+    SUM(st.error_count) as total_errors,
+    SUM(st.result_tokens) as total_result_tokens
+FROM session_tools st
+JOIN sessions s ON st.session_id = s.id
+WHERE s.experiment_id = ? AND s.created_at >= ? AND st.mcp_server IS NOT NULL
+GROUP BY st.mcp_server, st.mcp_tool
+ORDER BY st.mcp_server ASC, total_invocations DESC
+`
+
+type GetMCPToolUsageByExperimentParams struct {
+	ExperimentID sql.NullString `json:"experiment_id"`
+	CreatedAt    string         `json:"created_at"`
+}
+
+type GetMCPToolUsageByExperimentRow struct {
+	McpServer         sql.NullString  `json:"mcp_server"`
+	McpTool           sql.NullString  `json:"mcp_tool"`
+	TotalInvocations  sql.NullFloat64 `json:"total_invocations"`
+	TotalErrors       sql.NullFloat64 `json:"total_errors"`
+	TotalResultTokens sql.NullFloat64 `json:"total_result_tokens"`
+}
+
+func (q *Queries) GetMCPToolUsageByExperiment(ctx context.Context, arg GetMCPToolUsageByExperimentParams) ([]GetMCPToolUsageByExperimentRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMCPToolUsageByExperiment, arg.ExperimentID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetMCPToolUsageByExperimentRow{}
+	for rows.Next() {
+		var i GetMCPToolUsageByExperimentRow
+		if err := rows.Scan(
+			&i.McpServer,
+			&i.McpTool,
+			&i.TotalInvocations,
+			&i.TotalErrors,
+			&i.TotalResultTokens,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getModelUsageStats = `-- name: GetModelUsageStats :many
+SELECT
+    mu.model_id,
+    COUNT(DISTINCT mu.session_id) as session_count,
+    COALESCE(SUM(mu.message_count), 0) as total_messages,
+    COALESCE(SUM(mu.token_input), 0) as total_token_input,
+    COALESCE(SUM(mu.token_output), 0) as total_token_output,
+    COALESCE(SUM(mu.token_cache_read), 0) as total_token_cache_read,
+    COALESCE(SUM(mu.token_cache_write), 0) as total_token_cache_write,
+    COALESCE(SUM(mu.cost_estimate_usd), 0) as total_cost_usd
+FROM session_model_usage mu
+JOIN sessions s ON mu.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY mu.model_id
+ORDER BY total_cost_usd DESC
+`
+
+type GetModelUsageStatsRow struct {
+	ModelID              string      `json:"model_id"`
+	SessionCount         int64       `json:"session_count"`
+	TotalMessages        interface{} `json:"total_messages"`
+	TotalTokenInput      interface{} `json:"total_token_input"`
+	TotalTokenOutput     interface{} `json:"total_token_output"`
+	TotalTokenCacheRead  interface{} `json:"total_token_cache_read"`
+	TotalTokenCacheWrite interface{} `json:"total_token_cache_write"`
+	TotalCostUsd         interface{} `json:"total_cost_usd"`
+}
+
+func (q *Queries) GetModelUsageStats(ctx context.Context, createdAt string) ([]GetModelUsageStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getModelUsageStats, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetModelUsageStatsRow{}
+	for rows.Next() {
+		var i GetModelUsageStatsRow
+		if err := rows.Scan(
+			&i.ModelID,
+			&i.SessionCount,
+			&i.TotalMessages,
 			&i.TotalTokenInput,
 			&i.TotalTokenOutput,
 			&i.TotalTokenCacheRead,
 			&i.TotalTokenCacheWrite,
 			&i.TotalCostUsd,
-			&i.TotalErrors,
 		); err != nil {
 			return nil, err
 		}
@@ -462,42 +1273,755 @@ func (q *Queries) GetStatsForAllExperiments(ctx context.Context) ([]GetStatsForA
 	return items, nil
 }
 
-const getSubagentStatsBySession = `-- name: GetSubagentStatsBySession :many
-SELECT
-    agent_type,
-    agent_kind,
-    COUNT(*) as invocation_count,
-    COALESCE(SUM(total_tokens), 0) as total_tokens,
-    COALESCE(SUM(cost_estimate_usd), 0) as total_cost
-FROM session_subagents
-WHERE session_id = ?
-GROUP BY agent_type, agent_kind
-ORDER BY total_tokens DESC
+const getMostFailingCommands = `-- name: GetMostFailingCommands :many
+SELECT
+    command,
+    COUNT(*) as run_count,
+    SUM(CASE WHEN sc.status = 'failed' THEN 1 ELSE 0 END) as failure_count,
+    AVG(sc.duration_ms) as avg_duration_ms,
+    MAX(sc.duration_ms) as max_duration_ms
+FROM session_commands sc
+JOIN sessions s ON sc.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY command
+HAVING failure_count > 0
+ORDER BY failure_count DESC, run_count DESC
+LIMIT ?
+`
+
+type GetMostFailingCommandsParams struct {
+	CreatedAt string `json:"created_at"`
+	Limit     int64  `json:"limit"`
+}
+
+type GetMostFailingCommandsRow struct {
+	Command       string          `json:"command"`
+	RunCount      int64           `json:"run_count"`
+	FailureCount  sql.NullFloat64 `json:"failure_count"`
+	AvgDurationMs sql.NullFloat64 `json:"avg_duration_ms"`
+	MaxDurationMs interface{}     `json:"max_duration_ms"`
+}
+
+func (q *Queries) GetMostFailingCommands(ctx context.Context, arg GetMostFailingCommandsParams) ([]GetMostFailingCommandsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMostFailingCommands, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetMostFailingCommandsRow{}
+	for rows.Next() {
+		var i GetMostFailingCommandsRow
+		if err := rows.Scan(
+			&i.Command,
+			&i.RunCount,
+			&i.FailureCount,
+			&i.AvgDurationMs,
+			&i.MaxDurationMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getParseAnomalyUsage = `-- name: GetParseAnomalyUsage :many
+SELECT
+    a.kind,
+    a.detail,
+    COUNT(DISTINCT a.session_id) as session_count,
+    SUM(a.occurrence_count) as total_occurrences,
+    CAST(MIN(s.created_at) AS TEXT) as first_seen_at,
+    CAST(MAX(s.created_at) AS TEXT) as last_seen_at
+FROM session_parse_anomalies a
+JOIN sessions s ON a.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY a.kind, a.detail
+ORDER BY last_seen_at DESC, total_occurrences DESC
+`
+
+type GetParseAnomalyUsageRow struct {
+	Kind             string          `json:"kind"`
+	Detail           string          `json:"detail"`
+	SessionCount     int64           `json:"session_count"`
+	TotalOccurrences sql.NullFloat64 `json:"total_occurrences"`
+	FirstSeenAt      string          `json:"first_seen_at"`
+	LastSeenAt       string          `json:"last_seen_at"`
+}
+
+func (q *Queries) GetParseAnomalyUsage(ctx context.Context, createdAt string) ([]GetParseAnomalyUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getParseAnomalyUsage, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetParseAnomalyUsageRow{}
+	for rows.Next() {
+		var i GetParseAnomalyUsageRow
+		if err := rows.Scan(
+			&i.Kind,
+			&i.Detail,
+			&i.SessionCount,
+			&i.TotalOccurrences,
+			&i.FirstSeenAt,
+			&i.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getParseDiagnosticsCoverage = `-- name: GetParseDiagnosticsCoverage :one
+SELECT
+    COUNT(*) as session_count,
+    CAST(COALESCE(SUM(CASE WHEN sm.parser_version = 0 THEN 1 ELSE 0 END), 0) AS INTEGER) as unchecked_count
+FROM sessions s
+JOIN session_metrics sm ON sm.session_id = s.id
+WHERE s.created_at >= ?
+`
+
+type GetParseDiagnosticsCoverageRow struct {
+	SessionCount   int64 `json:"session_count"`
+	UncheckedCount int64 `json:"unchecked_count"`
+}
+
+func (q *Queries) GetParseDiagnosticsCoverage(ctx context.Context, createdAt string) (GetParseDiagnosticsCoverageRow, error) {
+	row := q.db.QueryRowContext(ctx, getParseDiagnosticsCoverage, createdAt)
+	var i GetParseDiagnosticsCoverageRow
+	err := row.Scan(&i.SessionCount, &i.UncheckedCount)
+	return i, err
+}
+
+const getSessionMetricsBySessionID = `-- name: GetSessionMetricsBySessionID :one
+SELECT session_id, message_count_user, message_count_assistant, turn_count, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd, error_count, model_id, lines_added, lines_removed, compaction_count, token_cache_wasted, thinking_block_count, thinking_tokens, web_search_requests, web_fetch_requests, interruption_count, tool_rejection_count, permission_denial_count, transcript_line_count, parser_version FROM session_metrics WHERE session_id = ?
+`
+
+func (q *Queries) GetSessionMetricsBySessionID(ctx context.Context, sessionID string) (SessionMetric, error) {
+	row := q.db.QueryRowContext(ctx, getSessionMetricsBySessionID, sessionID)
+	var i SessionMetric
+	err := row.Scan(
+		&i.SessionID,
+		&i.MessageCountUser,
+		&i.MessageCountAssistant,
+		&i.TurnCount,
+		&i.TokenInput,
+		&i.TokenOutput,
+		&i.TokenCacheRead,
+		&i.TokenCacheWrite,
+		&i.CostEstimateUsd,
+		&i.ErrorCount,
+		&i.ModelID,
+		&i.LinesAdded,
+		&i.LinesRemoved,
+		&i.CompactionCount,
+		&i.TokenCacheWasted,
+		&i.ThinkingBlockCount,
+		&i.ThinkingTokens,
+		&i.WebSearchRequests,
+		&i.WebFetchRequests,
+		&i.InterruptionCount,
+		&i.ToolRejectionCount,
+		&i.PermissionDenialCount,
+		&i.TranscriptLineCount,
+		&i.ParserVersion,
+	)
+	return i, err
+}
+
+const getSkillUsage = `-- name: GetSkillUsage :many
+SELECT
+    sa.agent_type as skill,
+    COUNT(DISTINCT sa.session_id) as session_count,
+    COUNT(*) as total_invocations,
+    CAST(MAX(s.created_at) AS TEXT) as last_used_at
+FROM session_subagents sa
+JOIN sessions s ON sa.session_id = s.id
+WHERE s.created_at >= ? AND sa.agent_kind = 'skill'
+GROUP BY sa.agent_type
+ORDER BY total_invocations DESC, sa.agent_type ASC
+`
+
+type GetSkillUsageRow struct {
+	Skill            string `json:"skill"`
+	SessionCount     int64  `json:"session_count"`
+	TotalInvocations int64  `json:"total_invocations"`
+	LastUsedAt       string `json:"last_used_at"`
+}
+
+func (q *Queries) GetSkillUsage(ctx context.Context, createdAt string) ([]GetSkillUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSkillUsage, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSkillUsageRow{}
+	for rows.Next() {
+		var i GetSkillUsageRow
+		if err := rows.Scan(
+			&i.Skill,
+			&i.SessionCount,
+			&i.TotalInvocations,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSlashCommandUsage = `-- name: GetSlashCommandUsage :many
+SELECT
+    sc.command,
+    sc.kind,
+    COUNT(DISTINCT sc.session_id) as session_count,
+    SUM(sc.invocation_count) as total_invocations,
+    CAST(MAX(s.created_at) AS TEXT) as last_used_at
+FROM session_slash_commands sc
+JOIN sessions s ON sc.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY sc.command, sc.kind
+ORDER BY total_invocations DESC, sc.command ASC
+`
+
+type GetSlashCommandUsageRow struct {
+	Command          string          `json:"command"`
+	Kind             string          `json:"kind"`
+	SessionCount     int64           `json:"session_count"`
+	TotalInvocations sql.NullFloat64 `json:"total_invocations"`
+	LastUsedAt       string          `json:"last_used_at"`
+}
+
+func (q *Queries) GetSlashCommandUsage(ctx context.Context, createdAt string) ([]GetSlashCommandUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSlashCommandUsage, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSlashCommandUsageRow{}
+	for rows.Next() {
+		var i GetSlashCommandUsageRow
+		if err := rows.Scan(
+			&i.Command,
+			&i.Kind,
+			&i.SessionCount,
+			&i.TotalInvocations,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSlowestCommands = `-- name: GetSlowestCommands :many
+SELECT
+    command,
+    COUNT(*) as run_count,
+    SUM(CASE WHEN sc.status = 'failed' THEN 1 ELSE 0 END) as failure_count,
+    AVG(sc.duration_ms) as avg_duration_ms,
+    MAX(sc.duration_ms) as max_duration_ms
+FROM session_commands sc
+JOIN sessions s ON sc.session_id = s.id
+WHERE s.created_at >= ? AND sc.duration_ms IS NOT NULL
+GROUP BY command
+ORDER BY avg_duration_ms DESC
+LIMIT ?
+`
+
+type GetSlowestCommandsParams struct {
+	CreatedAt string `json:"created_at"`
+	Limit     int64  `json:"limit"`
+}
+
+type GetSlowestCommandsRow struct {
+	Command       string          `json:"command"`
+	RunCount      int64           `json:"run_count"`
+	FailureCount  sql.NullFloat64 `json:"failure_count"`
+	AvgDurationMs sql.NullFloat64 `json:"avg_duration_ms"`
+	MaxDurationMs interface{}     `json:"max_duration_ms"`
+}
+
+func (q *Queries) GetSlowestCommands(ctx context.Context, arg GetSlowestCommandsParams) ([]GetSlowestCommandsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSlowestCommands, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSlowestCommandsRow{}
+	for rows.Next() {
+		var i GetSlowestCommandsRow
+		if err := rows.Scan(
+			&i.Command,
+			&i.RunCount,
+			&i.FailureCount,
+			&i.AvgDurationMs,
+			&i.MaxDurationMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStatsForAllExperiments = `-- name: GetStatsForAllExperiments :many
+SELECT
+    e.id as experiment_id,
+    e.name as experiment_name,
+    COUNT(DISTINCT s.id) as session_count,
+    COALESCE(SUM(m.message_count_user), 0) as total_user_messages,
+    COALESCE(SUM(m.message_count_assistant), 0) as total_assistant_messages,
+    COALESCE(SUM(m.turn_count), 0) as total_turns,
+    COALESCE(SUM(m.token_input), 0) as total_token_input,
+    COALESCE(SUM(m.token_output), 0) as total_token_output,
+    COALESCE(SUM(m.token_cache_read), 0) as total_token_cache_read,
+    COALESCE(SUM(m.token_cache_write), 0) as total_token_cache_write,
+    COALESCE(SUM(m.cost_estimate_usd), 0) as total_cost_usd,
+    COALESCE(SUM(m.error_count), 0) as total_errors
+FROM experiments e
+LEFT JOIN sessions s ON s.experiment_id = e.id
+LEFT JOIN session_metrics m ON s.id = m.session_id
+GROUP BY e.id, e.name
+ORDER BY e.created_at DESC
+`
+
+type GetStatsForAllExperimentsRow struct {
+	ExperimentID           string      `json:"experiment_id"`
+	ExperimentName         string      `json:"experiment_name"`
+	SessionCount           int64       `json:"session_count"`
+	TotalUserMessages      interface{} `json:"total_user_messages"`
+	TotalAssistantMessages interface{} `json:"total_assistant_messages"`
+	TotalTurns             interface{} `json:"total_turns"`
+	TotalTokenInput        interface{} `json:"total_token_input"`
+	TotalTokenOutput       interface{} `json:"total_token_output"`
+	TotalTokenCacheRead    interface{} `json:"total_token_cache_read"`
+	TotalTokenCacheWrite   interface{} `json:"total_token_cache_write"`
+	TotalCostUsd           interface{} `json:"total_cost_usd"`
+	TotalErrors            interface{} `json:"total_errors"`
+}
+
+func (q *Queries) GetStatsForAllExperiments(ctx context.Context) ([]GetStatsForAllExperimentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStatsForAllExperiments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetStatsForAllExperimentsRow{}
+	for rows.Next() {
+		var i GetStatsForAllExperimentsRow
+		if err := rows.Scan(
+			&i.ExperimentID,
+			&i.ExperimentName,
+			&i.SessionCount,
+			&i.TotalUserMessages,
+			&i.TotalAssistantMessages,
+			&i.TotalTurns,
+			&i.TotalTokenInput,
+			&i.TotalTokenOutput,
+			&i.TotalTokenCacheRead,
+			&i.TotalTokenCacheWrite,
+			&i.TotalCostUsd,
+			&i.TotalErrors,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSubagentStatsBySession = `-- name: GetSubagentStatsBySession :many
+SELECT
+    agent_type,
+    agent_kind,
+    COUNT(*) as invocation_count,
+    COALESCE(SUM(total_tokens), 0) as total_tokens,
+    COALESCE(SUM(cost_estimate_usd), 0) as total_cost
+FROM session_subagents
+WHERE session_id = ?
+GROUP BY agent_type, agent_kind
+ORDER BY total_tokens DESC
+`
+
+type GetSubagentStatsBySessionRow struct {
+	AgentType       string      `json:"agent_type"`
+	AgentKind       string      `json:"agent_kind"`
+	InvocationCount int64       `json:"invocation_count"`
+	TotalTokens     interface{} `json:"total_tokens"`
+	TotalCost       interface{} `json:"total_cost"`
+}
+
+func (q *Queries) GetSubagentStatsBySession(ctx context.Context, sessionID string) ([]GetSubagentStatsBySessionRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSubagentStatsBySession, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSubagentStatsBySessionRow{}
+	for rows.Next() {
+		var i GetSubagentStatsBySessionRow
+		if err := rows.Scan(
+			&i.AgentType,
+			&i.AgentKind,
+			&i.InvocationCount,
+			&i.TotalTokens,
+			&i.TotalCost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTopSubagentUsage = `-- name: GetTopSubagentUsage :many
+SELECT
+    agent_type,
+    agent_kind,
+    COUNT(*) as invocation_count,
+    COALESCE(SUM(total_tokens), 0) as total_tokens,
+    COALESCE(SUM(cost_estimate_usd), 0) as total_cost
+FROM session_subagents sa
+JOIN sessions s ON sa.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY agent_type, agent_kind
+ORDER BY total_tokens DESC
+LIMIT ?
+`
+
+type GetTopSubagentUsageParams struct {
+	CreatedAt string `json:"created_at"`
+	Limit     int64  `json:"limit"`
+}
+
+type GetTopSubagentUsageRow struct {
+	AgentType       string      `json:"agent_type"`
+	AgentKind       string      `json:"agent_kind"`
+	InvocationCount int64       `json:"invocation_count"`
+	TotalTokens     interface{} `json:"total_tokens"`
+	TotalCost       interface{} `json:"total_cost"`
+}
+
+func (q *Queries) GetTopSubagentUsage(ctx context.Context, arg GetTopSubagentUsageParams) ([]GetTopSubagentUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTopSubagentUsage, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTopSubagentUsageRow{}
+	for rows.Next() {
+		var i GetTopSubagentUsageRow
+		if err := rows.Scan(
+			&i.AgentType,
+			&i.AgentKind,
+			&i.InvocationCount,
+			&i.TotalTokens,
+			&i.TotalCost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTopToolsUsage = `-- name: GetTopToolsUsage :many
+SELECT
+    tool_name,
+    SUM(invocation_count) as total_invocations,
+    SUM(error_count) as total_errors,
+    SUM(total_duration_ms) as total_duration_ms,
+    SUM(CASE WHEN total_duration_ms IS NOT NULL THEN invocation_count ELSE 0 END) as timed_invocations
+FROM session_tools st
+JOIN sessions s ON st.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY tool_name
+ORDER BY total_invocations DESC
+LIMIT ?
+`
+
+type GetTopToolsUsageParams struct {
+	CreatedAt string `json:"created_at"`
+	Limit     int64  `json:"limit"`
+}
+
+type GetTopToolsUsageRow struct {
+	ToolName         string          `json:"tool_name"`
+	TotalInvocations sql.NullFloat64 `json:"total_invocations"`
+	TotalErrors      sql.NullFloat64 `json:"total_errors"`
+	TotalDurationMs  sql.NullFloat64 `json:"total_duration_ms"`
+	TimedInvocations sql.NullFloat64 `json:"timed_invocations"`
+}
+
+func (q *Queries) GetTopToolsUsage(ctx context.Context, arg GetTopToolsUsageParams) ([]GetTopToolsUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTopToolsUsage, arg.CreatedAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTopToolsUsageRow{}
+	for rows.Next() {
+		var i GetTopToolsUsageRow
+		if err := rows.Scan(
+			&i.ToolName,
+			&i.TotalInvocations,
+			&i.TotalErrors,
+			&i.TotalDurationMs,
+			&i.TimedInvocations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTopToolsUsageByExperiment = `-- name: GetTopToolsUsageByExperiment :many
+SELECT
+    tool_name,
+    SUM(invocation_count) as total_invocations,
+    SUM(error_count) as total_errors,
+    SUM(total_duration_ms) as total_duration_ms,
+    SUM(CASE WHEN total_duration_ms IS NOT NULL THEN invocation_count ELSE 0 END) as timed_invocations
+FROM session_tools st
+JOIN sessions s ON st.session_id = s.id
+WHERE s.experiment_id = ?
+GROUP BY tool_name
+ORDER BY total_invocations DESC
+LIMIT ?
+`
+
+type GetTopToolsUsageByExperimentParams struct {
+	ExperimentID sql.NullString `json:"experiment_id"`
+	Limit        int64          `json:"limit"`
+}
+
+type GetTopToolsUsageByExperimentRow struct {
+	ToolName         string          `json:"tool_name"`
+	TotalInvocations sql.NullFloat64 `json:"total_invocations"`
+	TotalErrors      sql.NullFloat64 `json:"total_errors"`
+	TotalDurationMs  sql.NullFloat64 `json:"total_duration_ms"`
+	TimedInvocations sql.NullFloat64 `json:"timed_invocations"`
+}
+
+func (q *Queries) GetTopToolsUsageByExperiment(ctx context.Context, arg GetTopToolsUsageByExperimentParams) ([]GetTopToolsUsageByExperimentRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTopToolsUsageByExperiment, arg.ExperimentID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTopToolsUsageByExperimentRow{}
+	for rows.Next() {
+		var i GetTopToolsUsageByExperimentRow
+		if err := rows.Scan(
+			&i.ToolName,
+			&i.TotalInvocations,
+			&i.TotalErrors,
+			&i.TotalDurationMs,
+			&i.TimedInvocations,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionCommandsBySessionID = `-- name: ListSessionCommandsBySessionID :many
+SELECT id, session_id, command, exit_code, executed_at, tool_use_id, status, duration_ms FROM session_commands WHERE session_id = ? ORDER BY id ASC
+`
+
+func (q *Queries) ListSessionCommandsBySessionID(ctx context.Context, sessionID string) ([]SessionCommand, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionCommandsBySessionID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SessionCommand{}
+	for rows.Next() {
+		var i SessionCommand
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Command,
+			&i.ExitCode,
+			&i.ExecutedAt,
+			&i.ToolUseID,
+			&i.Status,
+			&i.DurationMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionCommitsBySessionID = `-- name: ListSessionCommitsBySessionID :many
+SELECT id, session_id, sha, subject, author, committed_at, files_changed, insertions, deletions FROM session_commits WHERE session_id = ? ORDER BY committed_at ASC
+`
+
+func (q *Queries) ListSessionCommitsBySessionID(ctx context.Context, sessionID string) ([]SessionCommit, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionCommitsBySessionID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SessionCommit{}
+	for rows.Next() {
+		var i SessionCommit
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Sha,
+			&i.Subject,
+			&i.Author,
+			&i.CommittedAt,
+			&i.FilesChanged,
+			&i.Insertions,
+			&i.Deletions,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionFilesBySessionID = `-- name: ListSessionFilesBySessionID :many
+SELECT id, session_id, file_path, operation, operation_count, lines_added, lines_removed, language FROM session_files WHERE session_id = ? ORDER BY operation_count DESC
+`
+
+func (q *Queries) ListSessionFilesBySessionID(ctx context.Context, sessionID string) ([]SessionFile, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionFilesBySessionID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SessionFile{}
+	for rows.Next() {
+		var i SessionFile
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.FilePath,
+			&i.Operation,
+			&i.OperationCount,
+			&i.LinesAdded,
+			&i.LinesRemoved,
+			&i.Language,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionHooksBySessionID = `-- name: ListSessionHooksBySessionID :many
+SELECT id, session_id, event, hook_name, command, execution_count, error_count, blocked_count FROM session_hooks WHERE session_id = ? ORDER BY execution_count DESC, hook_name ASC
 `
 
-type GetSubagentStatsBySessionRow struct {
-	AgentType       string      `json:"agent_type"`
-	AgentKind       string      `json:"agent_kind"`
-	InvocationCount int64       `json:"invocation_count"`
-	TotalTokens     interface{} `json:"total_tokens"`
-	TotalCost       interface{} `json:"total_cost"`
-}
-
-func (q *Queries) GetSubagentStatsBySession(ctx context.Context, sessionID string) ([]GetSubagentStatsBySessionRow, error) {
-	rows, err := q.db.QueryContext(ctx, getSubagentStatsBySession, sessionID)
+func (q *Queries) ListSessionHooksBySessionID(ctx context.Context, sessionID string) ([]SessionHook, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionHooksBySessionID, sessionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetSubagentStatsBySessionRow{}
+	items := []SessionHook{}
 	for rows.Next() {
-		var i GetSubagentStatsBySessionRow
+		var i SessionHook
 		if err := rows.Scan(
-			&i.AgentType,
-			&i.AgentKind,
-			&i.InvocationCount,
-			&i.TotalTokens,
-			&i.TotalCost,
+			&i.ID,
+			&i.SessionID,
+			&i.Event,
+			&i.HookName,
+			&i.Command,
+			&i.ExecutionCount,
+			&i.ErrorCount,
+			&i.BlockedCount,
 		); err != nil {
 			return nil, err
 		}
@@ -512,49 +2036,29 @@ func (q *Queries) GetSubagentStatsBySession(ctx context.Context, sessionID strin
 	return items, nil
 }
 
-const getTopSubagentUsage = `-- name: GetTopSubagentUsage :many
-SELECT
-    agent_type,
-    agent_kind,
-    COUNT(*) as invocation_count,
-    COALESCE(SUM(total_tokens), 0) as total_tokens,
-    COALESCE(SUM(cost_estimate_usd), 0) as total_cost
-FROM session_subagents sa
-JOIN sessions s ON sa.session_id = s.id
-WHERE s.created_at >= ?
-GROUP BY agent_type, agent_kind
-ORDER BY total_tokens DESC
-LIMIT ?
+const listSessionModelUsageBySessionID = `-- name: ListSessionModelUsageBySessionID :many
+SELECT id, session_id, model_id, message_count, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd FROM session_model_usage WHERE session_id = ? ORDER BY id ASC
 `
 
-type GetTopSubagentUsageParams struct {
-	CreatedAt string `json:"created_at"`
-	Limit     int64  `json:"limit"`
-}
-
-type GetTopSubagentUsageRow struct {
-	AgentType       string      `json:"agent_type"`
-	AgentKind       string      `json:"agent_kind"`
-	InvocationCount int64       `json:"invocation_count"`
-	TotalTokens     interface{} `json:"total_tokens"`
-	TotalCost       interface{} `json:"total_cost"`
-}
-
-func (q *Queries) GetTopSubagentUsage(ctx context.Context, arg GetTopSubagentUsageParams) ([]GetTopSubagentUsageRow, error) {
-	rows, err := q.db.QueryContext(ctx, getTopSubagentUsage, arg.CreatedAt, arg.Limit)
+func (q *Queries) ListSessionModelUsageBySessionID(ctx context.Context, sessionID string) ([]SessionModelUsage, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionModelUsageBySessionID, sessionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetTopSubagentUsageRow{}
+	items := []SessionModelUsage{}
 	for rows.Next() {
-		var i GetTopSubagentUsageRow
+		var i SessionModelUsage
 		if err := rows.Scan(
-			&i.AgentType,
-			&i.AgentKind,
-			&i.InvocationCount,
-			&i.TotalTokens,
-			&i.TotalCost,
+			&i.ID,
+			&i.SessionID,
+			&i.ModelID,
+			&i.MessageCount,
+			&i.TokenInput,
+			&i.TokenOutput,
+			&i.TokenCacheRead,
+			&i.TokenCacheWrite,
+			&i.CostEstimateUsd,
 		); err != nil {
 			return nil, err
 		}
@@ -569,40 +2073,27 @@ func (q *Queries) GetTopSubagentUsage(ctx context.Context, arg GetTopSubagentUsa
 	return items, nil
 }
 
-const getTopToolsUsage = `-- name: GetTopToolsUsage :many
-SELECT
-    tool_name,
-    SUM(invocation_count) as total_invocations,
-    SUM(error_count) as total_errors
-FROM session_tools st
-JOIN sessions s ON st.session_id = s.id
-WHERE s.created_at >= ?
-GROUP BY tool_name
-ORDER BY total_invocations DESC
-LIMIT ?
+const listSessionParseAnomaliesBySessionID = `-- name: ListSessionParseAnomaliesBySessionID :many
+SELECT id, session_id, kind, detail, occurrence_count, first_line FROM session_parse_anomalies WHERE session_id = ? ORDER BY first_line ASC
 `
 
-type GetTopToolsUsageParams struct {
-	CreatedAt string `json:"created_at"`
-	Limit     int64  `json:"limit"`
-}
-
-type GetTopToolsUsageRow struct {
-	ToolName         string          `json:"tool_name"`
-	TotalInvocations sql.NullFloat64 `json:"total_invocations"`
-	TotalErrors      sql.NullFloat64 `json:"total_errors"`
-}
-
-func (q *Queries) GetTopToolsUsage(ctx context.Context, arg GetTopToolsUsageParams) ([]GetTopToolsUsageRow, error) {
-	rows, err := q.db.QueryContext(ctx, getTopToolsUsage, arg.CreatedAt, arg.Limit)
+func (q *Queries) ListSessionParseAnomaliesBySessionID(ctx context.Context, sessionID string) ([]SessionParseAnomaly, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionParseAnomaliesBySessionID, sessionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetTopToolsUsageRow{}
+	items := []SessionParseAnomaly{}
 	for rows.Next() {
-		var i GetTopToolsUsageRow
-		if err := rows.Scan(&i.ToolName, &i.TotalInvocations, &i.TotalErrors); err != nil {
+		var i SessionParseAnomaly
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Kind,
+			&i.Detail,
+			&i.OccurrenceCount,
+			&i.FirstLine,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -616,40 +2107,26 @@ func (q *Queries) GetTopToolsUsage(ctx context.Context, arg GetTopToolsUsagePara
 	return items, nil
 }
 
-const getTopToolsUsageByExperiment = `-- name: GetTopToolsUsageByExperiment :many
-SELECT
-    tool_name,
-    SUM(invocation_count) as total_invocations,
-    SUM(error_count) as total_errors
-FROM session_tools st
-JOIN sessions s ON st.session_id = s.id
-WHERE s.experiment_id = ?
-GROUP BY tool_name
-ORDER BY total_invocations DESC
-LIMIT ?
+const listSessionSlashCommandsBySessionID = `-- name: ListSessionSlashCommandsBySessionID :many
+SELECT id, session_id, command, kind, invocation_count FROM session_slash_commands WHERE session_id = ? ORDER BY invocation_count DESC, command ASC
 `
 
-type GetTopToolsUsageByExperimentParams struct {
-	ExperimentID sql.NullString `json:"experiment_id"`
-	Limit        int64          `json:"limit"`
-}
-
-type GetTopToolsUsageByExperimentRow struct {
-	ToolName         string          `json:"tool_name"`
-	TotalInvocations sql.NullFloat64 `json:"total_invocations"`
-	TotalErrors      sql.NullFloat64 `json:"total_errors"`
-}
-
-func (q *Queries) GetTopToolsUsageByExperiment(ctx context.Context, arg GetTopToolsUsageByExperimentParams) ([]GetTopToolsUsageByExperimentRow, error) {
-	rows, err := q.db.QueryContext(ctx, getTopToolsUsageByExperiment, arg.ExperimentID, arg.Limit)
+func (q *Queries) ListSessionSlashCommandsBySessionID(ctx context.Context, sessionID string) ([]SessionSlashCommand, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionSlashCommandsBySessionID, sessionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetTopToolsUsageByExperimentRow{}
+	items := []SessionSlashCommand{}
 	for rows.Next() {
-		var i GetTopToolsUsageByExperimentRow
-		if err := rows.Scan(&i.ToolName, &i.TotalInvocations, &i.TotalErrors); err != nil {
+		var i SessionSlashCommand
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Command,
+			&i.Kind,
+			&i.InvocationCount,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -663,25 +2140,28 @@ func (q *Queries) GetTopToolsUsageByExperiment(ctx context.Context, arg GetTopTo
 	return items, nil
 }
 
-const listSessionCommandsBySessionID = `-- name: ListSessionCommandsBySessionID :many
-SELECT id, session_id, command, exit_code, executed_at FROM session_commands WHERE session_id = ? ORDER BY id ASC
+const listSessionSubagentFilesBySessionID = `-- name: ListSessionSubagentFilesBySessionID :many
+SELECT id, session_id, subagent_tool_use_id, file_path, operation, operation_count, lines_added, lines_removed FROM session_subagent_files WHERE session_id = ? ORDER BY file_path ASC
 `
 
-func (q *Queries) ListSessionCommandsBySessionID(ctx context.Context, sessionID string) ([]SessionCommand, error) {
-	rows, err := q.db.QueryContext(ctx, listSessionCommandsBySessionID, sessionID)
+func (q *Queries) ListSessionSubagentFilesBySessionID(ctx context.Context, sessionID string) ([]SessionSubagentFile, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionSubagentFilesBySessionID, sessionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []SessionCommand{}
+	items := []SessionSubagentFile{}
 	for rows.Next() {
-		var i SessionCommand
+		var i SessionSubagentFile
 		if err := rows.Scan(
 			&i.ID,
 			&i.SessionID,
-			&i.Command,
-			&i.ExitCode,
-			&i.ExecutedAt,
+			&i.SubagentToolUseID,
+			&i.FilePath,
+			&i.Operation,
+			&i.OperationCount,
+			&i.LinesAdded,
+			&i.LinesRemoved,
 		); err != nil {
 			return nil, err
 		}
@@ -696,25 +2176,27 @@ func (q *Queries) ListSessionCommandsBySessionID(ctx context.Context, sessionID
 	return items, nil
 }
 
-const listSessionFilesBySessionID = `-- name: ListSessionFilesBySessionID :many
-SELECT id, session_id, file_path, operation, operation_count FROM session_files WHERE session_id = ? ORDER BY operation_count DESC
+const listSessionSubagentToolsBySessionID = `-- name: ListSessionSubagentToolsBySessionID :many
+SELECT id, session_id, subagent_tool_use_id, tool_name, invocation_count, error_count, total_duration_ms FROM session_subagent_tools WHERE session_id = ? ORDER BY invocation_count DESC, tool_name ASC
 `
 
-func (q *Queries) ListSessionFilesBySessionID(ctx context.Context, sessionID string) ([]SessionFile, error) {
-	rows, err := q.db.QueryContext(ctx, listSessionFilesBySessionID, sessionID)
+func (q *Queries) ListSessionSubagentToolsBySessionID(ctx context.Context, sessionID string) ([]SessionSubagentTool, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionSubagentToolsBySessionID, sessionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []SessionFile{}
+	items := []SessionSubagentTool{}
 	for rows.Next() {
-		var i SessionFile
+		var i SessionSubagentTool
 		if err := rows.Scan(
 			&i.ID,
 			&i.SessionID,
-			&i.FilePath,
-			&i.Operation,
-			&i.OperationCount,
+			&i.SubagentToolUseID,
+			&i.ToolName,
+			&i.InvocationCount,
+			&i.ErrorCount,
+			&i.TotalDurationMs,
 		); err != nil {
 			return nil, err
 		}
@@ -730,7 +2212,7 @@ func (q *Queries) ListSessionFilesBySessionID(ctx context.Context, sessionID str
 }
 
 const listSessionSubagentsBySessionID = `-- name: ListSessionSubagentsBySessionID :many
-SELECT id, session_id, agent_type, agent_kind, description, model, total_tokens, token_input, token_output, token_cache_read, token_cache_write, total_duration_ms, tool_use_count, cost_estimate_usd FROM session_subagents WHERE session_id = ? ORDER BY id ASC
+SELECT id, session_id, agent_type, agent_kind, description, model, total_tokens, token_input, token_output, token_cache_read, token_cache_write, total_duration_ms, tool_use_count, cost_estimate_usd, tool_use_id, agent_id, parent_tool_use_id, error_count, model_id FROM session_subagents WHERE session_id = ? ORDER BY id ASC
 `
 
 func (q *Queries) ListSessionSubagentsBySessionID(ctx context.Context, sessionID string) ([]SessionSubagent, error) {
@@ -757,6 +2239,11 @@ func (q *Queries) ListSessionSubagentsBySessionID(ctx context.Context, sessionID
 			&i.TotalDurationMs,
 			&i.ToolUseCount,
 			&i.CostEstimateUsd,
+			&i.ToolUseID,
+			&i.AgentID,
+			&i.ParentToolUseID,
+			&i.ErrorCount,
+			&i.ModelID,
 		); err != nil {
 			return nil, err
 		}
@@ -772,7 +2259,7 @@ func (q *Queries) ListSessionSubagentsBySessionID(ctx context.Context, sessionID
 }
 
 const listSessionToolsBySessionID = `-- name: ListSessionToolsBySessionID :many
-SELECT id, session_id, tool_name, invocation_count, total_duration_ms, error_count FROM session_tools WHERE session_id = ? ORDER BY invocation_count DESC
+SELECT id, session_id, tool_name, invocation_count, total_duration_ms, error_count, mcp_server, mcp_tool, result_tokens, rejection_count, permission_denial_count FROM session_tools WHERE session_id = ? ORDER BY invocation_count DESC
 `
 
 func (q *Queries) ListSessionToolsBySessionID(ctx context.Context, sessionID string) ([]SessionTool, error) {
@@ -791,6 +2278,127 @@ func (q *Queries) ListSessionToolsBySessionID(ctx context.Context, sessionID str
 			&i.InvocationCount,
 			&i.TotalDurationMs,
 			&i.ErrorCount,
+			&i.McpServer,
+			&i.McpTool,
+			&i.ResultTokens,
+			&i.RejectionCount,
+			&i.PermissionDenialCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionTurnsBySessionID = `-- name: ListSessionTurnsBySessionID :many
+SELECT id, session_id, turn_index, prompt, started_at, ended_at, duration_ms, message_count, tool_calls, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd FROM session_turns WHERE session_id = ? ORDER BY turn_index ASC
+`
+
+func (q *Queries) ListSessionTurnsBySessionID(ctx context.Context, sessionID string) ([]SessionTurn, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionTurnsBySessionID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SessionTurn{}
+	for rows.Next() {
+		var i SessionTurn
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.TurnIndex,
+			&i.Prompt,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.DurationMs,
+			&i.MessageCount,
+			&i.ToolCalls,
+			&i.TokenInput,
+			&i.TokenOutput,
+			&i.TokenCacheRead,
+			&i.TokenCacheWrite,
+			&i.CostEstimateUsd,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionsForCostRecompute = `-- name: ListSessionsForCostRecompute :many
+SELECT s.id, s.started_at, m.model_id, m.token_input, m.token_output, m.token_cache_read, m.token_cache_write, m.cost_estimate_usd, m.web_search_requests, m.web_fetch_requests
+FROM sessions s
+JOIN session_metrics m ON s.id = m.session_id
+WHERE s.created_at >= ?1 AND s.created_at < ?2
+  AND (?3 IS NULL OR s.project_id = ?3)
+  AND (?4 IS NULL OR s.experiment_id = ?4)
+  AND (?5 IS NULL OR m.model_id = ?5 OR EXISTS (
+      SELECT 1 FROM session_model_usage mu WHERE mu.session_id = s.id AND mu.model_id = ?5
+  ))
+ORDER BY s.created_at ASC
+`
+
+type ListSessionsForCostRecomputeParams struct {
+	Since        string      `json:"since"`
+	Until        string      `json:"until"`
+	ProjectID    interface{} `json:"project_id"`
+	ExperimentID interface{} `json:"experiment_id"`
+	ModelID      interface{} `json:"model_id"`
+}
+
+type ListSessionsForCostRecomputeRow struct {
+	ID                string          `json:"id"`
+	StartedAt         sql.NullString  `json:"started_at"`
+	ModelID           sql.NullString  `json:"model_id"`
+	TokenInput        int64           `json:"token_input"`
+	TokenOutput       int64           `json:"token_output"`
+	TokenCacheRead    int64           `json:"token_cache_read"`
+	TokenCacheWrite   int64           `json:"token_cache_write"`
+	CostEstimateUsd   sql.NullFloat64 `json:"cost_estimate_usd"`
+	WebSearchRequests int64           `json:"web_search_requests"`
+	WebFetchRequests  int64           `json:"web_fetch_requests"`
+}
+
+func (q *Queries) ListSessionsForCostRecompute(ctx context.Context, arg ListSessionsForCostRecomputeParams) ([]ListSessionsForCostRecomputeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionsForCostRecompute,
+		arg.Since,
+		arg.Until,
+		arg.ProjectID,
+		arg.ExperimentID,
+		arg.ModelID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSessionsForCostRecomputeRow{}
+	for rows.Next() {
+		var i ListSessionsForCostRecomputeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartedAt,
+			&i.ModelID,
+			&i.TokenInput,
+			&i.TokenOutput,
+			&i.TokenCacheRead,
+			&i.TokenCacheWrite,
+			&i.CostEstimateUsd,
+			&i.WebSearchRequests,
+			&i.WebFetchRequests,
 		); err != nil {
 			return nil, err
 		}
@@ -804,3 +2412,46 @@ func (q *Queries) ListSessionToolsBySessionID(ctx context.Context, sessionID str
 	}
 	return items, nil
 }
+
+const updateSessionMetricsCost = `-- name: UpdateSessionMetricsCost :exec
+UPDATE session_metrics SET cost_estimate_usd = ? WHERE session_id = ?
+`
+
+type UpdateSessionMetricsCostParams struct {
+	CostEstimateUsd sql.NullFloat64 `json:"cost_estimate_usd"`
+	SessionID       string          `json:"session_id"`
+}
+
+func (q *Queries) UpdateSessionMetricsCost(ctx context.Context, arg UpdateSessionMetricsCostParams) error {
+	_, err := q.db.ExecContext(ctx, updateSessionMetricsCost, arg.CostEstimateUsd, arg.SessionID)
+	return err
+}
+
+const updateSessionSubagentCost = `-- name: UpdateSessionSubagentCost :exec
+UPDATE session_subagents SET cost_estimate_usd = ? WHERE id = ?
+`
+
+type UpdateSessionSubagentCostParams struct {
+	CostEstimateUsd sql.NullFloat64 `json:"cost_estimate_usd"`
+	ID              int64           `json:"id"`
+}
+
+func (q *Queries) UpdateSessionSubagentCost(ctx context.Context, arg UpdateSessionSubagentCostParams) error {
+	_, err := q.db.ExecContext(ctx, updateSessionSubagentCost, arg.CostEstimateUsd, arg.ID)
+	return err
+}
+
+const updateSessionTurnCost = `-- name: UpdateSessionTurnCost :exec
+UPDATE session_turns SET cost_estimate_usd = ? WHERE session_id = ? AND turn_index = ?
+`
+
+type UpdateSessionTurnCostParams struct {
+	CostEstimateUsd sql.NullFloat64 `json:"cost_estimate_usd"`
+	SessionID       string          `json:"session_id"`
+	TurnIndex       int64           `json:"turn_index"`
+}
+
+func (q *Queries) UpdateSessionTurnCost(ctx context.Context, arg UpdateSessionTurnCostParams) error {
+	_, err := q.db.ExecContext(ctx, updateSessionTurnCost, arg.CostEstimateUsd, arg.SessionID, arg.TurnIndex)
+	return err
+}
@@ -19,6 +19,29 @@ type Experiment struct {
 	CreatedAt   string         `json:"created_at"`
 }
 
+type LiveSession struct {
+	SessionID           string          `json:"session_id"`
+	TranscriptPath      string          `json:"transcript_path"`
+	Cwd                 string          `json:"cwd"`
+	LastEvent           string          `json:"last_event"`
+	ByteOffset          int64           `json:"byte_offset"`
+	ModelID             sql.NullString  `json:"model_id"`
+	TokenInput          int64           `json:"token_input"`
+	TokenOutput         int64           `json:"token_output"`
+	TokenCacheRead      int64           `json:"token_cache_read"`
+	TokenCacheWrite     int64           `json:"token_cache_write"`
+	CostEstimateUsd     sql.NullFloat64 `json:"cost_estimate_usd"`
+	StartedAt           sql.NullString  `json:"started_at"`
+	UpdatedAt           string          `json:"updated_at"`
+	TranscriptLineCount int64           `json:"transcript_line_count"`
+}
+
+type ModelAlias struct {
+	Alias     string `json:"alias"`
+	ModelID   string `json:"model_id"`
+	CreatedAt string `json:"created_at"`
+}
+
 type ModelPricing struct {
 	ID                          string          `json:"id"`
 	DisplayName                 string          `json:"display_name"`
@@ -26,11 +49,15 @@ type ModelPricing struct {
 	OutputPerMillion            float64         `json:"output_per_million"`
 	CacheReadPerMillion         sql.NullFloat64 `json:"cache_read_per_million"`
 	CacheWritePerMillion        sql.NullFloat64 `json:"cache_write_per_million"`
-	IsDefault                   int64           `json:"is_default"`
-	CreatedAt                   string          `json:"created_at"`
 	LongContextInputPerMillion  sql.NullFloat64 `json:"long_context_input_per_million"`
 	LongContextOutputPerMillion sql.NullFloat64 `json:"long_context_output_per_million"`
 	LongContextThreshold        sql.NullInt64   `json:"long_context_threshold"`
+	IsDefault                   int64           `json:"is_default"`
+	CreatedAt                   string          `json:"created_at"`
+	EffectiveFrom               string          `json:"effective_from"`
+	EffectiveTo                 sql.NullString  `json:"effective_to"`
+	WebSearchPerThousand        sql.NullFloat64 `json:"web_search_per_thousand"`
+	WebFetchPerThousand         sql.NullFloat64 `json:"web_fetch_per_thousand"`
 }
 
 type PlanConfig struct {
@@ -67,6 +94,10 @@ type Session struct {
 	EndedAt              sql.NullString `json:"ended_at"`
 	DurationSeconds      sql.NullInt64  `json:"duration_seconds"`
 	CreatedAt            string         `json:"created_at"`
+	GitBranch            sql.NullString `json:"git_branch"`
+	GitHeadBefore        sql.NullString `json:"git_head_before"`
+	GitHeadAfter         sql.NullString `json:"git_head_after"`
+	GitDirty             sql.NullInt64  `json:"git_dirty"`
 }
 
 type SessionCommand struct {
@@ -75,14 +106,43 @@ type SessionCommand struct {
 	Command    string         `json:"command"`
 	ExitCode   sql.NullInt64  `json:"exit_code"`
 	ExecutedAt sql.NullString `json:"executed_at"`
+	ToolUseID  sql.NullString `json:"tool_use_id"`
+	Status     sql.NullString `json:"status"`
+	DurationMs sql.NullInt64  `json:"duration_ms"`
+}
+
+type SessionCommit struct {
+	ID           int64  `json:"id"`
+	SessionID    string `json:"session_id"`
+	Sha          string `json:"sha"`
+	Subject      string `json:"subject"`
+	Author       string `json:"author"`
+	CommittedAt  string `json:"committed_at"`
+	FilesChanged int64  `json:"files_changed"`
+	Insertions   int64  `json:"insertions"`
+	Deletions    int64  `json:"deletions"`
 }
 
 type SessionFile struct {
+	ID             int64          `json:"id"`
+	SessionID      string         `json:"session_id"`
+	FilePath       string         `json:"file_path"`
+	Operation      string         `json:"operation"`
+	OperationCount int64          `json:"operation_count"`
+	LinesAdded     int64          `json:"lines_added"`
+	LinesRemoved   int64          `json:"lines_removed"`
+	Language       sql.NullString `json:"language"`
+}
+
+type SessionHook struct {
 	ID             int64  `json:"id"`
 	SessionID      string `json:"session_id"`
-	FilePath       string `json:"file_path"`
-	Operation      string `json:"operation"`
-	OperationCount int64  `json:"operation_count"`
+	Event          string `json:"event"`
+	HookName       string `json:"hook_name"`
+	Command        string `json:"command"`
+	ExecutionCount int64  `json:"execution_count"`
+	ErrorCount     int64  `json:"error_count"`
+	BlockedCount   int64  `json:"blocked_count"`
 }
 
 type SessionMetric struct {
@@ -97,6 +157,40 @@ type SessionMetric struct {
 	CostEstimateUsd       sql.NullFloat64 `json:"cost_estimate_usd"`
 	ErrorCount            int64           `json:"error_count"`
 	ModelID               sql.NullString  `json:"model_id"`
+	LinesAdded            int64           `json:"lines_added"`
+	LinesRemoved          int64           `json:"lines_removed"`
+	CompactionCount       int64           `json:"compaction_count"`
+	TokenCacheWasted      int64           `json:"token_cache_wasted"`
+	ThinkingBlockCount    int64           `json:"thinking_block_count"`
+	ThinkingTokens        int64           `json:"thinking_tokens"`
+	WebSearchRequests     int64           `json:"web_search_requests"`
+	WebFetchRequests      int64           `json:"web_fetch_requests"`
+	InterruptionCount     int64           `json:"interruption_count"`
+	ToolRejectionCount    int64           `json:"tool_rejection_count"`
+	PermissionDenialCount int64           `json:"permission_denial_count"`
+	TranscriptLineCount   int64           `json:"transcript_line_count"`
+	ParserVersion         int64           `json:"parser_version"`
+}
+
+type SessionModelUsage struct {
+	ID              int64           `json:"id"`
+	SessionID       string          `json:"session_id"`
+	ModelID         string          `json:"model_id"`
+	MessageCount    int64           `json:"message_count"`
+	TokenInput      int64           `json:"token_input"`
+	TokenOutput     int64           `json:"token_output"`
+	TokenCacheRead  int64           `json:"token_cache_read"`
+	TokenCacheWrite int64           `json:"token_cache_write"`
+	CostEstimateUsd sql.NullFloat64 `json:"cost_estimate_usd"`
+}
+
+type SessionParseAnomaly struct {
+	ID              int64  `json:"id"`
+	SessionID       string `json:"session_id"`
+	Kind            string `json:"kind"`
+	Detail          string `json:"detail"`
+	OccurrenceCount int64  `json:"occurrence_count"`
+	FirstLine       int64  `json:"first_line"`
 }
 
 type SessionQuality struct {
@@ -111,6 +205,14 @@ type SessionQuality struct {
 	CreatedAt         string         `json:"created_at"`
 }
 
+type SessionSlashCommand struct {
+	ID              int64  `json:"id"`
+	SessionID       string `json:"session_id"`
+	Command         string `json:"command"`
+	Kind            string `json:"kind"`
+	InvocationCount int64  `json:"invocation_count"`
+}
+
 type SessionSubagent struct {
 	ID              int64           `json:"id"`
 	SessionID       string          `json:"session_id"`
@@ -126,15 +228,78 @@ type SessionSubagent struct {
 	TotalDurationMs sql.NullInt64   `json:"total_duration_ms"`
 	ToolUseCount    int64           `json:"tool_use_count"`
 	CostEstimateUsd sql.NullFloat64 `json:"cost_estimate_usd"`
+	ToolUseID       sql.NullString  `json:"tool_use_id"`
+	AgentID         sql.NullString  `json:"agent_id"`
+	ParentToolUseID sql.NullString  `json:"parent_tool_use_id"`
+	ErrorCount      int64           `json:"error_count"`
+	ModelID         sql.NullString  `json:"model_id"`
+}
+
+type SessionSubagentFile struct {
+	ID                int64  `json:"id"`
+	SessionID         string `json:"session_id"`
+	SubagentToolUseID string `json:"subagent_tool_use_id"`
+	FilePath          string `json:"file_path"`
+	Operation         string `json:"operation"`
+	OperationCount    int64  `json:"operation_count"`
+	LinesAdded        int64  `json:"lines_added"`
+	LinesRemoved      int64  `json:"lines_removed"`
+}
+
+type SessionSubagentTool struct {
+	ID                int64         `json:"id"`
+	SessionID         string        `json:"session_id"`
+	SubagentToolUseID string        `json:"subagent_tool_use_id"`
+	ToolName          string        `json:"tool_name"`
+	InvocationCount   int64         `json:"invocation_count"`
+	ErrorCount        int64         `json:"error_count"`
+	TotalDurationMs   sql.NullInt64 `json:"total_duration_ms"`
 }
 
 type SessionTool struct {
-	ID              int64         `json:"id"`
-	SessionID       string        `json:"session_id"`
-	ToolName        string        `json:"tool_name"`
-	InvocationCount int64         `json:"invocation_count"`
-	TotalDurationMs sql.NullInt64 `json:"total_duration_ms"`
-	ErrorCount      int64         `json:"error_count"`
+	ID                    int64          `json:"id"`
+	SessionID             string         `json:"session_id"`
+	ToolName              string         `json:"tool_name"`
+	InvocationCount       int64          `json:"invocation_count"`
+	TotalDurationMs       sql.NullInt64  `json:"total_duration_ms"`
+	ErrorCount            int64          `json:"error_count"`
+	McpServer             sql.NullString `json:"mcp_server"`
+	McpTool               sql.NullString `json:"mcp_tool"`
+	ResultTokens          int64          `json:"result_tokens"`
+	RejectionCount        int64          `json:"rejection_count"`
+	PermissionDenialCount int64          `json:"permission_denial_count"`
+}
+
+type SessionTurn struct {
+	ID              int64           `json:"id"`
+	SessionID       string          `json:"session_id"`
+	TurnIndex       int64           `json:"turn_index"`
+	Prompt          string          `json:"prompt"`
+	StartedAt       sql.NullString  `json:"started_at"`
+	EndedAt         sql.NullString  `json:"ended_at"`
+	DurationMs      sql.NullInt64   `json:"duration_ms"`
+	MessageCount    int64           `json:"message_count"`
+	ToolCalls       int64           `json:"tool_calls"`
+	TokenInput      int64           `json:"token_input"`
+	TokenOutput     int64           `json:"token_output"`
+	TokenCacheRead  int64           `json:"token_cache_read"`
+	TokenCacheWrite int64           `json:"token_cache_write"`
+	CostEstimateUsd sql.NullFloat64 `json:"cost_estimate_usd"`
+}
+
+type ToolRule struct {
+	Tool      string `json:"tool"`
+	Operation string `json:"operation"`
+	Fields    string `json:"fields"`
+	CreatedAt string `json:"created_at"`
+}
+
+type TranscriptState struct {
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path"`
+	ByteOffset     int64  `json:"byte_offset"`
+	State          string `json:"state"`
+	UpdatedAt      string `json:"updated_at"`
 }
 
 type UsageLimit struct {
@@ -10,9 +10,23 @@ import (
 	"database/sql"
 )
 
+const closeModelPricing = `-- name: CloseModelPricing :exec
+UPDATE model_pricing SET effective_to = ? WHERE id = ? AND effective_to IS NULL
+`
+
+type CloseModelPricingParams struct {
+	EffectiveTo sql.NullString `json:"effective_to"`
+	ID          string         `json:"id"`
+}
+
+func (q *Queries) CloseModelPricing(ctx context.Context, arg CloseModelPricingParams) error {
+	_, err := q.db.ExecContext(ctx, closeModelPricing, arg.EffectiveTo, arg.ID)
+	return err
+}
+
 const createModelPricing = `-- name: CreateModelPricing :exec
-INSERT INTO model_pricing (id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, is_default, created_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO model_pricing (id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, web_search_per_thousand, web_fetch_per_thousand, is_default, created_at, effective_from, effective_to)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type CreateModelPricingParams struct {
@@ -25,8 +39,12 @@ type CreateModelPricingParams struct {
 	LongContextInputPerMillion  sql.NullFloat64 `json:"long_context_input_per_million"`
 	LongContextOutputPerMillion sql.NullFloat64 `json:"long_context_output_per_million"`
 	LongContextThreshold        sql.NullInt64   `json:"long_context_threshold"`
+	WebSearchPerThousand        sql.NullFloat64 `json:"web_search_per_thousand"`
+	WebFetchPerThousand         sql.NullFloat64 `json:"web_fetch_per_thousand"`
 	IsDefault                   int64           `json:"is_default"`
 	CreatedAt                   string          `json:"created_at"`
+	EffectiveFrom               string          `json:"effective_from"`
+	EffectiveTo                 sql.NullString  `json:"effective_to"`
 }
 
 func (q *Queries) CreateModelPricing(ctx context.Context, arg CreateModelPricingParams) error {
@@ -40,12 +58,25 @@ func (q *Queries) CreateModelPricing(ctx context.Context, arg CreateModelPricing
 		arg.LongContextInputPerMillion,
 		arg.LongContextOutputPerMillion,
 		arg.LongContextThreshold,
+		arg.WebSearchPerThousand,
+		arg.WebFetchPerThousand,
 		arg.IsDefault,
 		arg.CreatedAt,
+		arg.EffectiveFrom,
+		arg.EffectiveTo,
 	)
 	return err
 }
 
+const deleteModelAlias = `-- name: DeleteModelAlias :exec
+DELETE FROM model_aliases WHERE alias = ?
+`
+
+func (q *Queries) DeleteModelAlias(ctx context.Context, alias string) error {
+	_, err := q.db.ExecContext(ctx, deleteModelAlias, alias)
+	return err
+}
+
 const deleteModelPricing = `-- name: DeleteModelPricing :exec
 DELETE FROM model_pricing WHERE id = ?
 `
@@ -56,7 +87,7 @@ func (q *Queries) DeleteModelPricing(ctx context.Context, id string) error {
 }
 
 const getDefaultModelPricing = `-- name: GetDefaultModelPricing :one
-SELECT id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, is_default, created_at, long_context_input_per_million, long_context_output_per_million, long_context_threshold FROM model_pricing WHERE is_default = 1 LIMIT 1
+SELECT id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, is_default, created_at, effective_from, effective_to, web_search_per_thousand, web_fetch_per_thousand FROM model_pricing WHERE is_default = 1 AND effective_to IS NULL LIMIT 1
 `
 
 func (q *Queries) GetDefaultModelPricing(ctx context.Context) (ModelPricing, error) {
@@ -69,17 +100,69 @@ func (q *Queries) GetDefaultModelPricing(ctx context.Context) (ModelPricing, err
 		&i.OutputPerMillion,
 		&i.CacheReadPerMillion,
 		&i.CacheWritePerMillion,
+		&i.LongContextInputPerMillion,
+		&i.LongContextOutputPerMillion,
+		&i.LongContextThreshold,
 		&i.IsDefault,
 		&i.CreatedAt,
+		&i.EffectiveFrom,
+		&i.EffectiveTo,
+		&i.WebSearchPerThousand,
+		&i.WebFetchPerThousand,
+	)
+	return i, err
+}
+
+const getModelAlias = `-- name: GetModelAlias :one
+SELECT alias, model_id, created_at FROM model_aliases WHERE alias = ?
+`
+
+func (q *Queries) GetModelAlias(ctx context.Context, alias string) (ModelAlias, error) {
+	row := q.db.QueryRowContext(ctx, getModelAlias, alias)
+	var i ModelAlias
+	err := row.Scan(&i.Alias, &i.ModelID, &i.CreatedAt)
+	return i, err
+}
+
+const getModelPricingAt = `-- name: GetModelPricingAt :one
+SELECT id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, is_default, created_at, effective_from, effective_to, web_search_per_thousand, web_fetch_per_thousand FROM model_pricing
+WHERE id = ?1
+  AND effective_from <= ?2
+  AND (effective_to IS NULL OR effective_to > ?2)
+ORDER BY effective_from DESC
+LIMIT 1
+`
+
+type GetModelPricingAtParams struct {
+	ID string `json:"id"`
+	At string `json:"at"`
+}
+
+func (q *Queries) GetModelPricingAt(ctx context.Context, arg GetModelPricingAtParams) (ModelPricing, error) {
+	row := q.db.QueryRowContext(ctx, getModelPricingAt, arg.ID, arg.At)
+	var i ModelPricing
+	err := row.Scan(
+		&i.ID,
+		&i.DisplayName,
+		&i.InputPerMillion,
+		&i.OutputPerMillion,
+		&i.CacheReadPerMillion,
+		&i.CacheWritePerMillion,
 		&i.LongContextInputPerMillion,
 		&i.LongContextOutputPerMillion,
 		&i.LongContextThreshold,
+		&i.IsDefault,
+		&i.CreatedAt,
+		&i.EffectiveFrom,
+		&i.EffectiveTo,
+		&i.WebSearchPerThousand,
+		&i.WebFetchPerThousand,
 	)
 	return i, err
 }
 
 const getModelPricingByID = `-- name: GetModelPricingByID :one
-SELECT id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, is_default, created_at, long_context_input_per_million, long_context_output_per_million, long_context_threshold FROM model_pricing WHERE id = ?
+SELECT id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, is_default, created_at, effective_from, effective_to, web_search_per_thousand, web_fetch_per_thousand FROM model_pricing WHERE id = ? AND effective_to IS NULL
 `
 
 func (q *Queries) GetModelPricingByID(ctx context.Context, id string) (ModelPricing, error) {
@@ -92,17 +175,48 @@ func (q *Queries) GetModelPricingByID(ctx context.Context, id string) (ModelPric
 		&i.OutputPerMillion,
 		&i.CacheReadPerMillion,
 		&i.CacheWritePerMillion,
-		&i.IsDefault,
-		&i.CreatedAt,
 		&i.LongContextInputPerMillion,
 		&i.LongContextOutputPerMillion,
 		&i.LongContextThreshold,
+		&i.IsDefault,
+		&i.CreatedAt,
+		&i.EffectiveFrom,
+		&i.EffectiveTo,
+		&i.WebSearchPerThousand,
+		&i.WebFetchPerThousand,
 	)
 	return i, err
 }
 
+const listModelAliases = `-- name: ListModelAliases :many
+SELECT alias, model_id, created_at FROM model_aliases ORDER BY alias ASC
+`
+
+func (q *Queries) ListModelAliases(ctx context.Context) ([]ModelAlias, error) {
+	rows, err := q.db.QueryContext(ctx, listModelAliases)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ModelAlias{}
+	for rows.Next() {
+		var i ModelAlias
+		if err := rows.Scan(&i.Alias, &i.ModelID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listModelPricing = `-- name: ListModelPricing :many
-SELECT id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, is_default, created_at, long_context_input_per_million, long_context_output_per_million, long_context_threshold FROM model_pricing ORDER BY display_name ASC
+SELECT id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, is_default, created_at, effective_from, effective_to, web_search_per_thousand, web_fetch_per_thousand FROM model_pricing WHERE effective_to IS NULL ORDER BY display_name ASC
 `
 
 func (q *Queries) ListModelPricing(ctx context.Context) ([]ModelPricing, error) {
@@ -121,11 +235,58 @@ func (q *Queries) ListModelPricing(ctx context.Context) ([]ModelPricing, error)
 			&i.OutputPerMillion,
 			&i.CacheReadPerMillion,
 			&i.CacheWritePerMillion,
+			&i.LongContextInputPerMillion,
+			&i.LongContextOutputPerMillion,
+			&i.LongContextThreshold,
 			&i.IsDefault,
 			&i.CreatedAt,
+			&i.EffectiveFrom,
+			&i.EffectiveTo,
+			&i.WebSearchPerThousand,
+			&i.WebFetchPerThousand,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listModelPricingHistory = `-- name: ListModelPricingHistory :many
+SELECT id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, is_default, created_at, effective_from, effective_to, web_search_per_thousand, web_fetch_per_thousand FROM model_pricing ORDER BY id ASC, effective_from DESC
+`
+
+func (q *Queries) ListModelPricingHistory(ctx context.Context) ([]ModelPricing, error) {
+	rows, err := q.db.QueryContext(ctx, listModelPricingHistory)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ModelPricing{}
+	for rows.Next() {
+		var i ModelPricing
+		if err := rows.Scan(
+			&i.ID,
+			&i.DisplayName,
+			&i.InputPerMillion,
+			&i.OutputPerMillion,
+			&i.CacheReadPerMillion,
+			&i.CacheWritePerMillion,
 			&i.LongContextInputPerMillion,
 			&i.LongContextOutputPerMillion,
 			&i.LongContextThreshold,
+			&i.IsDefault,
+			&i.CreatedAt,
+			&i.EffectiveFrom,
+			&i.EffectiveTo,
+			&i.WebSearchPerThousand,
+			&i.WebFetchPerThousand,
 		); err != nil {
 			return nil, err
 		}
@@ -141,7 +302,7 @@ func (q *Queries) ListModelPricing(ctx context.Context) ([]ModelPricing, error)
 }
 
 const setDefaultModelPricing = `-- name: SetDefaultModelPricing :exec
-UPDATE model_pricing SET is_default = CASE WHEN id = ? THEN 1 ELSE 0 END
+UPDATE model_pricing SET is_default = CASE WHEN id = ? THEN 1 ELSE 0 END WHERE effective_to IS NULL
 `
 
 func (q *Queries) SetDefaultModelPricing(ctx context.Context, id string) error {
@@ -151,8 +312,8 @@ func (q *Queries) SetDefaultModelPricing(ctx context.Context, id string) error {
 
 const updateModelPricing = `-- name: UpdateModelPricing :exec
 UPDATE model_pricing
-SET display_name = ?, input_per_million = ?, output_per_million = ?, cache_read_per_million = ?, cache_write_per_million = ?, long_context_input_per_million = ?, long_context_output_per_million = ?, long_context_threshold = ?, is_default = ?
-WHERE id = ?
+SET display_name = ?, input_per_million = ?, output_per_million = ?, cache_read_per_million = ?, cache_write_per_million = ?, long_context_input_per_million = ?, long_context_output_per_million = ?, long_context_threshold = ?, web_search_per_thousand = ?, web_fetch_per_thousand = ?, is_default = ?
+WHERE id = ? AND effective_to IS NULL
 `
 
 type UpdateModelPricingParams struct {
@@ -164,6 +325,8 @@ type UpdateModelPricingParams struct {
 	LongContextInputPerMillion  sql.NullFloat64 `json:"long_context_input_per_million"`
 	LongContextOutputPerMillion sql.NullFloat64 `json:"long_context_output_per_million"`
 	LongContextThreshold        sql.NullInt64   `json:"long_context_threshold"`
+	WebSearchPerThousand        sql.NullFloat64 `json:"web_search_per_thousand"`
+	WebFetchPerThousand         sql.NullFloat64 `json:"web_fetch_per_thousand"`
 	IsDefault                   int64           `json:"is_default"`
 	ID                          string          `json:"id"`
 }
@@ -178,8 +341,27 @@ func (q *Queries) UpdateModelPricing(ctx context.Context, arg UpdateModelPricing
 		arg.LongContextInputPerMillion,
 		arg.LongContextOutputPerMillion,
 		arg.LongContextThreshold,
+		arg.WebSearchPerThousand,
+		arg.WebFetchPerThousand,
 		arg.IsDefault,
 		arg.ID,
 	)
 	return err
 }
+
+const upsertModelAlias = `-- name: UpsertModelAlias :exec
+INSERT INTO model_aliases (alias, model_id, created_at)
+VALUES (?, ?, ?)
+ON CONFLICT(alias) DO UPDATE SET model_id = excluded.model_id
+`
+
+type UpsertModelAliasParams struct {
+	Alias     string `json:"alias"`
+	ModelID   string `json:"model_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (q *Queries) UpsertModelAlias(ctx context.Context, arg UpsertModelAliasParams) error {
+	_, err := q.db.ExecContext(ctx, upsertModelAlias, arg.Alias, arg.ModelID, arg.CreatedAt)
+	return err
+}
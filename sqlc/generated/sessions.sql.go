@@ -11,8 +11,24 @@ import (
 )
 
 const createSession = `-- name: CreateSession :exec
-INSERT OR REPLACE INTO sessions (id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO sessions (id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at, git_branch, git_head_before, git_head_after, git_dirty)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    project_id = excluded.project_id,
+    experiment_id = excluded.experiment_id,
+    transcript_path = excluded.transcript_path,
+    transcript_stored_path = excluded.transcript_stored_path,
+    cwd = excluded.cwd,
+    permission_mode = excluded.permission_mode,
+    exit_reason = excluded.exit_reason,
+    started_at = excluded.started_at,
+    ended_at = excluded.ended_at,
+    duration_seconds = excluded.duration_seconds,
+    created_at = excluded.created_at,
+    git_branch = excluded.git_branch,
+    git_head_before = excluded.git_head_before,
+    git_head_after = excluded.git_head_after,
+    git_dirty = excluded.git_dirty
 `
 
 type CreateSessionParams struct {
@@ -28,6 +44,10 @@ type CreateSessionParams struct {
 	EndedAt              sql.NullString `json:"ended_at"`
 	DurationSeconds      sql.NullInt64  `json:"duration_seconds"`
 	CreatedAt            string         `json:"created_at"`
+	GitBranch            sql.NullString `json:"git_branch"`
+	GitHeadBefore        sql.NullString `json:"git_head_before"`
+	GitHeadAfter         sql.NullString `json:"git_head_after"`
+	GitDirty             sql.NullInt64  `json:"git_dirty"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) error {
@@ -44,6 +64,10 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) er
 		arg.EndedAt,
 		arg.DurationSeconds,
 		arg.CreatedAt,
+		arg.GitBranch,
+		arg.GitHeadBefore,
+		arg.GitHeadAfter,
+		arg.GitDirty,
 	)
 	return err
 }
@@ -94,7 +118,7 @@ func (q *Queries) DeleteSessionsByProject(ctx context.Context, projectID string)
 }
 
 const getSessionByID = `-- name: GetSessionByID :one
-SELECT id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at FROM sessions WHERE id = ?
+SELECT id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at, git_branch, git_head_before, git_head_after, git_dirty FROM sessions WHERE id = ?
 `
 
 func (q *Queries) GetSessionByID(ctx context.Context, id string) (Session, error) {
@@ -113,6 +137,10 @@ func (q *Queries) GetSessionByID(ctx context.Context, id string) (Session, error
 		&i.EndedAt,
 		&i.DurationSeconds,
 		&i.CreatedAt,
+		&i.GitBranch,
+		&i.GitHeadBefore,
+		&i.GitHeadAfter,
+		&i.GitDirty,
 	)
 	return i, err
 }
@@ -245,8 +273,37 @@ func (q *Queries) GetSessionTranscriptPathsByProject(ctx context.Context, projec
 	return items, nil
 }
 
+const listGitBranches = `-- name: ListGitBranches :many
+SELECT DISTINCT git_branch FROM sessions
+WHERE git_branch IS NOT NULL
+ORDER BY git_branch
+`
+
+func (q *Queries) ListGitBranches(ctx context.Context) ([]sql.NullString, error) {
+	rows, err := q.db.QueryContext(ctx, listGitBranches)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []sql.NullString{}
+	for rows.Next() {
+		var git_branch sql.NullString
+		if err := rows.Scan(&git_branch); err != nil {
+			return nil, err
+		}
+		items = append(items, git_branch)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSessions = `-- name: ListSessions :many
-SELECT id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at FROM sessions
+SELECT id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at, git_branch, git_head_before, git_head_after, git_dirty FROM sessions
 ORDER BY created_at DESC
 LIMIT ?
 `
@@ -273,6 +330,62 @@ func (q *Queries) ListSessions(ctx context.Context, limit int64) ([]Session, err
 			&i.EndedAt,
 			&i.DurationSeconds,
 			&i.CreatedAt,
+			&i.GitBranch,
+			&i.GitHeadBefore,
+			&i.GitHeadAfter,
+			&i.GitDirty,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionsByBranch = `-- name: ListSessionsByBranch :many
+SELECT id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at, git_branch, git_head_before, git_head_after, git_dirty FROM sessions
+WHERE git_branch = ?
+ORDER BY created_at DESC
+LIMIT ?
+`
+
+type ListSessionsByBranchParams struct {
+	GitBranch sql.NullString `json:"git_branch"`
+	Limit     int64          `json:"limit"`
+}
+
+func (q *Queries) ListSessionsByBranch(ctx context.Context, arg ListSessionsByBranchParams) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, listSessionsByBranch, arg.GitBranch, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ExperimentID,
+			&i.TranscriptPath,
+			&i.TranscriptStoredPath,
+			&i.Cwd,
+			&i.PermissionMode,
+			&i.ExitReason,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.DurationSeconds,
+			&i.CreatedAt,
+			&i.GitBranch,
+			&i.GitHeadBefore,
+			&i.GitHeadAfter,
+			&i.GitDirty,
 		); err != nil {
 			return nil, err
 		}
@@ -288,7 +401,7 @@ func (q *Queries) ListSessions(ctx context.Context, limit int64) ([]Session, err
 }
 
 const listSessionsByExperiment = `-- name: ListSessionsByExperiment :many
-SELECT id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at FROM sessions
+SELECT id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at, git_branch, git_head_before, git_head_after, git_dirty FROM sessions
 WHERE experiment_id = ?
 ORDER BY created_at DESC
 LIMIT ?
@@ -321,6 +434,10 @@ func (q *Queries) ListSessionsByExperiment(ctx context.Context, arg ListSessions
 			&i.EndedAt,
 			&i.DurationSeconds,
 			&i.CreatedAt,
+			&i.GitBranch,
+			&i.GitHeadBefore,
+			&i.GitHeadAfter,
+			&i.GitDirty,
 		); err != nil {
 			return nil, err
 		}
@@ -336,7 +453,7 @@ func (q *Queries) ListSessionsByExperiment(ctx context.Context, arg ListSessions
 }
 
 const listSessionsByProject = `-- name: ListSessionsByProject :many
-SELECT id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at FROM sessions
+SELECT id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at, git_branch, git_head_before, git_head_after, git_dirty FROM sessions
 WHERE project_id = ?
 ORDER BY created_at DESC
 LIMIT ?
@@ -369,6 +486,10 @@ func (q *Queries) ListSessionsByProject(ctx context.Context, arg ListSessionsByP
 			&i.EndedAt,
 			&i.DurationSeconds,
 			&i.CreatedAt,
+			&i.GitBranch,
+			&i.GitHeadBefore,
+			&i.GitHeadAfter,
+			&i.GitDirty,
 		); err != nil {
 			return nil, err
 		}
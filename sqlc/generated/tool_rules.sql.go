@@ -0,0 +1,92 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: tool_rules.sql
+
+package sqlc
+
+import (
+	"context"
+)
+
+const deleteToolRule = `-- name: DeleteToolRule :exec
+DELETE FROM tool_rules WHERE tool = ?
+`
+
+func (q *Queries) DeleteToolRule(ctx context.Context, tool string) error {
+	_, err := q.db.ExecContext(ctx, deleteToolRule, tool)
+	return err
+}
+
+const getToolRule = `-- name: GetToolRule :one
+SELECT tool, operation, fields, created_at FROM tool_rules WHERE tool = ?
+`
+
+func (q *Queries) GetToolRule(ctx context.Context, tool string) (ToolRule, error) {
+	row := q.db.QueryRowContext(ctx, getToolRule, tool)
+	var i ToolRule
+	err := row.Scan(
+		&i.Tool,
+		&i.Operation,
+		&i.Fields,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listToolRules = `-- name: ListToolRules :many
+SELECT tool, operation, fields, created_at FROM tool_rules ORDER BY tool ASC
+`
+
+func (q *Queries) ListToolRules(ctx context.Context) ([]ToolRule, error) {
+	rows, err := q.db.QueryContext(ctx, listToolRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ToolRule{}
+	for rows.Next() {
+		var i ToolRule
+		if err := rows.Scan(
+			&i.Tool,
+			&i.Operation,
+			&i.Fields,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertToolRule = `-- name: UpsertToolRule :exec
+INSERT INTO tool_rules (tool, operation, fields, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(tool) DO UPDATE SET
+    operation = excluded.operation,
+    fields = excluded.fields
+`
+
+type UpsertToolRuleParams struct {
+	Tool      string `json:"tool"`
+	Operation string `json:"operation"`
+	Fields    string `json:"fields"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (q *Queries) UpsertToolRule(ctx context.Context, arg UpsertToolRuleParams) error {
+	_, err := q.db.ExecContext(ctx, upsertToolRule,
+		arg.Tool,
+		arg.Operation,
+		arg.Fields,
+		arg.CreatedAt,
+	)
+	return err
+}
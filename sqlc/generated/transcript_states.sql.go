@@ -0,0 +1,88 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: transcript_states.sql
+
+package sqlc
+
+import (
+	"context"
+)
+
+const deleteOrphanedTranscriptStates = `-- name: DeleteOrphanedTranscriptStates :exec
+DELETE FROM transcript_states
+WHERE session_id NOT IN (SELECT id FROM sessions)
+  AND session_id NOT IN (SELECT session_id FROM live_sessions)
+`
+
+func (q *Queries) DeleteOrphanedTranscriptStates(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteOrphanedTranscriptStates)
+	return err
+}
+
+const deleteStaleTranscriptStates = `-- name: DeleteStaleTranscriptStates :exec
+DELETE FROM transcript_states
+WHERE datetime(updated_at) < datetime(?1)
+  AND session_id NOT IN (SELECT id FROM sessions)
+  AND session_id NOT IN (SELECT session_id FROM live_sessions)
+`
+
+func (q *Queries) DeleteStaleTranscriptStates(ctx context.Context, before interface{}) error {
+	_, err := q.db.ExecContext(ctx, deleteStaleTranscriptStates, before)
+	return err
+}
+
+const deleteTranscriptState = `-- name: DeleteTranscriptState :exec
+DELETE FROM transcript_states WHERE session_id = ?
+`
+
+func (q *Queries) DeleteTranscriptState(ctx context.Context, sessionID string) error {
+	_, err := q.db.ExecContext(ctx, deleteTranscriptState, sessionID)
+	return err
+}
+
+const getTranscriptState = `-- name: GetTranscriptState :one
+SELECT session_id, transcript_path, byte_offset, state, updated_at FROM transcript_states WHERE session_id = ?
+`
+
+func (q *Queries) GetTranscriptState(ctx context.Context, sessionID string) (TranscriptState, error) {
+	row := q.db.QueryRowContext(ctx, getTranscriptState, sessionID)
+	var i TranscriptState
+	err := row.Scan(
+		&i.SessionID,
+		&i.TranscriptPath,
+		&i.ByteOffset,
+		&i.State,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const saveTranscriptState = `-- name: SaveTranscriptState :exec
+INSERT INTO transcript_states (session_id, transcript_path, byte_offset, state, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (session_id) DO UPDATE SET
+    transcript_path = excluded.transcript_path,
+    byte_offset = excluded.byte_offset,
+    state = excluded.state,
+    updated_at = excluded.updated_at
+`
+
+type SaveTranscriptStateParams struct {
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path"`
+	ByteOffset     int64  `json:"byte_offset"`
+	State          string `json:"state"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+func (q *Queries) SaveTranscriptState(ctx context.Context, arg SaveTranscriptStateParams) error {
+	_, err := q.db.ExecContext(ctx, saveTranscriptState,
+		arg.SessionID,
+		arg.TranscriptPath,
+		arg.ByteOffset,
+		arg.State,
+		arg.UpdatedAt,
+	)
+	return err
+}
@@ -35,43 +35,79 @@ func (q *Queries) GetPlanConfig(ctx context.Context) (PlanConfig, error) {
 
 const getRollingWindowUsage = `-- name: GetRollingWindowUsage :one
 SELECT
-    CAST(COALESCE(SUM(m.token_input + m.token_output + m.token_cache_read + m.token_cache_write), 0) AS REAL) as total_tokens,
-    CAST(COALESCE(SUM(m.cost_estimate_usd), 0) AS REAL) as total_cost
-FROM sessions s
-JOIN session_metrics m ON s.id = m.session_id
-WHERE datetime(s.started_at) >= datetime('now', ? || ' hours')
+    CAST(COALESCE(SUM(u.tokens), 0) AS REAL) as total_tokens,
+    CAST(COALESCE(SUM(u.cost), 0) AS REAL) as total_cost,
+    CAST(COALESCE(SUM(CASE WHEN u.live = 1 THEN u.tokens ELSE 0 END), 0) AS REAL) as live_tokens,
+    CAST(COALESCE(SUM(u.live), 0) AS INTEGER) as live_sessions
+FROM (
+    SELECT m.token_input + m.token_output + m.token_cache_read + m.token_cache_write as tokens,
+        m.cost_estimate_usd as cost, s.started_at, 0 as live
+    FROM sessions s
+    JOIN session_metrics m ON s.id = m.session_id
+    WHERE s.id NOT IN (SELECT session_id FROM live_sessions)
+    UNION ALL
+    SELECT l.token_input + l.token_output + l.token_cache_read + l.token_cache_write as tokens,
+        l.cost_estimate_usd as cost, l.started_at, 1 as live
+    FROM live_sessions l
+) u
+WHERE datetime(u.started_at) >= datetime('now', ? || ' hours')
 `
 
 type GetRollingWindowUsageRow struct {
-	TotalTokens float64 `json:"total_tokens"`
-	TotalCost   float64 `json:"total_cost"`
+	TotalTokens  float64 `json:"total_tokens"`
+	TotalCost    float64 `json:"total_cost"`
+	LiveTokens   float64 `json:"live_tokens"`
+	LiveSessions int64   `json:"live_sessions"`
 }
 
 func (q *Queries) GetRollingWindowUsage(ctx context.Context, dollar_1 sql.NullString) (GetRollingWindowUsageRow, error) {
 	row := q.db.QueryRowContext(ctx, getRollingWindowUsage, dollar_1)
 	var i GetRollingWindowUsageRow
-	err := row.Scan(&i.TotalTokens, &i.TotalCost)
+	err := row.Scan(
+		&i.TotalTokens,
+		&i.TotalCost,
+		&i.LiveTokens,
+		&i.LiveSessions,
+	)
 	return i, err
 }
 
 const getWeeklyWindowUsage = `-- name: GetWeeklyWindowUsage :one
 SELECT
-    CAST(COALESCE(SUM(m.token_input + m.token_output + m.token_cache_read + m.token_cache_write), 0) AS REAL) as total_tokens,
-    CAST(COALESCE(SUM(m.cost_estimate_usd), 0) AS REAL) as total_cost
-FROM sessions s
-JOIN session_metrics m ON s.id = m.session_id
-WHERE datetime(s.started_at) >= datetime('now', '-168 hours')
+    CAST(COALESCE(SUM(u.tokens), 0) AS REAL) as total_tokens,
+    CAST(COALESCE(SUM(u.cost), 0) AS REAL) as total_cost,
+    CAST(COALESCE(SUM(CASE WHEN u.live = 1 THEN u.tokens ELSE 0 END), 0) AS REAL) as live_tokens,
+    CAST(COALESCE(SUM(u.live), 0) AS INTEGER) as live_sessions
+FROM (
+    SELECT m.token_input + m.token_output + m.token_cache_read + m.token_cache_write as tokens,
+        m.cost_estimate_usd as cost, s.started_at, 0 as live
+    FROM sessions s
+    JOIN session_metrics m ON s.id = m.session_id
+    WHERE s.id NOT IN (SELECT session_id FROM live_sessions)
+    UNION ALL
+    SELECT l.token_input + l.token_output + l.token_cache_read + l.token_cache_write as tokens,
+        l.cost_estimate_usd as cost, l.started_at, 1 as live
+    FROM live_sessions l
+) u
+WHERE datetime(u.started_at) >= datetime('now', '-168 hours')
 `
 
 type GetWeeklyWindowUsageRow struct {
-	TotalTokens float64 `json:"total_tokens"`
-	TotalCost   float64 `json:"total_cost"`
+	TotalTokens  float64 `json:"total_tokens"`
+	TotalCost    float64 `json:"total_cost"`
+	LiveTokens   float64 `json:"live_tokens"`
+	LiveSessions int64   `json:"live_sessions"`
 }
 
 func (q *Queries) GetWeeklyWindowUsage(ctx context.Context) (GetWeeklyWindowUsageRow, error) {
 	row := q.db.QueryRowContext(ctx, getWeeklyWindowUsage)
 	var i GetWeeklyWindowUsageRow
-	err := row.Scan(&i.TotalTokens, &i.TotalCost)
+	err := row.Scan(
+		&i.TotalTokens,
+		&i.TotalCost,
+		&i.LiveTokens,
+		&i.LiveSessions,
+	)
 	return i, err
 }
 
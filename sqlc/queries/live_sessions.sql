@@ -0,0 +1,36 @@
+-- name: GetLiveSession :one
+SELECT * FROM live_sessions WHERE session_id = ?;
+
+-- name: ListLiveSessions :many
+SELECT * FROM live_sessions ORDER BY started_at ASC;
+
+-- A recorded session only gets live usage again once its transcript has
+-- grown past the recording, i.e. when it's resumed.
+-- name: CreateLiveSession :execrows
+INSERT INTO live_sessions (session_id, transcript_path, cwd, last_event, byte_offset, model_id, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd, started_at, updated_at, transcript_line_count)
+SELECT ?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13, ?14
+WHERE NOT EXISTS (SELECT 1 FROM session_metrics WHERE session_id = ?1 AND transcript_line_count >= ?14)
+ON CONFLICT (session_id) DO NOTHING;
+
+-- name: UpdateLiveSession :execrows
+UPDATE live_sessions SET
+    transcript_path = sqlc.arg(transcript_path),
+    cwd = sqlc.arg(cwd),
+    last_event = sqlc.arg(last_event),
+    byte_offset = sqlc.arg(byte_offset),
+    model_id = sqlc.narg(model_id),
+    token_input = sqlc.arg(token_input),
+    token_output = sqlc.arg(token_output),
+    token_cache_read = sqlc.arg(token_cache_read),
+    token_cache_write = sqlc.arg(token_cache_write),
+    cost_estimate_usd = sqlc.narg(cost_estimate_usd),
+    started_at = sqlc.narg(started_at),
+    updated_at = sqlc.arg(updated_at),
+    transcript_line_count = sqlc.arg(transcript_line_count)
+WHERE session_id = sqlc.arg(session_id) AND byte_offset = sqlc.arg(prev_byte_offset);
+
+-- name: DeleteLiveSession :exec
+DELETE FROM live_sessions WHERE session_id = ?;
+
+-- name: DeleteStaleLiveSessions :execrows
+DELETE FROM live_sessions WHERE datetime(updated_at) < datetime(sqlc.arg(before));
@@ -1,36 +1,65 @@
 -- name: CreateSessionMetrics :exec
-INSERT OR REPLACE INTO session_metrics (session_id, model_id, message_count_user, message_count_assistant, turn_count, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd, error_count)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+INSERT OR REPLACE INTO session_metrics (session_id, model_id, message_count_user, message_count_assistant, turn_count, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd, error_count, lines_added, lines_removed, compaction_count, token_cache_wasted, thinking_block_count, thinking_tokens, web_search_requests, web_fetch_requests, interruption_count, tool_rejection_count, permission_denial_count, transcript_line_count, parser_version)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetSessionMetricsBySessionID :one
 SELECT * FROM session_metrics WHERE session_id = ?;
 
+-- name: UpdateSessionMetricsCost :exec
+UPDATE session_metrics SET cost_estimate_usd = ? WHERE session_id = ?;
+
 -- name: CreateSessionTool :exec
-INSERT INTO session_tools (session_id, tool_name, invocation_count, total_duration_ms, error_count)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO session_tools (session_id, tool_name, invocation_count, total_duration_ms, error_count, mcp_server, mcp_tool, result_tokens, rejection_count, permission_denial_count)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT (session_id, tool_name) DO UPDATE SET
     invocation_count = invocation_count + excluded.invocation_count,
-    error_count = error_count + excluded.error_count;
+    total_duration_ms = COALESCE(total_duration_ms + excluded.total_duration_ms, total_duration_ms, excluded.total_duration_ms),
+    error_count = error_count + excluded.error_count,
+    result_tokens = result_tokens + excluded.result_tokens,
+    rejection_count = rejection_count + excluded.rejection_count,
+    permission_denial_count = permission_denial_count + excluded.permission_denial_count;
 
 -- name: ListSessionToolsBySessionID :many
 SELECT * FROM session_tools WHERE session_id = ? ORDER BY invocation_count DESC;
 
+-- name: DeleteSessionTools :exec
+DELETE FROM session_tools WHERE session_id = ?;
+
 -- name: CreateSessionFile :exec
-INSERT INTO session_files (session_id, file_path, operation, operation_count)
-VALUES (?, ?, ?, ?)
+INSERT INTO session_files (session_id, file_path, operation, operation_count, lines_added, lines_removed, language)
+VALUES (?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT (session_id, file_path, operation) DO UPDATE SET
-    operation_count = operation_count + excluded.operation_count;
+    operation_count = operation_count + excluded.operation_count,
+    lines_added = lines_added + excluded.lines_added,
+    lines_removed = lines_removed + excluded.lines_removed;
 
 -- name: ListSessionFilesBySessionID :many
 SELECT * FROM session_files WHERE session_id = ? ORDER BY operation_count DESC;
 
+-- name: DeleteSessionFiles :exec
+DELETE FROM session_files WHERE session_id = ?;
+
+-- name: CreateSessionCommit :exec
+INSERT INTO session_commits (session_id, sha, subject, author, committed_at, files_changed, insertions, deletions)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (session_id, sha) DO NOTHING;
+
+-- name: ListSessionCommitsBySessionID :many
+SELECT * FROM session_commits WHERE session_id = ? ORDER BY committed_at ASC;
+
+-- name: DeleteSessionCommits :exec
+DELETE FROM session_commits WHERE session_id = ?;
+
 -- name: CreateSessionCommand :exec
-INSERT INTO session_commands (session_id, command, exit_code, executed_at)
-VALUES (?, ?, ?, ?);
+INSERT INTO session_commands (session_id, command, exit_code, executed_at, tool_use_id, status, duration_ms)
+VALUES (?, ?, ?, ?, ?, ?, ?);
 
 -- name: ListSessionCommandsBySessionID :many
 SELECT * FROM session_commands WHERE session_id = ? ORDER BY id ASC;
 
+-- name: DeleteSessionCommands :exec
+DELETE FROM session_commands WHERE session_id = ?;
+
 -- name: GetAggregateStats :one
 SELECT
     COUNT(DISTINCT s.id) as session_count,
@@ -42,7 +71,11 @@ SELECT
     COALESCE(SUM(m.token_cache_read), 0) as total_token_cache_read,
     COALESCE(SUM(m.token_cache_write), 0) as total_token_cache_write,
     COALESCE(SUM(m.cost_estimate_usd), 0) as total_cost_usd,
-    COALESCE(SUM(m.error_count), 0) as total_errors
+    COALESCE(SUM(m.error_count), 0) as total_errors,
+    COALESCE(SUM(m.lines_added), 0) as total_lines_added,
+    COALESCE(SUM(m.lines_removed), 0) as total_lines_removed,
+    COALESCE(SUM(m.compaction_count), 0) as total_compactions,
+    COALESCE(SUM(m.token_cache_wasted), 0) as total_token_cache_wasted
 FROM sessions s
 LEFT JOIN session_metrics m ON s.id = m.session_id
 WHERE s.created_at >= ?;
@@ -58,7 +91,11 @@ SELECT
     COALESCE(SUM(m.token_cache_read), 0) as total_token_cache_read,
     COALESCE(SUM(m.token_cache_write), 0) as total_token_cache_write,
     COALESCE(SUM(m.cost_estimate_usd), 0) as total_cost_usd,
-    COALESCE(SUM(m.error_count), 0) as total_errors
+    COALESCE(SUM(m.error_count), 0) as total_errors,
+    COALESCE(SUM(m.lines_added), 0) as total_lines_added,
+    COALESCE(SUM(m.lines_removed), 0) as total_lines_removed,
+    COALESCE(SUM(m.compaction_count), 0) as total_compactions,
+    COALESCE(SUM(m.token_cache_wasted), 0) as total_token_cache_wasted
 FROM sessions s
 LEFT JOIN session_metrics m ON s.id = m.session_id
 WHERE s.experiment_id = ? AND s.created_at >= ?;
@@ -74,7 +111,11 @@ SELECT
     COALESCE(SUM(m.token_cache_read), 0) as total_token_cache_read,
     COALESCE(SUM(m.token_cache_write), 0) as total_token_cache_write,
     COALESCE(SUM(m.cost_estimate_usd), 0) as total_cost_usd,
-    COALESCE(SUM(m.error_count), 0) as total_errors
+    COALESCE(SUM(m.error_count), 0) as total_errors,
+    COALESCE(SUM(m.lines_added), 0) as total_lines_added,
+    COALESCE(SUM(m.lines_removed), 0) as total_lines_removed,
+    COALESCE(SUM(m.compaction_count), 0) as total_compactions,
+    COALESCE(SUM(m.token_cache_wasted), 0) as total_token_cache_wasted
 FROM sessions s
 LEFT JOIN session_metrics m ON s.id = m.session_id
 WHERE s.project_id = ? AND s.created_at >= ?;
@@ -83,7 +124,9 @@ WHERE s.project_id = ? AND s.created_at >= ?;
 SELECT
     tool_name,
     SUM(invocation_count) as total_invocations,
-    SUM(error_count) as total_errors
+    SUM(error_count) as total_errors,
+    SUM(total_duration_ms) as total_duration_ms,
+    SUM(CASE WHEN total_duration_ms IS NOT NULL THEN invocation_count ELSE 0 END) as timed_invocations
 FROM session_tools st
 JOIN sessions s ON st.session_id = s.id
 WHERE s.created_at >= ?
@@ -125,12 +168,121 @@ GROUP BY e.id, e.name
 ORDER BY e.created_at DESC;
 
 -- name: CreateSessionSubagent :exec
-INSERT INTO session_subagents (session_id, agent_type, agent_kind, description, model, total_tokens, token_input, token_output, token_cache_read, token_cache_write, total_duration_ms, tool_use_count, cost_estimate_usd)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+INSERT INTO session_subagents (session_id, agent_type, agent_kind, description, model, total_tokens, token_input, token_output, token_cache_read, token_cache_write, total_duration_ms, tool_use_count, cost_estimate_usd, tool_use_id, agent_id, parent_tool_use_id, error_count, model_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: ListSessionSubagentsBySessionID :many
 SELECT * FROM session_subagents WHERE session_id = ? ORDER BY id ASC;
 
+-- name: DeleteSessionSubagents :exec
+DELETE FROM session_subagents WHERE session_id = ?;
+
+-- name: CreateSessionSubagentTool :exec
+INSERT INTO session_subagent_tools (session_id, subagent_tool_use_id, tool_name, invocation_count, error_count, total_duration_ms)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ListSessionSubagentToolsBySessionID :many
+SELECT * FROM session_subagent_tools WHERE session_id = ? ORDER BY invocation_count DESC, tool_name ASC;
+
+-- name: DeleteSessionSubagentTools :exec
+DELETE FROM session_subagent_tools WHERE session_id = ?;
+
+-- name: CreateSessionSubagentFile :exec
+INSERT INTO session_subagent_files (session_id, subagent_tool_use_id, file_path, operation, operation_count, lines_added, lines_removed)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListSessionSubagentFilesBySessionID :many
+SELECT * FROM session_subagent_files WHERE session_id = ? ORDER BY file_path ASC;
+
+-- name: DeleteSessionSubagentFiles :exec
+DELETE FROM session_subagent_files WHERE session_id = ?;
+
+-- name: UpdateSessionSubagentCost :exec
+UPDATE session_subagents SET cost_estimate_usd = ? WHERE id = ?;
+
+-- name: CreateSessionTurn :exec
+INSERT INTO session_turns (session_id, turn_index, prompt, started_at, ended_at, duration_ms, message_count, tool_calls, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListSessionTurnsBySessionID :many
+SELECT * FROM session_turns WHERE session_id = ? ORDER BY turn_index ASC;
+
+-- name: DeleteSessionTurns :exec
+DELETE FROM session_turns WHERE session_id = ?;
+
+-- name: UpdateSessionTurnCost :exec
+UPDATE session_turns SET cost_estimate_usd = ? WHERE session_id = ? AND turn_index = ?;
+
+-- name: CreateSessionSlashCommand :exec
+INSERT INTO session_slash_commands (session_id, command, kind, invocation_count)
+VALUES (?, ?, ?, ?);
+
+-- name: ListSessionSlashCommandsBySessionID :many
+SELECT * FROM session_slash_commands WHERE session_id = ? ORDER BY invocation_count DESC, command ASC;
+
+-- name: DeleteSessionSlashCommands :exec
+DELETE FROM session_slash_commands WHERE session_id = ?;
+
+-- name: CreateSessionHook :exec
+INSERT INTO session_hooks (session_id, event, hook_name, command, execution_count, error_count, blocked_count)
+VALUES (?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListSessionHooksBySessionID :many
+SELECT * FROM session_hooks WHERE session_id = ? ORDER BY execution_count DESC, hook_name ASC;
+
+-- name: DeleteSessionHooks :exec
+DELETE FROM session_hooks WHERE session_id = ?;
+
+-- name: CreateSessionParseAnomaly :exec
+INSERT INTO session_parse_anomalies (session_id, kind, detail, occurrence_count, first_line)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: ListSessionParseAnomaliesBySessionID :many
+SELECT * FROM session_parse_anomalies WHERE session_id = ? ORDER BY first_line ASC;
+
+-- name: DeleteSessionParseAnomalies :exec
+DELETE FROM session_parse_anomalies WHERE session_id = ?;
+
+-- name: GetSlashCommandUsage :many
+SELECT
+    sc.command,
+    sc.kind,
+    COUNT(DISTINCT sc.session_id) as session_count,
+    SUM(sc.invocation_count) as total_invocations,
+    CAST(MAX(s.created_at) AS TEXT) as last_used_at
+FROM session_slash_commands sc
+JOIN sessions s ON sc.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY sc.command, sc.kind
+ORDER BY total_invocations DESC, sc.command ASC;
+
+-- name: GetSkillUsage :many
+SELECT
+    sa.agent_type as skill,
+    COUNT(DISTINCT sa.session_id) as session_count,
+    COUNT(*) as total_invocations,
+    CAST(MAX(s.created_at) AS TEXT) as last_used_at
+FROM session_subagents sa
+JOIN sessions s ON sa.session_id = s.id
+WHERE s.created_at >= ? AND sa.agent_kind = 'skill'
+GROUP BY sa.agent_type
+ORDER BY total_invocations DESC, sa.agent_type ASC;
+
+-- name: GetHookUsage :many
+SELECT
+    h.event,
+    h.hook_name,
+    h.command,
+    COUNT(DISTINCT h.session_id) as session_count,
+    SUM(h.execution_count) as total_executions,
+    SUM(h.error_count) as total_errors,
+    SUM(h.blocked_count) as total_blocked
+FROM session_hooks h
+JOIN sessions s ON h.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY h.event, h.hook_name, h.command
+ORDER BY total_executions DESC, h.hook_name ASC;
+
 -- name: GetSubagentStatsBySession :many
 SELECT
     agent_type,
@@ -161,10 +313,195 @@ LIMIT ?;
 SELECT
     tool_name,
     SUM(invocation_count) as total_invocations,
-    SUM(error_count) as total_errors
+    SUM(error_count) as total_errors,
+    SUM(total_duration_ms) as total_duration_ms,
+    SUM(CASE WHEN total_duration_ms IS NOT NULL THEN invocation_count ELSE 0 END) as timed_invocations
 FROM session_tools st
 JOIN sessions s ON st.session_id = s.id
 WHERE s.experiment_id = ?
 GROUP BY tool_name
 ORDER BY total_invocations DESC
 LIMIT ?;
+
+-- name: GetLineChangesByLanguageByExperiment :many
+SELECT
+    sf.language,
+    COUNT(DISTINCT sf.file_path) as file_count,
+    SUM(sf.lines_added) as total_lines_added,
+    SUM(sf.lines_removed) as total_lines_removed
+FROM session_files sf
+JOIN sessions s ON sf.session_id = s.id
+WHERE s.experiment_id = ? AND s.created_at >= ? AND sf.language IS NOT NULL
+    AND (sf.lines_added > 0 OR sf.lines_removed > 0)
+GROUP BY sf.language
+ORDER BY total_lines_added + total_lines_removed DESC;
+
+-- name: GetMCPServerUsage :many
+SELECT
+    st.mcp_server,
+    COUNT(DISTINCT st.mcp_tool) as tool_count,
+    COUNT(DISTINCT st.session_id) as session_count,
+    SUM(st.invocation_count) as total_invocations,
+    SUM(st.error_count) as total_errors,
+    SUM(st.result_tokens) as total_result_tokens
+FROM session_tools st
+JOIN sessions s ON st.session_id = s.id
+WHERE s.created_at >= ? AND st.mcp_server IS NOT NULL
+GROUP BY st.mcp_server
+ORDER BY total_invocations DESC;
+
+-- name: GetMCPServerUsageByExperiment :many
+SELECT
+    st.mcp_server,
+    COUNT(DISTINCT st.mcp_tool) as tool_count,
+    COUNT(DISTINCT st.session_id) as session_count,
+    SUM(st.invocation_count) as total_invocations,
+    SUM(st.error_count) as total_errors,
+    SUM(st.result_tokens) as total_result_tokens
+FROM session_tools st
+JOIN sessions s ON st.session_id = s.id
+WHERE s.experiment_id = ? AND s.created_at >= ? AND st.mcp_server IS NOT NULL
+GROUP BY st.mcp_server
+ORDER BY total_invocations DESC;
+
+-- name: GetMCPToolUsage :many
+SELECT
+    st.mcp_server,
+    st.mcp_tool,
+    SUM(st.invocation_count) as total_invocations,
+    SUM(st.error_count) as total_errors,
+    SUM(st.result_tokens) as total_result_tokens
+FROM session_tools st
+JOIN sessions s ON st.session_id = s.id
+WHERE s.created_at >= ? AND st.mcp_server IS NOT NULL
+GROUP BY st.mcp_server, st.mcp_tool
+ORDER BY st.mcp_server ASC, total_invocations DESC;
+
+-- name: GetMCPToolUsageByExperiment :many
+SELECT
+    st.mcp_server,
+    st.mcp_tool,
+    SUM(st.invocation_count) as total_invocations,
+    SUM(st.error_count) as total_errors,
+    SUM(st.result_tokens) as total_result_tokens
+FROM session_tools st
+JOIN sessions s ON st.session_id = s.id
+WHERE s.experiment_id = ? AND s.created_at >= ? AND st.mcp_server IS NOT NULL
+GROUP BY st.mcp_server, st.mcp_tool
+ORDER BY st.mcp_server ASC, total_invocations DESC;
+
+-- name: GetSlowestCommands :many
+SELECT
+    command,
+    COUNT(*) as run_count,
+    SUM(CASE WHEN sc.status = 'failed' THEN 1 ELSE 0 END) as failure_count,
+    AVG(sc.duration_ms) as avg_duration_ms,
+    MAX(sc.duration_ms) as max_duration_ms
+FROM session_commands sc
+JOIN sessions s ON sc.session_id = s.id
+WHERE s.created_at >= ? AND sc.duration_ms IS NOT NULL
+GROUP BY command
+ORDER BY avg_duration_ms DESC
+LIMIT ?;
+
+-- name: GetMostFailingCommands :many
+SELECT
+    command,
+    COUNT(*) as run_count,
+    SUM(CASE WHEN sc.status = 'failed' THEN 1 ELSE 0 END) as failure_count,
+    AVG(sc.duration_ms) as avg_duration_ms,
+    MAX(sc.duration_ms) as max_duration_ms
+FROM session_commands sc
+JOIN sessions s ON sc.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY command
+HAVING failure_count > 0
+ORDER BY failure_count DESC, run_count DESC
+LIMIT ?;
+
+-- name: CreateSessionModelUsage :exec
+INSERT INTO session_model_usage (session_id, model_id, message_count, token_input, token_output, token_cache_read, token_cache_write, cost_estimate_usd)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (session_id, model_id) DO UPDATE SET
+    message_count = excluded.message_count,
+    token_input = excluded.token_input,
+    token_output = excluded.token_output,
+    token_cache_read = excluded.token_cache_read,
+    token_cache_write = excluded.token_cache_write,
+    cost_estimate_usd = excluded.cost_estimate_usd;
+
+-- name: ListSessionModelUsageBySessionID :many
+SELECT * FROM session_model_usage WHERE session_id = ? ORDER BY id ASC;
+
+-- name: DeleteSessionModelUsage :exec
+DELETE FROM session_model_usage WHERE session_id = ?;
+
+-- name: GetModelUsageStats :many
+SELECT
+    mu.model_id,
+    COUNT(DISTINCT mu.session_id) as session_count,
+    COALESCE(SUM(mu.message_count), 0) as total_messages,
+    COALESCE(SUM(mu.token_input), 0) as total_token_input,
+    COALESCE(SUM(mu.token_output), 0) as total_token_output,
+    COALESCE(SUM(mu.token_cache_read), 0) as total_token_cache_read,
+    COALESCE(SUM(mu.token_cache_write), 0) as total_token_cache_write,
+    COALESCE(SUM(mu.cost_estimate_usd), 0) as total_cost_usd
+FROM session_model_usage mu
+JOIN sessions s ON mu.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY mu.model_id
+ORDER BY total_cost_usd DESC;
+
+-- name: ListSessionsForCostRecompute :many
+SELECT s.id, s.started_at, m.model_id, m.token_input, m.token_output, m.token_cache_read, m.token_cache_write, m.cost_estimate_usd, m.web_search_requests, m.web_fetch_requests
+FROM sessions s
+JOIN session_metrics m ON s.id = m.session_id
+WHERE s.created_at >= sqlc.arg(since) AND s.created_at < sqlc.arg(until)
+  AND (sqlc.narg(project_id) IS NULL OR s.project_id = sqlc.narg(project_id))
+  AND (sqlc.narg(experiment_id) IS NULL OR s.experiment_id = sqlc.narg(experiment_id))
+  AND (sqlc.narg(model_id) IS NULL OR m.model_id = sqlc.narg(model_id) OR EXISTS (
+      SELECT 1 FROM session_model_usage mu WHERE mu.session_id = s.id AND mu.model_id = sqlc.narg(model_id)
+  ))
+ORDER BY s.created_at ASC;
+
+-- name: GetParseAnomalyUsage :many
+SELECT
+    a.kind,
+    a.detail,
+    COUNT(DISTINCT a.session_id) as session_count,
+    SUM(a.occurrence_count) as total_occurrences,
+    CAST(MIN(s.created_at) AS TEXT) as first_seen_at,
+    CAST(MAX(s.created_at) AS TEXT) as last_seen_at
+FROM session_parse_anomalies a
+JOIN sessions s ON a.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY a.kind, a.detail
+ORDER BY last_seen_at DESC, total_occurrences DESC;
+
+-- name: GetAnomalousSessions :many
+SELECT
+    s.id,
+    s.transcript_path,
+    CAST(s.created_at AS TEXT) as recorded_at,
+    sm.transcript_line_count,
+    sm.message_count_assistant,
+    sm.token_input + sm.token_output + sm.token_cache_read + sm.token_cache_write as total_tokens,
+    CAST(COALESCE(SUM(CASE WHEN a.kind = 'malformed_line' THEN a.occurrence_count END), 0) AS INTEGER) as malformed_lines,
+    CAST(COALESCE(SUM(CASE WHEN a.kind IN ('unknown_entry_type', 'unknown_content_type') THEN a.occurrence_count END), 0) AS INTEGER) as unknown_types,
+    CAST(COALESCE(SUM(CASE WHEN a.kind = 'missing_usage' THEN a.occurrence_count END), 0) AS INTEGER) as missing_usage
+FROM sessions s
+JOIN session_metrics sm ON sm.session_id = s.id
+LEFT JOIN session_parse_anomalies a ON a.session_id = s.id
+WHERE s.created_at >= ?
+GROUP BY s.id
+HAVING COUNT(a.id) > 0 OR (sm.message_count_assistant > 0 AND total_tokens = 0)
+ORDER BY s.created_at DESC
+LIMIT ?;
+
+-- name: GetParseDiagnosticsCoverage :one
+SELECT
+    COUNT(*) as session_count,
+    CAST(COALESCE(SUM(CASE WHEN sm.parser_version = 0 THEN 1 ELSE 0 END), 0) AS INTEGER) as unchecked_count
+FROM sessions s
+JOIN session_metrics sm ON sm.session_id = s.id
+WHERE s.created_at >= ?;
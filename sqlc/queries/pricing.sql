@@ -1,23 +1,51 @@
 -- name: CreateModelPricing :exec
-INSERT INTO model_pricing (id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, is_default, created_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+INSERT INTO model_pricing (id, display_name, input_per_million, output_per_million, cache_read_per_million, cache_write_per_million, long_context_input_per_million, long_context_output_per_million, long_context_threshold, web_search_per_thousand, web_fetch_per_thousand, is_default, created_at, effective_from, effective_to)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: GetModelPricingByID :one
-SELECT * FROM model_pricing WHERE id = ?;
+SELECT * FROM model_pricing WHERE id = ? AND effective_to IS NULL;
+
+-- name: GetModelPricingAt :one
+SELECT * FROM model_pricing
+WHERE id = sqlc.arg(id)
+  AND effective_from <= sqlc.arg(at)
+  AND (effective_to IS NULL OR effective_to > sqlc.arg(at))
+ORDER BY effective_from DESC
+LIMIT 1;
 
 -- name: GetDefaultModelPricing :one
-SELECT * FROM model_pricing WHERE is_default = 1 LIMIT 1;
+SELECT * FROM model_pricing WHERE is_default = 1 AND effective_to IS NULL LIMIT 1;
 
 -- name: ListModelPricing :many
-SELECT * FROM model_pricing ORDER BY display_name ASC;
+SELECT * FROM model_pricing WHERE effective_to IS NULL ORDER BY display_name ASC;
+
+-- name: ListModelPricingHistory :many
+SELECT * FROM model_pricing ORDER BY id ASC, effective_from DESC;
 
 -- name: UpdateModelPricing :exec
 UPDATE model_pricing
-SET display_name = ?, input_per_million = ?, output_per_million = ?, cache_read_per_million = ?, cache_write_per_million = ?, long_context_input_per_million = ?, long_context_output_per_million = ?, long_context_threshold = ?, is_default = ?
-WHERE id = ?;
+SET display_name = ?, input_per_million = ?, output_per_million = ?, cache_read_per_million = ?, cache_write_per_million = ?, long_context_input_per_million = ?, long_context_output_per_million = ?, long_context_threshold = ?, web_search_per_thousand = ?, web_fetch_per_thousand = ?, is_default = ?
+WHERE id = ? AND effective_to IS NULL;
+
+-- name: CloseModelPricing :exec
+UPDATE model_pricing SET effective_to = ? WHERE id = ? AND effective_to IS NULL;
 
 -- name: SetDefaultModelPricing :exec
-UPDATE model_pricing SET is_default = CASE WHEN id = ? THEN 1 ELSE 0 END;
+UPDATE model_pricing SET is_default = CASE WHEN id = ? THEN 1 ELSE 0 END WHERE effective_to IS NULL;
 
 -- name: DeleteModelPricing :exec
 DELETE FROM model_pricing WHERE id = ?;
+
+-- name: UpsertModelAlias :exec
+INSERT INTO model_aliases (alias, model_id, created_at)
+VALUES (?, ?, ?)
+ON CONFLICT(alias) DO UPDATE SET model_id = excluded.model_id;
+
+-- name: GetModelAlias :one
+SELECT * FROM model_aliases WHERE alias = ?;
+
+-- name: ListModelAliases :many
+SELECT * FROM model_aliases ORDER BY alias ASC;
+
+-- name: DeleteModelAlias :exec
+DELETE FROM model_aliases WHERE alias = ?;
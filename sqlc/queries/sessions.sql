@@ -1,6 +1,22 @@
 -- name: CreateSession :exec
-INSERT OR REPLACE INTO sessions (id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+INSERT INTO sessions (id, project_id, experiment_id, transcript_path, transcript_stored_path, cwd, permission_mode, exit_reason, started_at, ended_at, duration_seconds, created_at, git_branch, git_head_before, git_head_after, git_dirty)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    project_id = excluded.project_id,
+    experiment_id = excluded.experiment_id,
+    transcript_path = excluded.transcript_path,
+    transcript_stored_path = excluded.transcript_stored_path,
+    cwd = excluded.cwd,
+    permission_mode = excluded.permission_mode,
+    exit_reason = excluded.exit_reason,
+    started_at = excluded.started_at,
+    ended_at = excluded.ended_at,
+    duration_seconds = excluded.duration_seconds,
+    created_at = excluded.created_at,
+    git_branch = excluded.git_branch,
+    git_head_before = excluded.git_head_before,
+    git_head_after = excluded.git_head_after,
+    git_dirty = excluded.git_dirty;
 
 -- name: GetSessionByID :one
 SELECT * FROM sessions WHERE id = ?;
@@ -22,6 +38,17 @@ WHERE experiment_id = ?
 ORDER BY created_at DESC
 LIMIT ?;
 
+-- name: ListSessionsByBranch :many
+SELECT * FROM sessions
+WHERE git_branch = ?
+ORDER BY created_at DESC
+LIMIT ?;
+
+-- name: ListGitBranches :many
+SELECT DISTINCT git_branch FROM sessions
+WHERE git_branch IS NOT NULL
+ORDER BY git_branch;
+
 -- name: DeleteSession :exec
 DELETE FROM sessions WHERE id = ?;
 
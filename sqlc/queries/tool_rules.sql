@@ -0,0 +1,15 @@
+-- name: UpsertToolRule :exec
+INSERT INTO tool_rules (tool, operation, fields, created_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(tool) DO UPDATE SET
+    operation = excluded.operation,
+    fields = excluded.fields;
+
+-- name: GetToolRule :one
+SELECT * FROM tool_rules WHERE tool = ?;
+
+-- name: ListToolRules :many
+SELECT * FROM tool_rules ORDER BY tool ASC;
+
+-- name: DeleteToolRule :exec
+DELETE FROM tool_rules WHERE tool = ?;
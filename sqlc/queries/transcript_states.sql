@@ -0,0 +1,25 @@
+-- name: GetTranscriptState :one
+SELECT * FROM transcript_states WHERE session_id = ?;
+
+-- name: SaveTranscriptState :exec
+INSERT INTO transcript_states (session_id, transcript_path, byte_offset, state, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (session_id) DO UPDATE SET
+    transcript_path = excluded.transcript_path,
+    byte_offset = excluded.byte_offset,
+    state = excluded.state,
+    updated_at = excluded.updated_at;
+
+-- name: DeleteTranscriptState :exec
+DELETE FROM transcript_states WHERE session_id = ?;
+
+-- name: DeleteOrphanedTranscriptStates :exec
+DELETE FROM transcript_states
+WHERE session_id NOT IN (SELECT id FROM sessions)
+  AND session_id NOT IN (SELECT session_id FROM live_sessions);
+
+-- name: DeleteStaleTranscriptStates :exec
+DELETE FROM transcript_states
+WHERE datetime(updated_at) < datetime(sqlc.arg(before))
+  AND session_id NOT IN (SELECT id FROM sessions)
+  AND session_id NOT IN (SELECT session_id FROM live_sessions);
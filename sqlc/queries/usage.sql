@@ -1,10 +1,21 @@
 -- name: GetRollingWindowUsage :one
 SELECT
-    CAST(COALESCE(SUM(m.token_input + m.token_output + m.token_cache_read + m.token_cache_write), 0) AS REAL) as total_tokens,
-    CAST(COALESCE(SUM(m.cost_estimate_usd), 0) AS REAL) as total_cost
-FROM sessions s
-JOIN session_metrics m ON s.id = m.session_id
-WHERE datetime(s.started_at) >= datetime('now', ? || ' hours');
+    CAST(COALESCE(SUM(u.tokens), 0) AS REAL) as total_tokens,
+    CAST(COALESCE(SUM(u.cost), 0) AS REAL) as total_cost,
+    CAST(COALESCE(SUM(CASE WHEN u.live = 1 THEN u.tokens ELSE 0 END), 0) AS REAL) as live_tokens,
+    CAST(COALESCE(SUM(u.live), 0) AS INTEGER) as live_sessions
+FROM (
+    SELECT m.token_input + m.token_output + m.token_cache_read + m.token_cache_write as tokens,
+        m.cost_estimate_usd as cost, s.started_at, 0 as live
+    FROM sessions s
+    JOIN session_metrics m ON s.id = m.session_id
+    WHERE s.id NOT IN (SELECT session_id FROM live_sessions)
+    UNION ALL
+    SELECT l.token_input + l.token_output + l.token_cache_read + l.token_cache_write as tokens,
+        l.cost_estimate_usd as cost, l.started_at, 1 as live
+    FROM live_sessions l
+) u
+WHERE datetime(u.started_at) >= datetime('now', ? || ' hours');
 
 -- name: UpdateWindowStartTime :exec
 UPDATE plan_config SET window_start_time = ?, updated_at = datetime('now') WHERE id = 1;
@@ -29,11 +40,22 @@ WHERE id = 1;
 
 -- name: GetWeeklyWindowUsage :one
 SELECT
-    CAST(COALESCE(SUM(m.token_input + m.token_output + m.token_cache_read + m.token_cache_write), 0) AS REAL) as total_tokens,
-    CAST(COALESCE(SUM(m.cost_estimate_usd), 0) AS REAL) as total_cost
-FROM sessions s
-JOIN session_metrics m ON s.id = m.session_id
-WHERE datetime(s.started_at) >= datetime('now', '-168 hours');
+    CAST(COALESCE(SUM(u.tokens), 0) AS REAL) as total_tokens,
+    CAST(COALESCE(SUM(u.cost), 0) AS REAL) as total_cost,
+    CAST(COALESCE(SUM(CASE WHEN u.live = 1 THEN u.tokens ELSE 0 END), 0) AS REAL) as live_tokens,
+    CAST(COALESCE(SUM(u.live), 0) AS INTEGER) as live_sessions
+FROM (
+    SELECT m.token_input + m.token_output + m.token_cache_read + m.token_cache_write as tokens,
+        m.cost_estimate_usd as cost, s.started_at, 0 as live
+    FROM sessions s
+    JOIN session_metrics m ON s.id = m.session_id
+    WHERE s.id NOT IN (SELECT session_id FROM live_sessions)
+    UNION ALL
+    SELECT l.token_input + l.token_output + l.token_cache_read + l.token_cache_write as tokens,
+        l.cost_estimate_usd as cost, l.started_at, 1 as live
+    FROM live_sessions l
+) u
+WHERE datetime(u.started_at) >= datetime('now', '-168 hours');
 
 -- name: UpdateWeeklyWindowStartTime :exec
 UPDATE plan_config SET weekly_window_start_time = ?, updated_at = datetime('now') WHERE id = 1;